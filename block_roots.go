@@ -0,0 +1,94 @@
+package ssz
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// BlockRoots decodes serialized objects, typically the blocks of an epoch or of a
+// backfill batch, and returns their hash tree roots in order. The objects are decoded
+// into new values of the type of prototype, which is usually a nil pointer to that type:
+//
+//  roots, err := ssz.BlockRoots(encodedBlocks, (*SignedBeaconBlock)(nil), 0)
+//  if err != nil {
+//      return errors.Wrap(err, "could not compute block roots")
+//  }
+//
+// The work is spread over up to workers goroutines, or one per CPU when workers is not
+// positive. Every worker reuses the scratch buffers of its own Hasher across objects,
+// and identical encodings, such as duplicates from overlapping batches, are decoded and
+// hashed once. The first error met, in the order of the objects, is returned.
+func BlockRoots(encoded [][]byte, prototype interface{}, workers int) ([][32]byte, error) {
+	if prototype == nil {
+		return nil, errors.New("untyped nil is not supported")
+	}
+	typ := reflect.TypeOf(prototype)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	// Objects with the same encoding share the root of their first occurrence.
+	first := make([]int, len(encoded))
+	seen := make(map[string]int, len(encoded))
+	jobs := make([]int, 0, len(encoded))
+	for i, enc := range encoded {
+		if j, ok := seen[string(enc)]; ok {
+			first[i] = j
+			continue
+		}
+		seen[string(enc)] = i
+		first[i] = i
+		jobs = append(jobs, i)
+	}
+	if workers > len(jobs) {
+		workers = len(jobs)
+	}
+
+	roots := make([][32]byte, len(encoded))
+	errs := make([]error, len(encoded))
+	queue := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			h := &Hasher{}
+			for i := range queue {
+				roots[i], errs[i] = blockRoot(encoded[i], typ, h)
+			}
+		}()
+	}
+	for _, i := range jobs {
+		queue <- i
+	}
+	close(queue)
+	wg.Wait()
+	for i := range encoded {
+		if err := errs[first[i]]; err != nil {
+			return nil, errors.Wrapf(err, "object %d", i)
+		}
+		roots[i] = roots[first[i]]
+	}
+	return roots, nil
+}
+
+// blockRoot decodes and hashes a single object. Panics on malformed input are turned
+// into errors, as they cannot be recovered by the caller from a worker goroutine.
+func blockRoot(enc []byte, typ reflect.Type, h *Hasher) (root [32]byte, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("could not decode %v: %v", typ, r)
+		}
+	}()
+	obj := reflect.New(typ)
+	if err := Unmarshal(enc, obj.Interface()); err != nil {
+		return [32]byte{}, err
+	}
+	return HashTreeRootWith(obj.Interface(), h)
+}
@@ -0,0 +1,29 @@
+// Ssz hashes, encodes and decodes files holding the SSZ values of the types defined by
+// this repository, such as deposit tree snapshots and state diffs:
+//
+//  ssz root --type DepositTreeSnapshot snapshot.ssz
+//  ssz decode --type StateDiff diff.ssz
+//  ssz encode --type DepositTreeSnapshot -o snapshot.ssz snapshot.json
+//
+// Projects build their own command registering their types with package sszcli.
+package main
+
+import (
+	"fmt"
+	"os"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/deposittree"
+	"github.com/prysmaticlabs/go-ssz/sszcli"
+)
+
+func main() {
+	sszcli.Register("Bytes32", (*[32]byte)(nil))
+	sszcli.Register("DepositTreeSnapshot", (*deposittree.Snapshot)(nil))
+	sszcli.Register("StateDiff", (*ssz.StateDiff)(nil))
+	sszcli.Register("Uint64", (*uint64)(nil))
+	if err := sszcli.Run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintf(os.Stderr, "ssz: %v\n", err)
+		os.Exit(1)
+	}
+}
@@ -0,0 +1,251 @@
+// Sszpath generates type-safe path builders for the SSZ containers declared in a
+// package, such that generalized indices and proof paths are written as
+//
+//  beaconstatepaths.Validators().At(5).ExitEpoch()
+//
+// rather than as strings which are only checked at runtime. Every container reachable
+// from the root type gets a path type with one method per exported field, and lists
+// and vectors of containers get a path type whose At method returns the path of the
+// element container. Other fields return a plain ssz.Path, and fields named Path are
+// only reachable through the Field method of ssz.Path. Usage:
+//
+//  sszpath -type BeaconState -import github.com/user/beacon/types -package beaconstatepaths -o paths.go ./types
+//
+// When -import is empty, the code is generated for the package declaring the types.
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"go/ast"
+	"go/format"
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"log"
+	"os"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	typeName := flag.String("type", "", "name of the root container type")
+	importPath := flag.String("import", "", "import path of the package declaring the types, if it differs from the output package")
+	pkgName := flag.String("package", "", "name of the generated package, defaults to the package declaring the types")
+	out := flag.String("o", "", "output file, defaults to standard output")
+	flag.Parse()
+	if *typeName == "" {
+		log.Fatal("sszpath: -type is required")
+	}
+	dir := "."
+	if flag.NArg() > 0 {
+		dir = flag.Arg(0)
+	}
+	src, err := generate(dir, *typeName, *importPath, *pkgName)
+	if err != nil {
+		log.Fatalf("sszpath: %v", err)
+	}
+	if *out == "" {
+		if _, err := os.Stdout.Write(src); err != nil {
+			log.Fatalf("sszpath: %v", err)
+		}
+		return
+	}
+	if err := ioutil.WriteFile(*out, src, 0644); err != nil {
+		log.Fatalf("sszpath: %v", err)
+	}
+}
+
+// generate parses the Go files of dir and returns the source of the path builders of
+// the containers reachable from the root type.
+func generate(dir string, rootType string, importPath string, pkgName string) ([]byte, error) {
+	pkgs, err := parser.ParseDir(token.NewFileSet(), dir, func(info os.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	if err != nil {
+		return nil, err
+	}
+	if len(pkgs) != 1 {
+		return nil, fmt.Errorf("expected a single package in %s, found %d", dir, len(pkgs))
+	}
+	var typesPkg string
+	structs := make(map[string]*ast.StructType)
+	for name, pkg := range pkgs {
+		typesPkg = name
+		for _, file := range pkg.Files {
+			for _, decl := range file.Decls {
+				gen, ok := decl.(*ast.GenDecl)
+				if !ok || gen.Tok != token.TYPE {
+					continue
+				}
+				for _, spec := range gen.Specs {
+					ts := spec.(*ast.TypeSpec)
+					if st, ok := ts.Type.(*ast.StructType); ok {
+						structs[ts.Name.Name] = st
+					}
+				}
+			}
+		}
+	}
+	if _, ok := structs[rootType]; !ok {
+		return nil, fmt.Errorf("no struct type %s in %s", rootType, dir)
+	}
+	g := &generator{structs: structs, lists: make(map[string]bool), visited: make(map[string]bool)}
+	if importPath != "" {
+		g.qualifier = typesPkg + "."
+	}
+	if pkgName == "" {
+		pkgName = typesPkg
+	}
+	g.visit(rootType)
+
+	fmt.Fprintf(&g.buf, "// Code generated by sszpath. DO NOT EDIT.\n\npackage %s\n\n", pkgName)
+	fmt.Fprintf(&g.buf, "import (\n\tssz %q\n", "github.com/prysmaticlabs/go-ssz")
+	if importPath != "" {
+		fmt.Fprintf(&g.buf, "\t%s %q\n", typesPkg, importPath)
+	}
+	fmt.Fprintf(&g.buf, ")\n\n")
+	fmt.Fprintf(&g.buf, "// New%sPath returns the path to the root of a %s.\n", rootType, rootType)
+	fmt.Fprintf(&g.buf, "func New%sPath() %sPath {\n\treturn %sPath{ssz.NewPath((*%s%s)(nil))}\n}\n\n", rootType, rootType, rootType, g.qualifier, rootType)
+	if importPath != "" {
+		// In a dedicated package, the fields of the root type are reachable from
+		// top-level functions.
+		for _, f := range g.fields(rootType) {
+			fmt.Fprintf(&g.buf, "// %s returns the path to the %s field of a %s.\n", f.name, f.name, rootType)
+			fmt.Fprintf(&g.buf, "func %s() %s {\n\treturn New%sPath().%s()\n}\n\n", f.name, f.pathType(), rootType, f.name)
+		}
+	}
+	names := make([]string, 0, len(g.visited))
+	for name := range g.visited {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		g.writeStruct(name)
+	}
+	lists := make([]string, 0, len(g.lists))
+	for name := range g.lists {
+		lists = append(lists, name)
+	}
+	sort.Strings(lists)
+	for _, name := range lists {
+		fmt.Fprintf(&g.buf, "// %sListPath is a path to a list or vector of %s.\n", name, name)
+		fmt.Fprintf(&g.buf, "type %sListPath struct {\n\tssz.Path\n}\n\n", name)
+		fmt.Fprintf(&g.buf, "// At returns the path to the element at index i.\n")
+		fmt.Fprintf(&g.buf, "func (p %sListPath) At(i uint64) %sPath {\n\treturn %sPath{p.Path.At(i)}\n}\n\n", name, name, name)
+	}
+	return format.Source(g.buf.Bytes())
+}
+
+type generator struct {
+	buf       bytes.Buffer
+	structs   map[string]*ast.StructType
+	qualifier string
+	// visited holds the containers to generate a path type for, and lists the element
+	// containers of lists and vectors.
+	visited map[string]bool
+	lists   map[string]bool
+}
+
+type pathField struct {
+	name string
+	// container is the name of the container the field holds, or the element container
+	// of the list or vector it holds, and is empty for other fields.
+	container string
+	list      bool
+}
+
+// pathType returns the name of the type of the path to the field.
+func (f pathField) pathType() string {
+	switch {
+	case f.container == "":
+		return "ssz.Path"
+	case f.list:
+		return f.container + "ListPath"
+	default:
+		return f.container + "Path"
+	}
+}
+
+// visit marks a container, along with the containers reachable from it, for
+// generation.
+func (g *generator) visit(name string) {
+	if g.visited[name] {
+		return
+	}
+	g.visited[name] = true
+	for _, f := range g.fields(name) {
+		if f.container == "" {
+			continue
+		}
+		if f.list {
+			g.lists[f.container] = true
+		}
+		g.visit(f.container)
+	}
+}
+
+// skipTagged reports whether a field is excluded from serialization with a ssz:"-" tag.
+func skipTagged(field *ast.Field) bool {
+	if field.Tag == nil {
+		return false
+	}
+	tag, err := strconv.Unquote(field.Tag.Value)
+	if err != nil {
+		return false
+	}
+	return reflect.StructTag(tag).Get("ssz") == "-"
+}
+
+// fields returns the exported fields of a container along with their path types.
+func (g *generator) fields(name string) []pathField {
+	var fields []pathField
+	for _, field := range g.structs[name].Fields.List {
+		for _, ident := range field.Names {
+			// A method named Path would clash with the embedded ssz.Path.
+			if !ident.IsExported() || strings.HasPrefix(ident.Name, "XXX_") || ident.Name == "Path" || skipTagged(field) {
+				continue
+			}
+			f := pathField{name: ident.Name}
+			if elem, ok := g.containerName(field.Type); ok {
+				f.container = elem
+			} else if seq, ok := field.Type.(*ast.ArrayType); ok {
+				if elem, ok := g.containerName(seq.Elt); ok {
+					f.container, f.list = elem, true
+				}
+			}
+			fields = append(fields, f)
+		}
+	}
+	return fields
+}
+
+// containerName returns the name of the container an expression refers to, directly
+// or through a pointer, if it is declared in the parsed package.
+func (g *generator) containerName(expr ast.Expr) (string, bool) {
+	if star, ok := expr.(*ast.StarExpr); ok {
+		expr = star.X
+	}
+	ident, ok := expr.(*ast.Ident)
+	if !ok {
+		return "", false
+	}
+	_, ok = g.structs[ident.Name]
+	return ident.Name, ok
+}
+
+func (g *generator) writeStruct(name string) {
+	fmt.Fprintf(&g.buf, "// %sPath is a path to a %s%s.\n", name, g.qualifier, name)
+	fmt.Fprintf(&g.buf, "type %sPath struct {\n\tssz.Path\n}\n\n", name)
+	for _, f := range g.fields(name) {
+		fmt.Fprintf(&g.buf, "// %s returns the path to the %s field.\n", f.name, f.name)
+		if f.container == "" {
+			fmt.Fprintf(&g.buf, "func (p %sPath) %s() ssz.Path {\n\treturn p.Path.Field(%q)\n}\n\n", name, f.name, f.name)
+			continue
+		}
+		fmt.Fprintf(&g.buf, "func (p %sPath) %s() %s {\n\treturn %s{p.Path.Field(%q)}\n}\n\n", name, f.name, f.pathType(), f.pathType(), f.name)
+	}
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"go/parser"
+	"go/token"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const testTypes = `package beacon
+
+type BeaconState struct {
+	Slot       uint64
+	Fork       *Fork
+	Validators []*Validator ` + "`ssz-max:\"1099511627776\"`" + `
+	Balances   []uint64     ` + "`ssz-max:\"1099511627776\"`" + `
+	internal   uint64
+}
+
+type Fork struct {
+	Epoch uint64
+}
+
+type Validator struct {
+	ExitEpoch uint64
+}
+`
+
+func TestGenerate(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sszpath")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	if err := ioutil.WriteFile(filepath.Join(dir, "types.go"), []byte(testTypes), 0644); err != nil {
+		t.Fatal(err)
+	}
+	src, err := generate(dir, "BeaconState", "example.com/beacon", "beaconstatepaths")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := parser.ParseFile(token.NewFileSet(), "paths.go", src, 0); err != nil {
+		t.Fatalf("Generated code does not parse: %v\n%s", err, src)
+	}
+	want := []string{
+		"package beaconstatepaths",
+		"ssz.NewPath((*beacon.BeaconState)(nil))",
+		"func Validators() ValidatorListPath {",
+		"func (p ValidatorListPath) At(i uint64) ValidatorPath {",
+		"func (p ValidatorPath) ExitEpoch() ssz.Path {",
+		"func (p BeaconStatePath) Fork() ForkPath {",
+		"func Balances() ssz.Path {",
+	}
+	for _, w := range want {
+		if !strings.Contains(string(src), w) {
+			t.Errorf("Expected generated code to contain %q:\n%s", w, src)
+		}
+	}
+	if strings.Contains(string(src), "internal") {
+		t.Error("Expected unexported fields to be skipped")
+	}
+	if _, err := generate(dir, "Missing", "", ""); err == nil {
+		t.Error("Expected error for a missing root type")
+	}
+}
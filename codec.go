@@ -0,0 +1,30 @@
+package ssz
+
+import (
+	"reflect"
+
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Codec holds hand-written functions encoding, decoding and hashing the values of a
+// type, see RegisterCodec.
+type Codec = types.Codec
+
+// RegisterCodec registers hand-written functions for the values of typ, which Marshal,
+// Unmarshal and HashTreeRoot then use instead of reflection wherever such values are
+// found, such as for performance-critical containers:
+//
+//  err := ssz.RegisterCodec(reflect.TypeOf(Validator{}), &ssz.Codec{
+//      MarshalTo:    marshalValidator,
+//      Unmarshal:    unmarshalValidator,
+//      HashTreeRoot: validatorRoot,
+//  })
+//  if err != nil {
+//      return errors.Wrap(err, "could not register validator codec")
+//  }
+//
+// Codecs are given a pointer to the value. A nil codec removes the codec of typ.
+// Registering fails once the configuration is locked with types.LockConfig.
+func RegisterCodec(typ reflect.Type, codec *Codec) error {
+	return types.RegisterCodec(typ, codec)
+}
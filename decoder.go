@@ -0,0 +1,133 @@
+package ssz
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// DefaultMaxDecodeSize is the maximum number of bytes a Decoder reads for a single
+// value unless configured otherwise.
+const DefaultMaxDecodeSize = uint64(1 << 30)
+
+type decodeConfig struct {
+	maxSize       uint64
+	strictOffsets bool
+	zeroCopy      bool
+}
+
+// DecodeOption configures the behavior of a Decoder or of a single call to Unmarshal:
+//
+//  if err := ssz.Unmarshal(data, block, ssz.WithMaxSize(1<<20), ssz.WithStrictOffsets()); err != nil {
+//      return errors.Wrap(err, "could not decode block")
+//  }
+type DecodeOption func(*decodeConfig)
+
+// WithMaxSize limits the number of bytes read for a single value to n, so that a peer
+// cannot make the decoder buffer an arbitrarily large payload. Unmarshal rejects input
+// longer than n.
+func WithMaxSize(n uint64) DecodeOption {
+	return func(c *decodeConfig) {
+		c.maxSize = n
+	}
+}
+
+// WithStrictOffsets decodes types with fastssz generated methods through reflection,
+// which validates every offset of the input, rather than by their UnmarshalSSZ method,
+// as code generated by older versions of fastssz only checks that offsets are within
+// bounds. It is meant for input from untrusted peers.
+func WithStrictOffsets() DecodeOption {
+	return func(c *decodeConfig) {
+		c.strictOffsets = true
+	}
+}
+
+// WithZeroCopy lets the byte slices of the decoded value, such as roots and signatures,
+// point into the input rather than into a copy of it, which saves copying the input.
+// The input must then not be modified, nor its buffer reused, while the decoded value
+// is in use. By default, Unmarshal decodes from a copy of the input, so that callers
+// can recycle their buffers, such as those of network reads, right after decoding.
+// A Decoder reads each value into a buffer of its own and never copies it again.
+func WithZeroCopy() DecodeOption {
+	return func(c *decodeConfig) {
+		c.zeroCopy = true
+	}
+}
+
+// Decoder reads SSZ encoded values from an input stream, such as a network connection
+// or a file handle.
+//
+//  dec := NewDecoder(conn, WithMaxSize(1<<20))
+//  block := &BeaconBlock{}
+//  if err := dec.Decode(block); err != nil {
+//      return fmt.Errorf("failed to decode: %v", err)
+//  }
+type Decoder struct {
+	r      io.Reader
+	config decodeConfig
+}
+
+// NewDecoder returns a new decoder reading from r.
+func NewDecoder(r io.Reader, opts ...DecodeOption) *Decoder {
+	d := &Decoder{
+		r:      r,
+		config: decodeConfig{maxSize: DefaultMaxDecodeSize},
+	}
+	for _, opt := range opts {
+		opt(&d.config)
+	}
+	return d
+}
+
+// Decode reads the next value from the stream and unmarshals it into the object pointed
+// to by val. Fixed-size values consume exactly their encoded size, so several of them can
+// be read from the same stream in a row, while variable-size values, which carry no
+// length prefix, consume the remainder of the stream.
+func (d *Decoder) Decode(val interface{}) error {
+	if val == nil {
+		return errors.New("cannot unmarshal into untyped, nil value")
+	}
+	rval := reflect.ValueOf(val)
+	if rval.Kind() != reflect.Ptr {
+		return errors.New("can only unmarshal into a pointer target")
+	}
+	if rval.IsNil() {
+		return errors.New("cannot output to pointer of nil value")
+	}
+	elemTyp := rval.Type().Elem()
+	if err := types.CheckDepth(elemTyp); err != nil {
+		return errors.Wrapf(err, "could not decode type: %v", elemTyp)
+	}
+	var input []byte
+	if !types.IsVariableSize(elemTyp) {
+		size := types.SizeOf(reflect.New(elemTyp).Elem(), elemTyp)
+		if size > d.config.maxSize {
+			return fmt.Errorf("encoded size %d of type %v exceeds the maximum of %d bytes", size, elemTyp, d.config.maxSize)
+		}
+		input = make([]byte, size)
+		if _, err := io.ReadFull(d.r, input); err != nil {
+			return errors.Wrapf(err, "could not read %d bytes for type: %v", size, elemTyp)
+		}
+	} else {
+		var err error
+		input, err = ioutil.ReadAll(io.LimitReader(d.r, int64(d.config.maxSize)+1))
+		if err != nil {
+			return errors.Wrapf(err, "could not read input for type: %v", elemTyp)
+		}
+		if uint64(len(input)) > d.config.maxSize {
+			return fmt.Errorf("input for type %v exceeds the maximum of %d bytes", elemTyp, d.config.maxSize)
+		}
+	}
+	// The input buffer is not shared with the caller.
+	c := d.config
+	c.zeroCopy = true
+	if err := unmarshal(input, val, &c); err != nil {
+		return err
+	}
+	reportDecoded(uint64(len(input)))
+	return nil
+}
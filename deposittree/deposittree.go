@@ -0,0 +1,225 @@
+// Package deposittree implements the incremental Merkle tree of the deposit contract,
+// in which deposit data roots are inserted one at a time, along with the snapshots of
+// EIP-4881 that let a node persist the tree without keeping every finalized deposit.
+package deposittree
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+
+	"github.com/minio/sha256-simd"
+)
+
+// Depth is the depth of the deposit contract tree, below its length mix-in.
+const Depth = 32
+
+// MaxDeposits is the number of deposits the tree can hold.
+const MaxDeposits = uint64(1)<<Depth - 1
+
+// zeroHashes holds the roots of all-zero subtrees of each height. The deposit contract
+// always hashes with sha256, whichever tree hash the ssz package is set to use.
+var zeroHashes [Depth + 1][32]byte
+
+func init() {
+	for i := 1; i <= Depth; i++ {
+		zeroHashes[i] = hashPair(zeroHashes[i-1], zeroHashes[i-1])
+	}
+}
+
+// Tree is the deposit contract tree. Deposits are inserted in order, and deposits
+// below the count of the last finalization are pruned, keeping only the roots of the
+// subtrees they fill:
+//
+//  t := deposittree.New()
+//  for _, d := range deposits {
+//      root, err := ssz.HashTreeRoot(d.Data)
+//      if err != nil {
+//          return err
+//      }
+//      if err := t.Insert(root); err != nil {
+//          return err
+//      }
+//  }
+//  proof, err := t.Proof(index)
+//
+// A Tree is not safe for concurrent use.
+type Tree struct {
+	// branch holds, at each height, the root of the last complete subtree of that
+	// height, as kept by the deposit contract.
+	branch [Depth][32]byte
+	count  uint64
+	// finalized holds the roots of the subtrees filled by pruned deposits, from the
+	// largest to the smallest, and leaves the deposits inserted after them.
+	finalized []subtree
+	pruned    uint64
+	leaves    [][32]byte
+	// executionBlockHash and executionBlockHeight locate the block of the execution
+	// chain at which the tree was last finalized.
+	executionBlockHash   [32]byte
+	executionBlockHeight uint64
+}
+
+// subtree is a complete subtree of the given height, at the given position among the
+// subtrees of that height.
+type subtree struct {
+	height uint8
+	index  uint64
+	root   [32]byte
+}
+
+// New returns an empty tree.
+func New() *Tree {
+	return &Tree{}
+}
+
+// Count returns the number of deposits inserted in the tree.
+func (t *Tree) Count() uint64 {
+	return t.count
+}
+
+// Insert adds the root of a deposit data at the end of the tree.
+func (t *Tree) Insert(leaf [32]byte) error {
+	if t.count >= MaxDeposits {
+		return errors.New("deposit tree is full")
+	}
+	t.leaves = append(t.leaves, leaf)
+	t.count++
+	node := leaf
+	size := t.count
+	for h := 0; h < Depth; h++ {
+		if size&1 == 1 {
+			t.branch[h] = node
+			break
+		}
+		node = hashPair(t.branch[h], node)
+		size >>= 1
+	}
+	return nil
+}
+
+// Root returns the deposit root, which mixes the deposit count into the root of the
+// tree as get_deposit_root of the deposit contract does.
+func (t *Tree) Root() [32]byte {
+	return depositRoot(&t.branch, t.count)
+}
+
+// Proof returns the branch of the deposit at the given index against Root, made of the
+// sibling roots from the leaf upwards followed by the deposit count, such that it is
+// checked by is_valid_merkle_branch with a depth of Depth+1. Pruned deposits cannot be
+// proven.
+func (t *Tree) Proof(index uint64) ([][32]byte, error) {
+	if index >= t.count {
+		return nil, fmt.Errorf("deposit %d out of range for %d deposits", index, t.count)
+	}
+	if index < t.pruned {
+		return nil, fmt.Errorf("deposit %d is pruned, the tree holds deposits from %d on", index, t.pruned)
+	}
+	proof := make([][32]byte, Depth+1)
+	for h := uint8(0); h < Depth; h++ {
+		sibling, err := t.node(h, index>>h^1, t.count)
+		if err != nil {
+			return nil, err
+		}
+		proof[h] = sibling
+	}
+	binary.LittleEndian.PutUint64(proof[Depth][:], t.count)
+	return proof, nil
+}
+
+// Finalize prunes the deposits below count, once the block of the execution chain of
+// the given hash and height holding them is finalized. The tree can then be persisted
+// as a Snapshot.
+func (t *Tree) Finalize(count uint64, executionBlockHash [32]byte, executionBlockHeight uint64) error {
+	if count > t.count {
+		return fmt.Errorf("cannot finalize %d deposits of a tree holding %d", count, t.count)
+	}
+	if count < t.pruned {
+		return fmt.Errorf("cannot finalize %d deposits, %d are already finalized", count, t.pruned)
+	}
+	finalized, err := t.subtrees(count)
+	if err != nil {
+		return err
+	}
+	t.leaves = append([][32]byte(nil), t.leaves[count-t.pruned:]...)
+	t.finalized = finalized
+	t.pruned = count
+	t.executionBlockHash = executionBlockHash
+	t.executionBlockHeight = executionBlockHeight
+	return nil
+}
+
+// subtrees returns the complete subtrees filled by the first count deposits, from the
+// largest to the smallest.
+func (t *Tree) subtrees(count uint64) ([]subtree, error) {
+	var subtrees []subtree
+	start := uint64(0)
+	for h := int(Depth) - 1; h >= 0; h-- {
+		if count>>uint(h)&1 == 0 {
+			continue
+		}
+		root, err := t.node(uint8(h), start>>uint(h), t.count)
+		if err != nil {
+			return nil, err
+		}
+		subtrees = append(subtrees, subtree{height: uint8(h), index: start >> uint(h), root: root})
+		start += uint64(1) << uint(h)
+	}
+	return subtrees, nil
+}
+
+// node returns the root of the subtree of the given height and position in the tree of
+// the first count deposits.
+func (t *Tree) node(height uint8, index uint64, count uint64) ([32]byte, error) {
+	start := index << height
+	if start >= count {
+		return zeroHashes[height], nil
+	}
+	end := start + uint64(1)<<height
+	for _, f := range t.finalized {
+		fStart := f.index << f.height
+		fEnd := fStart + uint64(1)<<f.height
+		if f.height == height && fStart == start {
+			return f.root, nil
+		}
+		if fStart <= start && end <= fEnd {
+			return [32]byte{}, fmt.Errorf("subtree of height %d at %d is pruned", height, index)
+		}
+	}
+	if height == 0 {
+		return t.leaves[start-t.pruned], nil
+	}
+	left, err := t.node(height-1, index*2, count)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	right, err := t.node(height-1, index*2+1, count)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashPair(left, right), nil
+}
+
+// depositRoot folds the branch of a tree of count deposits into the deposit root.
+func depositRoot(branch *[Depth][32]byte, count uint64) [32]byte {
+	var node [32]byte
+	size := count
+	for h := 0; h < Depth; h++ {
+		if size&1 == 1 {
+			node = hashPair(branch[h], node)
+		} else {
+			node = hashPair(node, zeroHashes[h])
+		}
+		size >>= 1
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], count)
+	return hashPair(node, length)
+}
+
+func hashPair(left [32]byte, right [32]byte) [32]byte {
+	var buf [64]byte
+	copy(buf[:32], left[:])
+	copy(buf[32:], right[:])
+	return sha256.Sum256(buf[:])
+}
@@ -0,0 +1,152 @@
+package deposittree_test
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/deposittree"
+	"github.com/prysmaticlabs/go-ssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+func leaf(i int) [32]byte {
+	var l [32]byte
+	l[0] = byte(i)
+	l[1] = byte(i >> 8)
+	l[31] = 0xdd
+	return l
+}
+
+// referenceRoot merkleizes every leaf inserted, as the hash tree root of a list of
+// roots of limit 2**32.
+func referenceRoot(t *testing.T, leaves [][32]byte) [32]byte {
+	node, err := tree.FromChunks(leaves, deposittree.Depth)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ssz.MixInLength(node.Root(), uint64(len(leaves)))
+}
+
+func TestTree_Root(t *testing.T) {
+	dt := deposittree.New()
+	// The deposit root of the empty deposit contract at mainnet genesis.
+	want, err := hex.DecodeString("d70a234731285c6804c2a4f56711ddb8c82c99740f207854891028af34e27e5e")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root := dt.Root(); !bytes.Equal(root[:], want) {
+		t.Fatalf("Wanted empty deposit root %#x, received %#x", want, root)
+	}
+	var leaves [][32]byte
+	for i := 0; i < 70; i++ {
+		leaves = append(leaves, leaf(i))
+		if err := dt.Insert(leaf(i)); err != nil {
+			t.Fatal(err)
+		}
+		if root, want := dt.Root(), referenceRoot(t, leaves); root != want {
+			t.Fatalf("After %d deposits: wanted root %#x, received %#x", i+1, want, root)
+		}
+	}
+	for i := uint64(0); i < dt.Count(); i++ {
+		proof, err := dt.Proof(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !merkle.IsValidMerkleBranch(leaf(int(i)), proof, deposittree.Depth+1, i, dt.Root()) {
+			t.Errorf("Proof of deposit %d does not verify", i)
+		}
+	}
+	if _, err := dt.Proof(70); err == nil {
+		t.Error("Expected an error for a deposit out of range")
+	}
+}
+
+func TestTree_FinalizeAndSnapshot(t *testing.T) {
+	dt := deposittree.New()
+	if _, err := dt.Snapshot(); err == nil {
+		t.Error("Expected an error for a snapshot of an unfinalized tree")
+	}
+	var leaves [][32]byte
+	for i := 0; i < 45; i++ {
+		leaves = append(leaves, leaf(i))
+		if err := dt.Insert(leaf(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var blockHash [32]byte
+	blockHash[0] = 0xbb
+	if err := dt.Finalize(27, blockHash, 100); err != nil {
+		t.Fatal(err)
+	}
+	if err := dt.Finalize(20, blockHash, 100); err == nil {
+		t.Error("Expected an error when finalizing fewer deposits")
+	}
+	if root, want := dt.Root(), referenceRoot(t, leaves); root != want {
+		t.Fatalf("Finalizing changed the root to %#x, wanted %#x", root, want)
+	}
+	if _, err := dt.Proof(26); err == nil {
+		t.Error("Expected an error for the proof of a pruned deposit")
+	}
+	for i := uint64(27); i < dt.Count(); i++ {
+		proof, err := dt.Proof(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !merkle.IsValidMerkleBranch(leaf(int(i)), proof, deposittree.Depth+1, i, dt.Root()) {
+			t.Errorf("Proof of deposit %d does not verify", i)
+		}
+	}
+
+	snapshot, err := dt.Snapshot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 27 deposits fill subtrees of 16, 8, 2 and 1 deposits.
+	if len(snapshot.Finalized) != 4 || snapshot.DepositCount != 27 || snapshot.ExecutionBlockHeight != 100 {
+		t.Fatalf("Unexpected snapshot %+v", snapshot)
+	}
+	if want := referenceRoot(t, leaves[:27]); !bytes.Equal(snapshot.DepositRoot, want[:]) {
+		t.Errorf("Wanted snapshot deposit root %#x, received %#x", want, snapshot.DepositRoot)
+	}
+	enc, err := ssz.Marshal(snapshot)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &deposittree.Snapshot{}
+	if err := ssz.Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	restored, err := deposittree.FromSnapshot(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root, want := restored.Root(), referenceRoot(t, leaves[:27]); root != want {
+		t.Fatalf("Restored tree has root %#x, wanted %#x", root, want)
+	}
+	for _, l := range leaves[27:] {
+		if err := restored.Insert(l); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if restored.Root() != dt.Root() {
+		t.Errorf("Restored tree has root %#x after new deposits, wanted %#x", restored.Root(), dt.Root())
+	}
+	proof, err := restored.Proof(40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merkle.IsValidMerkleBranch(leaf(40), proof, deposittree.Depth+1, 40, restored.Root()) {
+		t.Error("Proof of deposit 40 in the restored tree does not verify")
+	}
+
+	decoded.DepositRoot[0] ^= 1
+	if _, err := deposittree.FromSnapshot(decoded); err == nil {
+		t.Error("Expected an error for a snapshot with a wrong deposit root")
+	}
+	decoded.Finalized = decoded.Finalized[1:]
+	if _, err := deposittree.FromSnapshot(decoded); err == nil {
+		t.Error("Expected an error for a snapshot missing a finalized subtree")
+	}
+}
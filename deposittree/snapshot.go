@@ -0,0 +1,97 @@
+package deposittree
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"math/bits"
+)
+
+// Snapshot is the DepositTreeSnapshot of EIP-4881, holding the roots of the subtrees
+// filled by the finalized deposits. It is persisted with the ssz package:
+//
+//  snapshot, err := t.Snapshot()
+//  if err != nil {
+//      return err
+//  }
+//  enc, err := ssz.Marshal(snapshot)
+type Snapshot struct {
+	Finalized            [][]byte `ssz-size:"?,32" ssz-max:"32"`
+	DepositRoot          []byte   `ssz-size:"32"`
+	DepositCount         uint64
+	ExecutionBlockHash   []byte `ssz-size:"32"`
+	ExecutionBlockHeight uint64
+}
+
+// Snapshot returns the snapshot of the deposits of the last finalization.
+func (t *Tree) Snapshot() (*Snapshot, error) {
+	if len(t.finalized) == 0 {
+		return nil, errors.New("no deposit is finalized")
+	}
+	branch := finalizedBranch(t.finalized)
+	root := depositRoot(&branch, t.pruned)
+	s := &Snapshot{
+		Finalized:            make([][]byte, len(t.finalized)),
+		DepositRoot:          root[:],
+		DepositCount:         t.pruned,
+		ExecutionBlockHash:   append([]byte(nil), t.executionBlockHash[:]...),
+		ExecutionBlockHeight: t.executionBlockHeight,
+	}
+	for i, f := range t.finalized {
+		s.Finalized[i] = append([]byte(nil), f.root[:]...)
+	}
+	return s, nil
+}
+
+// FromSnapshot restores the tree persisted as a snapshot, once its deposit root is
+// checked against its finalized subtrees. Deposits made after the snapshot are then
+// inserted again.
+func FromSnapshot(s *Snapshot) (*Tree, error) {
+	if s == nil {
+		return nil, errors.New("nil snapshot")
+	}
+	if s.DepositCount > MaxDeposits {
+		return nil, fmt.Errorf("deposit count %d exceeds the maximum of %d", s.DepositCount, MaxDeposits)
+	}
+	if len(s.Finalized) != bits.OnesCount64(s.DepositCount) {
+		return nil, fmt.Errorf("%d deposits fill %d subtrees, snapshot holds %d", s.DepositCount, bits.OnesCount64(s.DepositCount), len(s.Finalized))
+	}
+	if len(s.DepositRoot) != 32 || len(s.ExecutionBlockHash) != 32 {
+		return nil, errors.New("snapshot roots must be 32 bytes long")
+	}
+	t := &Tree{
+		count:                s.DepositCount,
+		pruned:               s.DepositCount,
+		executionBlockHeight: s.ExecutionBlockHeight,
+	}
+	copy(t.executionBlockHash[:], s.ExecutionBlockHash)
+	start := uint64(0)
+	for h := int(Depth) - 1; h >= 0; h-- {
+		if s.DepositCount>>uint(h)&1 == 0 {
+			continue
+		}
+		f := s.Finalized[len(t.finalized)]
+		if len(f) != 32 {
+			return nil, fmt.Errorf("finalized root %d must be 32 bytes long, received %d", len(t.finalized), len(f))
+		}
+		sub := subtree{height: uint8(h), index: start >> uint(h)}
+		copy(sub.root[:], f)
+		t.finalized = append(t.finalized, sub)
+		start += uint64(1) << uint(h)
+	}
+	t.branch = finalizedBranch(t.finalized)
+	if root := t.Root(); !bytes.Equal(root[:], s.DepositRoot) {
+		return nil, fmt.Errorf("snapshot deposit root %#x does not match its finalized subtrees, computed %#x", s.DepositRoot, root)
+	}
+	return t, nil
+}
+
+// finalizedBranch returns the branch of the deposit contract of a tree holding the
+// finalized deposits only, where the subtree of each height is the complete one.
+func finalizedBranch(finalized []subtree) [Depth][32]byte {
+	var branch [Depth][32]byte
+	for _, f := range finalized {
+		branch[f.height] = f.root
+	}
+	return branch
+}
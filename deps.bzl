@@ -135,6 +135,32 @@ def go_ssz_dependencies():
         importpath = "github.com/cespare/xxhash",
     )
 
+    _maybe(
+        # MIT License
+        # https://github.com/ghodss/yaml/blob/master/LICENSE
+        go_repository,
+        name = "com_github_ghodss_yaml",
+        commit = "0ca9ea5df5451ffdf184b4428c902747c2c11cd7",  # v1.0.0
+        importpath = "github.com/ghodss/yaml",
+    )
+
+    _maybe(
+        # Apache License 2.0
+        go_repository,
+        name = "in_gopkg_yaml_v2",
+        commit = "51d6538a90f86fe93ac480b35f37b2be17fef232",  # v2.2.2
+        importpath = "gopkg.in/yaml.v2",
+    )
+
+    _maybe(
+        # BSD 3-Clause License
+        # https://github.com/golang/snappy/blob/master/LICENSE
+        go_repository,
+        name = "com_github_golang_snappy",
+        commit = "544b4180ac705b7605231d4a4550a1acb22a19fe",  # v0.0.4
+        importpath = "github.com/golang/snappy",
+    )
+
 def _maybe(repo_rule, name, **kwargs):
     if name not in native.existing_rules():
         repo_rule(name = name, **kwargs)
@@ -0,0 +1,26 @@
+package ssz
+
+import (
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// DefaultMaxDepth is the maximum nesting depth of decoded and hashed types unless
+// configured otherwise with SetMaxDepth.
+const DefaultMaxDepth = types.DefaultMaxDepth
+
+// ErrMaxDepthExceeded is returned by Unmarshal and HashTreeRoot for types which nest
+// deeper than the maximum depth or refer to themselves, rather than overflowing the
+// stack. Callers can compare it with errors.Cause.
+var ErrMaxDepthExceeded = types.ErrMaxDepthExceeded
+
+// SetMaxDepth sets the maximum number of nested pointers, structs, lists, vectors and
+// maps of the types decoded and hashed, 0 restoring DefaultMaxDepth:
+//
+//  if err := ssz.SetMaxDepth(16); err != nil {
+//      return err
+//  }
+//
+// The depth of a type is checked once, before walking any of its values.
+func SetMaxDepth(n int) error {
+	return types.SetMaxDepth(n)
+}
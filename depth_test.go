@@ -0,0 +1,56 @@
+package ssz
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+)
+
+type depthLink struct {
+	Slot uint64
+	Next *depthLink
+}
+
+type depthTree struct {
+	Children []*depthTree `ssz-max:"4"`
+}
+
+func TestMaxDepth_SelfReferentialTypes(t *testing.T) {
+	for _, val := range []interface{}{&depthLink{Slot: 1}, &depthTree{}} {
+		if _, err := HashTreeRoot(val); errors.Cause(err) != ErrMaxDepthExceeded {
+			t.Errorf("Expected %v hashing %T, received %v", ErrMaxDepthExceeded, val, err)
+		}
+		if err := Unmarshal(make([]byte, 16), val); errors.Cause(err) != ErrMaxDepthExceeded {
+			t.Errorf("Expected %v decoding %T, received %v", ErrMaxDepthExceeded, val, err)
+		}
+	}
+}
+
+func TestSetMaxDepth(t *testing.T) {
+	defer func() {
+		if err := SetMaxDepth(0); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	// A list of lists of uint16 nests two levels deep, and the pointer to it a third.
+	val := [][]uint16{{1, 2}, {3}}
+	enc := mustMarshal(t, val)
+	if err := SetMaxDepth(2); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := HashTreeRoot(val); err != nil {
+		t.Errorf("Unexpected error hashing within the maximum depth: %v", err)
+	}
+	if err := Unmarshal(enc, &[][]uint16{}); err != nil {
+		t.Errorf("Unexpected error decoding within the maximum depth: %v", err)
+	}
+	if _, err := HashTreeRoot(&val); errors.Cause(err) != ErrMaxDepthExceeded {
+		t.Errorf("Expected %v, received %v", ErrMaxDepthExceeded, err)
+	}
+	if err := SetMaxDepth(1); err != nil {
+		t.Fatal(err)
+	}
+	if err := Unmarshal(enc, &[][]uint16{}); errors.Cause(err) != ErrMaxDepthExceeded {
+		t.Errorf("Expected %v, received %v", ErrMaxDepthExceeded, err)
+	}
+}
@@ -0,0 +1,302 @@
+package ssz
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// StateDiff records the fields that changed between two values of the same container
+// type, along with the root of the new value. Lists and vectors of elements other than
+// bytes, such as the validators or balances of a state, record their changed elements
+// only, while other fields record their whole new serialization. A diff is itself
+// encoded with Marshal, so that an archive node can store a full state once in a while
+// and diffs in between:
+//
+//  diff, err := ssz.Diff(parentState, state)
+//  if err != nil {
+//      return err
+//  }
+//  enc, err := ssz.Marshal(diff)
+//
+// and later reconstruct the state from its parent:
+//
+//  if err := ssz.ApplyDiff(parentState, diff); err != nil {
+//      return err
+//  }
+type StateDiff struct {
+	Root   []byte       `ssz-size:"32"`
+	Fields []*FieldDiff `ssz-max:"1024"`
+}
+
+// FieldDiff records the new value of a field, given by its index in the container.
+type FieldDiff struct {
+	Index uint64
+	// Whole is set when Value holds the serialization of the whole field, otherwise
+	// the field is a list or vector of the given length whose changed elements are
+	// held by Elements.
+	Whole    bool
+	Value    []byte `ssz-max:"4294967296"`
+	Length   uint64
+	Elements []*ElementDiff `ssz-max:"1099511627776"`
+}
+
+// ElementDiff records the serialization of the element of a list or vector at Index.
+type ElementDiff struct {
+	Index uint64
+	Value []byte `ssz-max:"4294967296"`
+}
+
+// Diff returns the diff turning the container old into the container new, which must
+// be of the same type.
+func Diff(old interface{}, new interface{}) (*StateDiff, error) {
+	oldVal, err := containerValue(old)
+	if err != nil {
+		return nil, err
+	}
+	newVal, err := containerValue(new)
+	if err != nil {
+		return nil, err
+	}
+	typ := newVal.Type()
+	if oldVal.Type() != typ {
+		return nil, fmt.Errorf("cannot diff %v against %v", oldVal.Type(), typ)
+	}
+	fields, err := types.ContainerFields(typ)
+	if err != nil {
+		return nil, err
+	}
+	oldRoots, err := FieldRoots(oldVal.Interface())
+	if err != nil {
+		return nil, err
+	}
+	newRoots, err := FieldRoots(newVal.Interface())
+	if err != nil {
+		return nil, err
+	}
+	root, err := HashTreeRoot(newVal.Interface())
+	if err != nil {
+		return nil, err
+	}
+	diff := &StateDiff{Root: root[:]}
+	// The new value is only serialized when a field has to be recorded whole.
+	var encoded []byte
+	for k, f := range fields {
+		if oldRoots[k] == newRoots[k] {
+			continue
+		}
+		if elementWise(newVal.Field(f.Index), f.Type) {
+			fd, err := diffElements(oldVal.Field(f.Index), newVal.Field(f.Index), f)
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s.%s", typ.Name(), f.Name)
+			}
+			diff.Fields = append(diff.Fields, fd)
+			continue
+		}
+		if encoded == nil {
+			if encoded, err = marshal(newVal.Interface()); err != nil {
+				return nil, err
+			}
+		}
+		value, err := ExtractField(encoded, typ, f.Name)
+		if err != nil {
+			return nil, err
+		}
+		diff.Fields = append(diff.Fields, &FieldDiff{
+			Index: uint64(f.Index),
+			Whole: true,
+			Value: value,
+		})
+	}
+	return diff, nil
+}
+
+// ApplyDiff applies a diff to the container pointed to by obj, turning it into the new
+// value the diff was computed from. The diff is checked against the root it records,
+// and obj is left untouched when it does not match. Slices and pointers held by obj are
+// replaced rather than modified, so that values sharing them, such as the parent state,
+// are not affected.
+func ApplyDiff(obj interface{}, diff *StateDiff) error {
+	if obj == nil || diff == nil {
+		return errors.New("untyped nil is not supported")
+	}
+	rval := reflect.ValueOf(obj)
+	if rval.Kind() != reflect.Ptr || rval.IsNil() || rval.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("expected a non-nil pointer to a struct, received %v", rval.Type())
+	}
+	typ := rval.Elem().Type()
+	fields, err := types.ContainerFields(typ)
+	if err != nil {
+		return err
+	}
+	byIndex := make(map[uint64]types.ContainerField, len(fields))
+	for _, f := range fields {
+		byIndex[uint64(f.Index)] = f
+	}
+	val := reflect.New(typ).Elem()
+	val.Set(rval.Elem())
+	for _, fd := range diff.Fields {
+		if fd == nil {
+			return errors.New("nil field diff")
+		}
+		f, ok := byIndex[fd.Index]
+		if !ok {
+			return fmt.Errorf("no field of index %d in %v", fd.Index, typ)
+		}
+		if fd.Whole {
+			val.Field(f.Index).Set(reflect.Zero(val.Field(f.Index).Type()))
+			if err := types.UnmarshalField(val, f.Index, append([]byte(nil), fd.Value...)); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := applyElements(val.Field(f.Index), typ.Field(f.Index), f, fd); err != nil {
+			return errors.Wrapf(err, "%s.%s", typ.Name(), f.Name)
+		}
+	}
+	root, err := HashTreeRoot(val.Addr().Interface())
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(root[:], diff.Root) {
+		return fmt.Errorf("diff results in root %#x, recorded %#x", root, diff.Root)
+	}
+	rval.Elem().Set(val)
+	return nil
+}
+
+// containerValue returns the struct held by obj, or the zero value of the struct
+// pointed to by a nil pointer.
+func containerValue(obj interface{}) (reflect.Value, error) {
+	if obj == nil {
+		return reflect.Value{}, errors.New("untyped nil is not supported")
+	}
+	val := reflect.ValueOf(obj)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem())
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return reflect.Value{}, fmt.Errorf("expected a container, received %v", val.Type())
+	}
+	return val, nil
+}
+
+// elementWise returns true for fields held in slices whose elements are recorded one at
+// a time, which excludes byte lists and vectors.
+func elementWise(val reflect.Value, typ reflect.Type) bool {
+	if val.Kind() != reflect.Slice || (typ.Kind() != reflect.Slice && typ.Kind() != reflect.Array) {
+		return false
+	}
+	return typ.Elem().Kind() != reflect.Uint8
+}
+
+func diffElements(oldVal reflect.Value, newVal reflect.Value, f types.ContainerField) (*FieldDiff, error) {
+	fd := &FieldDiff{Index: uint64(f.Index), Length: uint64(newVal.Len())}
+	for i := 0; i < newVal.Len(); i++ {
+		if i < oldVal.Len() && DeepEqual(oldVal.Index(i).Interface(), newVal.Index(i).Interface()) {
+			continue
+		}
+		value, err := encodeElement(newVal.Index(i), f.Type.Elem())
+		if err != nil {
+			return nil, errors.Wrapf(err, "[%d]", i)
+		}
+		fd.Elements = append(fd.Elements, &ElementDiff{Index: uint64(i), Value: value})
+	}
+	return fd, nil
+}
+
+func applyElements(val reflect.Value, field reflect.StructField, f types.ContainerField, fd *FieldDiff) error {
+	if !elementWise(val, f.Type) {
+		return fmt.Errorf("cannot apply element diffs to %v", f.Type)
+	}
+	if f.Type.Kind() == reflect.Array && fd.Length != uint64(f.Type.Len()) {
+		return fmt.Errorf("vector of length %d cannot be given length %d", f.Type.Len(), fd.Length)
+	}
+	limit, elemLimits := splitLimits(types.FieldCapacities(field))
+	if f.Type.Kind() == reflect.Slice && limit > 0 && fd.Length > limit {
+		return fmt.Errorf("length %d exceeds the list limit %d", fd.Length, limit)
+	}
+	if fd.Length > uint64(val.Len())+uint64(len(fd.Elements)) {
+		return fmt.Errorf("length %d exceeds the %d elements held and %d changed", fd.Length, val.Len(), len(fd.Elements))
+	}
+	elems := reflect.MakeSlice(val.Type(), int(fd.Length), int(fd.Length))
+	reflect.Copy(elems, val)
+	for _, e := range fd.Elements {
+		if e == nil {
+			return errors.New("nil element diff")
+		}
+		if e.Index >= fd.Length {
+			return fmt.Errorf("index %d out of range for length %d", e.Index, fd.Length)
+		}
+		elem := elems.Index(int(e.Index))
+		if err := decodeElement(elem, f.Type.Elem(), e.Value); err != nil {
+			return errors.Wrapf(err, "[%d]", e.Index)
+		}
+		// Lists nested in the elements, such as transactions, have limits of their own.
+		if len(elemLimits) > 0 && elemLimits[0] > 0 && elem.Kind() == reflect.Slice && uint64(elem.Len()) > elemLimits[0] {
+			return fmt.Errorf("[%d]: length %d exceeds the list limit %d", e.Index, elem.Len(), elemLimits[0])
+		}
+	}
+	val.Set(elems)
+	return nil
+}
+
+// encodeElement serializes an element of a list or vector as the given type.
+func encodeElement(val reflect.Value, typ reflect.Type) ([]byte, error) {
+	if val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem())
+		}
+		val = val.Elem()
+	}
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	buf := make([]byte, types.SizeOf(val, typ))
+	factory, err := types.SSZFactory(val, typ)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := factory.Marshal(val, typ, buf, 0); err != nil {
+		return nil, err
+	}
+	return buf, nil
+}
+
+// decodeElement replaces the element held by val by the one decoded from input,
+// allocating a new element rather than decoding into the one held.
+func decodeElement(val reflect.Value, typ reflect.Type, input []byte) error {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	elem := reflect.New(val.Type()).Elem()
+	target := elem
+	if val.Kind() == reflect.Ptr {
+		elem.Set(reflect.New(val.Type().Elem()))
+		target = elem.Elem()
+	}
+	if target.Kind() == reflect.Slice && typ.Kind() == reflect.Array {
+		// Vectors held in slices are decoded into slices of their length.
+		target.Set(reflect.MakeSlice(target.Type(), typ.Len(), typ.Len()))
+	}
+	factory, err := types.SSZFactory(target, typ)
+	if err != nil {
+		return err
+	}
+	input = append([]byte(nil), input...)
+	end, err := factory.Unmarshal(target, typ, input, 0)
+	if err != nil {
+		return err
+	}
+	if end != uint64(len(input)) {
+		return fmt.Errorf("element of %d bytes decoded from %d bytes", end, len(input))
+	}
+	val.Set(elem)
+	return nil
+}
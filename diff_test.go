@@ -0,0 +1,108 @@
+package ssz
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+func diffTestState() *proofState {
+	state := &proofState{
+		Slot:       1,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{32, 32, 32, 32, 32},
+		Bits:       bitfield.Bitlist{0x0d},
+		Graffiti:   "go-ssz",
+	}
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = make([]byte, 32)
+	}
+	for i := 0; i < 5; i++ {
+		state.Validators = append(state.Validators, &proofValidator{
+			Pubkey:                make([]byte, 48),
+			WithdrawalCredentials: make([]byte, 32),
+			EffectiveBalance:      32,
+		})
+	}
+	return state
+}
+
+func TestDiff(t *testing.T) {
+	parent := diffTestState()
+	parentRoot, err := HashTreeRoot(parent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state := diffTestState()
+	state.Slot = 2
+	state.BlockRoots[1][0] = 0xaa
+	state.Validators[3].Slashed = true
+	state.Validators = append(state.Validators, &proofValidator{
+		Pubkey:                make([]byte, 48),
+		WithdrawalCredentials: make([]byte, 32),
+		EffectiveBalance:      16,
+	})
+	state.Balances = append(state.Balances[:4:4], 31)
+	state.Graffiti = "diff"
+
+	diff, err := Diff(parent, state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Slot, block roots, validators, balances and graffiti changed, bits did not.
+	if len(diff.Fields) != 5 {
+		t.Fatalf("Wanted 5 field diffs, received %d", len(diff.Fields))
+	}
+	for _, fd := range diff.Fields {
+		switch fd.Index {
+		case 1, 3:
+			if fd.Whole || len(fd.Elements) != 1 {
+				t.Errorf("Field %d: wanted 1 changed element, received %+v", fd.Index, fd)
+			}
+		case 2:
+			if fd.Whole || fd.Length != 6 || len(fd.Elements) != 2 {
+				t.Errorf("Field 2: wanted 2 changed elements out of 6, received %+v", fd)
+			}
+		default:
+			if !fd.Whole {
+				t.Errorf("Field %d: wanted a whole field, received %+v", fd.Index, fd)
+			}
+		}
+	}
+
+	enc, err := Marshal(diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &StateDiff{}
+	if err := Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	applied := *parent
+	if err := ApplyDiff(&applied, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !DeepEqual(&applied, state) {
+		t.Errorf("Wanted %+v, received %+v", state, &applied)
+	}
+	if root, err := HashTreeRoot(parent); err != nil || root != parentRoot {
+		t.Errorf("Applying a diff modified the parent state: %v", err)
+	}
+
+	// A diff applied to another state than its parent is rejected.
+	other := diffTestState()
+	other.Bits = bitfield.Bitlist{0x0f}
+	if err := ApplyDiff(other, decoded); err == nil {
+		t.Error("Expected an error when applying a diff to the wrong state")
+	}
+	if other.Slot != 1 || other.Graffiti != "go-ssz" {
+		t.Error("A rejected diff modified the state")
+	}
+
+	if diff, err := Diff(parent, parent); err != nil || len(diff.Fields) != 0 {
+		t.Errorf("Wanted an empty diff between equal states, received %v: %v", diff, err)
+	}
+	if _, err := Diff(parent, &fork{}); err == nil {
+		t.Error("Expected an error for values of different types")
+	}
+}
@@ -0,0 +1,175 @@
+package ssz
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Encoder writes SSZ encoded values to an output stream. Unlike Marshal, it never
+// materializes the whole encoding in memory: containers and lists are written field
+// by field and element by element, with offsets determined from the size of each
+// variable-size part ahead of writing it.
+//
+//  f, err := os.Create("state.ssz")
+//  if err != nil {
+//      return err
+//  }
+//  defer f.Close()
+//  if err := NewEncoder(f).Encode(beaconState); err != nil {
+//      return fmt.Errorf("failed to encode: %v", err)
+//  }
+type Encoder struct {
+	w       *bufio.Writer
+	scratch []byte
+}
+
+// NewEncoder returns a new encoder writing to w.
+func NewEncoder(w io.Writer) *Encoder {
+	return &Encoder{w: bufio.NewWriter(w)}
+}
+
+// Encode writes the SSZ encoding of val to the stream. The output is identical to the
+// result of Marshal(val).
+func (e *Encoder) Encode(val interface{}) error {
+	if val == nil {
+		return errors.New("untyped-value nil cannot be marshaled")
+	}
+	rval := reflect.ValueOf(val)
+	if err := e.encode(rval, rval.Type()); err != nil {
+		return errors.Wrapf(err, "failed to encode for type: %v", rval.Type())
+	}
+	if err := e.w.Flush(); err != nil {
+		return err
+	}
+	if currentMetrics() != nil {
+		reportEncoded(types.DetermineSize(rval))
+	}
+	return nil
+}
+
+func (e *Encoder) encode(val reflect.Value, typ reflect.Type) error {
+	if typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return e.encode(reflect.New(typ.Elem()).Elem(), typ.Elem())
+		}
+		return e.encode(val.Elem(), typ.Elem())
+	}
+	switch {
+	case typ.Kind() == reflect.Struct:
+		return e.encodeStruct(val, typ)
+	case (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) && typ.Elem().Kind() != reflect.Uint8:
+		return e.encodeSequence(val, typ)
+	default:
+		return e.encodeDirect(val, typ)
+	}
+}
+
+// encodeDirect marshals a value of bounded size, such as a basic type or a byte
+// array, through its SSZ factory.
+func (e *Encoder) encodeDirect(val reflect.Value, typ reflect.Type) error {
+	factory, err := types.SSZFactory(val, typ)
+	if err != nil {
+		return err
+	}
+	size := types.SizeOf(val, typ)
+	if typ.Kind() == reflect.Array && uint64(typ.Len()) > size {
+		size = uint64(typ.Len())
+	}
+	if uint64(cap(e.scratch)) < size {
+		e.scratch = make([]byte, size)
+	}
+	buf := e.scratch[:size]
+	for i := range buf {
+		buf[i] = 0
+	}
+	if _, err := factory.Marshal(val, typ, buf, 0); err != nil {
+		return err
+	}
+	_, err = e.w.Write(buf)
+	return err
+}
+
+func (e *Encoder) encodeSequence(val reflect.Value, typ reflect.Type) error {
+	length := val.Len()
+	if typ.Kind() == reflect.Array {
+		length = typ.Len()
+	}
+	elem := func(i int) reflect.Value {
+		if i < val.Len() {
+			return val.Index(i)
+		}
+		return reflect.New(val.Type().Elem()).Elem()
+	}
+	if types.IsVariableSize(typ.Elem()) {
+		offset := uint64(length) * types.BytesPerLengthOffset
+		for i := 0; i < length; i++ {
+			if err := e.writeOffset(offset); err != nil {
+				return err
+			}
+			offset += types.SizeOf(elem(i), typ.Elem())
+		}
+	}
+	for i := 0; i < length; i++ {
+		if err := e.encode(elem(i), typ.Elem()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Encoder) encodeStruct(val reflect.Value, typ reflect.Type) error {
+	fields := make([]int, 0, typ.NumField())
+	fieldTypes := make([]reflect.Type, 0, typ.NumField())
+	fixedLength := uint64(0)
+	for i := 0; i < typ.NumField(); i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(typ.Field(i)) {
+			continue
+		}
+		fType, err := types.FieldType(typ.Field(i))
+		if err != nil {
+			return err
+		}
+		fields = append(fields, i)
+		fieldTypes = append(fieldTypes, fType)
+		if types.IsVariableSize(fType) {
+			fixedLength += types.BytesPerLengthOffset
+		} else {
+			fixedLength += types.SizeOf(val.Field(i), fType)
+		}
+	}
+	offset := fixedLength
+	for j, i := range fields {
+		if !types.IsVariableSize(fieldTypes[j]) {
+			if err := e.encode(val.Field(i), fieldTypes[j]); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := e.writeOffset(offset); err != nil {
+			return err
+		}
+		offset += types.SizeOf(val.Field(i), fieldTypes[j])
+	}
+	for j, i := range fields {
+		if !types.IsVariableSize(fieldTypes[j]) {
+			continue
+		}
+		if err := e.encode(val.Field(i), fieldTypes[j]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (e *Encoder) writeOffset(offset uint64) error {
+	var buf [4]byte
+	binary.LittleEndian.PutUint32(buf[:], uint32(offset))
+	_, err := e.w.Write(buf[:])
+	return err
+}
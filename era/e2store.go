@@ -0,0 +1,94 @@
+// Package era reads and writes era files, the history archives of the beacon chain,
+// built on the e2store format: a sequence of entries made of an 8-byte header, holding
+// a 2-byte type and a 4-byte little-endian length, followed by the data of the entry.
+// Blocks and states are stored SSZ-encoded and compressed with the snappy framing
+// format.
+package era
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/golang/snappy"
+)
+
+// EntryType is the type of an e2store entry.
+type EntryType [2]byte
+
+// Types of the entries of an era file.
+var (
+	TypeVersion                     = EntryType{0x65, 0x32}
+	TypeEmpty                       = EntryType{0x00, 0x00}
+	TypeCompressedSignedBeaconBlock = EntryType{0x01, 0x00}
+	TypeCompressedBeaconState       = EntryType{0x02, 0x00}
+	TypeSlotIndex                   = EntryType{0x69, 0x32}
+)
+
+// HeaderSize is the size of the header of an e2store entry.
+const HeaderSize = 8
+
+// Entry is an e2store entry.
+type Entry struct {
+	Type EntryType
+	Data []byte
+}
+
+// WriteEntry writes an entry to w and returns the number of bytes written.
+func WriteEntry(w io.Writer, typ EntryType, data []byte) (int64, error) {
+	if uint64(len(data)) > 0xffffffff {
+		return 0, fmt.Errorf("entry of %d bytes exceeds the maximum length", len(data))
+	}
+	var header [HeaderSize]byte
+	copy(header[:2], typ[:])
+	binary.LittleEndian.PutUint32(header[2:6], uint32(len(data)))
+	n, err := w.Write(header[:])
+	if err != nil {
+		return int64(n), err
+	}
+	m, err := w.Write(data)
+	return int64(n + m), err
+}
+
+// ReadEntry reads the entry starting at offset in r.
+func ReadEntry(r io.ReaderAt, offset int64) (*Entry, error) {
+	var header [HeaderSize]byte
+	if _, err := r.ReadAt(header[:], offset); err != nil {
+		return nil, fmt.Errorf("could not read entry header at %d: %v", offset, err)
+	}
+	if header[6] != 0 || header[7] != 0 {
+		return nil, fmt.Errorf("entry at %d has non-zero reserved bytes", offset)
+	}
+	e := &Entry{Data: make([]byte, binary.LittleEndian.Uint32(header[2:6]))}
+	copy(e.Type[:], header[:2])
+	if len(e.Data) > 0 {
+		if _, err := r.ReadAt(e.Data, offset+HeaderSize); err != nil {
+			return nil, fmt.Errorf("could not read entry data at %d: %v", offset, err)
+		}
+	}
+	return e, nil
+}
+
+// Compress compresses an SSZ encoding into the data of a compressed entry.
+func Compress(enc []byte) ([]byte, error) {
+	var buf bytes.Buffer
+	w := snappy.NewBufferedWriter(&buf)
+	if _, err := w.Write(enc); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decompress returns the SSZ encoding held by the data of a compressed entry.
+func Decompress(data []byte) ([]byte, error) {
+	enc, err := ioutil.ReadAll(snappy.NewReader(bytes.NewReader(data)))
+	if err != nil {
+		return nil, fmt.Errorf("could not decompress entry: %v", err)
+	}
+	return enc, nil
+}
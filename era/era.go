@@ -0,0 +1,246 @@
+package era
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+// errNoIndex is returned when a slot index does not end where one is looked for.
+var errNoIndex = errors.New("no slot index at the end of the file")
+
+// Writer writes a group of an era file: a version entry, the blocks of the slots the
+// group covers, the state at the end of those slots, and the slot indices locating
+// them. The blocks and the state are given SSZ-encoded:
+//
+//  w, err := era.NewWriter(f, startSlot, slotsPerHistoricalRoot)
+//  if err != nil {
+//      return err
+//  }
+//  for _, b := range blocks {
+//      enc, err := ssz.Marshal(b)
+//      if err != nil {
+//          return err
+//      }
+//      if err := w.AddBlock(b.Block.Slot, enc); err != nil {
+//          return err
+//      }
+//  }
+//  enc, err := ssz.Marshal(state)
+//  if err != nil {
+//      return err
+//  }
+//  if err := w.Finish(state.Slot, enc); err != nil {
+//      return err
+//  }
+type Writer struct {
+	w          io.Writer
+	pos        int64
+	startSlot  uint64
+	offsets    []int64
+	nextSlot   uint64
+	finished   bool
+	writeError error
+}
+
+// NewWriter starts a group whose blocks are those of the given number of slots from
+// startSlot. The group of the genesis era holds no block, and is started with zero
+// slots.
+func NewWriter(w io.Writer, startSlot uint64, slots uint64) (*Writer, error) {
+	ew := &Writer{
+		w:         w,
+		startSlot: startSlot,
+		offsets:   make([]int64, slots),
+		nextSlot:  startSlot,
+	}
+	if err := ew.write(TypeVersion, nil); err != nil {
+		return nil, err
+	}
+	return ew, nil
+}
+
+// AddBlock adds the SSZ encoding of the signed block of a slot. Blocks are added by
+// increasing slot, and slots without a block are skipped.
+func (w *Writer) AddBlock(slot uint64, enc []byte) error {
+	if w.finished {
+		return errors.New("cannot add block to a finished group")
+	}
+	if slot < w.nextSlot || slot-w.startSlot >= uint64(len(w.offsets)) {
+		return fmt.Errorf("block of slot %d out of order or out of the range of the group", slot)
+	}
+	data, err := Compress(enc)
+	if err != nil {
+		return err
+	}
+	w.offsets[slot-w.startSlot] = w.pos
+	w.nextSlot = slot + 1
+	return w.write(TypeCompressedSignedBeaconBlock, data)
+}
+
+// Finish adds the SSZ encoding of the state of the given slot, followed by the slot
+// indices of the blocks and of the state, which completes the group.
+func (w *Writer) Finish(slot uint64, enc []byte) error {
+	if w.finished {
+		return errors.New("group is already finished")
+	}
+	w.finished = true
+	data, err := Compress(enc)
+	if err != nil {
+		return err
+	}
+	stateOffset := w.pos
+	if err := w.write(TypeCompressedBeaconState, data); err != nil {
+		return err
+	}
+	if len(w.offsets) > 0 {
+		if err := w.writeIndex(w.startSlot, w.offsets); err != nil {
+			return err
+		}
+	}
+	return w.writeIndex(slot, []int64{stateOffset})
+}
+
+// writeIndex writes a slot index, whose offsets are relative to the start of the index
+// entry, and zero for empty slots.
+func (w *Writer) writeIndex(startSlot uint64, offsets []int64) error {
+	data := make([]byte, 16+8*len(offsets))
+	binary.LittleEndian.PutUint64(data, startSlot)
+	for i, o := range offsets {
+		if o != 0 {
+			binary.LittleEndian.PutUint64(data[8+8*i:], uint64(o-w.pos))
+		}
+	}
+	binary.LittleEndian.PutUint64(data[len(data)-8:], uint64(len(offsets)))
+	return w.write(TypeSlotIndex, data)
+}
+
+func (w *Writer) write(typ EntryType, data []byte) error {
+	if w.writeError != nil {
+		return w.writeError
+	}
+	n, err := WriteEntry(w.w, typ, data)
+	w.pos += n
+	if err != nil {
+		w.writeError = err
+	}
+	return err
+}
+
+// Reader reads the blocks and the state of the last group of an era file, as located
+// by its slot indices.
+type Reader struct {
+	r            io.ReaderAt
+	startSlot    uint64
+	blockOffsets []int64
+	stateSlot    uint64
+	stateOffset  int64
+}
+
+// NewReader reads the slot indices at the end of an era file of the given size.
+func NewReader(r io.ReaderAt, size int64) (*Reader, error) {
+	stateIndex, stateSlot, stateOffsets, err := readIndex(r, size)
+	if err != nil {
+		return nil, err
+	}
+	if len(stateOffsets) != 1 || stateOffsets[0] == 0 {
+		return nil, fmt.Errorf("state index holds %d offsets, wanted 1", len(stateOffsets))
+	}
+	er := &Reader{
+		r:           r,
+		stateSlot:   stateSlot,
+		stateOffset: stateOffsets[0],
+	}
+	// The block index follows the state, except in the group of the genesis era which
+	// has none.
+	blockIndex, startSlot, blockOffsets, err := readIndex(r, stateIndex)
+	if err == errNoIndex || (err == nil && blockIndex <= er.stateOffset) {
+		return er, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	er.startSlot = startSlot
+	er.blockOffsets = blockOffsets
+	return er, nil
+}
+
+// readIndex reads the slot index ending at end, and returns its position along with
+// the absolute offsets it holds.
+func readIndex(r io.ReaderAt, end int64) (int64, uint64, []int64, error) {
+	if end < HeaderSize+16 {
+		return 0, 0, nil, errNoIndex
+	}
+	var buf [8]byte
+	if _, err := r.ReadAt(buf[:], end-8); err != nil {
+		return 0, 0, nil, fmt.Errorf("could not read slot index count: %v", err)
+	}
+	count := binary.LittleEndian.Uint64(buf[:])
+	if count > uint64(end-HeaderSize-16)/8 {
+		return 0, 0, nil, errNoIndex
+	}
+	start := end - HeaderSize - 16 - 8*int64(count)
+	e, err := ReadEntry(r, start)
+	if err != nil {
+		return 0, 0, nil, errNoIndex
+	}
+	if e.Type != TypeSlotIndex || int64(len(e.Data)) != end-start-HeaderSize {
+		return 0, 0, nil, errNoIndex
+	}
+	offsets := make([]int64, count)
+	for i := range offsets {
+		rel := int64(binary.LittleEndian.Uint64(e.Data[8+8*i:]))
+		if rel == 0 {
+			continue
+		}
+		if rel > 0 || start+rel < 0 {
+			return 0, 0, nil, fmt.Errorf("offset %d of slot index at %d is out of the file", rel, start)
+		}
+		offsets[i] = start + rel
+	}
+	return start, binary.LittleEndian.Uint64(e.Data), offsets, nil
+}
+
+// StartSlot returns the first slot of the blocks of the group.
+func (r *Reader) StartSlot() uint64 {
+	return r.startSlot
+}
+
+// Slots returns the number of slots whose blocks the group holds.
+func (r *Reader) Slots() uint64 {
+	return uint64(len(r.blockOffsets))
+}
+
+// StateSlot returns the slot of the state of the group.
+func (r *Reader) StateSlot() uint64 {
+	return r.stateSlot
+}
+
+// Block returns the SSZ encoding of the signed block of a slot, or nil for a slot
+// without a block.
+func (r *Reader) Block(slot uint64) ([]byte, error) {
+	if slot < r.startSlot || slot-r.startSlot >= uint64(len(r.blockOffsets)) {
+		return nil, fmt.Errorf("slot %d out of the range of the group", slot)
+	}
+	offset := r.blockOffsets[slot-r.startSlot]
+	if offset == 0 {
+		return nil, nil
+	}
+	return r.read(offset, TypeCompressedSignedBeaconBlock)
+}
+
+// State returns the SSZ encoding of the state of the group.
+func (r *Reader) State() ([]byte, error) {
+	return r.read(r.stateOffset, TypeCompressedBeaconState)
+}
+
+func (r *Reader) read(offset int64, typ EntryType) ([]byte, error) {
+	e, err := ReadEntry(r.r, offset)
+	if err != nil {
+		return nil, err
+	}
+	if e.Type != typ {
+		return nil, fmt.Errorf("entry at %d has type %#x, wanted %#x", offset, e.Type, typ)
+	}
+	return Decompress(e.Data)
+}
@@ -0,0 +1,135 @@
+package era_test
+
+import (
+	"bytes"
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/era"
+)
+
+type block struct {
+	Slot      uint64
+	Graffiti  []byte `ssz-max:"32"`
+	Signature []byte `ssz-size:"96"`
+}
+
+type state struct {
+	Slot     uint64
+	Balances []uint64 `ssz-max:"1099511627776"`
+}
+
+func TestWriterReader(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := era.NewWriter(&buf, 8, 8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blocks := map[uint64]*block{}
+	for _, slot := range []uint64{8, 9, 11, 15} {
+		blocks[slot] = &block{Slot: slot, Graffiti: []byte("era"), Signature: make([]byte, 96)}
+		enc, err := ssz.Marshal(blocks[slot])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.AddBlock(slot, enc); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.AddBlock(10, nil); err == nil {
+		t.Error("Expected an error for a block added out of order")
+	}
+	st := &state{Slot: 16, Balances: make([]uint64, 1000)}
+	enc, err := ssz.Marshal(st)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Finish(16, enc); err != nil {
+		t.Fatal(err)
+	}
+
+	r, err := era.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.StartSlot() != 8 || r.Slots() != 8 || r.StateSlot() != 16 {
+		t.Errorf("Wanted 8 slots from 8 and a state at 16, received %d slots from %d and a state at %d", r.Slots(), r.StartSlot(), r.StateSlot())
+	}
+	for slot := uint64(8); slot < 16; slot++ {
+		enc, err := r.Block(slot)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if blocks[slot] == nil {
+			if enc != nil {
+				t.Errorf("Slot %d: wanted no block", slot)
+			}
+			continue
+		}
+		b := &block{}
+		if err := ssz.Unmarshal(enc, b); err != nil {
+			t.Fatal(err)
+		}
+		if !ssz.DeepEqual(b, blocks[slot]) {
+			t.Errorf("Slot %d: wanted block %+v, received %+v", slot, blocks[slot], b)
+		}
+	}
+	if _, err := r.Block(16); err == nil {
+		t.Error("Expected an error for a slot out of the group")
+	}
+	enc, err = r.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &state{}
+	if err := ssz.Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !ssz.DeepEqual(decoded, st) {
+		t.Error("Decoded state differs from the one written")
+	}
+
+	e, err := era.ReadEntry(bytes.NewReader(buf.Bytes()), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if e.Type != era.TypeVersion || len(e.Data) != 0 {
+		t.Errorf("Wanted an empty version entry first, received %+v", e)
+	}
+}
+
+func TestGenesisGroup(t *testing.T) {
+	var buf bytes.Buffer
+	w, err := era.NewWriter(&buf, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.AddBlock(0, nil); err == nil {
+		t.Error("Expected an error for a block in a group without slots")
+	}
+	enc, err := ssz.Marshal(&state{Balances: []uint64{32}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Finish(0, enc); err != nil {
+		t.Fatal(err)
+	}
+	r, err := era.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Slots() != 0 {
+		t.Errorf("Wanted no block slots, received %d", r.Slots())
+	}
+	got, err := r.State()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, enc) {
+		t.Error("Decoded state differs from the one written")
+	}
+
+	if _, err := era.NewReader(bytes.NewReader(buf.Bytes()[:buf.Len()-1]), int64(buf.Len()-1)); err == nil {
+		t.Error("Expected an error for a truncated file")
+	}
+}
@@ -0,0 +1,199 @@
+package ssz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// ExtractField returns the serialization of the value found by following path from an
+// encoded value of type typ, without decoding anything but the offsets along the way.
+// Path elements are field names and indices as for Proof:
+//
+//  enc, err := ssz.ExtractField(encodedState, reflect.TypeOf(BeaconState{}), "Validators", 1234)
+//  if err != nil {
+//      return err
+//  }
+//  validator := &Validator{}
+//  if err := ssz.Unmarshal(enc, validator); err != nil {
+//      return err
+//  }
+//
+// The returned slice aliases encoded.
+func ExtractField(encoded []byte, typ reflect.Type, path ...interface{}) ([]byte, error) {
+	if typ == nil {
+		return nil, errors.New("nil type")
+	}
+	data := encoded
+	for i := range path {
+		for typ.Kind() == reflect.Ptr {
+			typ = typ.Elem()
+		}
+		var err error
+		data, typ, err = extractStep(data, typ, path[i])
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not follow path %v", path[:i+1])
+		}
+	}
+	return data, nil
+}
+
+// FieldRange locates the serialization of a container field within the encoding of the
+// container. Fields of variable size are found through an offset, which is held at
+// OffsetPosition in the fixed-size part of the container.
+type FieldRange struct {
+	Name           string
+	Start          uint64
+	End            uint64
+	Variable       bool
+	OffsetPosition uint64
+}
+
+// FieldRanges locates every field within the encoding of a container of type typ,
+// checking its offsets as Unmarshal does.
+func FieldRanges(encoded []byte, typ reflect.Type) ([]FieldRange, error) {
+	if typ == nil {
+		return nil, errors.New("nil type")
+	}
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a container, received %v", typ)
+	}
+	fields, err := types.ContainerFields(typ)
+	if err != nil {
+		return nil, err
+	}
+	fixedSize := fixedPartSize(fields)
+	if uint64(len(encoded)) < fixedSize {
+		return nil, fmt.Errorf("%v of %d bytes is smaller than its fixed-size part of %d bytes", typ, len(encoded), fixedSize)
+	}
+	spans, err := fieldSpans(fields, encoded[:fixedSize], uint64(len(encoded)))
+	if err != nil {
+		return nil, err
+	}
+	ranges := make([]FieldRange, len(fields))
+	pos := uint64(0)
+	for i, f := range fields {
+		ranges[i] = FieldRange{Name: f.Name, Start: spans[i].start, End: spans[i].end, Variable: f.Variable}
+		if f.Variable {
+			ranges[i].OffsetPosition = pos
+			pos += types.BytesPerLengthOffset
+		} else {
+			pos += f.Size
+		}
+	}
+	return ranges, nil
+}
+
+// extractStep returns the serialization of the field or element designated by step
+// within data, along with its type.
+func extractStep(data []byte, typ reflect.Type, step interface{}) ([]byte, reflect.Type, error) {
+	if typ == reflect.TypeOf(bitfield.Bitlist{}) {
+		return nil, nil, errors.New("cannot index into a bitlist")
+	}
+	if types.IsUnion(typ) || types.IsOptional(typ) || types.IsStableContainer(typ) || types.IsProfile(typ) {
+		return nil, nil, fmt.Errorf("cannot index into %v", typ)
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		name, ok := step.(string)
+		if !ok {
+			return nil, nil, fmt.Errorf("expected a field name to index into %v, received %v", typ, step)
+		}
+		return extractContainerField(data, typ, name)
+	case reflect.Slice, reflect.Array, reflect.String:
+		idx, ok := toIndex(step)
+		if !ok {
+			return nil, nil, fmt.Errorf("expected an index into %v, received %v", typ, step)
+		}
+		return extractElement(data, typ, idx)
+	default:
+		return nil, nil, fmt.Errorf("cannot follow path %v into kind %v", step, typ.Kind())
+	}
+}
+
+func extractContainerField(data []byte, typ reflect.Type, name string) ([]byte, reflect.Type, error) {
+	fields, err := types.ContainerFields(typ)
+	if err != nil {
+		return nil, nil, err
+	}
+	fixedSize := fixedPartSize(fields)
+	if uint64(len(data)) < fixedSize {
+		return nil, nil, fmt.Errorf("%v of %d bytes is smaller than its fixed-size part of %d bytes", typ, len(data), fixedSize)
+	}
+	spans, err := fieldSpans(fields, data[:fixedSize], uint64(len(data)))
+	if err != nil {
+		return nil, nil, err
+	}
+	for i, f := range fields {
+		if f.Name != name {
+			continue
+		}
+		fType := f.Type
+		if typ.Field(f.Index).Type == reflect.TypeOf(bitfield.Bitlist{}) {
+			fType = typ.Field(f.Index).Type
+		}
+		return data[spans[i].start:spans[i].end], fType, nil
+	}
+	return nil, nil, fmt.Errorf("no field %s in %v", name, typ)
+}
+
+func extractElement(data []byte, typ reflect.Type, idx uint64) ([]byte, reflect.Type, error) {
+	if typ.Kind() == reflect.String {
+		if idx >= uint64(len(data)) {
+			return nil, nil, fmt.Errorf("index %d out of range for length %d", idx, len(data))
+		}
+		return data[idx : idx+1], reflect.TypeOf(byte(0)), nil
+	}
+	elemTyp := typ.Elem()
+	if types.IsVariableSize(elemTyp) {
+		// Elements of variable size are located through the offsets leading the list.
+		var n uint64
+		if uint64(len(data)) >= types.BytesPerLengthOffset {
+			n = uint64(binary.LittleEndian.Uint32(data)) / types.BytesPerLengthOffset
+		}
+		if typ.Kind() == reflect.Array && n != uint64(typ.Len()) {
+			return nil, nil, fmt.Errorf("vector of length %d has %d offsets", typ.Len(), n)
+		}
+		if idx >= n {
+			return nil, nil, fmt.Errorf("index %d out of range for length %d", idx, n)
+		}
+		if uint64(len(data)) < n*types.BytesPerLengthOffset {
+			return nil, nil, fmt.Errorf("%d offsets do not fit in %d bytes", n, len(data))
+		}
+		start := uint64(binary.LittleEndian.Uint32(data[idx*types.BytesPerLengthOffset:]))
+		end := uint64(len(data))
+		if idx+1 < n {
+			end = uint64(binary.LittleEndian.Uint32(data[(idx+1)*types.BytesPerLengthOffset:]))
+		}
+		if start < n*types.BytesPerLengthOffset || start > end || end > uint64(len(data)) {
+			return nil, nil, fmt.Errorf("offset %d of element %d is out of order", start, idx)
+		}
+		return data[start:end], elemTyp, nil
+	}
+	sizeTyp := elemTyp
+	for sizeTyp.Kind() == reflect.Ptr {
+		sizeTyp = sizeTyp.Elem()
+	}
+	elemSize := types.SizeOf(reflect.New(sizeTyp).Elem(), sizeTyp)
+	if elemSize == 0 {
+		return nil, nil, fmt.Errorf("cannot index into elements of size 0 of %v", typ)
+	}
+	if uint64(len(data))%elemSize != 0 {
+		return nil, nil, fmt.Errorf("%d bytes are not a multiple of the element size %d", len(data), elemSize)
+	}
+	n := uint64(len(data)) / elemSize
+	if typ.Kind() == reflect.Array && n != uint64(typ.Len()) {
+		return nil, nil, fmt.Errorf("vector of length %d has %d elements", typ.Len(), n)
+	}
+	if idx >= n {
+		return nil, nil, fmt.Errorf("index %d out of range for length %d", idx, n)
+	}
+	return data[idx*elemSize : (idx+1)*elemSize], elemTyp, nil
+}
@@ -0,0 +1,125 @@
+package ssz
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+type extractNested struct {
+	Names []string  `ssz-max:"8"`
+	Inner []*fork   `ssz-max:"8"`
+	Roots [2][]byte `ssz-size:"2,32"`
+}
+
+func TestExtractField(t *testing.T) {
+	state := &proofState{
+		Slot:       9,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{1, 2, 3},
+		Bits:       bitfield.Bitlist{0x0d},
+		Graffiti:   "extract",
+	}
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = bytes.Repeat([]byte{byte(i)}, 32)
+	}
+	for i := 0; i < 10; i++ {
+		state.Validators = append(state.Validators, &proofValidator{Pubkey: make([]byte, 48), WithdrawalCredentials: make([]byte, 32), EffectiveBalance: uint64(i)})
+	}
+	enc := mustMarshal(t, state)
+	typ := reflect.TypeOf(proofState{})
+	tests := []struct {
+		path []interface{}
+		want []byte
+	}{
+		{path: []interface{}{"Slot"}, want: mustMarshal(t, state.Slot)},
+		{path: []interface{}{"BlockRoots", 5}, want: state.BlockRoots[5]},
+		{path: []interface{}{"Validators", 7}, want: mustMarshal(t, state.Validators[7])},
+		{path: []interface{}{"Validators", uint64(7), "EffectiveBalance"}, want: mustMarshal(t, uint64(7))},
+		{path: []interface{}{"Balances", 2}, want: mustMarshal(t, uint64(3))},
+		{path: []interface{}{"Bits"}, want: []byte{0x0d}},
+		{path: []interface{}{"Graffiti", 1}, want: []byte("x")},
+		{path: nil, want: enc},
+	}
+	for _, tt := range tests {
+		got, err := ExtractField(enc, typ, tt.path...)
+		if err != nil {
+			t.Errorf("%v: %v", tt.path, err)
+			continue
+		}
+		if !bytes.Equal(got, tt.want) {
+			t.Errorf("%v: wanted %#x, received %#x", tt.path, tt.want, got)
+		}
+	}
+
+	nested := &extractNested{
+		Names: []string{"a", "bcd", ""},
+		Inner: []*fork{{Epoch: 1}, {Epoch: 2}},
+		Roots: [2][]byte{make([]byte, 32), bytes.Repeat([]byte{7}, 32)},
+	}
+	nestedEnc := mustMarshal(t, nested)
+	for i, name := range nested.Names {
+		got, err := ExtractField(nestedEnc, reflect.TypeOf(nested), "Names", i)
+		if err != nil || string(got) != name {
+			t.Errorf("Wanted name %q, received %q: %v", name, got, err)
+		}
+	}
+	got, err := ExtractField(nestedEnc, reflect.TypeOf(nested), "Inner", 1)
+	if err != nil || !bytes.Equal(got, mustMarshal(t, nested.Inner[1])) {
+		t.Errorf("Wrong inner fork %#x: %v", got, err)
+	}
+
+	for _, path := range [][]interface{}{
+		{"Missing"},
+		{"Validators", 10},
+		{"Validators", -1},
+		{"Slot", 0},
+		{"Bits", 0},
+		{0},
+	} {
+		if _, err := ExtractField(enc, typ, path...); err == nil {
+			t.Errorf("%v: expected error", path)
+		}
+	}
+	if _, err := ExtractField(enc[:20], typ, "Slot"); err == nil {
+		t.Error("Expected error for truncated encoding")
+	}
+}
+
+func TestFieldRanges(t *testing.T) {
+	state := &proofState{
+		Slot:       3,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{1, 2},
+		Bits:       bitfield.Bitlist{0x05},
+		Graffiti:   "ssz",
+	}
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = make([]byte, 32)
+	}
+	enc := mustMarshal(t, state)
+	ranges, err := FieldRanges(enc, reflect.TypeOf(state))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fixed := uint64(8 + 8*32 + 4*4)
+	want := []FieldRange{
+		{Name: "Slot", Start: 0, End: 8},
+		{Name: "BlockRoots", Start: 8, End: 8 + 8*32},
+		{Name: "Validators", Start: fixed, End: fixed, Variable: true, OffsetPosition: 8 + 8*32},
+		{Name: "Balances", Start: fixed, End: fixed + 16, Variable: true, OffsetPosition: 8 + 8*32 + 4},
+		{Name: "Bits", Start: fixed + 16, End: fixed + 17, Variable: true, OffsetPosition: 8 + 8*32 + 8},
+		{Name: "Graffiti", Start: fixed + 17, End: fixed + 20, Variable: true, OffsetPosition: 8 + 8*32 + 12},
+	}
+	if !reflect.DeepEqual(ranges, want) {
+		t.Errorf("Wanted ranges %+v, received %+v", want, ranges)
+	}
+	if _, err := FieldRanges(enc[:100], reflect.TypeOf(state)); err == nil {
+		t.Error("Expected an error for a truncated encoding")
+	}
+	if _, err := FieldRanges(enc, reflect.TypeOf(uint64(0))); err == nil {
+		t.Error("Expected an error for a non-container type")
+	}
+}
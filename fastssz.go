@@ -0,0 +1,79 @@
+package ssz
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+)
+
+// Types with encoding methods generated by fastssz, as most prysm types are, are
+// marshaled, unmarshaled and hashed by their generated methods rather than through
+// reflection, so that Marshal, Unmarshal and HashTreeRoot are a single entry point for
+// generated and plain types alike. The interfaces are matched structurally, which
+// keeps fastssz out of the dependencies of this package.
+
+// fastsszMarshaler is implemented by types with fastssz generated encoding methods.
+type fastsszMarshaler interface {
+	MarshalSSZTo(buf []byte) ([]byte, error)
+	SizeSSZ() int
+}
+
+// fastsszUnmarshaler is implemented by types with fastssz generated decoding methods.
+type fastsszUnmarshaler interface {
+	fastsszMarshaler
+	UnmarshalSSZ(buf []byte) error
+}
+
+// fastsszHashRoot is implemented by types with fastssz generated hashing methods.
+type fastsszHashRoot interface {
+	HashTreeRoot() ([32]byte, error)
+}
+
+// fastsszMarshal encodes val with its generated methods, if any.
+func fastsszMarshal(val interface{}) ([]byte, bool, error) {
+	m, ok := val.(fastsszMarshaler)
+	if !ok || isNilPointer(val) {
+		return nil, false, nil
+	}
+	buf, err := m.MarshalSSZTo(make([]byte, 0, m.SizeSSZ()))
+	if err != nil {
+		return nil, true, errors.Wrapf(err, "failed to marshal for type: %T", val)
+	}
+	return buf, true, nil
+}
+
+// fastsszUnmarshal decodes input into val with its generated methods, if any.
+func fastsszUnmarshal(input []byte, val interface{}) (bool, error) {
+	u, ok := val.(fastsszUnmarshaler)
+	if !ok {
+		return false, nil
+	}
+	if err := u.UnmarshalSSZ(input); err != nil {
+		return true, errors.Wrapf(err, "could not unmarshal input into type: %T", val)
+	}
+	return true, nil
+}
+
+// fastsszRoot hashes val with its generated methods, if any. A HashTreeRoot method
+// alone is not enough, as types commonly implement it by calling HashTreeRoot of this
+// package, so the generated HashTreeRootWith method must be present as well.
+func fastsszRoot(val interface{}) ([32]byte, bool, error) {
+	r, ok := val.(fastsszHashRoot)
+	if !ok || isNilPointer(val) {
+		return [32]byte{}, false, nil
+	}
+	method, ok := reflect.TypeOf(val).MethodByName("HashTreeRootWith")
+	if !ok || method.Type.NumIn() != 2 || method.Type.NumOut() != 1 {
+		return [32]byte{}, false, nil
+	}
+	root, err := r.HashTreeRoot()
+	if err != nil {
+		return [32]byte{}, true, errors.Wrapf(err, "could not compute root for type: %T", val)
+	}
+	return root, true, nil
+}
+
+func isNilPointer(val interface{}) bool {
+	rval := reflect.ValueOf(val)
+	return rval.Kind() == reflect.Ptr && rval.IsNil()
+}
@@ -0,0 +1,124 @@
+// Package forkdigest maps the fork digests and message names of the networking layer of
+// the beacon chain to the Go types of the messages, so that a payload received on a
+// topic is decoded into the type of the fork it belongs to.
+package forkdigest
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sync"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+)
+
+// Digest is a fork digest, the first 4 bytes of the root of the fork data.
+type Digest [4]byte
+
+// UnknownTypeError is returned when decoding a message whose fork digest and name are
+// not registered.
+type UnknownTypeError struct {
+	Digest Digest
+	Name   string
+}
+
+func (e *UnknownTypeError) Error() string {
+	return fmt.Sprintf("no type registered for message %s of fork digest %#x", e.Name, e.Digest)
+}
+
+// forkData is the ForkData container whose root gives the fork digest.
+type forkData struct {
+	CurrentVersion        [4]byte
+	GenesisValidatorsRoot [32]byte
+}
+
+// Compute returns the fork digest of a fork version on the chain of the given genesis
+// validators root, as compute_fork_digest does.
+func Compute(currentVersion [4]byte, genesisValidatorsRoot [32]byte) (Digest, error) {
+	root, err := ssz.HashTreeRoot(&forkData{
+		CurrentVersion:        currentVersion,
+		GenesisValidatorsRoot: genesisValidatorsRoot,
+	})
+	if err != nil {
+		return Digest{}, err
+	}
+	var d Digest
+	copy(d[:], root[:4])
+	return d, nil
+}
+
+// Registry maps fork digests and message names to types:
+//
+//  reg := forkdigest.NewRegistry()
+//  if err := reg.Register(phase0Digest, "beacon_block", (*phase0.SignedBeaconBlock)(nil)); err != nil {
+//      return err
+//  }
+//  if err := reg.Register(altairDigest, "beacon_block", (*altair.SignedBeaconBlock)(nil)); err != nil {
+//      return err
+//  }
+//  msg, err := reg.Decode(digest, "beacon_block", payload)
+//  if err != nil {
+//      return err
+//  }
+//  switch blk := msg.(type) {
+//  case *phase0.SignedBeaconBlock:
+//      ...
+//  }
+//
+// A Registry is safe for concurrent use.
+type Registry struct {
+	lock  sync.RWMutex
+	types map[key]reflect.Type
+}
+
+type key struct {
+	digest Digest
+	name   string
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{types: make(map[key]reflect.Type)}
+}
+
+// Register maps the message of the given name under a fork digest to the type of val,
+// usually a nil pointer to the type. A message may only be registered once per digest.
+func (r *Registry) Register(digest Digest, name string, val interface{}) error {
+	if val == nil {
+		return errors.New("untyped nil is not supported")
+	}
+	typ := reflect.TypeOf(val)
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	k := key{digest: digest, name: name}
+	if prev, ok := r.types[k]; ok {
+		return fmt.Errorf("message %s of fork digest %#x is already registered as %v", name, digest, prev)
+	}
+	r.types[k] = typ
+	return nil
+}
+
+// Type returns the type registered for a message of a fork digest.
+func (r *Registry) Type(digest Digest, name string) (reflect.Type, bool) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+	typ, ok := r.types[key{digest: digest, name: name}]
+	return typ, ok
+}
+
+// Decode unmarshals the payload of a message of a fork digest into a new value of the
+// registered type, and returns a pointer to it.
+func (r *Registry) Decode(digest Digest, name string, payload []byte) (interface{}, error) {
+	typ, ok := r.Type(digest, name)
+	if !ok {
+		return nil, &UnknownTypeError{Digest: digest, Name: name}
+	}
+	val := reflect.New(typ)
+	if err := ssz.Unmarshal(payload, val.Interface()); err != nil {
+		return nil, fmt.Errorf("could not decode %s of fork digest %#x: %v", name, digest, err)
+	}
+	return val.Interface(), nil
+}
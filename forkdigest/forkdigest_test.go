@@ -0,0 +1,75 @@
+package forkdigest_test
+
+import (
+	"encoding/hex"
+	"reflect"
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/forkdigest"
+)
+
+type phase0Block struct {
+	Slot          uint64
+	ProposerIndex uint64
+}
+
+type altairBlock struct {
+	Slot          uint64
+	ProposerIndex uint64
+	SyncBits      [4]byte
+}
+
+func TestCompute(t *testing.T) {
+	// The phase 0 fork digest of mainnet.
+	root, err := hex.DecodeString("4b363db94e286120d76eb905340fdd4e54bfe9f06bf33ff6cf5ad27f511bfe95")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gvr [32]byte
+	copy(gvr[:], root)
+	digest, err := forkdigest.Compute([4]byte{0, 0, 0, 0}, gvr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (forkdigest.Digest{0xb5, 0x30, 0x3f, 0x2a}); digest != want {
+		t.Errorf("Wanted fork digest %#x, received %#x", want, digest)
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	phase0, altair := forkdigest.Digest{1}, forkdigest.Digest{2}
+	reg := forkdigest.NewRegistry()
+	if err := reg.Register(phase0, "beacon_block", (*phase0Block)(nil)); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register(altair, "beacon_block", altairBlock{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := reg.Register(altair, "beacon_block", (*phase0Block)(nil)); err == nil {
+		t.Error("Expected an error when registering a message twice")
+	}
+	if typ, ok := reg.Type(altair, "beacon_block"); !ok || typ != reflect.TypeOf(altairBlock{}) {
+		t.Errorf("Wanted altairBlock, received %v", typ)
+	}
+
+	enc, err := ssz.Marshal(&altairBlock{Slot: 5, SyncBits: [4]byte{0xff}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	msg, err := reg.Decode(altair, "beacon_block", enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blk, ok := msg.(*altairBlock)
+	if !ok || blk.Slot != 5 || blk.SyncBits[0] != 0xff {
+		t.Errorf("Wanted the decoded altair block, received %#v", msg)
+	}
+	if _, err := reg.Decode(phase0, "beacon_block", enc); err == nil {
+		t.Error("Expected an error when decoding an altair block as a phase 0 block")
+	}
+	_, err = reg.Decode(phase0, "attestation", enc)
+	if e, ok := err.(*forkdigest.UnknownTypeError); !ok || e.Name != "attestation" {
+		t.Errorf("Wanted an unknown type error, received %v", err)
+	}
+}
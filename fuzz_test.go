@@ -0,0 +1,133 @@
+//go:build go1.18
+// +build go1.18
+
+package ssz_test
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	ssz "github.com/prysmaticlabs/go-ssz"
+)
+
+type fuzzFixed struct {
+	A bool
+	B uint16
+	C [4]byte
+	D uint64
+}
+
+type fuzzInner struct {
+	A []uint16 `ssz-max:"4"`
+	B [][]byte `ssz-max:"4,8"`
+}
+
+type fuzzVariable struct {
+	A uint64
+	B []byte           `ssz-max:"64"`
+	C []uint32         `ssz-max:"16"`
+	D []*fuzzInner     `ssz-max:"8"`
+	E bitfield.Bitlist `ssz-max:"32"`
+	F *fuzzFixed
+	G [2][]uint8 `ssz-size:"2,?" ssz-max:"?,4"`
+}
+
+// fuzzTargets returns fresh values of the type shapes that fuzzed input is decoded as:
+// basic values, fixed and variable size containers, and lists of lists.
+func fuzzTargets() []interface{} {
+	return []interface{}{
+		new(uint64),
+		new([3]uint16),
+		new(fuzzFixed),
+		new(fuzzVariable),
+		new([]*fuzzInner),
+		new([][]uint16),
+		new([2][]uint16),
+	}
+}
+
+// fuzzSeeds returns valid encodings of the fuzzed shapes along with pathological offset
+// tables: offsets pointing back into the fixed part, past the end of the input, out of
+// order, or read from a truncated fixed part.
+func fuzzSeeds(f *testing.F) [][]byte {
+	valid := &fuzzVariable{
+		A: 1,
+		B: []byte{1, 2, 3},
+		C: []uint32{4, 5},
+		D: []*fuzzInner{{A: []uint16{6}, B: [][]byte{{7, 8}}}, {}},
+		E: bitfield.Bitlist{0x0b},
+		F: &fuzzFixed{A: true, B: 9},
+		G: [2][]uint8{{1}, {}},
+	}
+	enc, err := ssz.Marshal(valid)
+	if err != nil {
+		f.Fatal(err)
+	}
+	lists, err := ssz.Marshal([][]uint16{{1, 2}, {3}})
+	if err != nil {
+		f.Fatal(err)
+	}
+	seeds := [][]byte{enc, lists}
+	for _, offset := range []uint32{0, 4, 0xffffffff, uint32(len(enc)) + 1, uint32(len(enc))} {
+		seed := append([]byte{}, enc...)
+		seed[8], seed[9], seed[10], seed[11] = byte(offset), byte(offset>>8), byte(offset>>16), byte(offset>>24)
+		seeds = append(seeds, seed)
+	}
+	// Offsets of a list of lists pointing backwards, a truncated offset table, and a
+	// vector of lists with too few offsets.
+	seeds = append(seeds, []byte{8, 0, 0, 0, 4, 0, 0, 0}, []byte{8, 0, 0}, []byte{4, 0, 0, 0, 1, 0})
+	return seeds
+}
+
+// FuzzUnmarshal checks that Unmarshal rejects malformed input with an error rather
+// than a panic, and that values it accepts can be hashed.
+func FuzzUnmarshal(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for _, val := range fuzzTargets() {
+			if err := ssz.Unmarshal(data, val); err != nil {
+				continue
+			}
+			if _, err := ssz.HashTreeRoot(reflect.ValueOf(val).Elem().Interface()); err != nil {
+				t.Errorf("Could not hash decoded %T: %v", val, err)
+			}
+		}
+	})
+}
+
+// FuzzRoundTrip checks that values decoded from arbitrary input encode to an
+// equivalent value and that encoding them again is stable.
+func FuzzRoundTrip(f *testing.F) {
+	for _, seed := range fuzzSeeds(f) {
+		f.Add(seed)
+	}
+	f.Fuzz(func(t *testing.T, data []byte) {
+		for i, val := range fuzzTargets() {
+			if err := ssz.Unmarshal(data, val); err != nil {
+				continue
+			}
+			enc, err := ssz.Marshal(val)
+			if err != nil {
+				t.Fatalf("Could not encode decoded %T: %v", val, err)
+			}
+			again := fuzzTargets()[i]
+			if err := ssz.Unmarshal(enc, again); err != nil {
+				t.Fatalf("Could not decode re-encoded %T: %v", val, err)
+			}
+			if !ssz.DeepEqual(val, again) {
+				t.Fatalf("Round trip of %T changed value from %v to %v", val, val, again)
+			}
+			enc2, err := ssz.Marshal(again)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !bytes.Equal(enc, enc2) {
+				t.Fatalf("Encoding of %T is not stable: %#x then %#x", val, enc, enc2)
+			}
+		}
+	})
+}
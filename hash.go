@@ -0,0 +1,130 @@
+package ssz
+
+import (
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Hash returns the hash of the data passed in, using the same hash function as
+// HashTreeRoot. Callers building their own Merkle trees should use it, rather than
+// calling a sha256 implementation directly, to benefit from the same backend.
+func Hash(data []byte) [32]byte {
+	return hashing.Hash(data)
+}
+
+// HashPair returns the root of a Merkle tree node given the roots of its children.
+func HashPair(left [32]byte, right [32]byte) [32]byte {
+	return hashing.HashPair(left, right)
+}
+
+// MixInLength returns the root of a list given the root of its elements and its length,
+// as specified by the hash tree root of SSZ lists.
+func MixInLength(root [32]byte, length uint64) [32]byte {
+	return hashing.MixInLength(root, length)
+}
+
+// Merkleize returns the root of a Merkle tree with room for limit chunks whose first
+// leaves are chunks, padded with zero chunks, as merkleize does in the SSZ specification
+// given a limit. An error is returned if there are more chunks than the limit.
+func Merkleize(chunks [][32]byte, limit uint64) ([32]byte, error) {
+	return types.MerkleizeChunks(chunks, limit)
+}
+
+// ListRoot returns the hash tree root of a list of at most limit elements given the
+// roots of its elements, such as a list of containers hashed separately or a list of
+// block roots:
+//
+//  root, err := ssz.ListRoot(blockRoots, slotsPerHistoricalRoot)
+//  if err != nil {
+//      return err
+//  }
+//
+// It is the root of the merkleized elements mixed in with the length of the list.
+func ListRoot(roots [][32]byte, limit uint64) ([32]byte, error) {
+	root, err := types.MerkleizeChunks(roots, limit)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.MixInLength(root, uint64(len(roots))), nil
+}
+
+// ZeroHash returns the root of a Merkle tree of the given depth whose leaves
+// are all zero chunks.
+func ZeroHash(depth uint8) [32]byte {
+	return hashing.ZeroHash(depth)
+}
+
+// SetHashWorkers sets the number of goroutines hashing large lists, such as the
+// validator registry and balances of a beacon state, which is 1 by default:
+//
+//  if err := ssz.SetHashWorkers(runtime.NumCPU()); err != nil {
+//      return err
+//  }
+//
+// Roots do not depend on the number of workers.
+func SetHashWorkers(n int) error {
+	return types.SetHashWorkers(n)
+}
+
+// HashBackend is an implementation of sha256 used for hashing. No multi-buffer
+// implementation is built in; one can be plugged in with SetHashBackend, and is handed
+// whole levels of pairs if it also implements HashPairs.
+type HashBackend = hashing.Backend
+
+var (
+	// StdlibHashBackend is the crypto/sha256 implementation of the standard library,
+	// which is the most widely audited one.
+	StdlibHashBackend = hashing.Stdlib
+	// SIMDHashBackend is the minio sha256-simd implementation, which detects the
+	// SHA-NI, AVX2 or ARM SHA2 instructions of the CPU itself. It hashes one message
+	// at a time, and is the default on amd64 and arm64.
+	SIMDHashBackend = hashing.SIMD
+)
+
+// HashBackendByName returns the built-in hash backend with the given name, which is
+// "sha256", "sha256-simd" or "auto" for the one preferred on the current platform, such
+// as to honor a command line flag:
+//
+//  backend, err := ssz.HashBackendByName(*hashBackendFlag)
+//  if err != nil {
+//      return err
+//  }
+//  if err := ssz.SetHashBackend(backend); err != nil {
+//      return err
+//  }
+func HashBackendByName(name string) (HashBackend, error) {
+	return hashing.BackendByName(name)
+}
+
+// SetHashBackend replaces the sha256 implementation used by every hashing function of
+// this package. The backend is checked against the standard library first, and an
+// error is returned if it disagrees, or if the configuration is locked.
+func SetHashBackend(b HashBackend) error {
+	return types.SetHashBackend(b)
+}
+
+// CurrentHashBackend returns the hash backend in use.
+func CurrentHashBackend() HashBackend {
+	return hashing.CurrentBackend()
+}
+
+// NewHashBackend returns a hash backend computing hashes with the given function, such
+// as a BLAKE3 implementation for SetTreeHash.
+func NewHashBackend(name string, sum func(data []byte) [32]byte) HashBackend {
+	return hashing.NewBackend(name, sum)
+}
+
+// SetTreeHash is an experimental mode replacing sha256 by another 32-byte hash function
+// in Merkleization, for use cases outside of consensus such as content addressing SSZ
+// payloads in private systems:
+//
+//  if err := ssz.SetTreeHash(ssz.NewHashBackend("blake3", blake3.Sum256)); err != nil {
+//      return err
+//  }
+//
+// Roots computed in this mode are not those of the SSZ specification and must not be
+// shared with Ethereum clients. The hash tree root caches are bypassed while it is in
+// use. Calling SetHashBackend restores a sha256 backend.
+func SetTreeHash(b HashBackend) error {
+	return types.SetTreeHash(b)
+}
@@ -0,0 +1,182 @@
+package hashing
+
+import (
+	stdsha256 "crypto/sha256"
+	"fmt"
+	"runtime"
+	"sync/atomic"
+
+	"github.com/minio/sha256-simd"
+)
+
+// Backend is an implementation of sha256 used for hashing.
+type Backend interface {
+	// Name identifies the backend, such as in command line flags.
+	Name() string
+	// Sum256 returns the sha256 hash of data.
+	Sum256(data []byte) [32]byte
+}
+
+type stdlibBackend struct{}
+
+func (stdlibBackend) Name() string {
+	return "sha256"
+}
+
+func (stdlibBackend) Sum256(data []byte) [32]byte {
+	return stdsha256.Sum256(data)
+}
+
+type simdBackend struct{}
+
+func (simdBackend) Name() string {
+	return "sha256-simd"
+}
+
+func (simdBackend) Sum256(data []byte) [32]byte {
+	return sha256.Sum256(data)
+}
+
+var (
+	// Stdlib is the crypto/sha256 implementation of the standard library, which is the
+	// most widely audited one.
+	Stdlib Backend = stdlibBackend{}
+	// SIMD is the minio sha256-simd implementation. It hashes one message at a time,
+	// and sha256-simd itself detects whether the CPU has SHA-NI, AVX2 or ARM SHA2
+	// instructions to use.
+	SIMD Backend = simdBackend{}
+)
+
+// backendHolder lets backends of different concrete types be stored in an atomic.Value,
+// along with the zero subtree roots they compute.
+type backendHolder struct {
+	Backend
+	zeroHashes *[MaxZeroHashDepth][32]byte
+	// custom is set for tree hashes other than sha256.
+	custom bool
+}
+
+var (
+	backend atomic.Value
+	// sha256ZeroHashes are shared by all sha256 backends.
+	sha256ZeroHashes = zeroHashLadder(Stdlib)
+)
+
+func init() {
+	backend.Store(backendHolder{Backend: Auto(), zeroHashes: sha256ZeroHashes})
+}
+
+// Auto returns the built-in backend preferred on the current platform: sha256-simd on
+// amd64 and arm64, where it has accelerated code paths, and the standard library
+// elsewhere. It only looks at the architecture; CPU features are left to sha256-simd.
+// No multi-buffer backend is built in; one hashing many pairs at once in the lanes of
+// SHA-NI or AVX-512 registers is plugged in as a PairHasher with SetBackend.
+func Auto() Backend {
+	switch runtime.GOARCH {
+	case "amd64", "arm64":
+		return SIMD
+	default:
+		return Stdlib
+	}
+}
+
+// BackendByName returns the built-in backend with the given name, "auto" standing for
+// the backend returned by Auto.
+func BackendByName(name string) (Backend, error) {
+	switch name {
+	case "auto":
+		return Auto(), nil
+	case Stdlib.Name():
+		return Stdlib, nil
+	case SIMD.Name():
+		return SIMD, nil
+	default:
+		return nil, fmt.Errorf("unknown hash backend %q", name)
+	}
+}
+
+// CurrentBackend returns the backend in use.
+func CurrentBackend() Backend {
+	return backend.Load().(backendHolder).Backend
+}
+
+// sum hashes data with the backend in use. Built-in backends are called directly, as
+// passing data to an interface method would move every buffer hashed to the heap.
+func sum(data []byte) [32]byte {
+	b := CurrentBackend()
+	switch b.(type) {
+	case simdBackend:
+		return sha256.Sum256(data)
+	case stdlibBackend:
+		return stdsha256.Sum256(data)
+	default:
+		return b.Sum256(append([]byte(nil), data...))
+	}
+}
+
+// SetBackend replaces the backend in use, once it is checked against the standard
+// library over inputs spanning several blocks, and over pairs of chunks if it is a
+// PairHasher.
+func SetBackend(b Backend) error {
+	if b == nil {
+		return fmt.Errorf("nil hash backend")
+	}
+	data := make([]byte, 4*stdsha256.BlockSize+1)
+	for i := range data {
+		data[i] = byte(i*7 + 3)
+	}
+	for i := 0; i <= len(data); i++ {
+		if got, want := b.Sum256(data[:i]), stdsha256.Sum256(data[:i]); got != want {
+			return fmt.Errorf("hash backend %s computed %#x for %d bytes, wanted %#x", b.Name(), got, i, want)
+		}
+	}
+	if ph, ok := b.(PairHasher); ok {
+		pairs := make([][32]byte, 2*len(data)/64)
+		for i := range pairs {
+			copy(pairs[i][:], data[32*i:])
+		}
+		dst := make([][32]byte, len(pairs)/2)
+		ph.HashPairs(dst, pairs)
+		for i := range dst {
+			if want := stdsha256.Sum256(data[64*i : 64*i+64]); dst[i] != want {
+				return fmt.Errorf("hash backend %s computed %#x for pair %d, wanted %#x", b.Name(), dst[i], i, want)
+			}
+		}
+	}
+	backend.Store(backendHolder{Backend: b, zeroHashes: sha256ZeroHashes})
+	return nil
+}
+
+// SetTreeHash replaces the hash function of Merkleization by one other than sha256,
+// such as BLAKE3. Roots then differ from the ones of the SSZ specification.
+func SetTreeHash(b Backend) error {
+	if b == nil {
+		return fmt.Errorf("nil hash backend")
+	}
+	backend.Store(backendHolder{Backend: b, zeroHashes: zeroHashLadder(b), custom: true})
+	return nil
+}
+
+// CustomTreeHash returns true while a tree hash set by SetTreeHash is in use.
+func CustomTreeHash() bool {
+	return backend.Load().(backendHolder).custom
+}
+
+// funcBackend adapts a hash function to the Backend interface.
+type funcBackend struct {
+	name string
+	sum  func(data []byte) [32]byte
+}
+
+func (f funcBackend) Name() string {
+	return f.name
+}
+
+func (f funcBackend) Sum256(data []byte) [32]byte {
+	return f.sum(data)
+}
+
+// NewBackend returns a backend computing hashes with the given function.
+func NewBackend(name string, sum func(data []byte) [32]byte) Backend {
+	return funcBackend{name: name, sum: sum}
+}
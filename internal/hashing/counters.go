@@ -0,0 +1,43 @@
+package hashing
+
+import (
+	"sync/atomic"
+)
+
+// counting is the number of statistics collections in progress. Work is only counted
+// while it is positive, so that the hash function does not pay for atomic additions
+// when nobody is looking.
+var counting int32
+
+var (
+	bytesHashed  uint64
+	chunksHashed uint64
+)
+
+// StartCounting enables the counters until the matching call to StopCounting.
+func StartCounting() {
+	atomic.AddInt32(&counting, 1)
+}
+
+// StopCounting disables the counters once every collection in progress is done.
+func StopCounting() {
+	atomic.AddInt32(&counting, -1)
+}
+
+// Counting returns true while a statistics collection is in progress.
+func Counting() bool {
+	return atomic.LoadInt32(&counting) > 0
+}
+
+// Counters returns the number of bytes and 32-byte chunks hashed while counting was
+// enabled. Statistics of a call are the difference between two readings.
+func Counters() (bytes uint64, chunks uint64) {
+	return atomic.LoadUint64(&bytesHashed), atomic.LoadUint64(&chunksHashed)
+}
+
+func count(n int) {
+	if atomic.LoadInt32(&counting) > 0 {
+		atomic.AddUint64(&bytesHashed, uint64(n))
+		atomic.AddUint64(&chunksHashed, uint64(n+31)/32)
+	}
+}
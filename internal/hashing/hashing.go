@@ -0,0 +1,80 @@
+// Package hashing holds the hash function and the related Merkleization primitives
+// shared by every package of this repository, so that improvements to the hashing
+// backend benefit all code paths at once. It is exposed to users through the
+// functions of the ssz package.
+package hashing
+
+import (
+	"encoding/binary"
+)
+
+// MaxZeroHashDepth is the number of precomputed zero subtree roots.
+const MaxZeroHashDepth = 100
+
+// zeroHashLadder computes the roots of all-zero subtrees with the given backend.
+func zeroHashLadder(b Backend) *[MaxZeroHashDepth][32]byte {
+	var ladder [MaxZeroHashDepth][32]byte
+	var buf [64]byte
+	for i := 1; i < MaxZeroHashDepth; i++ {
+		copy(buf[:32], ladder[i-1][:])
+		copy(buf[32:], ladder[i-1][:])
+		ladder[i] = b.Sum256(buf[:])
+	}
+	return &ladder
+}
+
+// Hash returns the sha256 hash of the data passed in, computed by the backend in use.
+func Hash(data []byte) [32]byte {
+	count(len(data))
+	return sum(data)
+}
+
+// HashPair returns the hash of the concatenation of two 32-byte chunks, which is the
+// root of a Merkle tree node given the roots of its children.
+func HashPair(left [32]byte, right [32]byte) [32]byte {
+	var buf [64]byte
+	copy(buf[:32], left[:])
+	copy(buf[32:], right[:])
+	count(len(buf))
+	return sum(buf[:])
+}
+
+// PairHasher is implemented by backends which hash many independent 64-byte inputs at
+// once, such as multi-buffer sha256 implementations filling the lanes of vector
+// instructions with unrelated messages.
+type PairHasher interface {
+	// HashPairs sets dst[i] to the hash of the concatenation of pairs[2*i] and
+	// pairs[2*i+1], where pairs holds twice as many chunks as dst.
+	HashPairs(dst [][32]byte, pairs [][32]byte)
+}
+
+// HashPairs sets dst[i] to the hash of the concatenation of pairs[2*i] and pairs[2*i+1].
+// Backends implementing PairHasher receive all the pairs in a single call; others hash
+// them one by one. dst must not overlap pairs.
+func HashPairs(dst [][32]byte, pairs [][32]byte) {
+	count(64 * len(dst))
+	if ph, ok := CurrentBackend().(PairHasher); ok {
+		ph.HashPairs(dst, pairs[:2*len(dst)])
+		return
+	}
+	var buf [64]byte
+	for i := range dst {
+		copy(buf[:32], pairs[2*i][:])
+		copy(buf[32:], pairs[2*i+1][:])
+		dst[i] = sum(buf[:])
+	}
+}
+
+// MixInLength returns hash(root + length), with the length serialized as a
+// little-endian uint256.
+func MixInLength(root [32]byte, length uint64) [32]byte {
+	var lengthChunk [32]byte
+	binary.LittleEndian.PutUint64(lengthChunk[:], length)
+	return HashPair(root, lengthChunk)
+}
+
+// ZeroHash returns the root of a Merkle tree of the given depth whose leaves are
+// all zero chunks. Depths past MaxZeroHashDepth are not supported and panic.
+func ZeroHash(depth uint8) [32]byte {
+	return backend.Load().(backendHolder).zeroHashes[depth]
+}
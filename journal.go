@@ -0,0 +1,345 @@
+package ssz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/tree"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Journal records mutations of the fields of a container, such as a beacon state, and
+// applies them on Commit to both the container and a Merkle tree retained across
+// commits. Only the branches leading to the mutated fields and elements are re-hashed,
+// which makes the journal an exact dirty tracker without having to back every field
+// with a tree.
+//
+//  j, err := ssz.NewJournal(state)
+//  if err != nil {
+//      return err
+//  }
+//  j.SetField("Slot", slot+1)
+//  j.SetElement("Balances", 5, balance)
+//  j.Append("Validators", validator)
+//  root, err := j.Commit()
+//
+// The container must not be mutated other than through the journal while it is in use.
+type Journal struct {
+	*containerTree
+	pending []journalOp
+}
+
+// containerTree is the Merkle tree of a container retained across updates, in which
+// fields and elements are re-hashed individually.
+type containerTree struct {
+	obj    reflect.Value
+	typ    reflect.Type
+	node   *tree.Node
+	depth  uint8
+	fields map[string]journalField
+}
+
+// journalField describes a field of the journaled container.
+type journalField struct {
+	index       int
+	chunk       uint64
+	typ         reflect.Type
+	maxCapacity uint64
+	// limits are the limits of the ssz-max tag of the field, one per level of nesting.
+	limits      []uint64
+	progressive bool
+}
+
+type journalOpKind int
+
+const (
+	opSetField journalOpKind = iota
+	opSetElement
+	opAppend
+)
+
+type journalOp struct {
+	kind  journalOpKind
+	field string
+	index int
+	value reflect.Value
+}
+
+// NewJournal returns a journal of the mutations of the container pointed to by obj.
+func NewJournal(obj interface{}) (*Journal, error) {
+	c, err := newContainerTree(obj)
+	if err != nil {
+		return nil, err
+	}
+	return &Journal{containerTree: c}, nil
+}
+
+// newContainerTree builds the tree of the container pointed to by obj.
+func newContainerTree(obj interface{}) (*containerTree, error) {
+	if obj == nil {
+		return nil, errors.New("untyped nil is not supported")
+	}
+	val := reflect.ValueOf(obj)
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a non-nil pointer to a struct, received %v", val.Type())
+	}
+	c := &containerTree{
+		obj:    val.Elem(),
+		typ:    val.Elem().Type(),
+		fields: make(map[string]journalField),
+	}
+	for i := 0; i < c.typ.NumField(); i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(c.typ.Field(i)) {
+			continue
+		}
+		fType, err := types.FieldType(c.typ.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		if c.typ.Field(i).Type == reflect.TypeOf(bitfield.Bitlist{}) {
+			fType = c.typ.Field(i).Type
+		}
+		c.fields[c.typ.Field(i).Name] = journalField{
+			index:       i,
+			chunk:       uint64(len(c.fields)),
+			typ:         fType,
+			maxCapacity: types.FieldCapacity(c.typ.Field(i)),
+			limits:      types.FieldCapacities(c.typ.Field(i)),
+			progressive: types.IsProgressive(c.typ.Field(i)),
+		}
+	}
+	c.depth = merkle.GetDepth(uint64(len(c.fields)))
+	node, err := valueTree(val, val.Type(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not build tree for type: %v", val.Type())
+	}
+	c.node = node
+	return c, nil
+}
+
+// SetField records the replacement of the named field with value.
+func (j *Journal) SetField(name string, value interface{}) error {
+	f, ok := j.fields[name]
+	if !ok {
+		return fmt.Errorf("no field %s in %v", name, j.typ)
+	}
+	v, err := assignableValue(value, j.typ.Field(f.index).Type)
+	if err != nil {
+		return errors.Wrapf(err, "%s.%s", j.typ.Name(), name)
+	}
+	j.pending = append(j.pending, journalOp{kind: opSetField, field: name, value: v})
+	return nil
+}
+
+// SetElement records the replacement of the element at index of the named list or
+// vector field with value.
+func (j *Journal) SetElement(name string, index int, value interface{}) error {
+	return j.recordElement(opSetElement, name, index, value)
+}
+
+// Append records the addition of value at the end of the named list field.
+func (j *Journal) Append(name string, value interface{}) error {
+	return j.recordElement(opAppend, name, -1, value)
+}
+
+func (j *Journal) recordElement(kind journalOpKind, name string, index int, value interface{}) error {
+	f, ok := j.fields[name]
+	if !ok {
+		return fmt.Errorf("no field %s in %v", name, j.typ)
+	}
+	goTyp := j.typ.Field(f.index).Type
+	if !f.sequence(goTyp) {
+		return fmt.Errorf("field %s of type %v is not a list or vector", name, goTyp)
+	}
+	if kind == opAppend && (goTyp.Kind() != reflect.Slice || f.typ.Kind() != reflect.Slice) {
+		return fmt.Errorf("cannot append to vector field %s", name)
+	}
+	v, err := assignableValue(value, goTyp.Elem())
+	if err != nil {
+		return errors.Wrapf(err, "%s.%s", j.typ.Name(), name)
+	}
+	j.pending = append(j.pending, journalOp{kind: kind, field: name, index: index, value: v})
+	return nil
+}
+
+// Commit applies the recorded mutations to the container and to its Merkle tree, in
+// the order they were recorded, and returns the new hash tree root of the container.
+// Mutations are validated before any of them is applied, so that an invalid index or
+// an append past the list limit leaves both the container and the journal untouched.
+func (j *Journal) Commit() ([32]byte, error) {
+	lengths := make(map[string]int)
+	for _, op := range j.pending {
+		f := j.fields[op.field]
+		if op.kind == opSetField {
+			if kind := op.value.Kind(); kind == reflect.Slice || kind == reflect.Array {
+				lengths[op.field] = op.value.Len()
+			}
+			continue
+		}
+		length, ok := lengths[op.field]
+		if !ok {
+			length = j.obj.Field(f.index).Len()
+		}
+		if op.kind == opSetElement && (op.index < 0 || op.index >= length) {
+			return [32]byte{}, fmt.Errorf("index %d out of range for field %s of length %d", op.index, op.field, length)
+		}
+		if op.kind == opAppend {
+			if f.maxCapacity > 0 && uint64(length) >= f.maxCapacity {
+				return [32]byte{}, fmt.Errorf("cannot append to field %s which reached its limit %d", op.field, f.maxCapacity)
+			}
+			lengths[op.field] = length + 1
+		}
+	}
+	for _, op := range j.pending {
+		if err := j.apply(op); err != nil {
+			return [32]byte{}, errors.Wrapf(err, "%s.%s", j.typ.Name(), op.field)
+		}
+	}
+	j.pending = nil
+	return j.node.Root(), nil
+}
+
+// Root returns the hash tree root of the container as of the last commit.
+func (j *Journal) Root() [32]byte {
+	return j.node.Root()
+}
+
+func (j *Journal) apply(op journalOp) error {
+	f := j.fields[op.field]
+	fieldVal := j.obj.Field(f.index)
+	switch op.kind {
+	case opSetField:
+		fieldVal.Set(op.value)
+	case opSetElement:
+		fieldVal.Index(op.index).Set(op.value)
+	case opAppend:
+		fieldVal.Set(reflect.Append(fieldVal, op.value))
+	}
+	if op.kind == opSetField || !j.fixedDepth(f) {
+		return j.rebuildField(f)
+	}
+	if op.kind == opSetElement {
+		return j.updateElement(f, op.index)
+	}
+	if err := j.updateElement(f, fieldVal.Len()-1); err != nil {
+		return err
+	}
+	return j.updateLength(f)
+}
+
+// sequence returns true if the field, held in a Go value of type goTyp, is a list or
+// vector whose elements can be updated individually.
+func (f journalField) sequence(goTyp reflect.Type) bool {
+	return f.typ != reflect.TypeOf(bitfield.Bitlist{}) && (goTyp.Kind() == reflect.Slice || goTyp.Kind() == reflect.Array)
+}
+
+func (c *containerTree) fieldGindex(f journalField) uint64 {
+	return uint64(1)<<c.depth | f.chunk
+}
+
+// fixedDepth returns true if the tree of the field keeps its depth whatever its
+// elements. Lists without limit have a depth depending on their length, as do vectors
+// held in slices of the wrong length, and progressive lists are not balanced.
+func (c *containerTree) fixedDepth(f journalField) bool {
+	if f.progressive {
+		return false
+	}
+	if f.typ.Kind() == reflect.Array {
+		return c.obj.Field(f.index).Len() == f.typ.Len()
+	}
+	return f.maxCapacity > 0
+}
+
+// rebuildField replaces the whole subtree of the field.
+func (c *containerTree) rebuildField(f journalField) error {
+	fieldVal := c.obj.Field(f.index)
+	if f.progressive {
+		node, err := progressiveTree(fieldVal, f.typ)
+		if err != nil {
+			return err
+		}
+		return c.set(c.fieldGindex(f), node)
+	}
+	node, err := valueTree(fieldVal, f.typ, f.limits)
+	if err != nil {
+		return err
+	}
+	return c.set(c.fieldGindex(f), node)
+}
+
+// updateElement re-hashes the element at index of a field of fixed depth, along with
+// the branch leading to it. Elements of basic types are re-packed with the other
+// elements of their chunk.
+func (c *containerTree) updateElement(f journalField, index int) error {
+	fieldVal := c.obj.Field(f.index)
+	depth := merkle.GetDepth(sequenceLimit(fieldVal, f.typ, f.maxCapacity))
+	dataGindex := c.fieldGindex(f)
+	if f.typ.Kind() == reflect.Slice {
+		dataGindex <<= 1
+	}
+	if bits.Len64(dataGindex)-1+int(depth) >= 64 {
+		return errors.New("generalized index overflows uint64")
+	}
+	var leaf *tree.Node
+	chunkIdx := uint64(index)
+	if elemSize, basic := basicElementSize(f.typ); basic {
+		perChunk := int(32 / elemSize)
+		chunkIdx = uint64(index) * elemSize / 32
+		start := int(chunkIdx) * perChunk
+		end := start + perChunk
+		if end > fieldVal.Len() {
+			end = fieldVal.Len()
+		}
+		chunks, _, err := sequenceChunks(fieldVal.Slice(start, end), f.typ, nil)
+		if err != nil {
+			return err
+		}
+		leaf = tree.Leaf(chunks[0])
+	} else {
+		_, elemLimits := splitLimits(f.limits)
+		node, err := valueTree(fieldVal.Index(index), f.typ.Elem(), elemLimits)
+		if err != nil {
+			return err
+		}
+		leaf = node
+	}
+	return c.set(dataGindex<<depth|chunkIdx, leaf)
+}
+
+// updateLength replaces the length mixed into the root of a list field.
+func (c *containerTree) updateLength(f journalField) error {
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(c.obj.Field(f.index).Len()))
+	return c.set(c.fieldGindex(f)<<1|1, tree.Leaf(length))
+}
+
+func (c *containerTree) set(gindex uint64, node *tree.Node) error {
+	root, err := c.node.Set(gindex, node)
+	if err != nil {
+		return err
+	}
+	c.node = root
+	return nil
+}
+
+// assignableValue returns value as a reflect value which can be assigned to typ.
+func assignableValue(value interface{}, typ reflect.Type) (reflect.Value, error) {
+	if value == nil {
+		switch typ.Kind() {
+		case reflect.Ptr, reflect.Slice, reflect.Map:
+			return reflect.Zero(typ), nil
+		}
+		return reflect.Value{}, fmt.Errorf("cannot assign nil to %v", typ)
+	}
+	v := reflect.ValueOf(value)
+	if !v.Type().AssignableTo(typ) {
+		return reflect.Value{}, fmt.Errorf("cannot assign %v to %v", v.Type(), typ)
+	}
+	return v, nil
+}
@@ -0,0 +1,89 @@
+package ssz
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+func TestJournal_CommitMatchesHashTreeRoot(t *testing.T) {
+	state := &proofState{
+		Slot:       1,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{1, 2, 3, 4, 5},
+		Bits:       bitfield.Bitlist{0x0d},
+	}
+	j, err := NewJournal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := [32]byte{1}
+	validator := &proofValidator{Pubkey: make([]byte, 48), WithdrawalCredentials: make([]byte, 32), EffectiveBalance: 32}
+	steps := []func() error{
+		func() error { return j.SetField("Slot", uint64(2)) },
+		func() error { return j.SetElement("Balances", 2, uint64(30)) },
+		func() error { return j.Append("Balances", uint64(6)) },
+		func() error { return j.SetElement("BlockRoots", 7, root[:]) },
+		func() error { return j.Append("Validators", validator) },
+		func() error {
+			if err := j.Append("Validators", validator); err != nil {
+				return err
+			}
+			return j.SetElement("Validators", 0, &proofValidator{EffectiveBalance: 16})
+		},
+		func() error { return j.SetField("Bits", bitfield.Bitlist{0x1f}) },
+		func() error { return j.SetField("Graffiti", "journal") },
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("Step %d: %v", i, err)
+		}
+		got, err := j.Commit()
+		if err != nil {
+			t.Fatalf("Step %d: %v", i, err)
+		}
+		want, err := HashTreeRoot(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want || j.Root() != want {
+			t.Errorf("Step %d: wanted root %#x, received %#x", i, want, got)
+		}
+	}
+	if state.Slot != 2 || state.Balances[2] != 30 || len(state.Validators) != 2 || state.Validators[0].EffectiveBalance != 16 {
+		t.Errorf("Mutations were not applied to the state: %+v", state)
+	}
+}
+
+func TestJournal_InvalidMutations(t *testing.T) {
+	state := &proofState{Balances: []uint64{1}}
+	j, err := NewJournal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := j.SetField("Missing", uint64(1)); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+	if err := j.SetField("Slot", "string"); err == nil {
+		t.Error("Expected error for value of the wrong type")
+	}
+	if err := j.Append("Slot", uint64(1)); err == nil {
+		t.Error("Expected error for appending to a basic field")
+	}
+	if err := j.Append("BlockRoots", make([]byte, 32)); err == nil {
+		t.Error("Expected error for appending to a vector")
+	}
+	root := j.Root()
+	if err := j.SetField("Slot", uint64(9)); err != nil {
+		t.Fatal(err)
+	}
+	if err := j.SetElement("Balances", 1, uint64(2)); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := j.Commit(); err == nil {
+		t.Fatal("Expected error for index out of range")
+	}
+	if state.Slot != 0 || j.Root() != root {
+		t.Error("Failed commit modified the state")
+	}
+}
@@ -0,0 +1,178 @@
+package ssz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// LazyContainer decodes the fields of an encoded container on first access, reading
+// them from an io.ReaderAt such as a file. Only the fixed-size part of the container,
+// holding fixed-size fields and the offsets of the others, is read upfront, so that
+// reading the genesis time of a state file does not decode its validators:
+//
+//  f, err := os.Open("state.ssz")
+//  if err != nil {
+//      return err
+//  }
+//  info, err := f.Stat()
+//  if err != nil {
+//      return err
+//  }
+//  state := &BeaconState{}
+//  lazy, err := ssz.DecodeLazy(f, info.Size(), state)
+//  if err != nil {
+//      return err
+//  }
+//  if _, err := lazy.Field("GenesisTime"); err != nil {
+//      return err
+//  }
+//  fmt.Println(state.GenesisTime)
+//
+// A LazyContainer is not safe for concurrent use.
+type LazyContainer struct {
+	r       io.ReaderAt
+	val     reflect.Value
+	fields  map[string]lazyField
+	decoded map[string]bool
+}
+
+type lazyField struct {
+	types.ContainerField
+	span
+}
+
+// span is the range of bytes holding a serialized field.
+type span struct {
+	start uint64
+	end   uint64
+}
+
+// DecodeLazy reads the fixed-size part of a container of size bytes from r, and returns
+// a LazyContainer decoding fields into the struct pointed to by val on first access.
+func DecodeLazy(r io.ReaderAt, size int64, val interface{}) (*LazyContainer, error) {
+	if val == nil {
+		return nil, errors.New("cannot unmarshal into untyped, nil value")
+	}
+	rval := reflect.ValueOf(val)
+	if rval.Kind() != reflect.Ptr || rval.IsNil() || rval.Elem().Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a non-nil pointer to a struct, received %v", rval.Type())
+	}
+	if size < 0 {
+		return nil, fmt.Errorf("negative size %d", size)
+	}
+	fields, err := types.ContainerFields(rval.Elem().Type())
+	if err != nil {
+		return nil, err
+	}
+	fixed := make([]byte, fixedPartSize(fields))
+	if uint64(len(fixed)) > uint64(size) {
+		return nil, fmt.Errorf("container of %d bytes is smaller than its fixed-size part of %d bytes", size, len(fixed))
+	}
+	if _, err := r.ReadAt(fixed, 0); err != nil {
+		return nil, errors.Wrap(err, "could not read fixed-size part")
+	}
+	spans, err := fieldSpans(fields, fixed, uint64(size))
+	if err != nil {
+		return nil, err
+	}
+	l := &LazyContainer{
+		r:       r,
+		val:     rval.Elem(),
+		fields:  make(map[string]lazyField, len(fields)),
+		decoded: make(map[string]bool, len(fields)),
+	}
+	for i, f := range fields {
+		l.fields[f.Name] = lazyField{ContainerField: f, span: spans[i]}
+	}
+	return l, nil
+}
+
+// FieldBytes reads the serialization of the named field.
+func (l *LazyContainer) FieldBytes(name string) ([]byte, error) {
+	f, ok := l.fields[name]
+	if !ok {
+		return nil, fmt.Errorf("no field %s in %v", name, l.val.Type())
+	}
+	buf := make([]byte, f.end-f.start)
+	if len(buf) == 0 {
+		return buf, nil
+	}
+	if _, err := l.r.ReadAt(buf, int64(f.start)); err != nil {
+		return nil, errors.Wrapf(err, "could not read field %s", name)
+	}
+	return buf, nil
+}
+
+// Field decodes the named field into the struct on first access, and returns its value.
+func (l *LazyContainer) Field(name string) (interface{}, error) {
+	f, ok := l.fields[name]
+	if !ok {
+		return nil, fmt.Errorf("no field %s in %v", name, l.val.Type())
+	}
+	if !l.decoded[name] {
+		buf, err := l.FieldBytes(name)
+		if err != nil {
+			return nil, err
+		}
+		if err := types.UnmarshalField(l.val, f.Index, buf); err != nil {
+			return nil, errors.Wrapf(err, "could not unmarshal field %s", name)
+		}
+		l.decoded[name] = true
+	}
+	return l.val.Field(f.Index).Interface(), nil
+}
+
+// fixedPartSize returns the size of the fixed-size part of a container, where fields of
+// variable size are replaced by their offset.
+func fixedPartSize(fields []types.ContainerField) uint64 {
+	size := uint64(0)
+	for _, f := range fields {
+		if f.Variable {
+			size += types.BytesPerLengthOffset
+		} else {
+			size += f.Size
+		}
+	}
+	return size
+}
+
+// fieldSpans locates the fields of a container of size bytes from its fixed-size part.
+// Offsets must start right after the fixed-size part and must not decrease.
+func fieldSpans(fields []types.ContainerField, fixed []byte, size uint64) ([]span, error) {
+	spans := make([]span, len(fields))
+	var variable []int
+	pos := uint64(0)
+	for i, f := range fields {
+		if !f.Variable {
+			spans[i] = span{start: pos, end: pos + f.Size}
+			pos += f.Size
+			continue
+		}
+		spans[i].start = uint64(binary.LittleEndian.Uint32(fixed[pos:]))
+		pos += types.BytesPerLengthOffset
+		variable = append(variable, i)
+	}
+	if len(variable) == 0 && size != pos {
+		return nil, fmt.Errorf("fixed-size container of %d bytes has %d bytes", pos, size)
+	}
+	for k, i := range variable {
+		start := spans[i].start
+		if k == 0 && start != pos {
+			return nil, fmt.Errorf("first offset %d does not follow the fixed-size part of %d bytes", start, pos)
+		}
+		end := size
+		if k+1 < len(variable) {
+			end = spans[variable[k+1]].start
+		}
+		if start > end || end > size {
+			return nil, fmt.Errorf("offset %d of field %s is out of order", start, fields[i].Name)
+		}
+		spans[i].end = end
+	}
+	return spans, nil
+}
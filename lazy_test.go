@@ -0,0 +1,82 @@
+package ssz
+
+import (
+	"bytes"
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+// countingReaderAt records the number of bytes read.
+type countingReaderAt struct {
+	r    *bytes.Reader
+	read int
+}
+
+func (c *countingReaderAt) ReadAt(p []byte, off int64) (int, error) {
+	c.read += len(p)
+	return c.r.ReadAt(p, off)
+}
+
+func TestDecodeLazy(t *testing.T) {
+	state := &proofState{
+		Slot:       9,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{1, 2, 3},
+		Bits:       bitfield.Bitlist{0x0d},
+		Graffiti:   "lazy",
+	}
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = make([]byte, 32)
+		state.BlockRoots[i][0] = byte(i)
+	}
+	for i := 0; i < 100; i++ {
+		state.Validators = append(state.Validators, &proofValidator{Pubkey: make([]byte, 48), WithdrawalCredentials: make([]byte, 32), EffectiveBalance: uint64(i)})
+	}
+	enc := mustMarshal(t, state)
+	r := &countingReaderAt{r: bytes.NewReader(enc)}
+	decoded := &proofState{}
+	lazy, err := DecodeLazy(r, int64(len(enc)), decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	slot, err := lazy.Field("Slot")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if slot.(uint64) != 9 || decoded.Slot != 9 {
+		t.Errorf("Wanted slot 9, received %v", slot)
+	}
+	if r.read > (8+8*32+4*4)+8 {
+		t.Errorf("Read %d bytes to decode the slot", r.read)
+	}
+	if len(decoded.Validators) != 0 {
+		t.Error("Validators were decoded before being accessed")
+	}
+	for _, name := range []string{"BlockRoots", "Validators", "Balances", "Bits", "Graffiti"} {
+		if _, err := lazy.Field(name); err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+	}
+	if !reflect.DeepEqual(decoded, state) {
+		t.Errorf("Wanted %+v, received %+v", state, decoded)
+	}
+	raw, err := lazy.FieldBytes("Graffiti")
+	if err != nil || string(raw) != "lazy" {
+		t.Errorf("Wanted graffiti bytes, received %q: %v", raw, err)
+	}
+	if _, err := lazy.Field("Missing"); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+
+	if _, err := DecodeLazy(bytes.NewReader(enc[:10]), 10, &proofState{}); err == nil {
+		t.Error("Expected error for truncated container")
+	}
+	corrupted := append([]byte(nil), enc...)
+	binary.LittleEndian.PutUint32(corrupted[8+8*32:], 1)
+	if _, err := DecodeLazy(bytes.NewReader(corrupted), int64(len(corrupted)), &proofState{}); err == nil {
+		t.Error("Expected error for offset inside the fixed-size part")
+	}
+}
@@ -0,0 +1,34 @@
+package ssz
+
+// Generalized indices in an Altair beacon state of the values proven to light clients,
+// as defined by the light-client sync protocol. Later forks adding fields to the state
+// move them deeper in its tree, which the proofs below account for.
+const (
+	FinalizedRootGindex        = 105
+	CurrentSyncCommitteeGindex = 54
+	NextSyncCommitteeGindex    = 55
+)
+
+// CurrentSyncCommitteeProof generates the proof of the current sync committee of a
+// beacon state, which a light-client bootstrap carries along with the committee:
+//
+//  proof, err := ssz.CurrentSyncCommitteeProof(state)
+//  if err != nil {
+//      return errors.Wrap(err, "could not prove current sync committee")
+//  }
+//  bootstrap.CurrentSyncCommitteeBranch = proof.Branch
+func CurrentSyncCommitteeProof(state interface{}) (*MerkleProof, error) {
+	return Proof(state, "CurrentSyncCommittee")
+}
+
+// NextSyncCommitteeProof generates the proof of the next sync committee of a beacon
+// state, which a light-client update carries along with the committee.
+func NextSyncCommitteeProof(state interface{}) (*MerkleProof, error) {
+	return Proof(state, "NextSyncCommittee")
+}
+
+// FinalityProof generates the proof of the root of the finalized checkpoint of a beacon
+// state, which a light-client update carries along with the finalized header.
+func FinalityProof(state interface{}) (*MerkleProof, error) {
+	return Proof(state, "FinalizedCheckpoint", "Root")
+}
@@ -0,0 +1,85 @@
+package ssz
+
+import (
+	"testing"
+)
+
+type lightClientCheckpoint struct {
+	Epoch uint64
+	Root  []byte `ssz-size:"32"`
+}
+
+type lightClientSyncCommittee struct {
+	Pubkeys         [][]byte `ssz-size:"512,48"`
+	AggregatePubkey []byte   `ssz-size:"48"`
+}
+
+// lightClientState has the layout of an Altair beacon state, with the fields preceding
+// the finalized checkpoint replaced by integers.
+type lightClientState struct {
+	F0, F1, F2, F3, F4, F5, F6, F7, F8, F9           uint64
+	F10, F11, F12, F13, F14, F15, F16, F17, F18, F19 uint64
+	FinalizedCheckpoint                              *lightClientCheckpoint
+	InactivityScores                                 []uint64 `ssz-max:"1099511627776"`
+	CurrentSyncCommittee                             *lightClientSyncCommittee
+	NextSyncCommittee                                *lightClientSyncCommittee
+}
+
+func TestLightClientProofs(t *testing.T) {
+	state := &lightClientState{
+		F0:                  1,
+		FinalizedCheckpoint: &lightClientCheckpoint{Epoch: 3, Root: make([]byte, 32)},
+		InactivityScores:    []uint64{1, 2, 3},
+	}
+	state.FinalizedCheckpoint.Root[0] = 0xaa
+	for _, c := range []**lightClientSyncCommittee{&state.CurrentSyncCommittee, &state.NextSyncCommittee} {
+		*c = &lightClientSyncCommittee{Pubkeys: make([][]byte, 512), AggregatePubkey: make([]byte, 48)}
+		for i := range (*c).Pubkeys {
+			(*c).Pubkeys[i] = make([]byte, 48)
+			(*c).Pubkeys[i][0] = byte(i)
+		}
+	}
+	state.NextSyncCommittee.AggregatePubkey[0] = 1
+	root, err := HashTreeRoot(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name   string
+		prove  func(interface{}) (*MerkleProof, error)
+		leaf   interface{}
+		gindex uint64
+	}{
+		{name: "current sync committee", prove: CurrentSyncCommitteeProof, leaf: state.CurrentSyncCommittee, gindex: CurrentSyncCommitteeGindex},
+		{name: "next sync committee", prove: NextSyncCommitteeProof, leaf: state.NextSyncCommittee, gindex: NextSyncCommitteeGindex},
+		{name: "finality", prove: FinalityProof, gindex: FinalizedRootGindex},
+	}
+	for _, tt := range tests {
+		proof, err := tt.prove(state)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.name, err)
+		}
+		if proof.GeneralizedIndex != tt.gindex {
+			t.Errorf("%s: wanted generalized index %d, received %d", tt.name, tt.gindex, proof.GeneralizedIndex)
+		}
+		if proof.Root != root || verifyBranch(proof) != root {
+			t.Errorf("%s: branch does not verify against the state root", tt.name)
+		}
+		var leaf [32]byte
+		if tt.leaf != nil {
+			if leaf, err = HashTreeRoot(tt.leaf); err != nil {
+				t.Fatal(err)
+			}
+		} else {
+			copy(leaf[:], state.FinalizedCheckpoint.Root)
+		}
+		if proof.Leaf != leaf {
+			t.Errorf("%s: wanted leaf %#x, received %#x", tt.name, leaf, proof.Leaf)
+		}
+	}
+
+	if _, err := FinalityProof(&fork{}); err == nil {
+		t.Error("Expected an error for a state without a finalized checkpoint")
+	}
+}
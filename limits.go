@@ -0,0 +1,54 @@
+package ssz
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// LimitExceededError is returned by Unmarshal and HashTreeRoot when a list holds more
+// elements than the limit given by its ssz-max tag.
+type LimitExceededError = types.LimitExceededError
+
+// WriteLimitReport emits a report of every list limit, vector size and Merkle tree
+// depth used by the types of the given values, flagging suspicious configurations.
+// Misconfigured limits do not cause errors when computing roots, they silently produce
+// wrong ones, so this report is meant to be reviewed whenever types change.
+//
+//	if err := WriteLimitReport(os.Stdout, &BeaconState{}, &BeaconBlock{}); err != nil {
+//	    return err
+//	}
+func WriteLimitReport(w io.Writer, vals ...interface{}) error {
+	tw := tabwriter.NewWriter(w, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, "PATH\tKIND\tLIMIT\tCHUNKS\tDEPTH\tWARNINGS")
+	for _, val := range vals {
+		if val == nil {
+			return errors.New("untyped nil is not supported")
+		}
+		typ := reflect.TypeOf(val)
+		entries, err := types.CollectLimits(typ)
+		if err != nil {
+			return errors.Wrapf(err, "could not collect limits for type: %v", typ)
+		}
+		for _, e := range entries {
+			fmt.Fprintf(tw, "%s\t%s\t%d\t%d\t%d\t%s\n", e.Path, e.Kind, e.Limit, e.ChunkCount, e.Depth, strings.Join(e.Warnings, "; "))
+		}
+	}
+	return tw.Flush()
+}
+
+// Lint flags the field definitions of a struct type which are likely to diverge from the
+// specification, such as untagged slices, lists without limits, signed integers or
+// interface fields.
+//
+//  for _, issue := range Lint(reflect.TypeOf(BeaconState{})) {
+//      log.Println(issue)
+//  }
+func Lint(typ reflect.Type) []types.LintIssue {
+	return types.Lint(typ)
+}
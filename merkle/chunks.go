@@ -0,0 +1,67 @@
+package merkle
+
+import (
+	"fmt"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+)
+
+// BytesPerChunk is the size of the chunks which are the leaves of Merkle trees.
+const BytesPerChunk = 32
+
+// Pack concatenates the serializations of basic values of the same type, such as the
+// little-endian encodings of uint64 values, and splits them into chunks, right-padding
+// the last chunk with zero bytes, as pack does in the SSZ specification. Values must
+// all be 1, 2, 4, 8, 16 or 32 bytes long. No chunk is returned for no values.
+func Pack(values [][]byte) ([][32]byte, error) {
+	if len(values) == 0 {
+		return nil, nil
+	}
+	size := len(values[0])
+	switch size {
+	case 1, 2, 4, 8, 16, 32:
+	default:
+		return nil, fmt.Errorf("basic values of %d bytes cannot be packed", size)
+	}
+	chunks := make([][32]byte, (len(values)*size+BytesPerChunk-1)/BytesPerChunk)
+	for i, v := range values {
+		if len(v) != size {
+			return nil, fmt.Errorf("value %d is %d bytes long, wanted %d", i, len(v), size)
+		}
+		offset := i * size
+		copy(chunks[offset/BytesPerChunk][offset%BytesPerChunk:], v)
+	}
+	return chunks, nil
+}
+
+// PackBytes splits data into chunks, right-padding the last chunk with zero bytes, such
+// as the contents of a byte list. No chunk is returned for no data.
+func PackBytes(data []byte) [][32]byte {
+	chunks := make([][32]byte, (len(data)+BytesPerChunk-1)/BytesPerChunk)
+	for i := range chunks {
+		copy(chunks[i][:], data[i*BytesPerChunk:])
+	}
+	return chunks
+}
+
+// Merkleize returns the root of a Merkle tree with room for limit chunks whose first
+// leaves are chunks, padded with zero chunks, as merkleize does in the SSZ specification
+// given a limit. The limit of a vector is its number of chunks. An error is returned if
+// there are more chunks than the limit. Chunks are left unmodified.
+func Merkleize(chunks [][32]byte, limit uint64) ([32]byte, error) {
+	return ssz.Merkleize(chunks, limit)
+}
+
+// MixInLength returns the root of a list given the root of its merkleized chunks and its
+// length, as mix_in_length does in the SSZ specification.
+func MixInLength(root [32]byte, length uint64) [32]byte {
+	return ssz.MixInLength(root, length)
+}
+
+// MixInSelector returns the root of a union given the root of its value and the selector
+// of its type, as mix_in_selector does in the SSZ specification.
+func MixInSelector(root [32]byte, selector uint8) [32]byte {
+	var selectorChunk [32]byte
+	selectorChunk[0] = selector
+	return ssz.HashPair(root, selectorChunk)
+}
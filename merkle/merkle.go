@@ -0,0 +1,119 @@
+// Package merkle verifies the Merkle proofs generated by the ssz package against
+// hash tree roots, following the semantics of is_valid_merkle_branch and
+// calculate_multi_merkle_root from the consensus specification. It also exposes the
+// pack, merkleize and mix_in_length primitives of the SSZ specification, for projects
+// computing hash tree roots of their own.
+package merkle
+
+import (
+	"errors"
+	"sort"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+// IsValidMerkleBranch checks that a leaf at the given index of a tree of the given
+// depth is committed to by root, where branch holds the sibling roots from the leaf
+// upwards.
+func IsValidMerkleBranch(leaf [32]byte, branch [][32]byte, depth uint64, index uint64, root [32]byte) bool {
+	if uint64(len(branch)) < depth {
+		return false
+	}
+	node := leaf
+	for i := uint64(0); i < depth; i++ {
+		if index>>i&1 == 1 {
+			node = ssz.HashPair(branch[i], node)
+		} else {
+			node = ssz.HashPair(node, branch[i])
+		}
+	}
+	return node == root
+}
+
+// VerifyProof checks that a single-leaf proof is valid against root. The branch must
+// be exactly as long as the depth of the generalized index of the proof.
+func VerifyProof(root [32]byte, proof *ssz.MerkleProof) bool {
+	if proof == nil || proof.GeneralizedIndex == 0 {
+		return false
+	}
+	depth := uint64(0)
+	for g := proof.GeneralizedIndex; g > 1; g >>= 1 {
+		depth++
+	}
+	if uint64(len(proof.Branch)) != depth {
+		return false
+	}
+	index := proof.GeneralizedIndex - uint64(1)<<depth
+	return IsValidMerkleBranch(proof.Leaf, proof.Branch, depth, index, root)
+}
+
+// CalculateMultiMerkleRoot computes the root committing to the leaves at the given
+// generalized indices, given the helper nodes of a multiproof sorted by decreasing
+// generalized index.
+func CalculateMultiMerkleRoot(leaves [][32]byte, proof [][32]byte, indices []uint64) ([32]byte, error) {
+	if len(leaves) != len(indices) {
+		return [32]byte{}, errors.New("number of leaves and indices differ")
+	}
+	helpers := tree.HelperIndices(indices)
+	if len(proof) != len(helpers) {
+		return [32]byte{}, errors.New("number of proof nodes and helper indices differ")
+	}
+	objects, err := multiproofObjects(leaves, proof, indices, helpers)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	foldObjects(objects)
+	root, ok := objects[1]
+	if !ok {
+		return [32]byte{}, errors.New("proof does not cover the root")
+	}
+	return root, nil
+}
+
+// multiproofObjects maps the generalized indices of the leaves and helper nodes of a
+// multiproof to their roots.
+func multiproofObjects(leaves [][32]byte, proof [][32]byte, indices []uint64, helpers []uint64) (map[uint64][32]byte, error) {
+	objects := make(map[uint64][32]byte, len(indices)+len(helpers))
+	for i, g := range indices {
+		if g == 0 {
+			return nil, errors.New("generalized index 0 is invalid")
+		}
+		objects[g] = leaves[i]
+	}
+	for i, g := range helpers {
+		objects[g] = proof[i]
+	}
+	return objects, nil
+}
+
+// foldObjects hashes pairs of sibling nodes into their parent, from the deepest nodes
+// upwards, until no more parent can be computed.
+func foldObjects(objects map[uint64][32]byte) {
+	keys := make([]uint64, 0, len(objects))
+	for g := range objects {
+		keys = append(keys, g)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] > keys[j] })
+	for pos := 0; pos < len(keys); pos++ {
+		g := keys[pos]
+		if g <= 1 {
+			continue
+		}
+		_, hasSibling := objects[g^1]
+		_, hasParent := objects[g/2]
+		if hasSibling && !hasParent {
+			objects[g/2] = ssz.HashPair(objects[g&^1], objects[g|1])
+			keys = append(keys, g/2)
+		}
+	}
+}
+
+// VerifyMultiproof checks that a multiproof is valid against root.
+func VerifyMultiproof(root [32]byte, proof *ssz.MerkleMultiproof) bool {
+	if proof == nil {
+		return false
+	}
+	computed, err := CalculateMultiMerkleRoot(proof.Leaves, proof.Proof, proof.Indices)
+	return err == nil && computed == root
+}
@@ -0,0 +1,192 @@
+package merkle_test
+
+import (
+	"encoding/binary"
+	"reflect"
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/merkle"
+)
+
+type validator struct {
+	Pubkey                []byte `ssz-size:"48"`
+	WithdrawalCredentials []byte `ssz-size:"32"`
+	EffectiveBalance      uint64
+	Slashed               bool
+}
+
+type state struct {
+	Slot       uint64
+	Validators []*validator `ssz-max:"1099511627776"`
+	Balances   []uint64     `ssz-max:"1099511627776"`
+}
+
+func testState() *state {
+	s := &state{Slot: 9, Balances: []uint64{32, 31, 30}}
+	for i := 0; i < 3; i++ {
+		s.Validators = append(s.Validators, &validator{
+			Pubkey:                make([]byte, 48),
+			WithdrawalCredentials: []byte{byte(i + 1)},
+			EffectiveBalance:      uint64(i),
+		})
+	}
+	return s
+}
+
+func TestVerifyProof(t *testing.T) {
+	s := testState()
+	root, err := ssz.HashTreeRoot(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := ssz.Proof(s, "Validators", 1, "WithdrawalCredentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merkle.VerifyProof(root, proof) {
+		t.Error("Expected proof to verify")
+	}
+	proof.Leaf[0] ^= 1
+	if merkle.VerifyProof(root, proof) {
+		t.Error("Expected proof with a modified leaf to fail")
+	}
+	proof.Leaf[0] ^= 1
+	proof.GeneralizedIndex ^= 2
+	if merkle.VerifyProof(root, proof) {
+		t.Error("Expected proof with a modified generalized index to fail")
+	}
+	proof.GeneralizedIndex ^= 2
+	proof.Branch = proof.Branch[:len(proof.Branch)-1]
+	if merkle.VerifyProof(root, proof) {
+		t.Error("Expected proof with a truncated branch to fail")
+	}
+	if merkle.VerifyProof(root, nil) {
+		t.Error("Expected nil proof to fail")
+	}
+}
+
+func TestVerifyMultiproof(t *testing.T) {
+	s := testState()
+	root, err := ssz.HashTreeRoot(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gindices []uint64
+	for _, path := range [][]interface{}{
+		{"Slot"},
+		{"Validators", 0, "EffectiveBalance"},
+		{"Validators", 2, "Slashed"},
+		{"Balances", 1},
+	} {
+		proof, err := ssz.Proof(s, path...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gindices = append(gindices, proof.GeneralizedIndex)
+	}
+	mp, err := ssz.Multiproof(s, gindices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merkle.VerifyMultiproof(root, mp) {
+		t.Error("Expected multiproof to verify")
+	}
+	mp.Leaves[1][0] ^= 1
+	if merkle.VerifyMultiproof(root, mp) {
+		t.Error("Expected multiproof with a modified leaf to fail")
+	}
+	mp.Leaves[1][0] ^= 1
+	mp.Proof = mp.Proof[1:]
+	if merkle.VerifyMultiproof(root, mp) {
+		t.Error("Expected multiproof with a missing helper node to fail")
+	}
+}
+
+func TestVerifyMultiproofParallel(t *testing.T) {
+	s := &state{}
+	for i := 0; i < 1000; i++ {
+		s.Validators = append(s.Validators, &validator{
+			Pubkey:                make([]byte, 48),
+			WithdrawalCredentials: []byte{byte(i), byte(i >> 8)},
+			EffectiveBalance:      uint64(i),
+		})
+		s.Balances = append(s.Balances, uint64(i))
+	}
+	root, err := ssz.HashTreeRoot(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Withdrawal credentials of every other validator, along with the last balance.
+	var gindices []uint64
+	for i := uint64(0); i < 1000; i += 2 {
+		gindices = append(gindices, (10<<40|i)<<2|1)
+	}
+	gindices = append(gindices, 12<<38|999/4)
+	proof, err := ssz.Multiproof(s, gindices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{1, 2, 3, 8, 64} {
+		got, err := merkle.CalculateMultiMerkleRootParallel(proof.Leaves, proof.Proof, proof.Indices, workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != root {
+			t.Errorf("%d workers: wanted root %#x, received %#x", workers, root, got)
+		}
+		if !merkle.VerifyMultiproofParallel(root, proof, workers) {
+			t.Errorf("%d workers: valid multiproof rejected", workers)
+		}
+	}
+	proof.Leaves[100][0] ^= 1
+	if merkle.VerifyMultiproofParallel(root, proof, 8) {
+		t.Error("Tampered multiproof accepted")
+	}
+	if _, err := merkle.CalculateMultiMerkleRootParallel(proof.Leaves, proof.Proof[1:], proof.Indices, 8); err == nil {
+		t.Error("Expected error for missing proof nodes")
+	}
+}
+
+func TestPackMerkleize(t *testing.T) {
+	balances := []uint64{32, 31, 30, 29, 28}
+	want, err := ssz.HashTreeRootWithCapacity(balances, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := make([][]byte, len(balances))
+	for i, b := range balances {
+		values[i] = make([]byte, 8)
+		binary.LittleEndian.PutUint64(values[i], b)
+	}
+	chunks, err := merkle.Pack(values)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(chunks) != 2 {
+		t.Fatalf("Wanted 2 chunks, received %d", len(chunks))
+	}
+	root, err := merkle.Merkleize(chunks, 1024*8/merkle.BytesPerChunk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := merkle.MixInLength(root, uint64(len(balances))); got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+	var flat []byte
+	for _, v := range values {
+		flat = append(flat, v...)
+	}
+	if packed := merkle.PackBytes(flat); !reflect.DeepEqual(packed, chunks) {
+		t.Errorf("Wanted chunks %#x, received %#x", chunks, packed)
+	}
+	if _, err := merkle.Pack([][]byte{{1, 2, 3}}); err == nil {
+		t.Error("Expected error for values of an invalid size")
+	}
+	if _, err := merkle.Pack([][]byte{{1, 2}, {3}}); err == nil {
+		t.Error("Expected error for values of different sizes")
+	}
+	if _, err := merkle.Merkleize(chunks, 1); err == nil {
+		t.Error("Expected error for chunks over the limit")
+	}
+}
@@ -0,0 +1,104 @@
+package merkle
+
+import (
+	"errors"
+	"fmt"
+	"math/bits"
+	"sync"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+// minParallelLeaves is the number of leaves under which multiproofs are verified on the
+// calling goroutine, as spawning workers costs more than hashing a small proof.
+const minParallelLeaves = 256
+
+// CalculateMultiMerkleRootParallel computes the same root as CalculateMultiMerkleRoot,
+// using up to workers goroutines. The tree is split into disjoint subtrees whose roots
+// are computed concurrently, then joined at their shared ancestors. Every subtree is
+// folded the same way regardless of scheduling, so the result is deterministic.
+func CalculateMultiMerkleRootParallel(leaves [][32]byte, proof [][32]byte, indices []uint64, workers int) ([32]byte, error) {
+	if workers <= 1 || len(indices) < minParallelLeaves {
+		return CalculateMultiMerkleRoot(leaves, proof, indices)
+	}
+	if len(leaves) != len(indices) {
+		return [32]byte{}, errors.New("number of leaves and indices differ")
+	}
+	helpers := tree.HelperIndices(indices)
+	if len(proof) != len(helpers) {
+		return [32]byte{}, errors.New("number of proof nodes and helper indices differ")
+	}
+	objects, err := multiproofObjects(leaves, proof, indices, helpers)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	// We split the tree at a depth with a few subtrees per worker, so that the work is
+	// balanced even when the leaves are not evenly spread.
+	splitDepth := bits.Len(uint(workers*4 - 1))
+	top := make(map[uint64][32]byte)
+	subtrees := make(map[uint64]map[uint64][32]byte)
+	order := make([]uint64, 0)
+	for g, r := range objects {
+		depth := bits.Len64(g) - 1
+		if depth <= splitDepth {
+			top[g] = r
+			continue
+		}
+		ancestor := g >> uint(depth-splitDepth)
+		if _, ok := subtrees[ancestor]; !ok {
+			subtrees[ancestor] = make(map[uint64][32]byte)
+			order = append(order, ancestor)
+		}
+		subtrees[ancestor][g] = r
+	}
+	roots := make([][32]byte, len(order))
+	errs := make([]error, len(order))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				sub := subtrees[order[i]]
+				foldObjects(sub)
+				r, ok := sub[order[i]]
+				if !ok {
+					errs[i] = fmt.Errorf("proof does not cover the subtree at generalized index %d", order[i])
+					continue
+				}
+				roots[i] = r
+			}
+		}()
+	}
+	for i := range order {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+	for i, g := range order {
+		if errs[i] != nil {
+			return [32]byte{}, errs[i]
+		}
+		if _, ok := top[g]; !ok {
+			top[g] = roots[i]
+		}
+	}
+	foldObjects(top)
+	root, ok := top[1]
+	if !ok {
+		return [32]byte{}, errors.New("proof does not cover the root")
+	}
+	return root, nil
+}
+
+// VerifyMultiproofParallel checks that a multiproof is valid against root, verifying
+// disjoint parts of the proof on up to workers goroutines.
+func VerifyMultiproofParallel(root [32]byte, proof *ssz.MerkleMultiproof, workers int) bool {
+	if proof == nil {
+		return false
+	}
+	computed, err := CalculateMultiMerkleRootParallel(proof.Leaves, proof.Proof, proof.Indices, workers)
+	return err == nil && computed == root
+}
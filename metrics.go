@@ -0,0 +1,132 @@
+package ssz
+
+import (
+	"sync"
+	"sync/atomic"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Metrics receives counts of the work done by the package, such as to export them as
+// Prometheus counters without this package depending on a metrics library:
+//
+//  type promMetrics struct {
+//      encoded, decoded, roots, chunks, hits prometheus.Counter
+//  }
+//
+//  func (m *promMetrics) BytesEncoded(n uint64)  { m.encoded.Add(float64(n)) }
+//  func (m *promMetrics) BytesDecoded(n uint64)  { m.decoded.Add(float64(n)) }
+//  func (m *promMetrics) RootsComputed(n uint64) { m.roots.Add(float64(n)) }
+//  func (m *promMetrics) ChunksHashed(n uint64)  { m.chunks.Add(float64(n)) }
+//  func (m *promMetrics) CacheHits(n uint64)     { m.hits.Add(float64(n)) }
+//
+// Methods are called from the goroutines calling the package, and must be safe for
+// concurrent use.
+type Metrics interface {
+	// BytesEncoded is called with the size of the values encoded by Marshal or an
+	// Encoder.
+	BytesEncoded(n uint64)
+	// BytesDecoded is called with the size of the input decoded by Unmarshal or a
+	// Decoder.
+	BytesDecoded(n uint64)
+	// RootsComputed is called with the number of hash tree roots computed by
+	// HashTreeRoot and HashTreeRootWith.
+	RootsComputed(n uint64)
+	// ChunksHashed is called with the number of 32-byte chunks fed to the hash
+	// function, twice the number of Merkle tree nodes hashed.
+	ChunksHashed(n uint64)
+	// CacheHits is called with the number of roots served by the hash tree root
+	// caches rather than computed.
+	CacheHits(n uint64)
+}
+
+// metricsHolder wraps the metrics sink, which may be nil, for storage in an atomic.Value.
+type metricsHolder struct {
+	m Metrics
+}
+
+var (
+	metrics     atomic.Value
+	metricsLock sync.Mutex
+	// reportedChunks and reportedHits are the readings of the process-wide counters
+	// which were reported already.
+	reportedChunks uint64
+	reportedHits   uint64
+)
+
+func init() {
+	metrics.Store(metricsHolder{})
+}
+
+// SetMetrics sets the sink of the metrics of the package, or removes it if m is nil.
+// The hash function counts its work while a sink is set, at the cost of an atomic
+// addition per hash.
+func SetMetrics(m Metrics) {
+	metricsLock.Lock()
+	defer metricsLock.Unlock()
+	prev := currentMetrics()
+	switch {
+	case prev == nil && m != nil:
+		hashing.StartCounting()
+		_, chunks := hashing.Counters()
+		atomic.StoreUint64(&reportedChunks, chunks)
+		atomic.StoreUint64(&reportedHits, types.CacheHits())
+	case prev != nil && m == nil:
+		hashing.StopCounting()
+	}
+	metrics.Store(metricsHolder{m: m})
+}
+
+func currentMetrics() Metrics {
+	return metrics.Load().(metricsHolder).m
+}
+
+// reportHashing reports the chunks hashed and the cache hits since the last report.
+// Counters are process-wide, so the work of concurrent calls may be reported by either
+// of them, but it is reported exactly once.
+func reportHashing(m Metrics) {
+	_, chunks := hashing.Counters()
+	if n := claimDelta(&reportedChunks, chunks); n > 0 {
+		m.ChunksHashed(n)
+	}
+	if n := claimDelta(&reportedHits, types.CacheHits()); n > 0 {
+		m.CacheHits(n)
+	}
+}
+
+// claimDelta moves the reported reading of a counter forward to current, and returns the
+// amount it moved by.
+func claimDelta(reported *uint64, current uint64) uint64 {
+	for {
+		prev := atomic.LoadUint64(reported)
+		if current <= prev {
+			return 0
+		}
+		if atomic.CompareAndSwapUint64(reported, prev, current) {
+			return current - prev
+		}
+	}
+}
+
+// reportRoots reports the computation of n roots along with the hashing it took.
+func reportRoots(n uint64) {
+	if m := currentMetrics(); m != nil {
+		m.RootsComputed(n)
+		reportHashing(m)
+	}
+}
+
+// reportEncoded reports n bytes encoded.
+func reportEncoded(n uint64) {
+	if m := currentMetrics(); m != nil {
+		m.BytesEncoded(n)
+	}
+}
+
+// reportDecoded reports n bytes decoded.
+func reportDecoded(n uint64) {
+	if m := currentMetrics(); m != nil {
+		m.BytesDecoded(n)
+	}
+}
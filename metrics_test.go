@@ -0,0 +1,63 @@
+package ssz
+
+import (
+	"bytes"
+	"sync/atomic"
+	"testing"
+)
+
+type countingMetrics struct {
+	encoded, decoded, roots, chunks, hits uint64
+}
+
+func (m *countingMetrics) BytesEncoded(n uint64)  { atomic.AddUint64(&m.encoded, n) }
+func (m *countingMetrics) BytesDecoded(n uint64)  { atomic.AddUint64(&m.decoded, n) }
+func (m *countingMetrics) RootsComputed(n uint64) { atomic.AddUint64(&m.roots, n) }
+func (m *countingMetrics) ChunksHashed(n uint64)  { atomic.AddUint64(&m.chunks, n) }
+func (m *countingMetrics) CacheHits(n uint64)     { atomic.AddUint64(&m.hits, n) }
+
+func TestSetMetrics(t *testing.T) {
+	m := &countingMetrics{}
+	SetMetrics(m)
+	defer SetMetrics(nil)
+
+	val := &fork{PreviousVersion: [4]byte{1}, CurrentVersion: [4]byte{2}, Epoch: 3}
+	enc, err := Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Unmarshal(enc, &fork{}); err != nil {
+		t.Fatal(err)
+	}
+	var stream bytes.Buffer
+	if err := NewEncoder(&stream).Encode(val); err != nil {
+		t.Fatal(err)
+	}
+	if err := NewDecoder(&stream).Decode(&fork{}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := HashTreeRoot(val); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := HashTreeRoot(val, NoCache()); err != nil {
+		t.Fatal(err)
+	}
+	if m.encoded != 2*uint64(len(enc)) || m.decoded != 2*uint64(len(enc)) {
+		t.Errorf("Wanted %d bytes encoded and decoded, received %d and %d", 2*len(enc), m.encoded, m.decoded)
+	}
+	if m.roots != 2 || m.chunks == 0 {
+		t.Errorf("Unexpected hashing metrics %+v", m)
+	}
+
+	// Failed calls and calls made once the sink is removed are not reported.
+	if err := Unmarshal(enc[:4], &fork{}); err == nil {
+		t.Fatal("Expected error")
+	}
+	SetMetrics(nil)
+	if _, err := HashTreeRoot(val); err != nil {
+		t.Fatal(err)
+	}
+	if m.decoded != 2*uint64(len(enc)) || m.roots != 2 {
+		t.Errorf("Unexpected metrics after removing the sink %+v", m)
+	}
+}
@@ -0,0 +1,227 @@
+package ssz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/tree"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// MerkleMultiproof is a sparse Merkle proof of several leaves of an object at once,
+// laid out as specified by the consensus specification: the proof holds the roots of
+// the helper nodes needed to recompute the root, sorted by decreasing generalized index.
+type MerkleMultiproof struct {
+	// Root is the hash tree root of the object the proof was generated from.
+	Root [32]byte
+	// Indices are the generalized indices of the leaves, sorted in increasing order.
+	Indices []uint64
+	// Leaves holds the roots of the nodes at Indices.
+	Leaves [][32]byte
+	// Proof holds the roots of the helper nodes, by decreasing generalized index.
+	Proof [][32]byte
+}
+
+// Multiproof generates a multiproof of the nodes at the given generalized indices of
+// the Merkle tree of obj. Duplicate indices are removed, and helper nodes shared by
+// several branches, or which can be computed from the leaves, are only included once
+// or not at all.
+//
+//  p1, _ := ssz.Proof(state, "Validators", 5, "WithdrawalCredentials")
+//  p2, _ := ssz.Proof(state, "Validators", 6, "WithdrawalCredentials")
+//  multiproof, err := ssz.Multiproof(state, []uint64{p1.GeneralizedIndex, p2.GeneralizedIndex})
+//  if err != nil {
+//      return errors.Wrap(err, "could not generate multiproof")
+//  }
+func Multiproof(obj interface{}, gindices []uint64) (*MerkleMultiproof, error) {
+	if obj == nil {
+		return nil, errors.New("untyped nil is not supported")
+	}
+	if len(gindices) == 0 {
+		return nil, errors.New("no generalized index to prove")
+	}
+	rval := reflect.ValueOf(obj)
+	root, err := valueTree(rval, rval.Type(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not build tree for type: %v", rval.Type())
+	}
+	indices := make([]uint64, 0, len(gindices))
+	seen := make(map[uint64]bool, len(gindices))
+	for _, g := range gindices {
+		if g == 0 {
+			return nil, errors.New("generalized index 0 is invalid")
+		}
+		if !seen[g] {
+			seen[g] = true
+			indices = append(indices, g)
+		}
+	}
+	sort.Slice(indices, func(i, j int) bool { return indices[i] < indices[j] })
+	proof := &MerkleMultiproof{
+		Root:    root.Root(),
+		Indices: indices,
+		Leaves:  make([][32]byte, len(indices)),
+	}
+	for i, g := range indices {
+		node, err := root.Get(g)
+		if err != nil {
+			return nil, err
+		}
+		proof.Leaves[i] = node.Root()
+	}
+	for _, g := range tree.HelperIndices(indices) {
+		node, err := root.Get(g)
+		if err != nil {
+			return nil, err
+		}
+		proof.Proof = append(proof.Proof, node.Root())
+	}
+	return proof, nil
+}
+
+// BackingTree builds the Merkle tree of obj, whose root is the hash tree root of obj.
+// Views of the tree read and modify the value without converting it back to Go values:
+//
+//  node, err := ssz.BackingTree(state)
+//  if err != nil {
+//      return err
+//  }
+//  view, err := tree.NewContainerView(node, 21)
+//
+// Bitlists, maps, unions, optionals, stable containers and profiles are held by a single
+// leaf holding their root.
+func BackingTree(obj interface{}) (*tree.Node, error) {
+	if obj == nil {
+		return nil, errors.New("untyped nil is not supported")
+	}
+	rval := reflect.ValueOf(obj)
+	node, err := valueTree(rval, rval.Type(), nil)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not build tree for type: %v", rval.Type())
+	}
+	return node, nil
+}
+
+// valueTree builds the Merkle tree of a value, whose root is the hash tree root of
+// the value. Bitlists, maps, unions, optionals, stable containers and profiles are
+// represented by a single node holding their root.
+func valueTree(val reflect.Value, typ reflect.Type, limits []uint64) (*tree.Node, error) {
+	for typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(typ.Elem())
+		}
+		val, typ = val.Elem(), typ.Elem()
+	}
+	if typ == reflect.TypeOf(bitfield.Bitlist{}) || typ.Kind() == reflect.Map || types.IsUnion(typ) || types.IsOptional(typ) || types.IsStableContainer(typ) || types.IsProfile(typ) {
+		r, err := valueRoot(val, typ, limits)
+		if err != nil {
+			return nil, err
+		}
+		return tree.Leaf(r), nil
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		nodes := make([]*tree.Node, 0, typ.NumField())
+		for i := 0; i < typ.NumField(); i++ {
+			// We skip protobuf related metadata fields and fields tagged ssz:"-".
+			if types.SkipField(typ.Field(i)) {
+				continue
+			}
+			fType, err := types.FieldType(typ.Field(i))
+			if err != nil {
+				return nil, err
+			}
+			if typ.Field(i).Type == reflect.TypeOf(bitfield.Bitlist{}) {
+				fType = typ.Field(i).Type
+			}
+			var node *tree.Node
+			if types.IsProgressive(typ.Field(i)) {
+				node, err = progressiveTree(val.Field(i), fType)
+			} else {
+				node, err = valueTree(val.Field(i), fType, types.FieldCapacities(typ.Field(i)))
+			}
+			if err != nil {
+				return nil, errors.Wrapf(err, "%s.%s", typ.Name(), typ.Field(i).Name)
+			}
+			nodes = append(nodes, node)
+		}
+		return tree.FromNodes(nodes, merkle.GetDepth(uint64(len(nodes))))
+	case reflect.Slice, reflect.Array, reflect.String:
+		if typ.Kind() == reflect.Array && val.Kind() == reflect.Slice && val.Len() < typ.Len() {
+			padded := reflect.MakeSlice(val.Type(), typ.Len(), typ.Len())
+			reflect.Copy(padded, val)
+			val = padded
+		}
+		maxCapacity, elemLimits := splitLimits(limits)
+		depth := merkle.GetDepth(sequenceLimit(val, typ, maxCapacity))
+		var data *tree.Node
+		var err error
+		if _, basic := basicElementSize(typ); basic {
+			var chunks [][32]byte
+			if chunks, _, err = sequenceChunks(val, typ, limits); err != nil {
+				return nil, err
+			}
+			data, err = tree.FromChunks(chunks, depth)
+		} else {
+			nodes := make([]*tree.Node, val.Len())
+			for i := range nodes {
+				if nodes[i], err = valueTree(val.Index(i), typ.Elem(), elemLimits); err != nil {
+					return nil, errors.Wrapf(err, "[%d]", i)
+				}
+			}
+			data, err = tree.FromNodes(nodes, depth)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if typ.Kind() == reflect.Array {
+			return data, nil
+		}
+		var length [32]byte
+		binary.LittleEndian.PutUint64(length[:], uint64(val.Len()))
+		return tree.NewNode(data, tree.Leaf(length)), nil
+	case reflect.Bool, reflect.Uint8, reflect.Uint16, reflect.Int32, reflect.Uint32, reflect.Uint64:
+		r, err := valueRoot(val, typ, limits)
+		if err != nil {
+			return nil, err
+		}
+		return tree.Leaf(r), nil
+	default:
+		return nil, fmt.Errorf("unsupported kind: %v", typ.Kind())
+	}
+}
+
+// progressiveTree builds the Merkle tree of a list merkleized as a progressive list.
+func progressiveTree(val reflect.Value, typ reflect.Type) (*tree.Node, error) {
+	if typ == reflect.TypeOf(bitfield.Bitlist{}) || (typ.Kind() != reflect.Slice && typ.Kind() != reflect.String) {
+		return nil, fmt.Errorf("only lists can be progressive, not %v", typ)
+	}
+	var data *tree.Node
+	var err error
+	if _, basic := basicElementSize(typ); basic {
+		var chunks [][32]byte
+		if chunks, _, err = sequenceChunks(val, typ, nil); err != nil {
+			return nil, err
+		}
+		data, err = tree.FromChunksProgressive(chunks)
+	} else {
+		nodes := make([]*tree.Node, val.Len())
+		for i := range nodes {
+			if nodes[i], err = valueTree(val.Index(i), typ.Elem(), nil); err != nil {
+				return nil, errors.Wrapf(err, "[%d]", i)
+			}
+		}
+		data, err = tree.FromNodesProgressive(nodes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(val.Len()))
+	return tree.NewNode(data, tree.Leaf(length)), nil
+}
@@ -0,0 +1,21 @@
+package ssz
+
+import (
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// FirstOffsetError is returned by Unmarshal when the first offset of a composite value
+// does not point right past its fixed part.
+type FirstOffsetError = types.FirstOffsetError
+
+// OffsetOrderError is returned by Unmarshal when an offset is lower than the one before
+// it.
+type OffsetOrderError = types.OffsetOrderError
+
+// OffsetOutOfBoundsError is returned by Unmarshal when an offset points past the end of
+// the encoding of a composite value.
+type OffsetOutOfBoundsError = types.OffsetOutOfBoundsError
+
+// BitlistDelimiterError is returned by Unmarshal when the encoding of a bitlist does not
+// end with a byte holding its delimiter bit.
+type BitlistDelimiterError = types.BitlistDelimiterError
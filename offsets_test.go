@@ -0,0 +1,176 @@
+package ssz
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+func TestUnmarshal_InvalidOffsets(t *testing.T) {
+	type inner struct {
+		A []uint16 `ssz-max:"8"`
+		B []byte   `ssz-max:"8"`
+	}
+	type outer struct {
+		Slot  uint64
+		Inner *inner
+		Lists [][]uint16 `ssz-max:"4"`
+		Pair  [2][]byte  `ssz-size:"2,?"`
+	}
+	valid := &outer{
+		Slot:  1,
+		Inner: &inner{A: []uint16{2}, B: []byte{3}},
+		Lists: [][]uint16{{4}, {5, 6}},
+		Pair:  [2][]byte{{7}, {8, 9}},
+	}
+	enc := mustMarshal(t, valid)
+	// The fixed part of outer holds the slot and three offsets, which point to inner,
+	// to the list of lists and to the pair.
+	innerStart := uint64(binary.LittleEndian.Uint32(enc[8:]))
+	listsStart := uint64(binary.LittleEndian.Uint32(enc[12:]))
+	pairStart := uint64(binary.LittleEndian.Uint32(enc[16:]))
+	withUint32 := func(at uint64, v uint32) []byte {
+		b := append([]byte{}, enc...)
+		binary.LittleEndian.PutUint32(b[at:], v)
+		return b
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		check func(err error) bool
+	}{
+		{
+			name:  "first offset past the fixed part",
+			input: withUint32(8, 24),
+			check: func(err error) bool {
+				e, ok := err.(*FirstOffsetError)
+				return ok && e.Offset == 24 && e.FixedSize == 20 && e.Path == "Inner"
+			},
+		},
+		{
+			name:  "decreasing offsets",
+			input: withUint32(16, uint32(listsStart-1)),
+			check: func(err error) bool {
+				e, ok := err.(*OffsetOrderError)
+				return ok && e.Previous == listsStart && e.Path == "Pair"
+			},
+		},
+		{
+			name:  "offset out of bounds",
+			input: withUint32(16, uint32(len(enc)+1)),
+			check: func(err error) bool {
+				e, ok := err.(*OffsetOutOfBoundsError)
+				return ok && e.Size == uint64(len(enc))
+			},
+		},
+		{
+			name:  "nested first offset",
+			input: withUint32(innerStart, 4),
+			check: func(err error) bool {
+				e, ok := err.(*FirstOffsetError)
+				return ok && e.FixedSize == 8 && e.Path == "Inner.A"
+			},
+		},
+		{
+			name:  "list first offset not a multiple of the offset size",
+			input: withUint32(listsStart, 6),
+			check: func(err error) bool {
+				e, ok := err.(*FirstOffsetError)
+				return ok && e.FixedSize == 0 && e.Path == "Lists"
+			},
+		},
+		{
+			name:  "vector first offset",
+			input: withUint32(pairStart, 4),
+			check: func(err error) bool {
+				e, ok := err.(*FirstOffsetError)
+				return ok && e.FixedSize == 8 && e.Path == "Pair"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal(tt.input, &outer{})
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !tt.check(errors.Cause(err)) {
+				t.Errorf("Unexpected error %#v", errors.Cause(err))
+			}
+		})
+	}
+	if err := Unmarshal(enc, &outer{}); err != nil {
+		t.Errorf("Valid encoding failed to decode: %v", err)
+	}
+}
+
+func TestUnmarshal_InvalidBitlists(t *testing.T) {
+	type votes struct {
+		Slot uint64
+		Bits bitfield.Bitlist `ssz-max:"8"`
+	}
+	valid := &votes{Slot: 1, Bits: bitfield.Bitlist{0x0d}}
+	enc := mustMarshal(t, valid)
+	withBits := func(bits ...byte) []byte {
+		return append(append([]byte{}, enc[:12]...), bits...)
+	}
+
+	tests := []struct {
+		name  string
+		input []byte
+		check func(err error) bool
+	}{
+		{
+			name:  "empty encoding",
+			input: withBits(),
+			check: func(err error) bool {
+				e, ok := err.(*BitlistDelimiterError)
+				return ok && e.Size == 0 && e.Path == "Bits"
+			},
+		},
+		{
+			name:  "trailing zero byte",
+			input: withBits(0x0d, 0x00),
+			check: func(err error) bool {
+				e, ok := err.(*BitlistDelimiterError)
+				return ok && e.Size == 2 && e.Path == "Bits"
+			},
+		},
+		{
+			name:  "more bits than the limit",
+			input: withBits(0xff, 0x03),
+			check: func(err error) bool {
+				e, ok := err.(*LimitExceededError)
+				return ok && e.Limit == 8 && e.Length == 8+1 && e.Path == "Bits"
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := Unmarshal(tt.input, &votes{})
+			if err == nil {
+				t.Fatal("Expected error")
+			}
+			if !tt.check(errors.Cause(err)) {
+				t.Errorf("Unexpected error %#v", errors.Cause(err))
+			}
+		})
+	}
+
+	// An empty bitlist is encoded as its delimiter byte alone.
+	empty := mustMarshal(t, &votes{Slot: 1})
+	if !bytes.Equal(empty[12:], []byte{0x01}) {
+		t.Errorf("Expected empty bitlist to be encoded as 0x01, received %#x", empty[12:])
+	}
+	decoded := &votes{}
+	if err := Unmarshal(empty, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Bits.Len() != 0 {
+		t.Errorf("Expected empty bitlist, received %d bits", decoded.Bits.Len())
+	}
+}
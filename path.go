@@ -0,0 +1,220 @@
+package ssz
+
+import (
+	"fmt"
+	"math/bits"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/tree"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Path is a path into the Merkle tree of a type, built one field or index at a time.
+// It resolves to the generalized index of the value it leads to, and to the path
+// elements accepted by Proof, without requiring a value of the type:
+//
+//  path := ssz.NewPath((*BeaconState)(nil)).Field("Validators").At(5).Field("ExitEpoch")
+//  gindex, err := path.GeneralizedIndex()
+//  if err != nil {
+//      return errors.Wrap(err, "invalid path")
+//  }
+//  proof, err := ssz.Proof(state, path.Elements()...)
+//
+// Invalid steps do not panic, the first error is kept and returned by
+// GeneralizedIndex. Paths are values, so a common prefix can be extended in several
+// ways. Code generated by cmd/sszpath wraps paths into types with one method per
+// field, so that field names are checked at compile time.
+type Path struct {
+	typ         reflect.Type
+	limits      []uint64
+	progressive bool
+	gindex      uint64
+	elements    []interface{}
+	err         error
+}
+
+// NewPath returns the path to the root of the Merkle tree of the type of obj, which
+// is usually a nil pointer to that type.
+func NewPath(obj interface{}) Path {
+	if obj == nil {
+		return Path{err: errors.New("untyped nil is not supported")}
+	}
+	return Path{typ: reflect.TypeOf(obj), gindex: 1}
+}
+
+// Field returns the path to the field of the given name of the container the path
+// leads to.
+func (p Path) Field(name string) Path {
+	if p.err != nil {
+		return p
+	}
+	typ := p.elemType()
+	if err := p.checkIndexable(typ); err != nil {
+		return p.fail(err)
+	}
+	if typ.Kind() != reflect.Struct {
+		return p.fail(fmt.Errorf("cannot select field %s of %v", name, typ))
+	}
+	index, count := -1, uint64(0)
+	var field reflect.StructField
+	for i := 0; i < typ.NumField(); i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(typ.Field(i)) {
+			continue
+		}
+		if typ.Field(i).Name == name {
+			index, field = int(count), typ.Field(i)
+		}
+		count++
+	}
+	if index < 0 {
+		return p.fail(fmt.Errorf("no field %s in %v", name, typ))
+	}
+	fType, err := types.FieldType(field)
+	if err != nil {
+		return p.fail(err)
+	}
+	if field.Type == reflect.TypeOf(bitfield.Bitlist{}) {
+		fType = field.Type
+	}
+	next := p.step(fType, types.FieldCapacities(field), p.gindex, merkle.GetDepth(count), uint64(index), name)
+	next.progressive = next.err == nil && types.IsProgressive(field)
+	return next
+}
+
+// At returns the path to the element at index i of the list or vector the path leads
+// to. Elements of basic types are packed several per chunk, in which case the path
+// leads to the chunk containing the element. The generalized indices of list elements
+// depend on the list limit, so lists must be tagged with ssz-max, unless they are
+// progressive.
+func (p Path) At(i uint64) Path {
+	if p.err != nil {
+		return p
+	}
+	typ := p.elemType()
+	if err := p.checkIndexable(typ); err != nil {
+		return p.fail(err)
+	}
+	capacity, elemLimits := splitLimits(p.limits)
+	switch typ.Kind() {
+	case reflect.Array:
+		if i >= uint64(typ.Len()) {
+			return p.fail(fmt.Errorf("index %d out of range for vector %v", i, typ))
+		}
+	case reflect.Slice, reflect.String:
+		if p.progressive {
+			break
+		}
+		if capacity == 0 {
+			return p.fail(fmt.Errorf("list %v has no ssz-max limit", typ))
+		}
+		if i >= capacity {
+			return p.fail(fmt.Errorf("index %d out of range for list %v of limit %d", i, typ, capacity))
+		}
+	default:
+		return p.fail(fmt.Errorf("cannot index into %v", typ))
+	}
+	elemSize, basic := basicElementSize(typ)
+	var limit uint64
+	if typ.Kind() == reflect.Array {
+		limit = (uint64(typ.Len())*elemSize + 31) / 32
+	} else {
+		limit = (capacity*elemSize + 31) / 32
+	}
+	// The data of a list is the left child of its root, its length being on the right.
+	parent := p.gindex
+	if typ.Kind() != reflect.Array {
+		if parent >= uint64(1)<<63 {
+			return p.fail(errors.New("generalized index overflows uint64"))
+		}
+		parent <<= 1
+	}
+	var elemTyp reflect.Type
+	if typ.Kind() == reflect.String {
+		elemTyp = reflect.TypeOf(byte(0))
+	} else {
+		elemTyp = typ.Elem()
+	}
+	chunkIdx := i
+	if basic {
+		chunkIdx = i * elemSize / 32
+	}
+	if p.progressive {
+		gindex, err := tree.ProgressiveGindex(chunkIdx)
+		if err != nil {
+			return p.fail(err)
+		}
+		depth := uint8(bits.Len64(gindex) - 1)
+		return p.step(elemTyp, elemLimits, parent, depth, gindex-uint64(1)<<depth, i)
+	}
+	return p.step(elemTyp, elemLimits, parent, merkle.GetDepth(limit), chunkIdx, i)
+}
+
+// GeneralizedIndex returns the generalized index of the node the path leads to, or
+// the first error met while building the path.
+func (p Path) GeneralizedIndex() (uint64, error) {
+	if p.err != nil {
+		return 0, p.err
+	}
+	return p.gindex, nil
+}
+
+// Elements returns the field names and indices making up the path, in the form
+// accepted by Proof.
+func (p Path) Elements() []interface{} {
+	return append([]interface{}{}, p.elements...)
+}
+
+// Type returns the type of the value the path leads to.
+func (p Path) Type() reflect.Type {
+	return p.typ
+}
+
+// Err returns the first error met while building the path, if any.
+func (p Path) Err() error {
+	return p.err
+}
+
+// step returns the path to the node at index within the subtree of the given depth
+// rooted at parent.
+func (p Path) step(typ reflect.Type, limits []uint64, parent uint64, depth uint8, index uint64, element interface{}) Path {
+	if bits.Len64(parent)+int(depth) > 64 {
+		return p.fail(errors.New("generalized index overflows uint64"))
+	}
+	return Path{
+		typ:      typ,
+		limits:   limits,
+		gindex:   parent<<depth | index,
+		elements: append(p.Elements(), element),
+	}
+}
+
+// elemType returns the type the path leads to, with pointers dereferenced.
+func (p Path) elemType() reflect.Type {
+	typ := p.typ
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return typ
+}
+
+// checkIndexable returns an error for types whose root is opaque to proofs.
+func (p Path) checkIndexable(typ reflect.Type) error {
+	if typ == reflect.TypeOf(bitfield.Bitlist{}) {
+		return errors.New("cannot index into a bitlist")
+	}
+	if typ.Kind() == reflect.Map || types.IsUnion(typ) || types.IsOptional(typ) || types.IsStableContainer(typ) || types.IsProfile(typ) {
+		return fmt.Errorf("cannot index into %v", typ)
+	}
+	return nil
+}
+
+func (p Path) fail(err error) Path {
+	if len(p.elements) > 0 {
+		err = errors.Wrapf(err, "at %v", p.elements)
+	}
+	return Path{typ: p.typ, elements: p.elements, err: err}
+}
@@ -0,0 +1,455 @@
+package ssz
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+	"github.com/prysmaticlabs/go-ssz/tree"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// MerkleProof is a single-leaf Merkle proof of a value nested in an object, against
+// the hash tree root of that object.
+type MerkleProof struct {
+	// Root is the hash tree root of the object the proof was generated from.
+	Root [32]byte
+	// Leaf is the hash tree root of the proven value, or the chunk containing it
+	// for elements of a list or vector of basic types.
+	Leaf [32]byte
+	// Branch holds the sibling roots from the leaf up to the root.
+	Branch [][32]byte
+	// GeneralizedIndex is the position of the leaf in the Merkle tree of the object.
+	GeneralizedIndex uint64
+}
+
+// Proof generates a Merkle proof of the value found by following path from obj.
+// Path elements are either field names of containers, or integer indices of lists
+// and vectors, such that a proof of the withdrawal credentials of a validator can
+// be obtained as follows:
+//
+//  proof, err := ssz.Proof(state, "Validators", 5, "WithdrawalCredentials")
+//  if err != nil {
+//      return errors.Wrap(err, "could not generate proof")
+//  }
+//
+// Elements of lists and vectors of basic types are packed several per chunk, in
+// which case the proof leaf is the whole chunk containing the element.
+func Proof(obj interface{}, path ...interface{}) (*MerkleProof, error) {
+	if obj == nil {
+		return nil, errors.New("untyped nil is not supported")
+	}
+	rval := reflect.ValueOf(obj)
+	proof, err := proveValue(rval, rval.Type(), nil, path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not generate proof for type: %v", rval.Type())
+	}
+	return proof, nil
+}
+
+// ValidatorProof generates the proof of the index-th validator of a beacon state, that
+// is of the element of its Validators list. The leaf is the hash tree root of the
+// validator, and the branch runs through the registry, including its length mix-in, up
+// to the state root, so that a light client holding a trusted state root can check the
+// validator on its own:
+//
+//  proof, err := ssz.ValidatorProof(state, 1234)
+//  if err != nil {
+//      return errors.Wrap(err, "could not prove validator")
+//  }
+func ValidatorProof(state interface{}, index uint64) (*MerkleProof, error) {
+	return Proof(state, "Validators", index)
+}
+
+// proveValue generates the proof of the value at path, relative to val. Limits are the
+// limits of the ssz-max tag of the field holding val, one per level of nesting.
+func proveValue(val reflect.Value, typ reflect.Type, limits []uint64, path []interface{}) (*MerkleProof, error) {
+	for typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(typ.Elem())
+		}
+		val, typ = val.Elem(), typ.Elem()
+	}
+	if len(path) == 0 {
+		root, err := valueRoot(val, typ, limits)
+		if err != nil {
+			return nil, err
+		}
+		return &MerkleProof{Root: root, Leaf: root, GeneralizedIndex: 1}, nil
+	}
+	if typ == reflect.TypeOf(bitfield.Bitlist{}) {
+		return nil, errors.New("cannot index into a bitlist")
+	}
+	if types.IsUnion(typ) || types.IsOptional(typ) || types.IsStableContainer(typ) || types.IsProfile(typ) {
+		return nil, fmt.Errorf("cannot index into %v", typ)
+	}
+	switch typ.Kind() {
+	case reflect.Struct:
+		return proveField(val, typ, path)
+	case reflect.Slice, reflect.Array, reflect.String:
+		return proveElement(val, typ, limits, path)
+	default:
+		return nil, fmt.Errorf("cannot follow path %v into kind %v", path[0], typ.Kind())
+	}
+}
+
+func proveField(val reflect.Value, typ reflect.Type, path []interface{}) (*MerkleProof, error) {
+	name, ok := path[0].(string)
+	if !ok {
+		return nil, fmt.Errorf("expected a field name to index into %v, received %v", typ, path[0])
+	}
+	chunks := make([][32]byte, 0, typ.NumField())
+	index, field := -1, 0
+	var fieldTyp reflect.Type
+	var fieldLimits []uint64
+	var fieldProgressive bool
+	for i := 0; i < typ.NumField(); i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(typ.Field(i)) {
+			continue
+		}
+		fType, err := types.FieldType(typ.Field(i))
+		if err != nil {
+			return nil, err
+		}
+		fLimits := types.FieldCapacities(typ.Field(i))
+		if typ.Field(i).Type == reflect.TypeOf(bitfield.Bitlist{}) {
+			fType = typ.Field(i).Type
+		}
+		progressive := types.IsProgressive(typ.Field(i))
+		if typ.Field(i).Name == name {
+			index, field, fieldTyp, fieldLimits, fieldProgressive = len(chunks), i, fType, fLimits, progressive
+		}
+		r, err := fieldRoot(val, typ, i)
+		if err != nil {
+			return nil, err
+		}
+		chunks = append(chunks, r)
+	}
+	if index < 0 {
+		return nil, fmt.Errorf("no field %s in %v", name, typ)
+	}
+	var sub *MerkleProof
+	var err error
+	if fieldProgressive {
+		sub, err = proveProgressive(val.Field(field), fieldTyp, path[1:])
+	} else {
+		sub, err = proveValue(val.Field(field), fieldTyp, fieldLimits, path[1:])
+	}
+	if err != nil {
+		return nil, errors.Wrapf(err, "%s.%s", typ.Name(), name)
+	}
+	depth := merkle.GetDepth(uint64(len(chunks)))
+	root, branch, err := merkleBranch(chunks, depth, uint64(index))
+	if err != nil {
+		return nil, err
+	}
+	return sub.nest(root, branch, uint64(1)<<depth|uint64(index))
+}
+
+// fieldRoot returns the hash tree root of the i-th field of the struct val.
+func fieldRoot(val reflect.Value, typ reflect.Type, i int) ([32]byte, error) {
+	fType, err := types.FieldType(typ.Field(i))
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if typ.Field(i).Type == reflect.TypeOf(bitfield.Bitlist{}) {
+		fType = typ.Field(i).Type
+	}
+	if types.IsProgressive(typ.Field(i)) {
+		return types.ProgressiveListRoot(val.Field(i), fType)
+	}
+	return valueRoot(val.Field(i), fType, types.FieldCapacities(typ.Field(i)))
+}
+
+func proveElement(val reflect.Value, typ reflect.Type, limits []uint64, path []interface{}) (*MerkleProof, error) {
+	idx, ok := toIndex(path[0])
+	if !ok {
+		return nil, fmt.Errorf("expected an index into %v, received %v", typ, path[0])
+	}
+	if typ.Kind() == reflect.Array && val.Kind() == reflect.Slice && val.IsNil() {
+		val = reflect.MakeSlice(val.Type(), typ.Len(), typ.Len())
+	}
+	if idx >= uint64(val.Len()) {
+		return nil, fmt.Errorf("index %d out of range for length %d", idx, val.Len())
+	}
+	chunks, limit, err := sequenceChunks(val, typ, limits)
+	if err != nil {
+		return nil, err
+	}
+	var sub *MerkleProof
+	chunkIdx := idx
+	if elemSize, basic := basicElementSize(typ); basic {
+		if len(path) > 1 {
+			return nil, fmt.Errorf("cannot follow path %v into basic element of %v", path[1], typ)
+		}
+		chunkIdx = idx * elemSize / 32
+		sub = &MerkleProof{Root: chunks[chunkIdx], Leaf: chunks[chunkIdx], GeneralizedIndex: 1}
+	} else {
+		_, elemLimits := splitLimits(limits)
+		sub, err = proveValue(val.Index(int(idx)), typ.Elem(), elemLimits, path[1:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "[%d]", idx)
+		}
+	}
+	depth := merkle.GetDepth(limit)
+	root, branch, err := merkleBranch(chunks, depth, chunkIdx)
+	if err != nil {
+		return nil, err
+	}
+	if typ.Kind() == reflect.Array {
+		return sub.nest(root, branch, uint64(1)<<depth|chunkIdx)
+	}
+	var length [32]byte
+	binary.LittleEndian.PutUint64(length[:], uint64(val.Len()))
+	return sub.nest(hashing.HashPair(root, length), append(branch, length), uint64(2)<<depth|chunkIdx)
+}
+
+// proveProgressive proves a path into a list merkleized as a progressive list.
+func proveProgressive(val reflect.Value, typ reflect.Type, path []interface{}) (*MerkleProof, error) {
+	node, err := progressiveTree(val, typ)
+	if err != nil {
+		return nil, err
+	}
+	if len(path) == 0 {
+		root := node.Root()
+		return &MerkleProof{Root: root, Leaf: root, GeneralizedIndex: 1}, nil
+	}
+	idx, ok := toIndex(path[0])
+	if !ok {
+		return nil, fmt.Errorf("expected an index into %v, received %v", typ, path[0])
+	}
+	if idx >= uint64(val.Len()) {
+		return nil, fmt.Errorf("index %d out of range for length %d", idx, val.Len())
+	}
+	elemSize, basic := basicElementSize(typ)
+	chunkIdx := idx
+	if basic {
+		chunkIdx = idx * elemSize / 32
+	}
+	gindex, err := tree.ProgressiveGindex(chunkIdx)
+	if err != nil {
+		return nil, err
+	}
+	// The data of a list is the left child of its root, its length being on the right.
+	depth := uint(bits.Len64(gindex) - 1)
+	if depth >= 63 {
+		return nil, errors.New("generalized index overflows uint64")
+	}
+	gindex = uint64(2)<<depth | (gindex - uint64(1)<<depth)
+	var sub *MerkleProof
+	if basic {
+		if len(path) > 1 {
+			return nil, fmt.Errorf("cannot follow path %v into basic element of %v", path[1], typ)
+		}
+		leaf, err := node.Get(gindex)
+		if err != nil {
+			return nil, err
+		}
+		sub = &MerkleProof{Root: leaf.Root(), Leaf: leaf.Root(), GeneralizedIndex: 1}
+	} else {
+		sub, err = proveValue(val.Index(int(idx)), typ.Elem(), nil, path[1:])
+		if err != nil {
+			return nil, errors.Wrapf(err, "[%d]", idx)
+		}
+	}
+	branch, err := node.Branch(gindex)
+	if err != nil {
+		return nil, err
+	}
+	return sub.nest(node.Root(), branch, gindex)
+}
+
+// nest returns the proof of the sub proof leaf against the root of its parent, given
+// the branch and generalized index of the sub proof root within the parent.
+func (m *MerkleProof) nest(root [32]byte, branch [][32]byte, gindex uint64) (*MerkleProof, error) {
+	subDepth := merkle.GetDepth(m.GeneralizedIndex+1) - 1
+	if gindex >= uint64(1)<<(63-subDepth) {
+		return nil, errors.New("generalized index overflows uint64")
+	}
+	return &MerkleProof{
+		Root:             root,
+		Leaf:             m.Leaf,
+		Branch:           append(m.Branch, branch...),
+		GeneralizedIndex: gindex<<subDepth | (m.GeneralizedIndex - uint64(1)<<subDepth),
+	}, nil
+}
+
+// valueRoot computes the hash tree root of a value the same way as HashTreeRoot does
+// for struct fields, given the limits of the ssz-max tag of the field.
+func valueRoot(val reflect.Value, typ reflect.Type, limits []uint64) ([32]byte, error) {
+	maxCapacity, elemLimits := splitLimits(limits)
+	if b, ok := val.Interface().(bitfield.Bitlist); ok {
+		return types.BitlistRoot(b, maxCapacity)
+	}
+	if elemLimits != nil && (typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array) {
+		// The factories do not know about the limits of the lists nested in the
+		// elements, which are hashed one by one with their limits instead.
+		if typ.Kind() == reflect.Array && val.Kind() == reflect.Slice && val.Len() < typ.Len() {
+			padded := reflect.MakeSlice(val.Type(), typ.Len(), typ.Len())
+			reflect.Copy(padded, val)
+			val = padded
+		}
+		if typ.Kind() == reflect.Slice && maxCapacity > 0 && uint64(val.Len()) > maxCapacity {
+			return [32]byte{}, &types.LimitExceededError{Limit: maxCapacity, Length: uint64(val.Len())}
+		}
+		chunks, limit, err := sequenceChunks(val, typ, limits)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		root, err := types.MerkleizeChunks(chunks, limit)
+		if err != nil || typ.Kind() == reflect.Array {
+			return root, err
+		}
+		return hashing.MixInLength(root, uint64(val.Len())), nil
+	}
+	factory, err := types.SSZFactory(val, typ)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return factory.Root(val, typ, "", maxCapacity)
+}
+
+// sequenceChunks returns the leaf chunks of a list or vector along with the chunk limit
+// which determines the depth of its Merkle tree.
+func sequenceChunks(val reflect.Value, typ reflect.Type, limits []uint64) ([][32]byte, uint64, error) {
+	maxCapacity, elemLimits := splitLimits(limits)
+	numItems := uint64(val.Len())
+	chunks := make([][32]byte, 0, numItems)
+	elemSize, basic := basicElementSize(typ)
+	if basic {
+		buf := make([]byte, 0, numItems*elemSize)
+		if typ.Kind() == reflect.String {
+			buf = append(buf, val.String()...)
+		}
+		for i := 0; i < val.Len() && typ.Kind() != reflect.String; i++ {
+			elemBuf := make([]byte, elemSize)
+			factory, err := types.SSZFactory(val.Index(i), typ.Elem())
+			if err != nil {
+				return nil, 0, err
+			}
+			if _, err := factory.Marshal(val.Index(i), typ.Elem(), elemBuf, 0); err != nil {
+				return nil, 0, err
+			}
+			buf = append(buf, elemBuf...)
+		}
+		for i := 0; i < len(buf); i += 32 {
+			var chunk [32]byte
+			copy(chunk[:], buf[i:])
+			chunks = append(chunks, chunk)
+		}
+	} else {
+		for i := 0; i < val.Len(); i++ {
+			r, err := valueRoot(val.Index(i), typ.Elem(), elemLimits)
+			if err != nil {
+				return nil, 0, err
+			}
+			chunks = append(chunks, r)
+		}
+	}
+	return chunks, sequenceLimit(val, typ, maxCapacity), nil
+}
+
+// splitLimits returns the list limit of a value from the limits of the ssz-max tag of
+// its field, one per level of nesting, along with the limits of the lists nested in its
+// elements, or nil if they have none.
+func splitLimits(limits []uint64) (uint64, []uint64) {
+	if len(limits) == 0 {
+		return 0, nil
+	}
+	for _, l := range limits[1:] {
+		if l != 0 {
+			return limits[0], limits[1:]
+		}
+	}
+	return limits[0], nil
+}
+
+// sequenceLimit returns the chunk limit of a list or vector, which determines the
+// depth of its Merkle tree.
+func sequenceLimit(val reflect.Value, typ reflect.Type, maxCapacity uint64) uint64 {
+	numItems := uint64(val.Len())
+	elemSize, _ := basicElementSize(typ)
+	switch {
+	case typ.Kind() == reflect.Array:
+		return (numItems*elemSize + 31) / 32
+	case maxCapacity > 0:
+		return (maxCapacity*elemSize + 31) / 32
+	case numItems > 0:
+		return numItems
+	default:
+		return 1
+	}
+}
+
+// basicElementSize returns the serialized size of the elements of a list or vector
+// type, and whether they are basic types packed into chunks. Composite elements
+// take up a chunk each.
+func basicElementSize(typ reflect.Type) (uint64, bool) {
+	if typ.Kind() == reflect.String {
+		return 1, true
+	}
+	switch typ.Elem().Kind() {
+	case reflect.Bool, reflect.Uint8:
+		return 1, true
+	case reflect.Uint16:
+		return 2, true
+	case reflect.Int32, reflect.Uint32:
+		return 4, true
+	case reflect.Uint64:
+		return 8, true
+	default:
+		return 32, false
+	}
+}
+
+// merkleBranch merkleizes chunks into a tree of the given depth, right-padded with
+// zero chunks, and returns its root along with the branch of the chunk at index.
+func merkleBranch(chunks [][32]byte, depth uint8, index uint64) ([32]byte, [][32]byte, error) {
+	if depth < 64 && uint64(len(chunks)) > uint64(1)<<depth {
+		return [32]byte{}, nil, fmt.Errorf("%d chunks exceed the limit of a tree of depth %d", len(chunks), depth)
+	}
+	branch := make([][32]byte, depth)
+	layer := chunks
+	for d := uint8(0); d < depth; d++ {
+		if sibling := index ^ 1; sibling < uint64(len(layer)) {
+			branch[d] = layer[sibling]
+		} else {
+			branch[d] = hashing.ZeroHash(d)
+		}
+		next := make([][32]byte, (len(layer)+1)/2)
+		for i := range next {
+			right := hashing.ZeroHash(d)
+			if 2*i+1 < len(layer) {
+				right = layer[2*i+1]
+			}
+			next[i] = hashing.HashPair(layer[2*i], right)
+		}
+		layer = next
+		index >>= 1
+	}
+	if len(layer) == 0 {
+		return hashing.ZeroHash(depth), branch, nil
+	}
+	return layer[0], branch, nil
+}
+
+func toIndex(v interface{}) (uint64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		if rv.Int() < 0 {
+			return 0, false
+		}
+		return uint64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return rv.Uint(), true
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,155 @@
+package ssz
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// merkleProofJSON is the JSON layout of a Merkle proof, following the conventions of
+// the beacon node APIs: roots are 0x-prefixed hex strings, and integers are decimal
+// strings so that they survive JavaScript number precision.
+type merkleProofJSON struct {
+	Root   string   `json:"root"`
+	Leaf   string   `json:"leaf"`
+	Branch []string `json:"branch"`
+	Gindex string   `json:"gindex"`
+}
+
+// MarshalJSON encodes the proof as an object holding its root, leaf, branch array and
+// generalized index:
+//
+//  {"root": "0x…", "leaf": "0x…", "branch": ["0x…", …], "gindex": "105"}
+func (p *MerkleProof) MarshalJSON() ([]byte, error) {
+	return json.Marshal(&merkleProofJSON{
+		Root:   encodeHexRoot(p.Root),
+		Leaf:   encodeHexRoot(p.Leaf),
+		Branch: encodeHexRoots(p.Branch),
+		Gindex: strconv.FormatUint(p.GeneralizedIndex, 10),
+	})
+}
+
+// UnmarshalJSON decodes a proof encoded by MarshalJSON.
+func (p *MerkleProof) UnmarshalJSON(data []byte) error {
+	var enc merkleProofJSON
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	var proof MerkleProof
+	var err error
+	if proof.Root, err = decodeHexRoot(enc.Root); err != nil {
+		return fmt.Errorf("root: %v", err)
+	}
+	if proof.Leaf, err = decodeHexRoot(enc.Leaf); err != nil {
+		return fmt.Errorf("leaf: %v", err)
+	}
+	if proof.Branch, err = decodeHexRoots(enc.Branch, "branch"); err != nil {
+		return err
+	}
+	if proof.GeneralizedIndex, err = strconv.ParseUint(enc.Gindex, 10, 64); err != nil {
+		return fmt.Errorf("gindex: %v", err)
+	}
+	*p = proof
+	return nil
+}
+
+// merkleMultiproofJSON is the JSON layout of a multiproof, with the same conventions
+// as merkleProofJSON.
+type merkleMultiproofJSON struct {
+	Root    string   `json:"root"`
+	Indices []string `json:"indices"`
+	Leaves  []string `json:"leaves"`
+	Proof   []string `json:"proof"`
+}
+
+// MarshalJSON encodes the multiproof as an object holding its root, along with arrays
+// of generalized indices, leaves and helper nodes:
+//
+//  {"root": "0x…", "indices": ["42", "43"], "leaves": ["0x…", "0x…"], "proof": ["0x…", …]}
+func (p *MerkleMultiproof) MarshalJSON() ([]byte, error) {
+	indices := make([]string, len(p.Indices))
+	for i, g := range p.Indices {
+		indices[i] = strconv.FormatUint(g, 10)
+	}
+	return json.Marshal(&merkleMultiproofJSON{
+		Root:    encodeHexRoot(p.Root),
+		Indices: indices,
+		Leaves:  encodeHexRoots(p.Leaves),
+		Proof:   encodeHexRoots(p.Proof),
+	})
+}
+
+// UnmarshalJSON decodes a multiproof encoded by MarshalJSON.
+func (p *MerkleMultiproof) UnmarshalJSON(data []byte) error {
+	var enc merkleMultiproofJSON
+	if err := json.Unmarshal(data, &enc); err != nil {
+		return err
+	}
+	if len(enc.Indices) != len(enc.Leaves) {
+		return fmt.Errorf("%d indices for %d leaves", len(enc.Indices), len(enc.Leaves))
+	}
+	var proof MerkleMultiproof
+	var err error
+	if proof.Root, err = decodeHexRoot(enc.Root); err != nil {
+		return fmt.Errorf("root: %v", err)
+	}
+	proof.Indices = make([]uint64, len(enc.Indices))
+	for i, s := range enc.Indices {
+		if proof.Indices[i], err = strconv.ParseUint(s, 10, 64); err != nil {
+			return fmt.Errorf("indices[%d]: %v", i, err)
+		}
+	}
+	if proof.Leaves, err = decodeHexRoots(enc.Leaves, "leaves"); err != nil {
+		return err
+	}
+	if proof.Proof, err = decodeHexRoots(enc.Proof, "proof"); err != nil {
+		return err
+	}
+	*p = proof
+	return nil
+}
+
+func encodeHexRoot(root [32]byte) string {
+	return "0x" + hex.EncodeToString(root[:])
+}
+
+func encodeHexRoots(roots [][32]byte) []string {
+	enc := make([]string, len(roots))
+	for i, r := range roots {
+		enc[i] = encodeHexRoot(r)
+	}
+	return enc
+}
+
+func decodeHexRoot(s string) ([32]byte, error) {
+	var root [32]byte
+	if !strings.HasPrefix(s, "0x") {
+		return root, fmt.Errorf("hex string %q is missing the 0x prefix", s)
+	}
+	b, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return root, err
+	}
+	if len(b) != 32 {
+		return root, fmt.Errorf("expected 32 bytes, received %d", len(b))
+	}
+	copy(root[:], b)
+	return root, nil
+}
+
+func decodeHexRoots(enc []string, name string) ([][32]byte, error) {
+	if len(enc) == 0 {
+		return nil, nil
+	}
+	roots := make([][32]byte, len(enc))
+	for i, s := range enc {
+		r, err := decodeHexRoot(s)
+		if err != nil {
+			return nil, fmt.Errorf("%s[%d]: %v", name, i, err)
+		}
+		roots[i] = r
+	}
+	return roots, nil
+}
@@ -0,0 +1,549 @@
+package ssz
+
+import (
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+type proofValidator struct {
+	Pubkey                []byte `ssz-size:"48"`
+	WithdrawalCredentials []byte `ssz-size:"32"`
+	EffectiveBalance      uint64
+	Slashed               bool
+}
+
+type proofState struct {
+	Slot        uint64
+	BlockRoots  [][]byte          `ssz-size:"8,32"`
+	Validators  []*proofValidator `ssz-max:"1099511627776"`
+	Balances    []uint64          `ssz-max:"1099511627776"`
+	Bits        bitfield.Bitlist  `ssz-max:"2048"`
+	Graffiti    string            `ssz-max:"64"`
+	XXX_unknown []byte
+}
+
+// verifyBranch folds the branch of a proof into the root it commits to.
+func verifyBranch(p *MerkleProof) [32]byte {
+	node := p.Leaf
+	for i, sibling := range p.Branch {
+		if p.GeneralizedIndex>>uint(i)&1 == 1 {
+			node = HashPair(sibling, node)
+		} else {
+			node = HashPair(node, sibling)
+		}
+	}
+	return node
+}
+
+func TestProof(t *testing.T) {
+	state := &proofState{
+		Slot:       5,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{1, 2, 3, 4, 5},
+		Bits:       bitfield.Bitlist{0x0d},
+		Graffiti:   "go-ssz",
+	}
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = make([]byte, 32)
+		state.BlockRoots[i][0] = byte(i)
+	}
+	for i := 0; i < 3; i++ {
+		creds := make([]byte, 32)
+		creds[31] = byte(i + 1)
+		state.Validators = append(state.Validators, &proofValidator{
+			Pubkey:                make([]byte, 48),
+			WithdrawalCredentials: creds,
+			EffectiveBalance:      32,
+		})
+	}
+	root, err := HashTreeRoot(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path   []interface{}
+		gindex uint64
+	}{
+		{path: []interface{}{}, gindex: 1},
+		{path: []interface{}{"Slot"}, gindex: 8},
+		{path: []interface{}{"Graffiti"}, gindex: 13},
+		{path: []interface{}{"BlockRoots", 3}, gindex: 9<<3 | 3},
+		{path: []interface{}{"Balances", 4}, gindex: (11<<1|0)<<38 | 1},
+		{path: []interface{}{"Validators", uint64(2), "WithdrawalCredentials"}, gindex: ((10<<1)<<40|2)<<2 | 1},
+		{path: []interface{}{"Validators", 1}, gindex: (10<<1)<<40 | 1},
+	}
+	for _, tt := range tests {
+		proof, err := Proof(state, tt.path...)
+		if err != nil {
+			t.Fatalf("Proof(%v): %v", tt.path, err)
+		}
+		if proof.Root != root {
+			t.Errorf("Proof(%v): wanted root %#x, received %#x", tt.path, root, proof.Root)
+		}
+		if proof.GeneralizedIndex != tt.gindex {
+			t.Errorf("Proof(%v): wanted generalized index %d, received %d", tt.path, tt.gindex, proof.GeneralizedIndex)
+		}
+		if got := verifyBranch(proof); got != root {
+			t.Errorf("Proof(%v): branch does not verify against root", tt.path)
+		}
+	}
+	proof, err := Proof(state, "Validators", 2, "WithdrawalCredentials")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Leaf[31] != 3 || len(proof.Branch) != 2+41+3 {
+		t.Errorf("Unexpected withdrawal credentials proof %+v", proof)
+	}
+
+	badPaths := [][]interface{}{
+		{"Missing"},
+		{"Validators", 3},
+		{"Validators", "Slashed"},
+		{"Slot", 0},
+		{"Balances", 0, "Slot"},
+		{"Bits", 0},
+	}
+	for _, path := range badPaths {
+		if _, err := Proof(state, path...); err == nil {
+			t.Errorf("Proof(%v): expected error", path)
+		}
+	}
+}
+
+// multiproofRoot recomputes the root of a multiproof, as calculate_multi_merkle_root
+// does in the consensus specification.
+func multiproofRoot(t *testing.T, p *MerkleMultiproof) [32]byte {
+	objects := make(map[uint64][32]byte)
+	for i, g := range p.Indices {
+		objects[g] = p.Leaves[i]
+	}
+	helpers := tree.HelperIndices(p.Indices)
+	if len(helpers) != len(p.Proof) {
+		t.Fatalf("Wanted %d helper nodes, received %d", len(helpers), len(p.Proof))
+	}
+	for i, g := range helpers {
+		objects[g] = p.Proof[i]
+	}
+	keys := make([]uint64, 0, len(objects))
+	for g := range objects {
+		keys = append(keys, g)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i] > keys[j] })
+	for pos := 0; pos < len(keys); pos++ {
+		g := keys[pos]
+		if g <= 1 {
+			continue
+		}
+		_, hasSibling := objects[g^1]
+		_, hasParent := objects[g/2]
+		if hasSibling && !hasParent {
+			objects[g/2] = HashPair(objects[g&^1], objects[g|1])
+			keys = append(keys, g/2)
+		}
+	}
+	return objects[1]
+}
+
+func TestMultiproof(t *testing.T) {
+	state := &proofState{
+		Slot:       7,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{10, 20, 30},
+	}
+	for i := 0; i < 4; i++ {
+		state.Validators = append(state.Validators, &proofValidator{
+			WithdrawalCredentials: []byte{byte(i)},
+			EffectiveBalance:      uint64(i),
+		})
+	}
+	paths := [][]interface{}{
+		{"Validators", 1, "WithdrawalCredentials"},
+		{"Validators", 2, "WithdrawalCredentials"},
+		{"Balances", 2},
+		{"Slot"},
+		{"Slot"},
+	}
+	gindices := make([]uint64, len(paths))
+	leaves := make(map[uint64][32]byte)
+	for i, path := range paths {
+		p, err := Proof(state, path...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		gindices[i] = p.GeneralizedIndex
+		leaves[p.GeneralizedIndex] = p.Leaf
+	}
+	mp, err := Multiproof(state, gindices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := HashTreeRoot(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if mp.Root != root {
+		t.Errorf("Wanted root %#x, received %#x", root, mp.Root)
+	}
+	if len(mp.Indices) != 4 {
+		t.Errorf("Wanted 4 deduplicated indices, received %v", mp.Indices)
+	}
+	for i, g := range mp.Indices {
+		if mp.Leaves[i] != leaves[g] {
+			t.Errorf("Leaf at generalized index %d does not match single proof", g)
+		}
+	}
+	if got := multiproofRoot(t, mp); got != root {
+		t.Errorf("Multiproof does not verify against root %#x, received %#x", root, got)
+	}
+	if _, err := Multiproof(state, []uint64{0}); err == nil {
+		t.Error("Expected error for generalized index 0")
+	}
+}
+
+func TestProofJSON(t *testing.T) {
+	state := &proofState{
+		Slot:       3,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{1, 2, 3},
+	}
+	p, err := Proof(state, "Balances", 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	enc, err := json.Marshal(p)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(enc), `"leaf":"0x0100000000000000020000000000000003000000`) {
+		t.Errorf("Expected hex leaf in %s", enc)
+	}
+	if !strings.Contains(string(enc), `"gindex":"`) {
+		t.Errorf("Expected decimal string gindex in %s", enc)
+	}
+	decoded := &MerkleProof{}
+	if err := json.Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(p, decoded) {
+		t.Errorf("Wanted %+v, received %+v", p, decoded)
+	}
+
+	mp, err := Multiproof(state, []uint64{p.GeneralizedIndex, 8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if enc, err = json.Marshal(mp); err != nil {
+		t.Fatal(err)
+	}
+	decodedMulti := &MerkleMultiproof{}
+	if err := json.Unmarshal(enc, decodedMulti); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(mp, decodedMulti) {
+		t.Errorf("Wanted %+v, received %+v", mp, decodedMulti)
+	}
+
+	invalid := []string{
+		`{"root":"00","leaf":"0x00","branch":[],"gindex":"1"}`,
+		`{"root":"0x` + strings.Repeat("00", 31) + `","leaf":"0x00","branch":[],"gindex":"1"}`,
+		`{"root":"0x` + strings.Repeat("00", 32) + `","leaf":"0x` + strings.Repeat("00", 32) + `","branch":[],"gindex":"-1"}`,
+	}
+	for _, s := range invalid {
+		if err := json.Unmarshal([]byte(s), &MerkleProof{}); err == nil {
+			t.Errorf("Expected error decoding %s", s)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"root":"0x`+strings.Repeat("00", 32)+`","indices":["2"],"leaves":[]}`), &MerkleMultiproof{}); err == nil {
+		t.Error("Expected error for mismatched indices and leaves")
+	}
+}
+
+func TestPath(t *testing.T) {
+	state := &proofState{
+		Slot:       5,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{1, 2, 3, 4, 5},
+		Graffiti:   "go-ssz",
+	}
+	for i := 0; i < 3; i++ {
+		state.Validators = append(state.Validators, &proofValidator{WithdrawalCredentials: make([]byte, 32)})
+	}
+	root := NewPath((*proofState)(nil))
+	paths := []Path{
+		root.Field("Slot"),
+		root.Field("BlockRoots").At(3),
+		root.Field("Validators").At(2).Field("WithdrawalCredentials"),
+		root.Field("Validators").At(1).Field("Slashed"),
+		root.Field("Balances").At(4),
+		root.Field("Graffiti").At(1),
+	}
+	for _, path := range paths {
+		gindex, err := path.GeneralizedIndex()
+		if err != nil {
+			t.Fatal(err)
+		}
+		proof, err := Proof(state, path.Elements()...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if gindex != proof.GeneralizedIndex {
+			t.Errorf("Wanted generalized index %d for %v, received %d", proof.GeneralizedIndex, path.Elements(), gindex)
+		}
+	}
+	if typ := root.Field("Validators").At(0).Type(); typ != reflect.TypeOf(&proofValidator{}) {
+		t.Errorf("Wanted path to lead to %v, received %v", reflect.TypeOf(&proofValidator{}), typ)
+	}
+
+	invalid := []Path{
+		root.Field("Missing"),
+		root.Field("Slot").At(0),
+		root.Field("BlockRoots").At(8),
+		root.Field("Bits").At(0),
+		root.Field("Validators").At(1099511627776),
+		root.Field("Missing").Field("Slot"),
+		NewPath(nil),
+	}
+	for _, path := range invalid {
+		if _, err := path.GeneralizedIndex(); err == nil {
+			t.Errorf("Expected error for path %v", path.Elements())
+		}
+	}
+}
+
+type progressiveBlock struct {
+	Slot       uint64
+	Values     []uint64          `ssz:"progressive"`
+	Validators []*proofValidator `ssz:"progressive"`
+}
+
+func TestProgressiveList(t *testing.T) {
+	block := &progressiveBlock{Slot: 7}
+	for i := uint64(1); i <= 10; i++ {
+		block.Values = append(block.Values, i)
+	}
+	for i := 0; i < 2; i++ {
+		block.Validators = append(block.Validators, &proofValidator{EffectiveBalance: uint64(i)})
+	}
+	var zero, slot, c0, c1, c2 [32]byte
+	slot[0] = 7
+	for i, v := range block.Values {
+		chunk := []*[32]byte{&c0, &c1, &c2}[i/4]
+		chunk[i%4*8] = byte(v)
+	}
+	v0, err := HashTreeRoot(block.Validators[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	v1, err := HashTreeRoot(block.Validators[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The first chunk is alone in its subtree, the next four share the second one.
+	values := MixInLength(HashPair(HashPair(zero, HashPair(HashPair(c1, c2), HashPair(zero, zero))), c0), 10)
+	validators := MixInLength(HashPair(HashPair(zero, HashPair(HashPair(v1, zero), HashPair(zero, zero))), v0), 2)
+	want := HashPair(HashPair(slot, values), HashPair(validators, zero))
+	root, err := HashTreeRoot(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != want {
+		t.Fatalf("Wanted root %#x, received %#x", want, root)
+	}
+
+	tests := []struct {
+		path   []interface{}
+		gindex uint64
+	}{
+		{path: []interface{}{"Values"}, gindex: 5},
+		{path: []interface{}{"Values", 0}, gindex: 5<<2 | 1},
+		{path: []interface{}{"Values", 5}, gindex: 5<<5 | 4},
+		{path: []interface{}{"Validators", 1, "Slashed"}, gindex: (6<<5|4)<<2 | 3},
+	}
+	for _, tt := range tests {
+		proof, err := Proof(block, tt.path...)
+		if err != nil {
+			t.Fatalf("Proof(%v): %v", tt.path, err)
+		}
+		if proof.Root != root || verifyBranch(proof) != root {
+			t.Errorf("Proof(%v): branch does not verify against root", tt.path)
+		}
+		if proof.GeneralizedIndex != tt.gindex {
+			t.Errorf("Proof(%v): wanted generalized index %d, received %d", tt.path, tt.gindex, proof.GeneralizedIndex)
+		}
+		path := NewPath((*progressiveBlock)(nil)).Field(tt.path[0].(string))
+		if len(tt.path) > 1 {
+			path = path.At(uint64(tt.path[1].(int)))
+		}
+		if len(tt.path) > 2 {
+			path = path.Field(tt.path[2].(string))
+		}
+		if gindex, err := path.GeneralizedIndex(); err != nil || gindex != tt.gindex {
+			t.Errorf("Path(%v): wanted generalized index %d, received %d: %v", tt.path, tt.gindex, gindex, err)
+		}
+	}
+
+	j, err := NewJournal(block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 6; i++ {
+		if err := j.Append("Values", uint64(i)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := j.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, err := HashTreeRoot(block); err != nil || got != want {
+		t.Errorf("Wanted journal root %#x, received %#x: %v", want, got, err)
+	}
+}
+
+func TestFieldRoots(t *testing.T) {
+	state := &proofState{
+		Slot:       5,
+		BlockRoots: make([][]byte, 8),
+		Validators: []*proofValidator{{Pubkey: make([]byte, 48), WithdrawalCredentials: make([]byte, 32)}},
+		Balances:   []uint64{1, 2, 3},
+		Bits:       bitfield.Bitlist{0x0d},
+		Graffiti:   "go-ssz",
+	}
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = make([]byte, 32)
+	}
+	roots, err := FieldRoots(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(roots) != 6 {
+		t.Fatalf("Wanted 6 field roots, received %d", len(roots))
+	}
+	node, err := tree.FromChunks(roots, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := HashTreeRoot(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Root() != root {
+		t.Fatalf("Field roots merkleize to %#x, wanted %#x", node.Root(), root)
+	}
+
+	state.Balances[1] = 7
+	changed, err := FieldRoots(*state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range roots {
+		if (roots[i] != changed[i]) != (i == 3) {
+			t.Errorf("Root of field %d: before %#x, after %#x", i, roots[i], changed[i])
+		}
+	}
+
+	if _, err := FieldRoots(nil); err == nil {
+		t.Error("Expected an error for untyped nil")
+	}
+	if _, err := FieldRoots([]uint64{1}); err == nil {
+		t.Error("Expected an error for a non-container")
+	}
+	if roots, err := FieldRoots((*fork)(nil)); err != nil || len(roots) != 3 {
+		t.Errorf("Wanted the 3 field roots of a zero fork, received %d: %v", len(roots), err)
+	}
+}
+
+type nestedLimitPayload struct {
+	BlockNumber  uint64
+	Transactions [][]byte `ssz-max:"1048576,1073741824"`
+}
+
+func TestProof_NestedLimits(t *testing.T) {
+	payload := &nestedLimitPayload{
+		BlockNumber:  9,
+		Transactions: [][]byte{{0x01, 0x02}, make([]byte, 40), {}},
+	}
+	root, err := HashTreeRoot(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	paths := [][]interface{}{{"Transactions"}, {"Transactions", 1}, {"BlockNumber"}}
+	gindices := make([]uint64, len(paths))
+	for i, path := range paths {
+		proof, err := Proof(payload, path...)
+		if err != nil {
+			t.Fatalf("Proof(%v): %v", path, err)
+		}
+		if proof.Root != root || verifyBranch(proof) != root {
+			t.Errorf("Proof(%v): wanted root %#x, received %#x", path, root, proof.Root)
+		}
+		gindices[i] = proof.GeneralizedIndex
+	}
+	mp, err := Multiproof(payload, gindices)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := multiproofRoot(t, mp); mp.Root != root || got != root {
+		t.Errorf("Multiproof: wanted root %#x, received %#x", root, got)
+	}
+	roots, err := FieldRoots(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	node, err := tree.FromChunks(roots, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Root() != root {
+		t.Errorf("Field roots merkleize to %#x, wanted %#x", node.Root(), root)
+	}
+}
+
+func TestValidatorProof(t *testing.T) {
+	state := &proofState{BlockRoots: make([][]byte, 8)}
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = make([]byte, 32)
+	}
+	for i := 0; i < 5; i++ {
+		state.Validators = append(state.Validators, &proofValidator{
+			Pubkey:                make([]byte, 48),
+			WithdrawalCredentials: make([]byte, 32),
+			EffectiveBalance:      uint64(i),
+		})
+	}
+	root, err := HashTreeRoot(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaf, err := HashTreeRoot(state.Validators[3])
+	if err != nil {
+		t.Fatal(err)
+	}
+	proof, err := ValidatorProof(state, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if proof.Leaf != leaf {
+		t.Errorf("Wanted leaf %#x, received %#x", leaf, proof.Leaf)
+	}
+	if proof.Root != root || verifyBranch(proof) != root {
+		t.Error("Branch does not verify against the state root")
+	}
+	// Validators is the third field of eight, and the registry has a depth of 40
+	// below its length mix-in.
+	if want := (uint64(10)<<1<<40 | 3); proof.GeneralizedIndex != want {
+		t.Errorf("Wanted generalized index %d, received %d", want, proof.GeneralizedIndex)
+	}
+	if len(proof.Branch) != 3+1+40 {
+		t.Errorf("Wanted a branch of %d roots, received %d", 3+1+40, len(proof.Branch))
+	}
+	if _, err := ValidatorProof(state, 5); err == nil {
+		t.Error("Expected an error for an index out of range")
+	}
+}
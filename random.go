@@ -0,0 +1,146 @@
+package ssz
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// DefaultRandomListLength is the maximum length of the lists generated by Random, unless
+// their ssz-max tag gives a lower limit.
+const DefaultRandomListLength = 16
+
+type randomConfig struct {
+	maxListLength uint64
+}
+
+// RandomOption configures the values generated by Random.
+type RandomOption func(*randomConfig)
+
+// WithMaxListLength caps the length of generated lists, and the number of bits of
+// generated bitlists, at n rather than DefaultRandomListLength.
+func WithMaxListLength(n uint64) RandomOption {
+	return func(c *randomConfig) {
+		c.maxListLength = n
+	}
+}
+
+// Random returns a pointer to a new value of typ with random contents, such as for
+// property tests or to generate a corpus of benchmark inputs:
+//
+//  rng := rand.New(rand.NewSource(seed))
+//  val, err := ssz.Random(reflect.TypeOf(BeaconBlock{}), rng, ssz.WithMaxListLength(4))
+//  if err != nil {
+//      return errors.Wrap(err, "could not generate block")
+//  }
+//  block := val.(*BeaconBlock)
+//
+// Generated values are valid: vectors have the length given by their type or ssz-size
+// tag, lists stay within their ssz-max limit, and the padding bits of bitvectors are
+// clear, so that they round-trip through Marshal and Unmarshal. The same seed gives the
+// same value. Unions, optional values, stable containers and maps are not supported.
+func Random(typ reflect.Type, rng *rand.Rand, opts ...RandomOption) (interface{}, error) {
+	if typ == nil || rng == nil {
+		return nil, errors.New("random values need a type and a source of randomness")
+	}
+	c := &randomConfig{maxListLength: DefaultRandomListLength}
+	for _, opt := range opts {
+		opt(c)
+	}
+	val := reflect.New(typ)
+	if err := c.fill(val.Elem(), typ, nil, rng); err != nil {
+		return nil, errors.Wrapf(err, "could not generate random value of type %v", typ)
+	}
+	return val.Interface(), nil
+}
+
+// fill sets val to a random value serialized as typ, which differs from the type of val
+// for slices with ssz-size tags. Limits are the ssz-max limits of val and of the lists
+// nested in it, 0 for levels which are not bounded.
+func (c *randomConfig) fill(val reflect.Value, typ reflect.Type, limits []uint64, rng *rand.Rand) error {
+	var limit uint64
+	var elemLimits []uint64
+	if len(limits) > 0 {
+		limit, elemLimits = limits[0], limits[1:]
+	}
+	if types.IsUnion(typ) || types.IsOptional(typ) || types.IsStableContainer(typ) || types.IsProfile(typ) {
+		return fmt.Errorf("random values of type %v are not supported", typ)
+	}
+	if val.Type() == reflect.TypeOf(bitfield.Bitlist{}) {
+		n := c.length(limit, rng)
+		bits := bitfield.NewBitlist(n)
+		for i := uint64(0); i < n; i++ {
+			bits.SetBitAt(i, rng.Intn(2) == 1)
+		}
+		val.Set(reflect.ValueOf(bits))
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Bool:
+		val.SetBool(rng.Intn(2) == 1)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		// SetUint truncates to the size of the type.
+		val.SetUint(rng.Uint64())
+	case reflect.Int32:
+		val.SetInt(int64(int32(rng.Uint32())))
+	case reflect.String:
+		b := make([]byte, c.length(limit, rng))
+		rng.Read(b)
+		val.SetString(string(b))
+	case reflect.Ptr:
+		val.Set(reflect.New(typ.Elem()))
+		return c.fill(val.Elem(), typ.Elem(), limits, rng)
+	case reflect.Array:
+		if val.Kind() == reflect.Slice {
+			val.Set(reflect.MakeSlice(val.Type(), typ.Len(), typ.Len()))
+		}
+		for i := 0; i < typ.Len(); i++ {
+			if err := c.fill(val.Index(i), typ.Elem(), elemLimits, rng); err != nil {
+				return err
+			}
+		}
+		if n, ok := types.BitvectorLength(val.Type()); ok && n%8 != 0 {
+			last := val.Index(val.Len() - 1)
+			last.SetUint(last.Uint() & (1<<(n%8) - 1))
+		}
+	case reflect.Slice:
+		n := int(c.length(limit, rng))
+		val.Set(reflect.MakeSlice(val.Type(), n, n))
+		for i := 0; i < n; i++ {
+			if err := c.fill(val.Index(i), typ.Elem(), elemLimits, rng); err != nil {
+				return err
+			}
+		}
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			// We skip protobuf related metadata fields and fields tagged ssz:"-".
+			if types.SkipField(field) || field.PkgPath != "" {
+				continue
+			}
+			fType, err := types.FieldType(field)
+			if err != nil {
+				return err
+			}
+			if err := c.fill(val.Field(i), fType, types.FieldCapacities(field), rng); err != nil {
+				return errors.Wrapf(err, "field %s", field.Name)
+			}
+		}
+	default:
+		return fmt.Errorf("random values of type %v are not supported", typ)
+	}
+	return nil
+}
+
+// length returns a random length for a list of the given limit.
+func (c *randomConfig) length(limit uint64, rng *rand.Rand) uint64 {
+	max := c.maxListLength
+	if limit > 0 && limit < max {
+		max = limit
+	}
+	return uint64(rng.Int63n(int64(max) + 1))
+}
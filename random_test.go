@@ -0,0 +1,79 @@
+package ssz
+
+import (
+	"bytes"
+	"math/rand"
+	"reflect"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+func TestRandom_RoundTrips(t *testing.T) {
+	type limited struct {
+		Roots  [][32]byte       `ssz-max:"3"`
+		Bits   bitfield.Bitlist `ssz-max:"5"`
+		Vector bitfield.Bitvector4
+		Nested [][]uint16 `ssz-max:"2"`
+	}
+	for seed := int64(0); seed < 50; seed++ {
+		for _, typ := range []reflect.Type{reflect.TypeOf(proofState{}), reflect.TypeOf(limited{})} {
+			val, err := Random(typ, rand.New(rand.NewSource(seed)))
+			if err != nil {
+				t.Fatal(err)
+			}
+			enc, err := Marshal(val)
+			if err != nil {
+				t.Fatalf("Could not marshal random %v: %v", typ, err)
+			}
+			dec := reflect.New(typ).Interface()
+			if err := Unmarshal(enc, dec); err != nil {
+				t.Fatalf("Could not unmarshal random %v: %v", typ, err)
+			}
+			if !DeepEqual(val, dec) {
+				t.Errorf("Random %v did not round trip: %+v, %+v", typ, val, dec)
+			}
+			if _, err := HashTreeRoot(val); err != nil {
+				t.Fatal(err)
+			}
+			if l, ok := val.(*limited); ok {
+				if len(l.Roots) > 3 || l.Bits.Len() > 5 || len(l.Nested) > 2 {
+					t.Errorf("Random value exceeds its limits: %+v", l)
+				}
+				if l.Vector[0]&0xf0 != 0 {
+					t.Errorf("Random bitvector has padding bits set: %#x", l.Vector)
+				}
+			}
+		}
+	}
+
+	// The same seed gives the same value.
+	a, err := Random(reflect.TypeOf(proofState{}), rand.New(rand.NewSource(7)), WithMaxListLength(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := Random(reflect.TypeOf(proofState{}), rand.New(rand.NewSource(7)), WithMaxListLength(4))
+	if err != nil {
+		t.Fatal(err)
+	}
+	encA, err := Marshal(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	encB, err := Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encA, encB) {
+		t.Error("Expected the same value for the same seed")
+	}
+	if n := len(a.(*proofState).Validators); n > 4 {
+		t.Errorf("Expected at most 4 validators, generated %d", n)
+	}
+}
+
+func TestRandom_Unsupported(t *testing.T) {
+	if _, err := Random(reflect.TypeOf(map[string]uint64{}), rand.New(rand.NewSource(0))); err == nil {
+		t.Error("Expected error for map type")
+	}
+}
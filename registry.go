@@ -0,0 +1,98 @@
+package ssz
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// ValidatorRegistry maintains the hash tree root of a list of validators, or of any
+// other list of containers, across updates. The roots of the validators and of the
+// nodes above them are retained, so that updating or appending a validator re-hashes
+// the validator and a single branch, in logarithmic time, rather than the registry:
+//
+//  registry, err := ssz.NewValidatorRegistry(state.Validators, params.ValidatorRegistryLimit)
+//  if err != nil {
+//      return err
+//  }
+//  state.Validators[i].Slashed = true
+//  if err := registry.UpdateValidator(i, state.Validators[i]); err != nil {
+//      return err
+//  }
+//  root := registry.Root()
+//
+// The registry does not hold on to the validators, which callers keep in sync with the
+// updates they report.
+type ValidatorRegistry struct {
+	elemTyp reflect.Type
+	roots   *types.RootsList
+}
+
+// NewValidatorRegistry hashes the validators held in a slice into a registry of at most
+// limit validators.
+func NewValidatorRegistry(validators interface{}, limit uint64) (*ValidatorRegistry, error) {
+	if validators == nil {
+		return nil, errors.New("untyped nil is not supported")
+	}
+	val := reflect.ValueOf(validators)
+	if val.Kind() != reflect.Slice {
+		return nil, fmt.Errorf("expected a slice of validators, received %v", val.Type())
+	}
+	r := &ValidatorRegistry{elemTyp: val.Type().Elem()}
+	leaves := make([][32]byte, val.Len())
+	for i := range leaves {
+		root, err := r.validatorRoot(val.Index(i).Interface())
+		if err != nil {
+			return nil, errors.Wrapf(err, "validator %d", i)
+		}
+		leaves[i] = root
+	}
+	roots, err := types.NewRootsList(leaves, limit)
+	if err != nil {
+		return nil, err
+	}
+	r.roots = roots
+	return r, nil
+}
+
+// UpdateValidator replaces the i-th validator.
+func (r *ValidatorRegistry) UpdateValidator(i int, v interface{}) error {
+	root, err := r.validatorRoot(v)
+	if err != nil {
+		return errors.Wrapf(err, "validator %d", i)
+	}
+	return r.roots.Set(i, root)
+}
+
+// AppendValidator adds a validator at the end of the registry.
+func (r *ValidatorRegistry) AppendValidator(v interface{}) error {
+	root, err := r.validatorRoot(v)
+	if err != nil {
+		return errors.Wrapf(err, "validator %d", r.roots.Len())
+	}
+	return r.roots.Append(root)
+}
+
+// Len returns the number of validators.
+func (r *ValidatorRegistry) Len() int {
+	return r.roots.Len()
+}
+
+// ValidatorRoot returns the hash tree root of the i-th validator.
+func (r *ValidatorRegistry) ValidatorRoot(i int) [32]byte {
+	return r.roots.Leaf(i)
+}
+
+// Root returns the hash tree root of the registry, including its length.
+func (r *ValidatorRegistry) Root() [32]byte {
+	return r.roots.Root()
+}
+
+func (r *ValidatorRegistry) validatorRoot(v interface{}) ([32]byte, error) {
+	if v == nil || reflect.TypeOf(v) != r.elemTyp {
+		return [32]byte{}, fmt.Errorf("expected a validator of type %v, received %T", r.elemTyp, v)
+	}
+	return HashTreeRoot(v)
+}
@@ -0,0 +1,49 @@
+package ssz
+
+import (
+	"testing"
+)
+
+func TestValidatorRegistry_MatchesHashTreeRoot(t *testing.T) {
+	const limit = 1099511627776
+	validators := []*proofValidator{
+		{Pubkey: make([]byte, 48), WithdrawalCredentials: make([]byte, 32), EffectiveBalance: 32},
+		{Pubkey: make([]byte, 48), WithdrawalCredentials: make([]byte, 32), EffectiveBalance: 31},
+	}
+	registry, err := NewValidatorRegistry(validators, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(step string) {
+		want, err := HashTreeRootWithCapacity(validators, limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := registry.Root(); got != want {
+			t.Errorf("%s: wanted root %#x, received %#x", step, want, got)
+		}
+	}
+	check("new")
+	validators[1].Slashed = true
+	if err := registry.UpdateValidator(1, validators[1]); err != nil {
+		t.Fatal(err)
+	}
+	check("update")
+	for i := 0; i < 5; i++ {
+		v := &proofValidator{Pubkey: make([]byte, 48), WithdrawalCredentials: make([]byte, 32), EffectiveBalance: uint64(i)}
+		validators = append(validators, v)
+		if err := registry.AppendValidator(v); err != nil {
+			t.Fatal(err)
+		}
+		check("append")
+	}
+	if registry.Len() != len(validators) {
+		t.Errorf("Wanted %d validators, received %d", len(validators), registry.Len())
+	}
+	if err := registry.UpdateValidator(0, proofValidator{}); err == nil {
+		t.Error("Expected error for validator of the wrong type")
+	}
+	if err := registry.UpdateValidator(len(validators), validators[0]); err == nil {
+		t.Error("Expected error for index out of range")
+	}
+}
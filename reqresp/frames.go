@@ -0,0 +1,118 @@
+package reqresp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+
+	"github.com/golang/snappy"
+)
+
+// Chunk types of the snappy framing format.
+const (
+	chunkCompressed   = 0x00
+	chunkUncompressed = 0x01
+	chunkStreamID     = 0xff
+)
+
+// maxFrameData is the largest amount of uncompressed data held by a frame.
+const maxFrameData = 65536
+
+var streamID = []byte("sNaPpY")
+
+// streamHeader is the stream identifier frame that starts every framed stream.
+var streamHeader = append([]byte{chunkStreamID, byte(len(streamID)), 0x00, 0x00}, streamID...)
+
+var crcTable = crc32.MakeTable(crc32.Castagnoli)
+
+// maskedCRC returns the masked CRC-32C checksum of data used by the framing format.
+func maskedCRC(data []byte) uint32 {
+	c := crc32.Update(0, crcTable, data)
+	return c>>15 | c<<17 + 0xa282ead8
+}
+
+// maxCompressedLen bounds the framed size of a payload of n bytes: the stream
+// identifier, then for each frame of at most 64 KiB its header, checksum and the
+// worst-case snappy encoding of its data.
+func maxCompressedLen(n uint64) uint64 {
+	frames := n/maxFrameData + 1
+	return 4 + uint64(len(streamID)) + frames*8 + uint64(snappy.MaxEncodedLen(maxFrameData))*(n/maxFrameData) + uint64(snappy.MaxEncodedLen(int(n%maxFrameData)))
+}
+
+// readFrames decompresses exactly n bytes from a snappy framed stream. Frames are read
+// whole so that no byte following the payload is consumed, and reading stops with an
+// error once more than limit compressed bytes are read or a frame decompresses past n.
+func readFrames(r io.Reader, n uint64, limit uint64) ([]byte, error) {
+	out := make([]byte, 0, n)
+	read := uint64(0)
+	first := true
+	for first || uint64(len(out)) < n {
+		var header [4]byte
+		if _, err := io.ReadFull(r, header[:]); err != nil {
+			return nil, fmt.Errorf("payload of %d bytes ended after %d: %v", n, len(out), unexpectedEOF(err))
+		}
+		size := uint64(header[1]) | uint64(header[2])<<8 | uint64(header[3])<<16
+		read += 4 + size
+		if read > limit {
+			return nil, fmt.Errorf("compressed payload exceeds %d bytes for a length of %d", limit, n)
+		}
+		body := make([]byte, size)
+		if _, err := io.ReadFull(r, body); err != nil {
+			return nil, fmt.Errorf("could not read frame: %v", unexpectedEOF(err))
+		}
+		if first {
+			if header[0] != chunkStreamID || string(body) != string(streamID) {
+				return nil, errors.New("missing snappy stream identifier")
+			}
+			first = false
+			continue
+		}
+		switch {
+		case header[0] == chunkCompressed || header[0] == chunkUncompressed:
+			if len(body) < 4 {
+				return nil, errors.New("frame too short for its checksum")
+			}
+			data := body[4:]
+			if header[0] == chunkCompressed {
+				dLen, err := snappy.DecodedLen(data)
+				if err != nil {
+					return nil, err
+				}
+				if uint64(len(out))+uint64(dLen) > n {
+					return nil, fmt.Errorf("payload exceeds its declared length of %d bytes", n)
+				}
+				if data, err = snappy.Decode(nil, data); err != nil {
+					return nil, err
+				}
+			}
+			if len(data) > maxFrameData {
+				return nil, fmt.Errorf("frame holds %d bytes, more than %d", len(data), maxFrameData)
+			}
+			if uint64(len(out))+uint64(len(data)) > n {
+				return nil, fmt.Errorf("payload exceeds its declared length of %d bytes", n)
+			}
+			if maskedCRC(data) != binary.LittleEndian.Uint32(body) {
+				return nil, errors.New("frame checksum mismatch")
+			}
+			out = append(out, data...)
+		case header[0] == chunkStreamID:
+			if string(body) != string(streamID) {
+				return nil, errors.New("invalid snappy stream identifier")
+			}
+		case header[0] >= 0x80:
+			// Skippable frames, including padding, carry no data.
+		default:
+			return nil, fmt.Errorf("unskippable frame of type %#x", header[0])
+		}
+	}
+	return out, nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
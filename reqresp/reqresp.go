@@ -0,0 +1,221 @@
+// Package reqresp implements the ssz_snappy encoding of the req/resp protocols of the
+// beacon chain networking specification. A payload is the SSZ encoding of a value,
+// prefixed by its length as an unsigned varint and compressed with the snappy framing
+// format. Responses are streams of chunks, each starting with a result code and, for
+// successful chunks of some protocols, context bytes such as a fork digest.
+package reqresp
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+
+	"github.com/golang/snappy"
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Result codes of response chunks.
+const (
+	ResultSuccess             byte = 0
+	ResultInvalidRequest      byte = 1
+	ResultServerError         byte = 2
+	ResultResourceUnavailable byte = 3
+)
+
+// MaxErrorMessageSize is the size limit of the error message of a response chunk whose
+// result is not a success.
+const MaxErrorMessageSize = 256
+
+// WritePayload writes the length prefix and the snappy framed SSZ encoding enc.
+func WritePayload(w io.Writer, enc []byte) error {
+	var prefix [binary.MaxVarintLen64]byte
+	if _, err := w.Write(prefix[:binary.PutUvarint(prefix[:], uint64(len(enc)))]); err != nil {
+		return err
+	}
+	if len(enc) == 0 {
+		// The snappy writer only emits the stream identifier along with data, so an
+		// empty payload is written as the bare identifier that ReadPayload expects.
+		_, err := w.Write(streamHeader)
+		return err
+	}
+	sw := snappy.NewBufferedWriter(w)
+	if _, err := sw.Write(enc); err != nil {
+		return err
+	}
+	return sw.Close()
+}
+
+// ReadPayload reads a payload written by WritePayload, whose declared length must lie
+// within the bounds of the SSZ encodings of the expected type. The compressed payload
+// may not exceed the worst-case size of its declared length, and must decompress to
+// exactly its declared length. No byte past the payload is read, so that the chunks of
+// a response are read one after the other from the same stream.
+func ReadPayload(r io.Reader, minSize uint64, maxSize uint64) ([]byte, error) {
+	n, err := readUvarint(r)
+	if err != nil {
+		return nil, err
+	}
+	if n < minSize || n > maxSize {
+		return nil, fmt.Errorf("declared length %d is out of the bounds [%d, %d]", n, minSize, maxSize)
+	}
+	return readFrames(r, n, maxCompressedLen(n))
+}
+
+// readUvarint reads the length prefix one byte at a time, as an unsigned varint of at
+// most 10 bytes.
+func readUvarint(r io.Reader) (uint64, error) {
+	var b [1]byte
+	var x uint64
+	for i := uint(0); i < binary.MaxVarintLen64; i++ {
+		if _, err := io.ReadFull(r, b[:]); err != nil {
+			if i > 0 {
+				err = unexpectedEOF(err)
+			}
+			return 0, err
+		}
+		if i == binary.MaxVarintLen64-1 && b[0] > 1 {
+			return 0, errors.New("length prefix overflows 64 bits")
+		}
+		x |= uint64(b[0]&0x7f) << (7 * i)
+		if b[0] < 0x80 {
+			return x, nil
+		}
+	}
+	return 0, errors.New("length prefix longer than 10 bytes")
+}
+
+// WriteRequest writes the SSZ encoding of a request.
+func WriteRequest(w io.Writer, val interface{}) error {
+	enc, err := ssz.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return WritePayload(w, enc)
+}
+
+// ReadRequest reads a request into the value pointed to by val. The declared length of
+// a request of fixed size must be that size, the one of a request of variable size must
+// not exceed maxSize.
+func ReadRequest(r io.Reader, val interface{}, maxSize uint64) error {
+	minSize, maxSize, err := bounds(val, maxSize)
+	if err != nil {
+		return err
+	}
+	enc, err := ReadPayload(r, minSize, maxSize)
+	if err != nil {
+		return err
+	}
+	return ssz.Unmarshal(enc, val)
+}
+
+// ResponseChunk is a chunk of a response. Payload holds the SSZ encoding of the response
+// value for a successful chunk, and the error message otherwise.
+type ResponseChunk struct {
+	Result  byte
+	Context []byte
+	Payload []byte
+}
+
+// WriteResponseChunk writes a response chunk. Context bytes are only written along a
+// successful result.
+func WriteResponseChunk(w io.Writer, chunk *ResponseChunk) error {
+	if chunk.Result != ResultSuccess && len(chunk.Context) > 0 {
+		return errors.New("context bytes are only sent with a successful result")
+	}
+	if chunk.Result != ResultSuccess && len(chunk.Payload) > MaxErrorMessageSize {
+		return fmt.Errorf("error message of %d bytes exceeds %d bytes", len(chunk.Payload), MaxErrorMessageSize)
+	}
+	if _, err := w.Write([]byte{chunk.Result}); err != nil {
+		return err
+	}
+	if _, err := w.Write(chunk.Context); err != nil {
+		return err
+	}
+	return WritePayload(w, chunk.Payload)
+}
+
+// WriteResponse writes a successful response chunk holding the SSZ encoding of val.
+func WriteResponse(w io.Writer, context []byte, val interface{}) error {
+	enc, err := ssz.Marshal(val)
+	if err != nil {
+		return err
+	}
+	return WriteResponseChunk(w, &ResponseChunk{Result: ResultSuccess, Context: context, Payload: enc})
+}
+
+// ReadResponseChunk reads a response chunk with contextSize context bytes, whose payload
+// lies within the given bounds if successful. It returns io.EOF at the end of the
+// response stream.
+func ReadResponseChunk(r io.Reader, contextSize int, minSize uint64, maxSize uint64) (*ResponseChunk, error) {
+	var result [1]byte
+	if _, err := io.ReadFull(r, result[:]); err != nil {
+		return nil, err
+	}
+	chunk := &ResponseChunk{Result: result[0]}
+	if chunk.Result != ResultSuccess {
+		payload, err := ReadPayload(r, 0, MaxErrorMessageSize)
+		if err != nil {
+			return nil, err
+		}
+		chunk.Payload = payload
+		return chunk, nil
+	}
+	chunk.Context = make([]byte, contextSize)
+	if _, err := io.ReadFull(r, chunk.Context); err != nil {
+		return nil, fmt.Errorf("could not read context bytes: %v", unexpectedEOF(err))
+	}
+	payload, err := ReadPayload(r, minSize, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	chunk.Payload = payload
+	return chunk, nil
+}
+
+// ReadResponse reads a response chunk into the value pointed to by val, and returns its
+// context bytes. A chunk whose result is not a success is returned as an *ErrorResponse.
+func ReadResponse(r io.Reader, contextSize int, val interface{}, maxSize uint64) ([]byte, error) {
+	minSize, maxSize, err := bounds(val, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	chunk, err := ReadResponseChunk(r, contextSize, minSize, maxSize)
+	if err != nil {
+		return nil, err
+	}
+	if chunk.Result != ResultSuccess {
+		return nil, &ErrorResponse{Result: chunk.Result, Message: string(chunk.Payload)}
+	}
+	if err := ssz.Unmarshal(chunk.Payload, val); err != nil {
+		return nil, err
+	}
+	return chunk.Context, nil
+}
+
+// ErrorResponse is a response chunk whose result is not a success.
+type ErrorResponse struct {
+	Result  byte
+	Message string
+}
+
+func (e *ErrorResponse) Error() string {
+	return fmt.Sprintf("response error %d: %s", e.Result, e.Message)
+}
+
+// bounds returns the bounds of the declared length of the type pointed to by val, which
+// is its size for fixed-size types.
+func bounds(val interface{}, maxSize uint64) (uint64, uint64, error) {
+	rval := reflect.ValueOf(val)
+	if val == nil || rval.Kind() != reflect.Ptr || rval.IsNil() {
+		return 0, 0, errors.New("expected a non-nil pointer")
+	}
+	typ := rval.Type().Elem()
+	if types.IsVariableSize(typ) {
+		return 0, maxSize, nil
+	}
+	size := types.SizeOf(reflect.New(typ).Elem(), typ)
+	return size, size, nil
+}
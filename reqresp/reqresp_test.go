@@ -0,0 +1,168 @@
+package reqresp_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	"github.com/prysmaticlabs/go-ssz/reqresp"
+)
+
+type status struct {
+	ForkDigest     [4]byte
+	FinalizedRoot  [32]byte
+	FinalizedEpoch uint64
+	HeadRoot       [32]byte
+	HeadSlot       uint64
+}
+
+type blocksByRoot struct {
+	Roots [][]byte `ssz-size:"?,32" ssz-max:"1024"`
+}
+
+// streamHeader is the snappy stream identifier frame.
+var streamHeader = []byte{0xff, 0x06, 0x00, 0x00, 0x73, 0x4e, 0x61, 0x50, 0x70, 0x59}
+
+// prefix returns the varint length prefix of a payload of the declared length.
+func prefix(declared uint64) []byte {
+	var buf [binary.MaxVarintLen64]byte
+	return buf[:binary.PutUvarint(buf[:], declared)]
+}
+
+// frame returns a payload with the given declared length, followed by data in the
+// snappy framing format.
+func frame(declared uint64, data []byte) []byte {
+	var buf bytes.Buffer
+	buf.Write(prefix(declared))
+	w := snappy.NewBufferedWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		panic(err)
+	}
+	if err := w.Close(); err != nil {
+		panic(err)
+	}
+	return buf.Bytes()
+}
+
+func TestRequest(t *testing.T) {
+	req := &status{FinalizedEpoch: 3, HeadSlot: 100}
+	req.HeadRoot[0] = 0xaa
+	var buf bytes.Buffer
+	if err := reqresp.WriteRequest(&buf, req); err != nil {
+		t.Fatal(err)
+	}
+	got := &status{}
+	if err := reqresp.ReadRequest(&buf, got, 0); err != nil {
+		t.Fatal(err)
+	}
+	if *got != *req {
+		t.Errorf("Wanted %+v, received %+v", req, got)
+	}
+
+	tests := []struct {
+		name    string
+		payload []byte
+		err     string
+	}{
+		{name: "declared length below fixed size", payload: frame(83, make([]byte, 83)), err: "out of the bounds"},
+		{name: "declared length above fixed size", payload: frame(85, make([]byte, 85)), err: "out of the bounds"},
+		{name: "payload longer than declared", payload: frame(84, make([]byte, 90)), err: "exceeds its declared length"},
+		{name: "payload shorter than declared", payload: frame(84, make([]byte, 80)), err: "ended after 80"},
+		{name: "missing stream identifier", payload: append(prefix(84), frame(84, make([]byte, 84))[1+len(streamHeader):]...), err: "missing snappy stream identifier"},
+		{name: "overlong prefix", payload: bytes.Repeat([]byte{0x80}, 11), err: "overflows 64 bits"},
+	}
+	for _, tt := range tests {
+		err := reqresp.ReadRequest(bytes.NewReader(tt.payload), &status{}, 0)
+		if err == nil || !strings.Contains(err.Error(), tt.err) {
+			t.Errorf("%s: wanted error containing %q, received %v", tt.name, tt.err, err)
+		}
+	}
+
+	// Padding frames count against the compressed size limit of the declared length.
+	padded := append(prefix(84), streamHeader...)
+	padded = append(padded, 0xfe, 0x00, 0x00, 0x01)
+	padded = append(padded, make([]byte, 0x010000)...)
+	if err := reqresp.ReadRequest(bytes.NewReader(padded), &status{}, 0); err == nil || !strings.Contains(err.Error(), "compressed payload exceeds") {
+		t.Errorf("Wanted an error for an oversized compressed payload, received %v", err)
+	}
+}
+
+func TestEmptyPayload(t *testing.T) {
+	var buf bytes.Buffer
+	if err := reqresp.WritePayload(&buf, nil); err != nil {
+		t.Fatal(err)
+	}
+	if want := append(prefix(0), streamHeader...); !bytes.Equal(buf.Bytes(), want) {
+		t.Errorf("Wanted empty payload %#x, received %#x", want, buf.Bytes())
+	}
+	got, err := reqresp.ReadPayload(&buf, 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 0 || buf.Len() != 0 {
+		t.Errorf("Wanted an empty payload read whole, received %#x with %d bytes left", got, buf.Len())
+	}
+}
+
+func TestVariableSizeRequest(t *testing.T) {
+	req := &blocksByRoot{Roots: [][]byte{make([]byte, 32), make([]byte, 32)}}
+	var buf bytes.Buffer
+	if err := reqresp.WriteRequest(&buf, req); err != nil {
+		t.Fatal(err)
+	}
+	enc := buf.Bytes()
+	if err := reqresp.ReadRequest(bytes.NewReader(enc), &blocksByRoot{}, 63); err == nil {
+		t.Error("Expected an error for a request above the maximum size")
+	}
+	got := &blocksByRoot{}
+	if err := reqresp.ReadRequest(bytes.NewReader(enc), got, 4+1024*32); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Roots) != 2 {
+		t.Errorf("Wanted 2 roots, received %d", len(got.Roots))
+	}
+}
+
+func TestResponseStream(t *testing.T) {
+	digest := []byte{1, 2, 3, 4}
+	var buf bytes.Buffer
+	for i := uint64(0); i < 3; i++ {
+		if err := reqresp.WriteResponse(&buf, digest, &status{HeadSlot: i}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := reqresp.WriteResponseChunk(&buf, &reqresp.ResponseChunk{
+		Result:  reqresp.ResultResourceUnavailable,
+		Payload: []byte("block pruned"),
+	}); err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(0); i < 3; i++ {
+		got := &status{}
+		context, err := reqresp.ReadResponse(&buf, len(digest), got, 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(context, digest) || got.HeadSlot != i {
+			t.Errorf("Chunk %d: received context %#x and %+v", i, context, got)
+		}
+	}
+	_, err := reqresp.ReadResponse(&buf, len(digest), &status{}, 0)
+	errResp, ok := err.(*reqresp.ErrorResponse)
+	if !ok || errResp.Result != reqresp.ResultResourceUnavailable || errResp.Message != "block pruned" {
+		t.Errorf("Wanted a resource unavailable error, received %v", err)
+	}
+	if _, err := reqresp.ReadResponseChunk(&buf, len(digest), 0, 1024); err != io.EOF {
+		t.Errorf("Wanted io.EOF at the end of the stream, received %v", err)
+	}
+
+	if err := reqresp.WriteResponseChunk(&buf, &reqresp.ResponseChunk{
+		Result:  reqresp.ResultServerError,
+		Payload: make([]byte, reqresp.MaxErrorMessageSize+1),
+	}); err == nil {
+		t.Error("Expected an error for an oversized error message")
+	}
+}
@@ -0,0 +1,269 @@
+package rlpbridge
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz"
+)
+
+const (
+	// MaxDepositRequestsPerPayload is the list limit of the deposit requests of a payload.
+	MaxDepositRequestsPerPayload = 8192
+	// MaxWithdrawalRequestsPerPayload is the list limit of the withdrawal requests of a payload.
+	MaxWithdrawalRequestsPerPayload = 16
+	// MaxConsolidationRequestsPerPayload is the list limit of the consolidation requests
+	// of a payload.
+	MaxConsolidationRequestsPerPayload = 2
+)
+
+// The EIP-7685 request types, prefixing the encoded requests of each type.
+const (
+	DepositRequestType       = 0x00
+	WithdrawalRequestType    = 0x01
+	ConsolidationRequestType = 0x02
+)
+
+// DepositRequest is an EIP-6110 deposit, as processed from the deposit contract logs.
+type DepositRequest struct {
+	Pubkey                []byte `ssz-size:"48"`
+	WithdrawalCredentials []byte `ssz-size:"32"`
+	Amount                uint64
+	Signature             []byte `ssz-size:"96"`
+	Index                 uint64
+}
+
+// WithdrawalRequest is an EIP-7002 withdrawal triggered from the execution layer.
+type WithdrawalRequest struct {
+	SourceAddress   []byte `ssz-size:"20"`
+	ValidatorPubkey []byte `ssz-size:"48"`
+	Amount          uint64
+}
+
+// ConsolidationRequest is an EIP-7251 consolidation triggered from the execution layer.
+type ConsolidationRequest struct {
+	SourceAddress []byte `ssz-size:"20"`
+	SourcePubkey  []byte `ssz-size:"48"`
+	TargetPubkey  []byte `ssz-size:"48"`
+}
+
+// ExecutionRequests holds the requests of an Electra beacon block body. The consensus
+// layer commits to them through their hash tree root, while the execution block header
+// commits to them through the EIP-7685 requests hash, see RequestsHash.
+type ExecutionRequests struct {
+	Deposits       []*DepositRequest       `ssz-max:"8192"`
+	Withdrawals    []*WithdrawalRequest    `ssz-max:"16"`
+	Consolidations []*ConsolidationRequest `ssz-max:"2"`
+}
+
+// ExecutionRequestsRoot returns the hash tree root of execution requests. It gives the
+// same result as ssz.HashTreeRoot, without reflection, and is meant for verifying the
+// requests of every block.
+func ExecutionRequestsRoot(r *ExecutionRequests) ([32]byte, error) {
+	if len(r.Deposits) > MaxDepositRequestsPerPayload || len(r.Withdrawals) > MaxWithdrawalRequestsPerPayload ||
+		len(r.Consolidations) > MaxConsolidationRequestsPerPayload {
+		return [32]byte{}, fmt.Errorf("too many requests: %d deposits, %d withdrawals, %d consolidations",
+			len(r.Deposits), len(r.Withdrawals), len(r.Consolidations))
+	}
+	deposits := make([][32]byte, len(r.Deposits))
+	for i, d := range r.Deposits {
+		root, err := depositRequestRoot(d)
+		if err != nil {
+			return [32]byte{}, errors.Wrapf(err, "deposit request %d", i)
+		}
+		deposits[i] = root
+	}
+	withdrawals := make([][32]byte, len(r.Withdrawals))
+	for i, w := range r.Withdrawals {
+		root, err := withdrawalRequestRoot(w)
+		if err != nil {
+			return [32]byte{}, errors.Wrapf(err, "withdrawal request %d", i)
+		}
+		withdrawals[i] = root
+	}
+	consolidations := make([][32]byte, len(r.Consolidations))
+	for i, c := range r.Consolidations {
+		root, err := consolidationRequestRoot(c)
+		if err != nil {
+			return [32]byte{}, errors.Wrapf(err, "consolidation request %d", i)
+		}
+		consolidations[i] = root
+	}
+	return merkleizeChunks([][32]byte{
+		ssz.MixInLength(merkleizeChunks(deposits, MaxDepositRequestsPerPayload), uint64(len(deposits))),
+		ssz.MixInLength(merkleizeChunks(withdrawals, MaxWithdrawalRequestsPerPayload), uint64(len(withdrawals))),
+		ssz.MixInLength(merkleizeChunks(consolidations, MaxConsolidationRequestsPerPayload), uint64(len(consolidations))),
+	}, 4), nil
+}
+
+// RequestsList returns the EIP-7685 encoding of execution requests, as sent to the
+// execution layer: for every request type with at least one request, the type byte
+// followed by the SSZ serialization of the list of requests of that type.
+func RequestsList(r *ExecutionRequests) ([][]byte, error) {
+	var list [][]byte
+	for _, requests := range []struct {
+		typ  byte
+		size int
+		objs []interface{}
+	}{
+		{DepositRequestType, 192, depositObjs(r.Deposits)},
+		{WithdrawalRequestType, 76, withdrawalObjs(r.Withdrawals)},
+		{ConsolidationRequestType, 116, consolidationObjs(r.Consolidations)},
+	} {
+		if len(requests.objs) == 0 {
+			continue
+		}
+		enc := make([]byte, 1, 1+requests.size*len(requests.objs))
+		enc[0] = requests.typ
+		for i, obj := range requests.objs {
+			b, err := ssz.Marshal(obj)
+			if err != nil {
+				return nil, errors.Wrapf(err, "request %d of type %d", i, requests.typ)
+			}
+			if len(b) != requests.size {
+				return nil, fmt.Errorf("request %d of type %d: expected %d bytes, received %d", i, requests.typ, requests.size, len(b))
+			}
+			enc = append(enc, b...)
+		}
+		list = append(list, enc)
+	}
+	return list, nil
+}
+
+// RequestsHash returns the EIP-7685 commitment of the execution block header to a list
+// of encoded requests, that is, the hash of the concatenated hashes of the requests.
+func RequestsHash(requests [][]byte) [32]byte {
+	h := sha256.New()
+	for _, r := range requests {
+		inner := sha256.Sum256(r)
+		h.Write(inner[:])
+	}
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
+
+// VerifyRequestsHash checks that the requests hash of an execution block header commits
+// to the given execution requests.
+func VerifyRequestsHash(r *ExecutionRequests, requestsHash [32]byte) error {
+	list, err := RequestsList(r)
+	if err != nil {
+		return errors.Wrap(err, "could not encode requests")
+	}
+	if h := RequestsHash(list); h != requestsHash {
+		return fmt.Errorf("requests hash %#x does not match requests with hash %#x", requestsHash, h)
+	}
+	return nil
+}
+
+func depositObjs(requests []*DepositRequest) []interface{} {
+	objs := make([]interface{}, len(requests))
+	for i, r := range requests {
+		objs[i] = r
+	}
+	return objs
+}
+
+func withdrawalObjs(requests []*WithdrawalRequest) []interface{} {
+	objs := make([]interface{}, len(requests))
+	for i, r := range requests {
+		objs[i] = r
+	}
+	return objs
+}
+
+func consolidationObjs(requests []*ConsolidationRequest) []interface{} {
+	objs := make([]interface{}, len(requests))
+	for i, r := range requests {
+		objs[i] = r
+	}
+	return objs
+}
+
+func depositRequestRoot(d *DepositRequest) ([32]byte, error) {
+	pubkey, err := bytesRoot(d.Pubkey, 48)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "pubkey")
+	}
+	creds, err := bytesRoot(d.WithdrawalCredentials, 32)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "withdrawal credentials")
+	}
+	signature, err := bytesRoot(d.Signature, 96)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "signature")
+	}
+	return merkleizeChunks([][32]byte{pubkey, creds, uintChunk(d.Amount), signature, uintChunk(d.Index)}, 8), nil
+}
+
+func withdrawalRequestRoot(w *WithdrawalRequest) ([32]byte, error) {
+	address, err := bytesRoot(w.SourceAddress, 20)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "source address")
+	}
+	pubkey, err := bytesRoot(w.ValidatorPubkey, 48)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "validator pubkey")
+	}
+	return merkleizeChunks([][32]byte{address, pubkey, uintChunk(w.Amount)}, 4), nil
+}
+
+func consolidationRequestRoot(c *ConsolidationRequest) ([32]byte, error) {
+	address, err := bytesRoot(c.SourceAddress, 20)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "source address")
+	}
+	source, err := bytesRoot(c.SourcePubkey, 48)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "source pubkey")
+	}
+	target, err := bytesRoot(c.TargetPubkey, 48)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "target pubkey")
+	}
+	return merkleizeChunks([][32]byte{address, source, target}, 4), nil
+}
+
+// bytesRoot returns the root of a byte vector of the given size.
+func bytesRoot(b []byte, size int) ([32]byte, error) {
+	if len(b) != size {
+		return [32]byte{}, fmt.Errorf("expected %d bytes, received %d", size, len(b))
+	}
+	chunks := make([][32]byte, (size+31)/32)
+	for i := range chunks {
+		copy(chunks[i][:], b[i*32:])
+	}
+	return merkleizeChunks(chunks, uint64(len(chunks))), nil
+}
+
+func uintChunk(n uint64) [32]byte {
+	var chunk [32]byte
+	binary.LittleEndian.PutUint64(chunk[:], n)
+	return chunk
+}
+
+// merkleizeChunks merkleizes chunks into a tree with room for limit chunks, padding
+// with zero subtrees.
+func merkleizeChunks(chunks [][32]byte, limit uint64) [32]byte {
+	depth := uint8(0)
+	for uint64(1)<<depth < limit {
+		depth++
+	}
+	layer := append([][32]byte{}, chunks...)
+	for d := uint8(0); d < depth; d++ {
+		if len(layer)%2 == 1 {
+			layer = append(layer, ssz.ZeroHash(d))
+		}
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = ssz.HashPair(layer[2*i], layer[2*i+1])
+		}
+		layer = next
+	}
+	if len(layer) == 0 {
+		return ssz.ZeroHash(depth)
+	}
+	return layer[0]
+}
@@ -0,0 +1,145 @@
+package rlpbridge
+
+import (
+	"encoding/binary"
+
+	"github.com/pkg/errors"
+)
+
+// encodeString returns the RLP encoding of a byte string.
+func encodeString(b []byte) []byte {
+	if len(b) == 1 && b[0] < 0x80 {
+		return []byte{b[0]}
+	}
+	return append(encodeLength(len(b), 0x80), b...)
+}
+
+// encodeUint returns the RLP encoding of an integer, as a big-endian byte string
+// without leading zeros.
+func encodeUint(v uint64) []byte {
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, v)
+	return encodeBigEndian(buf)
+}
+
+// encodeBigEndian returns the RLP encoding of a big-endian integer of any size.
+func encodeBigEndian(b []byte) []byte {
+	for len(b) > 0 && b[0] == 0 {
+		b = b[1:]
+	}
+	return encodeString(b)
+}
+
+// encodeList returns the RLP encoding of a list, given the encodings of its items.
+func encodeList(items ...[]byte) []byte {
+	size := 0
+	for _, item := range items {
+		size += len(item)
+	}
+	out := encodeLength(size, 0xc0)
+	for _, item := range items {
+		out = append(out, item...)
+	}
+	return out
+}
+
+func encodeLength(n int, offset byte) []byte {
+	if n < 56 {
+		return []byte{offset + byte(n)}
+	}
+	buf := make([]byte, 8)
+	binary.BigEndian.PutUint64(buf, uint64(n))
+	for buf[0] == 0 {
+		buf = buf[1:]
+	}
+	return append([]byte{offset + 55 + byte(len(buf))}, buf...)
+}
+
+// split returns the content of the first RLP item in data, whether it is a list,
+// and the data following it.
+func split(data []byte) (content []byte, isList bool, rest []byte, err error) {
+	if len(data) == 0 {
+		return nil, false, nil, errors.New("unexpected end of input")
+	}
+	prefix := data[0]
+	var offset, size uint64
+	switch {
+	case prefix < 0x80:
+		return data[:1], false, data[1:], nil
+	case prefix < 0xb8:
+		offset, size = 1, uint64(prefix-0x80)
+		if size == 1 && len(data) > 1 && data[1] < 0x80 {
+			return nil, false, nil, errors.New("non-canonical encoding of a single byte")
+		}
+	case prefix < 0xc0:
+		offset, size, err = readLength(data, prefix-0xb7)
+	case prefix < 0xf8:
+		offset, size, isList = 1, uint64(prefix-0xc0), true
+	default:
+		offset, size, err = readLength(data, prefix-0xf7)
+		isList = true
+	}
+	if err != nil {
+		return nil, false, nil, err
+	}
+	if uint64(len(data))-offset < size {
+		return nil, false, nil, errors.Errorf("item of size %d exceeds the %d remaining bytes", size, uint64(len(data))-offset)
+	}
+	return data[offset : offset+size], isList, data[offset+size:], nil
+}
+
+func readLength(data []byte, lenOfLen byte) (uint64, uint64, error) {
+	if uint64(len(data)) < 1+uint64(lenOfLen) || lenOfLen > 8 {
+		return 0, 0, errors.New("unexpected end of input")
+	}
+	if data[1] == 0 {
+		return 0, 0, errors.New("non-canonical size with leading zeros")
+	}
+	buf := make([]byte, 8)
+	copy(buf[8-lenOfLen:], data[1:1+lenOfLen])
+	size := binary.BigEndian.Uint64(buf)
+	if size < 56 {
+		return 0, 0, errors.New("non-canonical size for a short item")
+	}
+	return 1 + uint64(lenOfLen), size, nil
+}
+
+// decodeList returns the contents of the string items of an RLP list.
+func decodeList(data []byte) ([][]byte, error) {
+	content, isList, rest, err := split(data)
+	if err != nil {
+		return nil, err
+	}
+	if !isList {
+		return nil, errors.New("expected a list")
+	}
+	if len(rest) > 0 {
+		return nil, errors.Errorf("%d trailing bytes after list", len(rest))
+	}
+	items := make([][]byte, 0)
+	for len(content) > 0 {
+		item, itemIsList, next, err := split(content)
+		if err != nil {
+			return nil, err
+		}
+		if itemIsList {
+			return nil, errors.New("unexpected nested list")
+		}
+		items = append(items, item)
+		content = next
+	}
+	return items, nil
+}
+
+// decodeUint decodes the content of an RLP string holding an integer.
+func decodeUint(b []byte) (uint64, error) {
+	if len(b) > 8 {
+		return 0, errors.Errorf("integer of %d bytes overflows uint64", len(b))
+	}
+	if len(b) > 0 && b[0] == 0 {
+		return 0, errors.New("non-canonical integer with leading zeros")
+	}
+	buf := make([]byte, 8)
+	copy(buf[8-len(b):], b)
+	return binary.BigEndian.Uint64(buf), nil
+}
@@ -0,0 +1,255 @@
+// Package rlpbridge converts the execution structures shared by the consensus and
+// execution layers between their SSZ and RLP encodings. Bridge software receives
+// the same data under both encodings, such as an execution payload through the
+// Beacon API and the matching block from an execution client, and must check that
+// the hash tree root and the block hash commit to the same contents.
+package rlpbridge
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz"
+)
+
+const (
+	// MaxTransactionsPerPayload is the list limit of the transactions of a payload.
+	MaxTransactionsPerPayload = 1 << 20
+	// MaxBytesPerTransaction is the byte list limit of a single transaction.
+	MaxBytesPerTransaction = 1 << 30
+	// MaxWithdrawalsPerPayload is the list limit of the withdrawals of a payload.
+	MaxWithdrawalsPerPayload = 16
+)
+
+// emptyUncleHash is the hash of the RLP encoding of an empty list, which is the
+// ommers hash of every post-merge block.
+var emptyUncleHash = keccak256(encodeList())
+
+// Withdrawal is a withdrawal from the consensus layer to an execution address.
+type Withdrawal struct {
+	Index          uint64
+	ValidatorIndex uint64
+	Address        []byte `ssz-size:"20"`
+	Amount         uint64
+}
+
+// ExecutionPayload is the execution payload of a Capella beacon block.
+type ExecutionPayload struct {
+	ParentHash    []byte `ssz-size:"32"`
+	FeeRecipient  []byte `ssz-size:"20"`
+	StateRoot     []byte `ssz-size:"32"`
+	ReceiptsRoot  []byte `ssz-size:"32"`
+	LogsBloom     []byte `ssz-size:"256"`
+	PrevRandao    []byte `ssz-size:"32"`
+	BlockNumber   uint64
+	GasLimit      uint64
+	GasUsed       uint64
+	Timestamp     uint64
+	ExtraData     []byte        `ssz-max:"32"`
+	BaseFeePerGas []byte        `ssz-size:"32"`
+	BlockHash     []byte        `ssz-size:"32"`
+	Transactions  [][]byte      `ssz-max:"1048576,1073741824"`
+	Withdrawals   []*Withdrawal `ssz-max:"16"`
+}
+
+// ExecutionPayloadHeader is the execution payload header of a Capella beacon state,
+// in which transactions and withdrawals are replaced by their hash tree roots.
+type ExecutionPayloadHeader struct {
+	ParentHash       []byte `ssz-size:"32"`
+	FeeRecipient     []byte `ssz-size:"20"`
+	StateRoot        []byte `ssz-size:"32"`
+	ReceiptsRoot     []byte `ssz-size:"32"`
+	LogsBloom        []byte `ssz-size:"256"`
+	PrevRandao       []byte `ssz-size:"32"`
+	BlockNumber      uint64
+	GasLimit         uint64
+	GasUsed          uint64
+	Timestamp        uint64
+	ExtraData        []byte `ssz-max:"32"`
+	BaseFeePerGas    []byte `ssz-size:"32"`
+	BlockHash        []byte `ssz-size:"32"`
+	TransactionsRoot []byte `ssz-size:"32"`
+	WithdrawalsRoot  []byte `ssz-size:"32"`
+}
+
+// EncodeWithdrawal returns the RLP encoding of a withdrawal, as included in the
+// withdrawals trie of an execution block.
+func EncodeWithdrawal(w *Withdrawal) ([]byte, error) {
+	if len(w.Address) != 20 {
+		return nil, fmt.Errorf("expected address of 20 bytes, received %d", len(w.Address))
+	}
+	return encodeList(
+		encodeUint(w.Index),
+		encodeUint(w.ValidatorIndex),
+		encodeString(w.Address),
+		encodeUint(w.Amount),
+	), nil
+}
+
+// DecodeWithdrawal decodes the RLP encoding of a withdrawal.
+func DecodeWithdrawal(data []byte) (*Withdrawal, error) {
+	items, err := decodeList(data)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decode withdrawal")
+	}
+	if len(items) != 4 {
+		return nil, fmt.Errorf("expected 4 withdrawal fields, received %d", len(items))
+	}
+	if len(items[2]) != 20 {
+		return nil, fmt.Errorf("expected address of 20 bytes, received %d", len(items[2]))
+	}
+	w := &Withdrawal{Address: items[2]}
+	for _, f := range []struct {
+		dst  *uint64
+		item []byte
+	}{{&w.Index, items[0]}, {&w.ValidatorIndex, items[1]}, {&w.Amount, items[3]}} {
+		if *f.dst, err = decodeUint(f.item); err != nil {
+			return nil, errors.Wrap(err, "could not decode withdrawal")
+		}
+	}
+	return w, nil
+}
+
+// WithdrawalsTrieRoot returns the withdrawals root of the execution block header
+// committing to the given withdrawals.
+func WithdrawalsTrieRoot(withdrawals []*Withdrawal) ([32]byte, error) {
+	values := make([][]byte, len(withdrawals))
+	for i, w := range withdrawals {
+		enc, err := EncodeWithdrawal(w)
+		if err != nil {
+			return [32]byte{}, errors.Wrapf(err, "withdrawal %d", i)
+		}
+		values[i] = enc
+	}
+	return listTrieRoot(values), nil
+}
+
+// TransactionsTrieRoot returns the transactions root of the execution block header
+// committing to the given transactions, which are opaque encoded transactions as
+// found in an execution payload.
+func TransactionsTrieRoot(transactions [][]byte) [32]byte {
+	return listTrieRoot(transactions)
+}
+
+// EncodeHeader returns the RLP encoding of the execution block header corresponding
+// to a payload.
+func EncodeHeader(payload *ExecutionPayload) ([]byte, error) {
+	withdrawalsRoot, err := WithdrawalsTrieRoot(payload.Withdrawals)
+	if err != nil {
+		return nil, err
+	}
+	transactionsRoot := TransactionsTrieRoot(payload.Transactions)
+	if len(payload.BaseFeePerGas) != 32 {
+		return nil, fmt.Errorf("expected base fee of 32 bytes, received %d", len(payload.BaseFeePerGas))
+	}
+	// The base fee is a little-endian uint256 in SSZ, and a big-endian integer in RLP.
+	baseFee := make([]byte, 32)
+	for i := range baseFee {
+		baseFee[i] = payload.BaseFeePerGas[31-i]
+	}
+	return encodeList(
+		encodeString(payload.ParentHash),
+		encodeString(emptyUncleHash[:]),
+		encodeString(payload.FeeRecipient),
+		encodeString(payload.StateRoot),
+		encodeString(transactionsRoot[:]),
+		encodeString(payload.ReceiptsRoot),
+		encodeString(payload.LogsBloom),
+		encodeUint(0), // Difficulty.
+		encodeUint(payload.BlockNumber),
+		encodeUint(payload.GasLimit),
+		encodeUint(payload.GasUsed),
+		encodeUint(payload.Timestamp),
+		encodeString(payload.ExtraData),
+		encodeString(payload.PrevRandao),
+		encodeString(make([]byte, 8)), // Nonce.
+		encodeBigEndian(baseFee),
+		encodeString(withdrawalsRoot[:]),
+	), nil
+}
+
+// BlockHash computes the hash of the execution block corresponding to a payload.
+func BlockHash(payload *ExecutionPayload) ([32]byte, error) {
+	enc, err := EncodeHeader(payload)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "could not encode block header")
+	}
+	return keccak256(enc), nil
+}
+
+// VerifyBlockHash checks that the block hash of a payload matches the hash of the
+// execution block built from its contents.
+func VerifyBlockHash(payload *ExecutionPayload) error {
+	h, err := BlockHash(payload)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(h[:], payload.BlockHash) {
+		return fmt.Errorf("block hash %#x does not match payload contents with hash %#x", payload.BlockHash, h)
+	}
+	return nil
+}
+
+// PayloadHeader returns the execution payload header of a payload, computing the
+// hash tree roots of its transactions and withdrawals.
+func PayloadHeader(payload *ExecutionPayload) (*ExecutionPayloadHeader, error) {
+	txRoots := make([][32]byte, len(payload.Transactions))
+	for i, tx := range payload.Transactions {
+		r, err := ssz.HashTreeRootWithCapacity(tx, MaxBytesPerTransaction)
+		if err != nil {
+			return nil, errors.Wrapf(err, "could not compute root of transaction %d", i)
+		}
+		txRoots[i] = r
+	}
+	transactionsRoot, err := ssz.HashTreeRootWithCapacity(txRoots, MaxTransactionsPerPayload)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compute transactions root")
+	}
+	withdrawals := payload.Withdrawals
+	if withdrawals == nil {
+		withdrawals = []*Withdrawal{}
+	}
+	withdrawalsRoot, err := ssz.HashTreeRootWithCapacity(withdrawals, MaxWithdrawalsPerPayload)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not compute withdrawals root")
+	}
+	return &ExecutionPayloadHeader{
+		ParentHash:       payload.ParentHash,
+		FeeRecipient:     payload.FeeRecipient,
+		StateRoot:        payload.StateRoot,
+		ReceiptsRoot:     payload.ReceiptsRoot,
+		LogsBloom:        payload.LogsBloom,
+		PrevRandao:       payload.PrevRandao,
+		BlockNumber:      payload.BlockNumber,
+		GasLimit:         payload.GasLimit,
+		GasUsed:          payload.GasUsed,
+		Timestamp:        payload.Timestamp,
+		ExtraData:        payload.ExtraData,
+		BaseFeePerGas:    payload.BaseFeePerGas,
+		BlockHash:        payload.BlockHash,
+		TransactionsRoot: transactionsRoot[:],
+		WithdrawalsRoot:  withdrawalsRoot[:],
+	}, nil
+}
+
+// VerifyPayloadHeader checks that a payload header commits to the given payload,
+// both through its hash tree root and through the block hash it carries.
+func VerifyPayloadHeader(header *ExecutionPayloadHeader, payload *ExecutionPayload) error {
+	expected, err := PayloadHeader(payload)
+	if err != nil {
+		return err
+	}
+	want, err := ssz.HashTreeRoot(expected)
+	if err != nil {
+		return err
+	}
+	got, err := ssz.HashTreeRoot(header)
+	if err != nil {
+		return err
+	}
+	if got != want {
+		return fmt.Errorf("payload header root %#x does not match payload root %#x", got, want)
+	}
+	return VerifyBlockHash(payload)
+}
@@ -0,0 +1,189 @@
+package rlpbridge
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/prysmaticlabs/go-ssz"
+)
+
+func TestTrieRoot(t *testing.T) {
+	items := []trieItem{
+		{key: []byte("do"), value: []byte("verb")},
+		{key: []byte("dog"), value: []byte("puppy")},
+		{key: []byte("doge"), value: []byte("coin")},
+		{key: []byte("horse"), value: []byte("stallion")},
+	}
+	root := trieRoot(items)
+	want := "5991bb8c6514148a29db676a14ac506cd2cd5775ace63c30a4fe457715e9ac84"
+	if hex.EncodeToString(root[:]) != want {
+		t.Errorf("Wanted trie root %s, received %#x", want, root)
+	}
+	if root := listTrieRoot(nil); hex.EncodeToString(root[:]) != "56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421" {
+		t.Errorf("Unexpected empty trie root %#x", root)
+	}
+}
+
+func TestWithdrawal_RoundTrip(t *testing.T) {
+	w := &Withdrawal{
+		Index:          0,
+		ValidatorIndex: 1024,
+		Address:        bytes.Repeat([]byte{0xaa}, 20),
+		Amount:         32000000000,
+	}
+	enc, err := EncodeWithdrawal(w)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "df80820400" + "94" + hex.EncodeToString(w.Address) + "850773594000"
+	if hex.EncodeToString(enc) != want {
+		t.Errorf("Wanted encoding %s, received %#x", want, enc)
+	}
+	dec, err := DecodeWithdrawal(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if dec.Index != w.Index || dec.ValidatorIndex != w.ValidatorIndex || dec.Amount != w.Amount || !bytes.Equal(dec.Address, w.Address) {
+		t.Errorf("Wanted %+v, received %+v", w, dec)
+	}
+	for _, bad := range []string{
+		"e08083000400" + "94" + hex.EncodeToString(w.Address) + "850773594000",   // Leading zero in validator index.
+		"de80820400" + "93" + hex.EncodeToString(w.Address[1:]) + "850773594000", // Short address.
+		"df80820400" + "94" + hex.EncodeToString(w.Address) + "8507735940",       // Truncated.
+	} {
+		data, _ := hex.DecodeString(bad)
+		if _, err := DecodeWithdrawal(data); err == nil {
+			t.Errorf("Expected error decoding %s", bad)
+		}
+	}
+}
+
+func testPayload() *ExecutionPayload {
+	baseFee := make([]byte, 32)
+	baseFee[0] = 7
+	return &ExecutionPayload{
+		ParentHash:    bytes.Repeat([]byte{1}, 32),
+		FeeRecipient:  bytes.Repeat([]byte{2}, 20),
+		StateRoot:     bytes.Repeat([]byte{3}, 32),
+		ReceiptsRoot:  bytes.Repeat([]byte{4}, 32),
+		LogsBloom:     make([]byte, 256),
+		PrevRandao:    bytes.Repeat([]byte{5}, 32),
+		BlockNumber:   17034870,
+		GasLimit:      30000000,
+		GasUsed:       21000,
+		Timestamp:     1681338455,
+		ExtraData:     []byte("go-ssz"),
+		BaseFeePerGas: baseFee,
+		BlockHash:     make([]byte, 32),
+		Transactions:  [][]byte{{0x02, 0xc0}, bytes.Repeat([]byte{0xf8}, 100)},
+		Withdrawals: []*Withdrawal{
+			{Index: 1, ValidatorIndex: 2, Address: bytes.Repeat([]byte{9}, 20), Amount: 3},
+		},
+	}
+}
+
+func TestVerifyBlockHash(t *testing.T) {
+	payload := testPayload()
+	h, err := BlockHash(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyBlockHash(payload); err == nil {
+		t.Error("Expected error for zero block hash")
+	}
+	payload.BlockHash = h[:]
+	if err := VerifyBlockHash(payload); err != nil {
+		t.Error(err)
+	}
+	payload.Withdrawals[0].Amount++
+	if err := VerifyBlockHash(payload); err == nil {
+		t.Error("Expected error after modifying a withdrawal")
+	}
+}
+
+func TestVerifyPayloadHeader(t *testing.T) {
+	payload := testPayload()
+	h, err := BlockHash(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	payload.BlockHash = h[:]
+	header, err := PayloadHeader(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyPayloadHeader(header, payload); err != nil {
+		t.Fatal(err)
+	}
+	// A payload and its header are the same container, with the transactions and
+	// withdrawals replaced by their roots.
+	payloadRoot, err := ssz.HashTreeRoot(payload)
+	if err != nil {
+		t.Fatal(err)
+	}
+	headerRoot, err := ssz.HashTreeRoot(header)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if payloadRoot != headerRoot {
+		t.Errorf("Payload root %#x does not match header root %#x", payloadRoot, headerRoot)
+	}
+	header.TransactionsRoot = make([]byte, 32)
+	if err := VerifyPayloadHeader(header, payload); err == nil {
+		t.Error("Expected error for header with the wrong transactions root")
+	}
+}
+
+func TestExecutionRequests(t *testing.T) {
+	requests := &ExecutionRequests{
+		Deposits: []*DepositRequest{{
+			Pubkey:                bytes.Repeat([]byte{0x01}, 48),
+			WithdrawalCredentials: bytes.Repeat([]byte{0x02}, 32),
+			Amount:                32000000000,
+			Signature:             bytes.Repeat([]byte{0x03}, 96),
+			Index:                 7,
+		}},
+		Consolidations: []*ConsolidationRequest{{
+			SourceAddress: bytes.Repeat([]byte{0xaa}, 20),
+			SourcePubkey:  bytes.Repeat([]byte{0x04}, 48),
+			TargetPubkey:  bytes.Repeat([]byte{0x05}, 48),
+		}},
+	}
+	for _, r := range []*ExecutionRequests{{}, requests} {
+		want, err := ssz.HashTreeRoot(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, err := ExecutionRequestsRoot(r)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != want {
+			t.Errorf("Wanted root %#x, received %#x", want, root)
+		}
+	}
+
+	list, err := RequestsList(requests)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || len(list[0]) != 1+192 || list[0][0] != DepositRequestType || list[1][0] != ConsolidationRequestType {
+		t.Fatalf("Unexpected requests list %#x", list)
+	}
+	if err := VerifyRequestsHash(requests, RequestsHash(list)); err != nil {
+		t.Error(err)
+	}
+	// Blocks without requests commit to the hash of no data at all.
+	empty := "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+	if h := RequestsHash(nil); hex.EncodeToString(h[:]) != empty {
+		t.Errorf("Wanted empty requests hash %s, received %#x", empty, h)
+	}
+	if err := VerifyRequestsHash(requests, RequestsHash(nil)); err == nil {
+		t.Error("Expected mismatching requests hash to fail")
+	}
+	requests.Withdrawals = make([]*WithdrawalRequest, MaxWithdrawalRequestsPerPayload+1)
+	if _, err := ExecutionRequestsRoot(requests); err == nil {
+		t.Error("Expected error for too many withdrawal requests")
+	}
+}
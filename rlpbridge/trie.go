@@ -0,0 +1,132 @@
+package rlpbridge
+
+import (
+	"bytes"
+	"sort"
+
+	"golang.org/x/crypto/sha3"
+)
+
+// EmptyTrieRoot is the root of an empty Merkle Patricia trie.
+var EmptyTrieRoot = keccak256(encodeString(nil))
+
+type trieItem struct {
+	key   []byte
+	value []byte
+}
+
+// listTrieRoot returns the root of the Merkle Patricia trie mapping the RLP encoding
+// of each index to the item at that index, which is how the execution layer commits
+// to the transactions and withdrawals of a block.
+func listTrieRoot(values [][]byte) [32]byte {
+	items := make([]trieItem, len(values))
+	for i, v := range values {
+		items[i] = trieItem{key: encodeUint(uint64(i)), value: v}
+	}
+	return trieRoot(items)
+}
+
+// trieRoot returns the root of the Merkle Patricia trie holding the given items.
+func trieRoot(items []trieItem) [32]byte {
+	if len(items) == 0 {
+		return EmptyTrieRoot
+	}
+	nibbleItems := make([]trieItem, len(items))
+	for i, item := range items {
+		nibbleItems[i] = trieItem{key: toNibbles(item.key), value: item.value}
+	}
+	sort.Slice(nibbleItems, func(i, j int) bool {
+		return bytes.Compare(nibbleItems[i].key, nibbleItems[j].key) < 0
+	})
+	return keccak256(trieNode(nibbleItems, 0))
+}
+
+// trieNode returns the RLP encoding of the node holding the sorted items, whose keys
+// share their first depth nibbles.
+func trieNode(items []trieItem, depth int) []byte {
+	if len(items) == 1 {
+		return encodeList(encodeString(hexPrefix(items[0].key[depth:], true)), encodeString(items[0].value))
+	}
+	prefix := commonPrefix(items[0].key[depth:], items[len(items)-1].key[depth:])
+	if prefix > 0 {
+		return encodeList(
+			encodeString(hexPrefix(items[0].key[depth:depth+prefix], false)),
+			nodeRef(trieNode(items, depth+prefix)),
+		)
+	}
+	children := make([][]byte, 17)
+	for nibble := byte(0); nibble < 16; nibble++ {
+		children[nibble] = encodeString(nil)
+	}
+	children[16] = encodeString(nil)
+	start := 0
+	if len(items[0].key) == depth {
+		children[16] = encodeString(items[0].value)
+		start = 1
+	}
+	for start < len(items) {
+		nibble := items[start].key[depth]
+		end := start + 1
+		for end < len(items) && items[end].key[depth] == nibble {
+			end++
+		}
+		children[nibble] = nodeRef(trieNode(items[start:end], depth+1))
+		start = end
+	}
+	return encodeList(children...)
+}
+
+// nodeRef embeds nodes shorter than a hash into their parent, and references the
+// others by hash.
+func nodeRef(node []byte) []byte {
+	if len(node) < 32 {
+		return node
+	}
+	h := keccak256(node)
+	return encodeString(h[:])
+}
+
+func commonPrefix(a []byte, b []byte) int {
+	i := 0
+	for i < len(a) && i < len(b) && a[i] == b[i] {
+		i++
+	}
+	return i
+}
+
+func toNibbles(key []byte) []byte {
+	nibbles := make([]byte, 2*len(key))
+	for i, b := range key {
+		nibbles[2*i] = b >> 4
+		nibbles[2*i+1] = b & 0x0f
+	}
+	return nibbles
+}
+
+// hexPrefix packs nibbles into bytes, flagging odd lengths and leaf nodes in the
+// first nibble.
+func hexPrefix(nibbles []byte, leaf bool) []byte {
+	flag := byte(0)
+	if leaf {
+		flag = 2
+	}
+	out := make([]byte, 0, len(nibbles)/2+1)
+	if len(nibbles)%2 == 1 {
+		out = append(out, (flag+1)<<4|nibbles[0])
+		nibbles = nibbles[1:]
+	} else {
+		out = append(out, flag<<4)
+	}
+	for i := 0; i < len(nibbles); i += 2 {
+		out = append(out, nibbles[i]<<4|nibbles[i+1])
+	}
+	return out
+}
+
+func keccak256(data []byte) [32]byte {
+	h := sha3.NewLegacyKeccak256()
+	h.Write(data)
+	var out [32]byte
+	copy(out[:], h.Sum(nil))
+	return out
+}
@@ -0,0 +1,177 @@
+package ssz
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// CacheHandle controls caching of the roots of the fields of a single struct type.
+type CacheHandle = types.CacheHandle
+
+// CacheFor returns the cache handle of a struct type, with which a library can opt its
+// own types out of the hash tree root caches without changing the package-level option:
+//
+//  ssz.CacheFor(reflect.TypeOf(LightClientUpdate{})).SetEnabled(false)
+func CacheFor(typ reflect.Type) CacheHandle {
+	return types.CacheFor(typ)
+}
+
+// RootCache caches the subtree roots of the fields of a container, such as a beacon
+// state, which is mutated in place by its owner. Fields, or elements of list and vector
+// fields, are marked dirty as they are mutated, and only their branches are re-hashed
+// when the root is next computed:
+//
+//  cache, err := ssz.NewRootCache(state)
+//  if err != nil {
+//      return err
+//  }
+//  state.Balances[idx] += reward
+//  if err := cache.MarkDirty("Balances", idx); err != nil {
+//      return err
+//  }
+//  root, err := cache.Root()
+//
+// Unlike a Journal, the cache does not see the mutations themselves: a mutation which
+// is not marked dirty leaves a stale root. A RootCache is not safe for concurrent use.
+type RootCache struct {
+	*containerTree
+	// dirty holds the indices of the dirty elements of each field, a nil set standing
+	// for the whole field.
+	dirty map[string]map[int]struct{}
+	// lengths holds the length of fields held in slices as of the last root computation.
+	lengths map[string]int
+}
+
+// NewRootCache hashes the container pointed to by obj and returns a cache of the roots
+// of its fields.
+func NewRootCache(obj interface{}) (*RootCache, error) {
+	c, err := newContainerTree(obj)
+	if err != nil {
+		return nil, err
+	}
+	r := &RootCache{
+		containerTree: c,
+		dirty:         make(map[string]map[int]struct{}),
+		lengths:       make(map[string]int),
+	}
+	r.recordLengths()
+	return r, nil
+}
+
+// MarkDirty marks the named field as mutated. Given indices, only the elements at those
+// indices of a list or vector field are marked. Elements appended to or removed from
+// list fields are detected from their length and need not be marked.
+func (r *RootCache) MarkDirty(name string, indices ...int) error {
+	f, ok := r.fields[name]
+	if !ok {
+		return fmt.Errorf("no field %s in %v", name, r.typ)
+	}
+	if len(indices) == 0 {
+		r.dirty[name] = nil
+		return nil
+	}
+	goTyp := r.typ.Field(f.index).Type
+	if !f.sequence(goTyp) {
+		return fmt.Errorf("field %s of type %v is not a list or vector", name, goTyp)
+	}
+	set, ok := r.dirty[name]
+	if ok && set == nil {
+		// The whole field is already dirty.
+		return nil
+	}
+	if set == nil {
+		set = make(map[int]struct{}, len(indices))
+		r.dirty[name] = set
+	}
+	for _, index := range indices {
+		if index < 0 {
+			return fmt.Errorf("negative index %d for field %s", index, name)
+		}
+		set[index] = struct{}{}
+	}
+	return nil
+}
+
+// Root re-hashes the dirty fields and elements and returns the hash tree root of the
+// container. Dirty marks are cleared once they are all re-hashed.
+func (r *RootCache) Root() ([32]byte, error) {
+	for name, prevLength := range r.lengths {
+		f := r.fields[name]
+		if _, ok := r.dirty[name]; ok || r.obj.Field(f.index).Len() == prevLength {
+			continue
+		}
+		if f.typ.Kind() == reflect.Slice && f.sequence(r.typ.Field(f.index).Type) {
+			r.dirty[name] = make(map[int]struct{})
+		} else {
+			r.dirty[name] = nil
+		}
+	}
+	names := make([]string, 0, len(r.dirty))
+	for name := range r.dirty {
+		names = append(names, name)
+	}
+	// Fields are re-hashed in their order in the container for errors to be reported
+	// deterministically.
+	sort.Slice(names, func(i, k int) bool {
+		return r.fields[names[i]].index < r.fields[names[k]].index
+	})
+	for _, name := range names {
+		if err := r.refresh(name); err != nil {
+			return [32]byte{}, errors.Wrapf(err, "%s.%s", r.typ.Name(), name)
+		}
+		delete(r.dirty, name)
+	}
+	r.recordLengths()
+	return r.node.Root(), nil
+}
+
+func (r *RootCache) refresh(name string) error {
+	f := r.fields[name]
+	set := r.dirty[name]
+	if set == nil || !r.fixedDepth(f) {
+		return r.rebuildField(f)
+	}
+	length := r.obj.Field(f.index).Len()
+	prevLength, tracked := r.lengths[name]
+	isList := f.typ.Kind() == reflect.Slice
+	if tracked && (length < prevLength || (!isList && length != prevLength)) {
+		// Chunks of removed elements would otherwise linger in the tree, as would the
+		// padding of a vector held in a slice of the wrong length.
+		return r.rebuildField(f)
+	}
+	indices := make([]int, 0, len(set))
+	for index := range set {
+		if index >= length {
+			return fmt.Errorf("index %d out of range for field %s of length %d", index, name, length)
+		}
+		indices = append(indices, index)
+	}
+	for index := prevLength; isList && index < length; index++ {
+		if _, ok := set[index]; !ok {
+			indices = append(indices, index)
+		}
+	}
+	for _, index := range indices {
+		if err := r.updateElement(f, index); err != nil {
+			return err
+		}
+	}
+	if isList && length != prevLength {
+		return r.updateLength(f)
+	}
+	return nil
+}
+
+// recordLengths records the length of fields held in slices, so that elements appended
+// before the next root computation are hashed.
+func (r *RootCache) recordLengths() {
+	for name, f := range r.fields {
+		if r.typ.Field(f.index).Type.Kind() == reflect.Slice {
+			r.lengths[name] = r.obj.Field(f.index).Len()
+		}
+	}
+}
@@ -0,0 +1,109 @@
+package ssz
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+func TestRootCache_MatchesHashTreeRoot(t *testing.T) {
+	state := &proofState{
+		Slot:       1,
+		BlockRoots: make([][]byte, 8),
+		Balances:   []uint64{1, 2, 3, 4, 5},
+		Bits:       bitfield.Bitlist{0x0d},
+	}
+	cache, err := NewRootCache(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator := &proofValidator{Pubkey: make([]byte, 48), WithdrawalCredentials: make([]byte, 32), EffectiveBalance: 32}
+	steps := []func() error{
+		func() error {
+			state.Slot = 2
+			return cache.MarkDirty("Slot")
+		},
+		func() error {
+			state.Balances[2] = 30
+			state.Balances[4] = 50
+			return cache.MarkDirty("Balances", 2, 4)
+		},
+		// Appended elements are detected without being marked.
+		func() error {
+			state.Balances = append(state.Balances, 6, 7, 8, 9)
+			return nil
+		},
+		func() error {
+			state.BlockRoots[7] = make([]byte, 32)
+			state.BlockRoots[7][0] = 1
+			return cache.MarkDirty("BlockRoots", 7)
+		},
+		func() error {
+			state.Validators = append(state.Validators, validator, &proofValidator{EffectiveBalance: 16})
+			return nil
+		},
+		func() error {
+			state.Validators[1].Slashed = true
+			return cache.MarkDirty("Validators", 1)
+		},
+		func() error {
+			state.Balances = state.Balances[:3]
+			return nil
+		},
+		func() error {
+			state.Bits = bitfield.Bitlist{0x1f}
+			state.Graffiti = "cache"
+			if err := cache.MarkDirty("Bits"); err != nil {
+				return err
+			}
+			return cache.MarkDirty("Graffiti")
+		},
+	}
+	for i, step := range steps {
+		if err := step(); err != nil {
+			t.Fatalf("Step %d: %v", i, err)
+		}
+		got, err := cache.Root()
+		if err != nil {
+			t.Fatalf("Step %d: %v", i, err)
+		}
+		want, err := HashTreeRoot(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Step %d: wanted root %#x, received %#x", i, want, got)
+		}
+	}
+}
+
+func TestRootCache_InvalidMarks(t *testing.T) {
+	state := &proofState{Balances: []uint64{1}}
+	cache, err := NewRootCache(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cache.MarkDirty("Missing"); err == nil {
+		t.Error("Expected error for unknown field")
+	}
+	if err := cache.MarkDirty("Slot", 0); err == nil {
+		t.Error("Expected error for marking an element of a basic field")
+	}
+	if err := cache.MarkDirty("Balances", -1); err == nil {
+		t.Error("Expected error for negative index")
+	}
+	if err := cache.MarkDirty("Balances", 3); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := cache.Root(); err == nil {
+		t.Error("Expected error for index out of range")
+	}
+	state.Balances = append(state.Balances, 2, 3, 4)
+	got, err := cache.Root()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, err := HashTreeRoot(state); err != nil || got != want {
+		t.Errorf("Wanted root %#x, received %#x: %v", want, got, err)
+	}
+}
@@ -1,6 +1,7 @@
 package ssz_test
 
 import (
+	"bytes"
 	"math/rand"
 	"reflect"
 	"testing"
@@ -179,6 +180,99 @@ func generateData(count int) []allTypes {
 	return data
 }
 
+func TestSize_MatchesMarshal(t *testing.T) {
+	inputs := []interface{}{
+		forkExample,
+		&forkExample,
+		(*fork)(nil),
+		nestedItemExample,
+		nestedVarItemExample,
+		varItemExample,
+		[]varItem{varItemExample, varItemAmbiguous},
+		[]*fork{&forkExample, nil},
+		"hello",
+		uint16(300),
+	}
+	for _, item := range generateData(32) {
+		inputs = append(inputs, item)
+	}
+	for _, input := range inputs {
+		enc, err := ssz.Marshal(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		size, err := ssz.Size(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size != uint64(len(enc)) {
+			t.Errorf("Wanted size %d for %+v, received %d", len(enc), input, size)
+		}
+	}
+}
+
+func TestSize_UnsupportedType(t *testing.T) {
+	if _, err := ssz.Size(nil); err == nil {
+		t.Error("Expected error for untyped nil")
+	}
+	if _, err := ssz.Size(struct{ Foo []complex64 }{}); err == nil {
+		t.Error("Expected error for nested unsupported type")
+	}
+}
+
+func TestHashTreeRootWith_MatchesHashTreeRoot(t *testing.T) {
+	inputs := []interface{}{
+		forkExample,
+		&forkExample,
+		nestedItemExample,
+		nestedVarItemExample,
+		varItemExample,
+		varItemAmbiguous,
+		(*nestedItem)(nil),
+		[]uint64{1, 2, 3, 4, 5},
+		[][32]byte{{1}, {2}, {3}},
+		[]varItem{varItemExample, varItemAmbiguous},
+		"hello",
+		uint16(300),
+	}
+	for _, item := range generateData(32) {
+		inputs = append(inputs, item)
+	}
+	h := &ssz.Hasher{}
+	for _, input := range inputs {
+		want, err := ssz.HashTreeRoot(input)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := ssz.HashTreeRootWith(input, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Wanted root %#x for %+v, received %#x", want, input, got)
+		}
+	}
+}
+
+func BenchmarkHashTreeRoot(b *testing.B) {
+	data := generateData(b.N)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ssz.HashTreeRoot(data[i])
+	}
+}
+
+func BenchmarkHashTreeRootWith(b *testing.B) {
+	data := generateData(b.N)
+	h := &ssz.Hasher{}
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		ssz.HashTreeRootWith(data[i], h)
+	}
+}
+
 func BenchmarkMarshal(b *testing.B) {
 	data := generateData(b.N)
 	b.ResetTimer()
@@ -213,3 +307,90 @@ func BenchmarkUnmarshal(b *testing.B) {
 		}
 	}
 }
+
+func TestEncoder_MatchesMarshal(t *testing.T) {
+	type tagged struct {
+		Root   []byte   `ssz-size:"32"`
+		Roots  [][]byte `ssz-size:"?,32"`
+		Forks  []*fork
+		Nested []nestedItem
+		Items  nestedVarItem
+	}
+	tests := []interface{}{
+		uint64(23929309),
+		[8]byte{1, 2, 3, 4, 5, 6, 7, 8},
+		[]byte{9, 8, 9, 8},
+		[20][2]uint32{{3, 4}, {5}, {8}, {9, 10}},
+		[]bool{true, false, true, true, true},
+		"hello world",
+		varItemExample,
+		[][]uint64{{4, 3, 2}, {1}, {0}},
+		[][][]uint64{{{1, 2}, {3}}, {{4, 5}}, {{0}}},
+		[3][]uint64{{1, 2}, {4, 5, 6}, {7}},
+		[]*nestedItem{&nestedItemExample, &nestedItemExample},
+		&tagged{
+			Root:   make([]byte, 32),
+			Roots:  [][]byte{make([]byte, 32), make([]byte, 32)},
+			Forks:  []*fork{&forkExample, nil},
+			Nested: []nestedItem{nestedItemExample},
+			Items:  nestedVarItem{Field1: []varItem{varItemExample, varItemAmbiguous}, Field2: 3},
+		},
+		generateData(3),
+	}
+	for _, tt := range tests {
+		want, err := ssz.Marshal(tt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var buf bytes.Buffer
+		if err := ssz.NewEncoder(&buf).Encode(tt); err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(want, buf.Bytes()) {
+			t.Errorf("Encoding of %T differs from Marshal: wanted %v, received %v", tt, want, buf.Bytes())
+		}
+	}
+}
+
+func TestDecoder_ReadsFromStream(t *testing.T) {
+	var stream bytes.Buffer
+	enc := ssz.NewEncoder(&stream)
+	for _, item := range []interface{}{forkExample, fork{Epoch: 9}, nestedItemExample} {
+		if err := enc.Encode(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	dec := ssz.NewDecoder(&stream)
+	var first, second fork
+	if err := dec.Decode(&first); err != nil {
+		t.Fatal(err)
+	}
+	if err := dec.Decode(&second); err != nil {
+		t.Fatal(err)
+	}
+	third := &nestedItem{}
+	if err := dec.Decode(third); err != nil {
+		t.Fatal(err)
+	}
+	if first != forkExample || second.Epoch != 9 || !ssz.DeepEqual(third, &nestedItemExample) {
+		t.Errorf("Unexpected decoded values %v, %v, %v", first, second, third)
+	}
+}
+
+func TestDecoder_MaxSize(t *testing.T) {
+	encoded, err := ssz.Marshal([]uint64{1, 2, 3, 4})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []uint64
+	if err := ssz.NewDecoder(bytes.NewReader(encoded), ssz.WithMaxSize(31)).Decode(&out); err == nil {
+		t.Error("Expected error when input exceeds the maximum size")
+	}
+	if err := ssz.NewDecoder(bytes.NewReader(encoded), ssz.WithMaxSize(32)).Decode(&out); err != nil {
+		t.Fatal(err)
+	}
+	var f fork
+	if err := ssz.NewDecoder(bytes.NewReader(encoded), ssz.WithMaxSize(8)).Decode(&f); err == nil {
+		t.Error("Expected error when fixed size exceeds the maximum size")
+	}
+}
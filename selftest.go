@@ -0,0 +1,110 @@
+package ssz
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+type selfTestContainer struct {
+	PreviousVersion [4]byte
+	CurrentVersion  [4]byte
+	Epoch           uint64
+}
+
+type selfTestByteOrder struct {
+	A uint16
+	B int32
+	C uint32
+	D uint64
+}
+
+var selfTestByteOrderValue = selfTestByteOrder{A: 0x0102, B: -2, C: 0x01020304, D: 0x0102030405060708}
+
+// SelfTest hashes a set of built-in vectors using the hashing backend currently in use
+// and returns an error on the first mismatch. It is meant to be called at process start,
+// to catch miscompiled or buggy hardware acceleration paths before they produce roots
+// which diverge from the rest of the network, or byte order bugs on big-endian hosts.
+func SelfTest() error {
+	vectors := []struct {
+		name string
+		want string
+		root func() ([32]byte, error)
+	}{
+		{
+			name: "sha256 of empty input",
+			want: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+			root: func() ([32]byte, error) { return Hash([]byte{}), nil },
+		},
+		{
+			name: "sha256 of abc",
+			want: "ba7816bf8f01cfea414140de5dae2223b00361a396177a9cb410ff61f20015ad",
+			root: func() ([32]byte, error) { return Hash([]byte("abc")), nil },
+		},
+		{
+			name: "zero hash at depth 1",
+			want: "f5a5fd42d16a20302798ef6ed309979b43003d2320d9f0e8ea9831a92759fb4b",
+			root: func() ([32]byte, error) { return HashPair([32]byte{}, [32]byte{}), nil },
+		},
+		{
+			name: "zero hash at depth 3",
+			want: "c78009fdf07fc56a11f122370658a353aaa542ed63e44c4bc15ff4cd105ab33c",
+			root: func() ([32]byte, error) { return ZeroHash(3), nil },
+		},
+		{
+			name: "empty container root",
+			want: "db56114e00fdd4c1f85c892bf35ac9a89289aaecb1ebd0a96cde606a748b5d71",
+			root: func() ([32]byte, error) { return HashTreeRoot(selfTestContainer{}) },
+		},
+		{
+			name: "byte order root",
+			want: "ce93bf41e098399265e88b74e095b0242e25305f48fda25df426e9109890d2a5",
+			root: func() ([32]byte, error) { return HashTreeRoot(selfTestByteOrderValue) },
+		},
+		{
+			name: "uint64 list root",
+			want: "8dfcc0c61e1cfbec317bfc62c874364d717f1ba3ca13cfe07d86864883c24093",
+			root: func() ([32]byte, error) { return HashTreeRootWithCapacity([]uint64{1, 2, 3}, 4) },
+		},
+	}
+	for _, v := range vectors {
+		root, err := v.root()
+		if err != nil {
+			return fmt.Errorf("self test %q failed: %v", v.name, err)
+		}
+		if hex.EncodeToString(root[:]) != v.want {
+			return fmt.Errorf("self test %q failed: wanted %s, received %#x", v.name, v.want, root)
+		}
+	}
+	// Integers are serialized little-endian regardless of the host byte order, which we
+	// assert explicitly so that a regression on big-endian platforms such as s390x is
+	// caught at process start rather than by peers rejecting our messages.
+	enc, err := Marshal(selfTestByteOrderValue)
+	if err != nil {
+		return fmt.Errorf("self test \"byte order serialization\" failed: %v", err)
+	}
+	if want := "0201feffffff040302010807060504030201"; hex.EncodeToString(enc) != want {
+		return fmt.Errorf("self test \"byte order serialization\" failed: wanted %s, received %#x", want, enc)
+	}
+	var dec selfTestByteOrder
+	if err := Unmarshal(enc, &dec); err != nil || dec != selfTestByteOrderValue {
+		return fmt.Errorf("self test \"byte order deserialization\" failed: received %+v, %v", dec, err)
+	}
+	// Accelerated implementations process inputs in blocks, so we compare against the
+	// standard library for every input length across several block boundaries.
+	data := make([]byte, 4*sha256.BlockSize+1)
+	for i := range data {
+		data[i] = byte(i*7 + 3)
+	}
+	for i := 0; i <= len(data); i++ {
+		want := sha256.Sum256(data[:i])
+		got := hashing.Hash(data[:i])
+		if !bytes.Equal(want[:], got[:]) {
+			return fmt.Errorf("self test failed: hash of %d bytes is %#x, wanted %#x", i, got, want)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,45 @@
+package ssz
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ComputeForkDataRoot returns the hash tree root of the ForkData container holding a
+// fork version and the root of the genesis validators, which tells apart the networks
+// and forks a signature is valid on. This is compute_fork_data_root from the consensus
+// specification.
+func ComputeForkDataRoot(currentVersion [4]byte, genesisValidatorsRoot [32]byte) [32]byte {
+	var versionChunk [32]byte
+	copy(versionChunk[:], currentVersion[:])
+	return HashPair(versionChunk, genesisValidatorsRoot)
+}
+
+// ComputeDomain returns the domain of signatures of the given type, such as beacon block
+// proposals, made under a fork version of a network. It is the domain type followed by
+// the first 28 bytes of the fork data root. This is compute_domain from the consensus
+// specification:
+//
+//  domain := ssz.ComputeDomain(domainBeaconProposer, forkVersion, genesisValidatorsRoot)
+//  root, err := ssz.ComputeSigningRoot(block, domain)
+//  if err != nil {
+//      return errors.Wrap(err, "could not compute signing root")
+//  }
+//  signature := secretKey.Sign(root[:])
+func ComputeDomain(domainType [4]byte, forkVersion [4]byte, genesisValidatorsRoot [32]byte) [32]byte {
+	forkDataRoot := ComputeForkDataRoot(forkVersion, genesisValidatorsRoot)
+	var domain [32]byte
+	copy(domain[:4], domainType[:])
+	copy(domain[4:], forkDataRoot[:28])
+	return domain
+}
+
+// ComputeSigningRoot returns the root signed for an object in a domain, that is the hash
+// tree root of the SigningData container holding the root of the object and the domain.
+// This is compute_signing_root from the consensus specification.
+func ComputeSigningRoot(val interface{}, domain [32]byte) ([32]byte, error) {
+	objectRoot, err := HashTreeRoot(val)
+	if err != nil {
+		return [32]byte{}, errors.Wrap(err, "could not compute object root")
+	}
+	return HashPair(objectRoot, domain), nil
+}
@@ -0,0 +1,50 @@
+package ssz
+
+import (
+	"testing"
+)
+
+type forkData struct {
+	CurrentVersion        [4]byte
+	GenesisValidatorsRoot [32]byte
+}
+
+type signingData struct {
+	ObjectRoot [32]byte
+	Domain     [32]byte
+}
+
+func TestComputeSigningRoot(t *testing.T) {
+	version := [4]byte{1, 0, 0, 0}
+	genesisValidatorsRoot := [32]byte{0x4b, 0x36, 0x3d, 0xb9}
+	forkDataRoot, err := HashTreeRoot(&forkData{CurrentVersion: version, GenesisValidatorsRoot: genesisValidatorsRoot})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root := ComputeForkDataRoot(version, genesisValidatorsRoot); root != forkDataRoot {
+		t.Errorf("Wanted fork data root %#x, received %#x", forkDataRoot, root)
+	}
+	domain := ComputeDomain([4]byte{7}, version, genesisValidatorsRoot)
+	var wantDomain [32]byte
+	wantDomain[0] = 7
+	copy(wantDomain[4:], forkDataRoot[:28])
+	if domain != wantDomain {
+		t.Errorf("Wanted domain %#x, received %#x", wantDomain, domain)
+	}
+
+	f := &fork{PreviousVersion: [4]byte{1}, CurrentVersion: [4]byte{2}, Epoch: 3}
+	objectRoot, err := HashTreeRoot(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := HashTreeRoot(&signingData{ObjectRoot: objectRoot, Domain: domain})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root, err := ComputeSigningRoot(f, domain); err != nil || root != want {
+		t.Errorf("Wanted signing root %#x, received %#x: %v", want, root, err)
+	}
+	if _, err := ComputeSigningRoot(nil, domain); err == nil {
+		t.Error("Expected error for an untyped nil")
+	}
+}
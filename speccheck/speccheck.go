@@ -0,0 +1,329 @@
+// Package speccheck compares Go struct definitions against the container schemas of
+// the consensus specification, as dumped from the executable pyspec. Field order
+// determines both the serialization and the hash tree root of a container, yet a
+// reordered or retyped field still encodes and decodes without error, so drift is
+// otherwise only caught by failing spec tests or a chain split.
+//
+// A schema lists the fields of each container in order, along with the constants
+// used by list limits and vector lengths:
+//
+//  constants:
+//    MAX_VALIDATORS_PER_COMMITTEE: 2048
+//  containers:
+//    Checkpoint:
+//      - {name: epoch, type: Epoch}
+//      - {name: root, type: Root}
+//    PendingAttestation:
+//      - {name: aggregation_bits, type: "Bitlist[MAX_VALIDATORS_PER_COMMITTEE]"}
+//      ...
+package speccheck
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// DefaultAliases maps the custom types of the consensus specification to the SSZ
+// types they stand for.
+var DefaultAliases = map[string]string{
+	"Slot":           "uint64",
+	"Epoch":          "uint64",
+	"CommitteeIndex": "uint64",
+	"ValidatorIndex": "uint64",
+	"Gwei":           "uint64",
+	"Root":           "Bytes32",
+	"Hash32":         "Bytes32",
+	"Version":        "Bytes4",
+	"DomainType":     "Bytes4",
+	"ForkDigest":     "Bytes4",
+	"Domain":         "Bytes32",
+	"BLSPubkey":      "Bytes48",
+	"BLSSignature":   "Bytes96",
+}
+
+var bytesN = regexp.MustCompile(`^Bytes([0-9]+)$`)
+
+// Field is a single field of a container schema.
+type Field struct {
+	Name string `json:"name"`
+	Type string `json:"type"`
+}
+
+// Schema holds the ordered fields of spec containers, keyed by container name.
+type Schema struct {
+	Constants  map[string]uint64  `json:"constants"`
+	Aliases    map[string]string  `json:"aliases"`
+	Containers map[string][]Field `json:"containers"`
+}
+
+// Parse reads a schema in YAML or JSON format.
+func Parse(data []byte) (*Schema, error) {
+	schema := &Schema{}
+	if err := yaml.Unmarshal(data, schema); err != nil {
+		return nil, errors.Wrap(err, "could not parse schema")
+	}
+	return schema, nil
+}
+
+// MismatchError lists every difference found between Go types and a schema.
+type MismatchError struct {
+	Mismatches []string
+}
+
+func (m *MismatchError) Error() string {
+	return fmt.Sprintf("%d mismatches against spec schema:\n  %s", len(m.Mismatches), strings.Join(m.Mismatches, "\n  "))
+}
+
+// Check compares the struct type typ against the schema container of the same name,
+// as well as every nested struct type which also has a container in the schema.
+// It returns a *MismatchError listing all differences in field count, order, names
+// and types.
+func Check(typ reflect.Type, schema *Schema) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return CheckAs(typ, typ.Name(), schema)
+}
+
+// CheckAs is like Check, but compares typ against the named schema container, for Go
+// types whose name differs from the spec.
+func CheckAs(typ reflect.Type, container string, schema *Schema) error {
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	c := &checker{schema: schema, visited: make(map[reflect.Type]bool)}
+	c.checkContainer(typ, container)
+	if len(c.mismatches) > 0 {
+		return &MismatchError{Mismatches: c.mismatches}
+	}
+	return nil
+}
+
+type checker struct {
+	schema     *Schema
+	visited    map[reflect.Type]bool
+	mismatches []string
+}
+
+func (c *checker) reportf(format string, args ...interface{}) {
+	c.mismatches = append(c.mismatches, fmt.Sprintf(format, args...))
+}
+
+func (c *checker) checkContainer(typ reflect.Type, name string) {
+	if c.visited[typ] {
+		return
+	}
+	c.visited[typ] = true
+	if typ.Kind() != reflect.Struct {
+		c.reportf("%v: expected a struct for container %s", typ, name)
+		return
+	}
+	fields, ok := c.schema.Containers[name]
+	if !ok {
+		c.reportf("%v: no container %s in schema", typ, name)
+		return
+	}
+	goFields := make([]reflect.StructField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(typ.Field(i)) {
+			continue
+		}
+		goFields = append(goFields, typ.Field(i))
+	}
+	if len(goFields) != len(fields) {
+		c.reportf("%s: Go type %v has %d fields, spec has %d", name, typ, len(goFields), len(fields))
+	}
+	for i := 0; i < len(goFields) && i < len(fields); i++ {
+		goField, specField := goFields[i], fields[i]
+		if normalizeName(goField.Name) != normalizeName(specField.Name) {
+			c.reportf("%s: field %d is %s in Go but %s in spec", name, i, goField.Name, specField.Name)
+			continue
+		}
+		fType, err := types.FieldType(goField)
+		if err != nil {
+			c.reportf("%s.%s: %v", name, goField.Name, err)
+			continue
+		}
+		goCanon := canonicalGoType(goField.Type, fType, types.FieldCapacities(goField))
+		specCanon := c.canonicalSpecType(specField.Type)
+		if !typesMatch(goCanon, specCanon) {
+			c.reportf("%s.%s: Go type %s does not match spec type %s (%s)", name, goField.Name, goCanon, specCanon, specField.Type)
+			continue
+		}
+		c.checkNested(fType)
+	}
+}
+
+// checkNested checks the struct types nested in a field against the schema, if present.
+func (c *checker) checkNested(typ reflect.Type) {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		c.checkNested(typ.Elem())
+	case reflect.Struct:
+		if _, ok := c.schema.Containers[typ.Name()]; ok {
+			c.checkContainer(typ, typ.Name())
+		}
+	}
+}
+
+func normalizeName(name string) string {
+	return strings.ToLower(strings.Replace(name, "_", "", -1))
+}
+
+// canonicalGoType renders the SSZ type of a Go field in the canonical form used for
+// comparison, such as List[Vector[uint8,32],1024]. Limits which cannot be known from
+// the Go type alone are rendered as a question mark.
+func canonicalGoType(declared reflect.Type, typ reflect.Type, capacities []uint64) string {
+	limit := "?"
+	if len(capacities) > 0 && capacities[0] > 0 {
+		limit = strconv.FormatUint(capacities[0], 10)
+	}
+	// The following limits of an ssz-max tag bound the lists nested in the elements.
+	var elemCapacities []uint64
+	if len(capacities) > 1 {
+		elemCapacities = capacities[1:]
+	}
+	if declared == reflect.TypeOf(bitfield.Bitlist{}) {
+		return "Bitlist[" + limit + "]"
+	}
+	if declared.PkgPath() == reflect.TypeOf(bitfield.Bitlist{}).PkgPath() && strings.HasPrefix(declared.Name(), "Bitvector") {
+		return "Bitvector[" + strings.TrimPrefix(declared.Name(), "Bitvector") + "]"
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return canonicalGoType(declared.Elem(), typ.Elem(), capacities)
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return typ.Kind().String()
+	case reflect.Array:
+		return fmt.Sprintf("Vector[%s,%d]", canonicalGoType(typ.Elem(), typ.Elem(), elemCapacities), typ.Len())
+	case reflect.Slice:
+		return fmt.Sprintf("List[%s,%s]", canonicalGoType(typ.Elem(), typ.Elem(), elemCapacities), limit)
+	case reflect.String:
+		return "List[uint8," + limit + "]"
+	case reflect.Struct:
+		return typ.Name()
+	default:
+		return typ.Kind().String()
+	}
+}
+
+// canonicalSpecType renders a pyspec type expression in canonical form, resolving
+// aliases and constants.
+func (c *checker) canonicalSpecType(expr string) string {
+	expr = strings.Replace(expr, " ", "", -1)
+	for i := 0; i < 8; i++ {
+		alias, ok := c.schema.Aliases[expr]
+		if !ok {
+			alias, ok = DefaultAliases[expr]
+		}
+		if !ok {
+			break
+		}
+		expr = alias
+	}
+	name, args := splitTypeExpr(expr)
+	if m := bytesN.FindStringSubmatch(name); m != nil && len(args) == 0 {
+		return "Vector[uint8," + m[1] + "]"
+	}
+	switch {
+	case name == "boolean" || name == "bool" || name == "bit":
+		return "boolean"
+	case name == "byte":
+		return "uint8"
+	case name == "ByteVector" && len(args) == 1:
+		return "Vector[uint8," + c.resolveLimit(args[0]) + "]"
+	case name == "ByteList" && len(args) == 1:
+		return "List[uint8," + c.resolveLimit(args[0]) + "]"
+	case (name == "Vector" || name == "List") && len(args) == 2:
+		return name + "[" + c.canonicalSpecType(args[0]) + "," + c.resolveLimit(args[1]) + "]"
+	case (name == "Bitlist" || name == "Bitvector") && len(args) == 1:
+		return name + "[" + c.resolveLimit(args[0]) + "]"
+	}
+	return expr
+}
+
+// resolveLimit evaluates a limit made of integers, constants and powers of two.
+func (c *checker) resolveLimit(expr string) string {
+	if v, ok := c.evalLimit(expr); ok {
+		return strconv.FormatUint(v, 10)
+	}
+	return expr
+}
+
+func (c *checker) evalLimit(expr string) (uint64, bool) {
+	if parts := strings.SplitN(expr, "*", 2); len(parts) == 2 {
+		a, ok := c.evalLimit(parts[0])
+		if !ok {
+			return 0, false
+		}
+		if strings.HasPrefix(parts[1], "*") {
+			b, ok := c.evalLimit(parts[1][1:])
+			if !ok || b > 63 || a != 2 {
+				return 0, false
+			}
+			return uint64(1) << b, true
+		}
+		b, ok := c.evalLimit(parts[1])
+		return a * b, ok
+	}
+	if v, err := strconv.ParseUint(expr, 10, 64); err == nil {
+		return v, true
+	}
+	v, ok := c.schema.Constants[expr]
+	return v, ok
+}
+
+// splitTypeExpr splits an expression such as List[Vector[uint8,32],1024] into its
+// name and top-level arguments.
+func splitTypeExpr(expr string) (string, []string) {
+	open := strings.Index(expr, "[")
+	if open < 0 || !strings.HasSuffix(expr, "]") {
+		return expr, nil
+	}
+	name := expr[:open]
+	inner := expr[open+1 : len(expr)-1]
+	args := make([]string, 0, 2)
+	depth, start := 0, 0
+	for i, ch := range inner {
+		switch ch {
+		case '[':
+			depth++
+		case ']':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, inner[start:i])
+				start = i + 1
+			}
+		}
+	}
+	return name, append(args, inner[start:])
+}
+
+// typesMatch compares canonical types, where a question mark on the Go side matches
+// any limit on the spec side.
+func typesMatch(goCanon string, specCanon string) bool {
+	split := func(r rune) bool { return r == '[' || r == ']' || r == ',' }
+	goTokens := strings.FieldsFunc(goCanon, split)
+	specTokens := strings.FieldsFunc(specCanon, split)
+	if len(goTokens) != len(specTokens) {
+		return false
+	}
+	for i := range goTokens {
+		if goTokens[i] != specTokens[i] && goTokens[i] != "?" {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,88 @@
+package speccheck
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+var testSchema = []byte(`{
+	"constants": {"MAX_VALIDATORS_PER_COMMITTEE": 2048, "SLOTS_PER_HISTORICAL_ROOT": 8192},
+	"containers": {
+		"Checkpoint": [
+			{"name": "epoch", "type": "Epoch"},
+			{"name": "root", "type": "Root"}
+		],
+		"AttestationData": [
+			{"name": "slot", "type": "Slot"},
+			{"name": "source", "type": "Checkpoint"},
+			{"name": "target", "type": "Checkpoint"}
+		],
+		"PendingAttestation": [
+			{"name": "aggregation_bits", "type": "Bitlist[MAX_VALIDATORS_PER_COMMITTEE]"},
+			{"name": "data", "type": "AttestationData"},
+			{"name": "block_roots", "type": "Vector[Root, SLOTS_PER_HISTORICAL_ROOT]"},
+			{"name": "balances", "type": "List[Gwei, 2**40]"}
+		]
+	}
+}`)
+
+type Checkpoint struct {
+	Epoch uint64
+	Root  []byte `ssz-size:"32"`
+}
+
+type AttestationData struct {
+	Slot   uint64
+	Source *Checkpoint
+	Target *Checkpoint
+}
+
+type PendingAttestation struct {
+	AggregationBits bitfield.Bitlist `ssz-max:"2048"`
+	Data            *AttestationData
+	BlockRoots      [][]byte `ssz-size:"8192,32"`
+	Balances        []uint64 `ssz-max:"1099511627776"`
+}
+
+type reorderedCheckpoint struct {
+	Root  [32]byte
+	Epoch uint64
+}
+
+type AttestationDataWrongType struct {
+	Slot   uint32
+	Source Checkpoint
+	Target reorderedCheckpoint
+}
+
+func TestCheck_Matches(t *testing.T) {
+	schema, err := Parse(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Check(reflect.TypeOf(&PendingAttestation{}), schema); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestCheck_ReportsDrift(t *testing.T) {
+	schema, err := Parse(testSchema)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = CheckAs(reflect.TypeOf(reorderedCheckpoint{}), "Checkpoint", schema)
+	if err == nil || !strings.Contains(err.Error(), "field 0 is Root in Go but epoch in spec") {
+		t.Errorf("Expected field order mismatch, received %v", err)
+	}
+	err = CheckAs(reflect.TypeOf(AttestationDataWrongType{}), "AttestationData", schema)
+	mismatchErr, ok := err.(*MismatchError)
+	if !ok {
+		t.Fatalf("Expected *MismatchError, received %v", err)
+	}
+	if len(mismatchErr.Mismatches) != 2 {
+		t.Errorf("Wanted 2 mismatches, received %v", mismatchErr.Mismatches)
+	}
+}
@@ -0,0 +1,250 @@
+//go:build spectests
+// +build spectests
+
+package spectests
+
+import (
+	"bytes"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io/ioutil"
+	"math"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ghodss/yaml"
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/sszvec"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+var specTestsDir = flag.String("spectests.dir", os.Getenv("SPEC_TESTS_DIR"),
+	"tests directory of an unpacked consensus-spec-tests release")
+
+// TestSSZGenericDir runs both the valid and the invalid cases of the ssz_generic tests
+// of a consensus-spec-tests release unpacked outside of Bazel:
+//
+//	go test -tags spectests ./spectests -run SSZGenericDir -spectests.dir=consensus-spec-tests/tests
+func TestSSZGenericDir(t *testing.T) {
+	if *specTestsDir == "" {
+		t.Skip("set -spectests.dir or SPEC_TESTS_DIR to run the consensus spec tests")
+	}
+	dir := filepath.Join(*specTestsDir, "general", "phase0", "ssz_generic")
+	handlers, err := ioutil.ReadDir(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, handler := range handlers {
+		handler := handler.Name()
+		t.Run(handler, func(t *testing.T) {
+			for _, validity := range []string{"valid", "invalid"} {
+				validity := validity
+				t.Run(validity, func(t *testing.T) {
+					cases, err := ioutil.ReadDir(filepath.Join(dir, handler, validity))
+					if os.IsNotExist(err) {
+						t.Skip("no cases")
+					}
+					if err != nil {
+						t.Fatal(err)
+					}
+					for _, cs := range cases {
+						name := cs.Name()
+						t.Run(name, func(t *testing.T) {
+							typ, err := genericCaseType(handler, name)
+							if err != nil {
+								t.Skip(err)
+							}
+							caseDir := filepath.Join(dir, handler, validity, name)
+							if validity == "valid" {
+								checkValidGenericCase(t, caseDir, typ)
+							} else {
+								checkInvalidGenericCase(t, caseDir, typ)
+							}
+						})
+					}
+				})
+			}
+		})
+	}
+}
+
+// genericType is the Go type that the cases of ssz_generic are decoded as. The lengths
+// of bitlists and bitvectors are part of the name of a case rather than of a Go type.
+type genericType struct {
+	typ reflect.Type
+	// bitlistLimit is the maximum number of bits of a bitlist.
+	bitlistLimit uint64
+	// bitvector is set for bitvectors, decoded as byte vectors of bitvectorLength bits.
+	bitvector       bool
+	bitvectorLength uint64
+}
+
+func (g *genericType) isBitlist() bool {
+	return g.typ == reflect.TypeOf(bitfield.Bitlist{})
+}
+
+func (g *genericType) decode(data []byte) (interface{}, error) {
+	if g.bitvector && g.bitvectorLength == 0 {
+		return nil, fmt.Errorf("bitvectors must have at least one bit")
+	}
+	val := reflect.New(g.typ)
+	if err := ssz.Unmarshal(data, val.Interface()); err != nil {
+		return nil, err
+	}
+	if g.isBitlist() {
+		if n := val.Elem().Interface().(bitfield.Bitlist).Len(); n > g.bitlistLimit {
+			return nil, fmt.Errorf("bitlist of %d bits exceeds its limit of %d", n, g.bitlistLimit)
+		}
+	}
+	if r := g.bitvectorLength % 8; r != 0 && len(data) > 0 && data[len(data)-1]>>r != 0 {
+		return nil, fmt.Errorf("bitvector of %d bits has padding bits set", g.bitvectorLength)
+	}
+	return val.Interface(), nil
+}
+
+func (g *genericType) root(val interface{}) ([32]byte, error) {
+	if g.isBitlist() {
+		return ssz.HashTreeRootBitfield(*val.(*bitfield.Bitlist), g.bitlistLimit)
+	}
+	return ssz.HashTreeRoot(reflect.ValueOf(val).Elem().Interface())
+}
+
+// genericCaseType returns the type of a case from its handler and name, such as
+// vec_uint16_5_max of the basic_vector handler.
+func genericCaseType(handler string, name string) (*genericType, error) {
+	parts := strings.Split(name, "_")
+	switch {
+	case handler == "boolean":
+		return &genericType{typ: reflect.TypeOf(false)}, nil
+	case handler == "uints" && len(parts) > 1:
+		typ, err := genericBasicType("uint" + parts[1])
+		if err != nil {
+			return nil, err
+		}
+		return &genericType{typ: typ}, nil
+	case handler == "basic_vector" && len(parts) > 2:
+		elem, err := genericBasicType(parts[1])
+		if err != nil {
+			return nil, err
+		}
+		n, err := strconv.Atoi(parts[2])
+		if err != nil {
+			return nil, err
+		}
+		return &genericType{typ: reflect.ArrayOf(n, elem)}, nil
+	case handler == "bitvector" && len(parts) > 1:
+		n, err := strconv.ParseUint(parts[1], 10, 32)
+		if err != nil {
+			return nil, err
+		}
+		return &genericType{typ: reflect.ArrayOf(int(n+7)/8, reflect.TypeOf(byte(0))), bitvector: true, bitvectorLength: n}, nil
+	case handler == "bitlist" && len(parts) > 1:
+		// Cases such as bitlist_no_delimiter_empty are invalid for any limit.
+		limit := uint64(math.MaxUint64)
+		if parts[1] != "no" {
+			n, err := strconv.ParseUint(parts[1], 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			limit = n
+		}
+		return &genericType{typ: reflect.TypeOf(bitfield.Bitlist{}), bitlistLimit: limit}, nil
+	case handler == "containers":
+		switch parts[0] {
+		case "SingleFieldTestStruct":
+			return &genericType{typ: reflect.TypeOf(singleFieldStruct{})}, nil
+		case "SmallTestStruct":
+			return &genericType{typ: reflect.TypeOf(smallTestStruct{})}, nil
+		case "FixedTestStruct":
+			return &genericType{typ: reflect.TypeOf(fixedTestStruct{})}, nil
+		case "VarTestStruct":
+			return &genericType{typ: reflect.TypeOf(varTestStruct{})}, nil
+		case "ComplexTestStruct":
+			return &genericType{typ: reflect.TypeOf(complexTestStruct{})}, nil
+		}
+	}
+	return nil, fmt.Errorf("unsupported %s case %s", handler, name)
+}
+
+func genericBasicType(name string) (reflect.Type, error) {
+	switch name {
+	case "bool":
+		return reflect.TypeOf(false), nil
+	case "uint8":
+		return reflect.TypeOf(uint8(0)), nil
+	case "uint16":
+		return reflect.TypeOf(uint16(0)), nil
+	case "uint32":
+		return reflect.TypeOf(uint32(0)), nil
+	case "uint64":
+		return reflect.TypeOf(uint64(0)), nil
+	case "uint128":
+		return reflect.TypeOf(types.Uint128{}), nil
+	case "uint256":
+		return reflect.TypeOf(types.Uint256{}), nil
+	default:
+		return nil, fmt.Errorf("unsupported basic type %s", name)
+	}
+}
+
+func checkValidGenericCase(t *testing.T, dir string, typ *genericType) {
+	serialized, err := sszvec.ReadSerialized(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val, err := typ.decode(serialized)
+	if err != nil {
+		t.Fatalf("Could not decode valid case: %v", err)
+	}
+	encoded, err := ssz.Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(encoded, serialized) {
+		t.Errorf("Expected encoding %#x, received %#x", serialized, encoded)
+	}
+	if value, err := ioutil.ReadFile(filepath.Join(dir, "value.yaml")); err == nil {
+		want := reflect.New(typ.typ).Interface()
+		if err := sszvec.Unmarshal(value, want); err != nil {
+			t.Fatal(err)
+		}
+		if !ssz.DeepEqual(val, want) {
+			t.Errorf("Decoded %v, expected %v", val, want)
+		}
+	}
+	meta, err := ioutil.ReadFile(filepath.Join(dir, "meta.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var roots sszRoots
+	if err := yaml.Unmarshal(meta, &roots); err != nil {
+		t.Fatal(err)
+	}
+	want, err := hex.DecodeString(strings.TrimPrefix(roots.Root, "0x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := typ.root(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(root[:], want) {
+		t.Errorf("Expected root %#x, received %#x", want, root)
+	}
+}
+
+func checkInvalidGenericCase(t *testing.T, dir string, typ *genericType) {
+	serialized, err := sszvec.ReadSerialized(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if val, err := typ.decode(serialized); err == nil {
+		t.Errorf("Expected invalid case %#x to fail decoding, received %v", serialized, val)
+	}
+}
@@ -6,32 +6,32 @@ type sszRoots struct {
 }
 
 type singleFieldStruct struct {
-	A byte
+	A byte `json:"A"`
 }
 
 type smallTestStruct struct {
-	A uint16
-	B uint16
+	A uint16 `json:"A"`
+	B uint16 `json:"B"`
 }
 
 type fixedTestStruct struct {
-	A uint8
-	B uint64
-	C uint32
+	A uint8  `json:"A"`
+	B uint64 `json:"B"`
+	C uint32 `json:"C"`
 }
 
 type varTestStruct struct {
-	A uint16
-	B []uint16 `ssz-max:"1024"`
-	C uint8
+	A uint16   `json:"A"`
+	B []uint16 `ssz-max:"1024" json:"B"`
+	C uint8    `json:"C"`
 }
 
 type complexTestStruct struct {
-	A uint16
-	B []uint16 `ssz-max:"128"`
-	C uint8
-	D []byte `ssz-max:"256"`
-	E varTestStruct
-	F []fixedTestStruct `ssz-size:"4"`
-	G []varTestStruct   `ssz-size:"2"`
+	A uint16            `json:"A"`
+	B []uint16          `ssz-max:"128" json:"B"`
+	C uint8             `json:"C"`
+	D []byte            `ssz-max:"256" json:"D"`
+	E varTestStruct     `json:"E"`
+	F []fixedTestStruct `ssz-size:"4" json:"F"`
+	G []varTestStruct   `ssz-size:"2" json:"G"`
 }
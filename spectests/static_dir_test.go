@@ -0,0 +1,136 @@
+// +build spectests
+
+package spectests
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/prysmaticlabs/go-ssz/sszvec"
+)
+
+// staticTypes maps the presets, forks and type names of the ssz_static tests to values
+// of the Go types that their cases are decoded as. Cases of other types are skipped.
+var staticTypes = map[string]map[string]map[string]interface{}{
+	"mainnet": {
+		"phase0": {
+			"AggregateAndProof":            mainnetAggregateAndProof{},
+			"Attestation":                  mainnetAttestation{},
+			"AttestationData":              mainnetAttestationData{},
+			"AttestationDataAndCustodyBit": mainnetAttestationAndCustodyBit{},
+			"AttesterSlashing":             mainnetAttesterSlashing{},
+			"BeaconBlock":                  mainnetBlock{},
+			"BeaconBlockBody":              mainnetBlockBody{},
+			"BeaconBlockHeader":            MainnetBlockHeader{},
+			"BeaconState":                  mainnetBeaconState{},
+			"Checkpoint":                   mainnetCheckpoint{},
+			"Deposit":                      mainnetDeposit{},
+			"DepositData":                  mainnetDepositData{},
+			"Eth1Data":                     mainnetEth1Data{},
+			"Fork":                         mainnetFork{},
+			"HistoricalBatch":              mainnetHistoricalBatch{},
+			"IndexedAttestation":           mainnetIndexedAttestation{},
+			"PendingAttestation":           mainnetPendingAttestation{},
+			"ProposerSlashing":             mainnetProposerSlashing{},
+			"Validator":                    mainnetValidator{},
+			"VoluntaryExit":                mainnetVoluntaryExit{},
+		},
+	},
+	"minimal": {
+		"phase0": {
+			"AggregateAndProof":            minimalAggregateAndProof{},
+			"Attestation":                  minimalAttestation{},
+			"AttestationData":              minimalAttestationData{},
+			"AttestationDataAndCustodyBit": minimalAttestationAndCustodyBit{},
+			"AttesterSlashing":             minimalAttesterSlashing{},
+			"BeaconBlock":                  minimalBlock{},
+			"BeaconBlockBody":              minimalBlockBody{},
+			"BeaconBlockHeader":            minimalBlockHeader{},
+			"BeaconState":                  minimalBeaconState{},
+			"Checkpoint":                   minimalCheckpoint{},
+			"Deposit":                      minimalDeposit{},
+			"DepositData":                  minimalDepositData{},
+			"Eth1Data":                     minimalEth1Data{},
+			"Fork":                         minimalFork{},
+			"HistoricalBatch":              minimalHistoricalBatch{},
+			"IndexedAttestation":           minimalIndexedAttestation{},
+			"PendingAttestation":           minimalPendingAttestation{},
+			"ProposerSlashing":             minimalProposerSlashing{},
+			"Validator":                    minimalValidator{},
+			"VoluntaryExit":                minimalVoluntaryExit{},
+		},
+	},
+}
+
+// TestSSZStaticDir runs the ssz_static tests of every preset and fork of a
+// consensus-spec-tests release unpacked outside of Bazel, checking that the value of
+// each case round-trips through its serialization and has the root of the case:
+//
+//  go test -tags spectests ./spectests -run SSZStaticDir -spectests.dir=consensus-spec-tests/tests
+func TestSSZStaticDir(t *testing.T) {
+	if *specTestsDir == "" {
+		t.Skip("set -spectests.dir or SPEC_TESTS_DIR to run the consensus spec tests")
+	}
+	for _, preset := range []string{"mainnet", "minimal"} {
+		preset := preset
+		t.Run(preset, func(t *testing.T) {
+			forks, err := ioutil.ReadDir(filepath.Join(*specTestsDir, preset))
+			if os.IsNotExist(err) {
+				t.Skip("no tests for preset")
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, fork := range forks {
+				fork := fork.Name()
+				t.Run(fork, func(t *testing.T) {
+					runStaticFork(t, filepath.Join(*specTestsDir, preset, fork, "ssz_static"), staticTypes[preset][fork])
+				})
+			}
+		})
+	}
+}
+
+func runStaticFork(t *testing.T, dir string, protos map[string]interface{}) {
+	typeNames, err := ioutil.ReadDir(dir)
+	if os.IsNotExist(err) {
+		t.Skip("no ssz_static tests")
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, typeName := range typeNames {
+		typeName := typeName.Name()
+		t.Run(typeName, func(t *testing.T) {
+			proto, ok := protos[typeName]
+			if !ok {
+				t.Skip("type not covered")
+			}
+			suites, err := ioutil.ReadDir(filepath.Join(dir, typeName))
+			if err != nil {
+				t.Fatal(err)
+			}
+			for _, suite := range suites {
+				cases, err := ioutil.ReadDir(filepath.Join(dir, typeName, suite.Name()))
+				if err != nil {
+					t.Fatal(err)
+				}
+				for _, cs := range cases {
+					caseDir := filepath.Join(dir, typeName, suite.Name(), cs.Name())
+					t.Run(suite.Name()+"/"+cs.Name(), func(t *testing.T) {
+						loaded, err := sszvec.LoadCase(caseDir, reflect.New(reflect.TypeOf(proto)).Interface())
+						if err != nil {
+							t.Fatal(err)
+						}
+						if err := loaded.Check(); err != nil {
+							t.Error(err)
+						}
+					})
+				}
+			}
+		})
+	}
+}
@@ -1,9 +1,10 @@
 package ssz
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"reflect"
-	"strings"
 
 	"github.com/pkg/errors"
 	"github.com/prysmaticlabs/go-bitfield"
@@ -43,11 +44,28 @@ import (
 //  }
 //
 // This will treat `Field2` as type [][32]byte when marshaling a
-// struct of that type.
-func Marshal(val interface{}) ([]byte, error) {
+// struct of that type. Fields tagged `ssz:"-"` are left out of the encoding and
+// the root. Types with fastssz generated methods are encoded by their MarshalSSZTo
+// method instead. Options, such as WithStats, only apply to the current call.
+func Marshal(val interface{}, opts ...Option) ([]byte, error) {
+	defer newCallConfig(opts).collect()()
+	end := startSpan("marshal", reflect.TypeOf(val))
+	buf, err := marshal(val)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+	reportEncoded(uint64(len(buf)))
+	return buf, nil
+}
+
+func marshal(val interface{}) ([]byte, error) {
 	if val == nil {
 		return nil, errors.New("untyped-value nil cannot be marshaled")
 	}
+	if buf, ok, err := fastsszMarshal(val); ok {
+		return buf, err
+	}
 	rval := reflect.ValueOf(val)
 
 	// We pre-allocate a buffer-size depending on the value's calculated total byte size.
@@ -58,6 +76,7 @@ func Marshal(val interface{}) ([]byte, error) {
 	}
 	if rval.Type().Kind() == reflect.Ptr {
 		if rval.IsNil() {
+			types.ReportNilSubstitution("marshal", rval.Type().String(), rval.Type())
 			return buf, nil
 		}
 		if _, err := factory.Marshal(rval.Elem(), rval.Type().Elem(), buf, 0 /* start offset */); err != nil {
@@ -83,13 +102,34 @@ func Marshal(val interface{}) ([]byte, error) {
 //  if err := Unmarshal(encodedBytes, &targetStruct); err != nil {
 //      return fmt.Errorf("failed to unmarshal: %v", err)
 //  }
-func Unmarshal(input []byte, val interface{}) error {
+//
+// Types with fastssz generated methods are decoded by their UnmarshalSSZ method. Options,
+// such as WithMaxSize, only apply to the current call.
+func Unmarshal(input []byte, val interface{}, opts ...DecodeOption) error {
+	c := &decodeConfig{maxSize: math.MaxUint64}
+	for _, opt := range opts {
+		opt(c)
+	}
+	end := startSpan("unmarshal", reflect.TypeOf(val))
+	err := unmarshal(input, val, c)
+	end(err)
+	if err != nil {
+		return err
+	}
+	reportDecoded(uint64(len(input)))
+	return nil
+}
+
+func unmarshal(input []byte, val interface{}, c *decodeConfig) error {
 	if val == nil {
 		return errors.New("cannot unmarshal into untyped, nil value")
 	}
 	if len(input) == 0 {
 		return errors.New("no data to unmarshal from, input is an empty byte slice []byte{}")
 	}
+	if uint64(len(input)) > c.maxSize {
+		return fmt.Errorf("input of %d bytes exceeds the maximum of %d bytes", len(input), c.maxSize)
+	}
 	rval := reflect.ValueOf(val)
 	rtyp := rval.Type()
 	// val must be a pointer, otherwise we refuse to unmarshal
@@ -99,10 +139,22 @@ func Unmarshal(input []byte, val interface{}) error {
 	if rval.IsNil() {
 		return errors.New("cannot output to pointer of nil value")
 	}
+	if !c.strictOffsets {
+		if ok, err := fastsszUnmarshal(input, val); ok {
+			return err
+		}
+	}
+	if err := types.CheckDepth(rtyp.Elem()); err != nil {
+		return errors.Wrapf(err, "could not unmarshal input into type: %v", rtyp.Elem())
+	}
 	factory, err := types.SSZFactory(rval.Elem(), rtyp.Elem())
 	if err != nil {
 		return err
 	}
+	if !c.zeroCopy {
+		// Byte slices of the decoded value point into the input it is decoded from.
+		input = append([]byte{}, input...)
+	}
 	if _, err := factory.Unmarshal(rval.Elem(), rval.Elem().Type(), input, 0); err != nil {
 		return errors.Wrapf(err, "could not unmarshal input into type: %v", rval.Elem().Type())
 	}
@@ -119,6 +171,28 @@ func Unmarshal(input []byte, val interface{}) error {
 	return nil
 }
 
+// Size returns the exact number of bytes Marshal outputs for a value, computed without
+// encoding it, such as to pre-allocate a frame or to enforce a message size limit before
+// serializing:
+//
+//  size, err := Size(block)
+//  if err != nil {
+//      return errors.Wrap(err, "failed to compute size")
+//  }
+//  if size > maxChunkSize {
+//      return fmt.Errorf("block of %d bytes exceeds the limit", size)
+//  }
+func Size(val interface{}) (uint64, error) {
+	if val == nil {
+		return 0, errors.New("untyped nil is not supported")
+	}
+	rval := reflect.ValueOf(val)
+	if err := types.CheckType(rval.Type()); err != nil {
+		return 0, errors.Wrapf(err, "could not determine size for type: %v", rval.Type())
+	}
+	return types.DetermineSize(rval), nil
+}
+
 // HashTreeRoot determines the root hash using SSZ's Merkleization.
 // Given a struct with the following fields, one can tree hash it as follows:
 //  type exampleStruct struct {
@@ -134,11 +208,46 @@ func Unmarshal(input []byte, val interface{}) error {
 //  if err != nil {
 //      return errors.Wrap(err, "failed to compute root")
 //  }
-func HashTreeRoot(val interface{}) ([32]byte, error) {
+//
+// Types with fastssz generated methods are hashed by their HashTreeRoot method. Options,
+// such as WithStats, only apply to the current call.
+func HashTreeRoot(val interface{}, opts ...Option) ([32]byte, error) {
+	c := newCallConfig(opts)
+	defer c.collect()()
+	end := startSpan("hash_tree_root", reflect.TypeOf(val))
+	var root [32]byte
+	var err error
+	if c.noCache {
+		// Hashers never use the caches.
+		root, err = hashTreeRootWith(val, &Hasher{})
+	} else {
+		root, err = hashTreeRoot(val)
+	}
+	end(err)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	reportRoots(1)
+	return root, nil
+}
+
+func hashTreeRoot(val interface{}) ([32]byte, error) {
 	if val == nil {
 		return [32]byte{}, errors.New("untyped nil is not supported")
 	}
+	if root, ok, err := fastsszRoot(val); ok {
+		return root, err
+	}
 	rval := reflect.ValueOf(val)
+	if rval.Kind() == reflect.Ptr && rval.IsNil() {
+		types.ReportNilSubstitution("root", rval.Type().String(), rval.Type())
+	}
+	if err := types.CheckDepth(rval.Type()); err != nil {
+		return [32]byte{}, errors.Wrapf(err, "could not compute root for type: %v", rval.Type())
+	}
+	if root, ok, err := tracedRoot(val); ok {
+		return root, err
+	}
 	factory, err := types.SSZFactory(rval, rval.Type())
 	if err != nil {
 		return [32]byte{}, errors.Wrapf(err, "could not generate tree hasher for type: %v", rval.Type())
@@ -146,17 +255,174 @@ func HashTreeRoot(val interface{}) ([32]byte, error) {
 	return factory.Root(rval, rval.Type(), "", 0)
 }
 
+// Hasher holds scratch buffers which are reused across hash tree root computations.
+// The zero value is ready to use, and a Hasher must not be used concurrently.
+type Hasher = types.Hasher
+
+// HashTreeRootWith determines the root hash using SSZ's Merkleization, like HashTreeRoot,
+// reusing the buffers of h rather than allocating chunks on every call. It is meant for
+// hashing many objects in a loop:
+//
+//  h := &ssz.Hasher{}
+//  for _, block := range blocks {
+//      root, err := ssz.HashTreeRootWith(block, h)
+//      if err != nil {
+//          return errors.Wrap(err, "failed to compute root")
+//      }
+//      roots = append(roots, root)
+//  }
+func HashTreeRootWith(val interface{}, h *Hasher) ([32]byte, error) {
+	end := startSpan("hash_tree_root", reflect.TypeOf(val))
+	root, err := hashTreeRootWith(val, h)
+	end(err)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	reportRoots(1)
+	return root, nil
+}
+
+// CompileHasher compiles the plans a Hasher follows to hash the fields of the type of
+// val, and of every container type it holds, which are otherwise compiled the first time
+// a value of each type is hashed. Services can compile the plans of the types they hash
+// at startup, such as beacon states and blocks, to catch invalid tags up front:
+//
+//  if err := ssz.CompileHasher(&pb.BeaconState{}); err != nil {
+//      return errors.Wrap(err, "invalid beacon state type")
+//  }
+func CompileHasher(val interface{}) error {
+	if val == nil {
+		return errors.New("untyped nil is not supported")
+	}
+	typ := reflect.TypeOf(val)
+	if err := types.CompileRootPlans(typ); err != nil {
+		return errors.Wrapf(err, "could not compile hasher for type: %v", typ)
+	}
+	return nil
+}
+
+func hashTreeRootWith(val interface{}, h *Hasher) ([32]byte, error) {
+	if val == nil {
+		return [32]byte{}, errors.New("untyped nil is not supported")
+	}
+	if h == nil {
+		return [32]byte{}, errors.New("nil hasher")
+	}
+	if root, ok, err := fastsszRoot(val); ok {
+		return root, err
+	}
+	rval := reflect.ValueOf(val)
+	if err := types.CheckDepth(rval.Type()); err != nil {
+		return [32]byte{}, errors.Wrapf(err, "could not compute root for type: %v", rval.Type())
+	}
+	root, err := h.Root(rval, rval.Type(), 0)
+	if err != nil {
+		return [32]byte{}, errors.Wrapf(err, "could not compute root for type: %v", rval.Type())
+	}
+	return root, nil
+}
+
+// HashTreeRootCtx determines the root hash using SSZ's Merkleization, like HashTreeRoot,
+// checking ctx periodically while hashing large lists, such as a validator registry, so
+// that the computation is abandoned once a request is cancelled or times out:
+//
+//  ctx, cancel := context.WithTimeout(ctx, time.Second)
+//  defer cancel()
+//  root, err := ssz.HashTreeRootCtx(ctx, state)
+//  if errors.Cause(err) == context.DeadlineExceeded {
+//      return status.Error(codes.DeadlineExceeded, "state root took too long")
+//  }
+//
+// The error of the context is returned, and can be retrieved with errors.Cause. Roots are
+// computed by a Hasher, which bypasses the hash tree root caches.
+func HashTreeRootCtx(ctx context.Context, val interface{}) ([32]byte, error) {
+	if ctx == nil {
+		return [32]byte{}, errors.New("nil context")
+	}
+	if err := ctx.Err(); err != nil {
+		return [32]byte{}, err
+	}
+	h := &Hasher{}
+	h.SetContext(ctx)
+	return HashTreeRootWith(val, h)
+}
+
+// HashTreeRootBatch determines the root hashes of many objects, such as the attestations
+// of a block, in one call. It amortizes the setup of a Hasher across them, and hashes the
+// Merkle trees of containers together, level by level, so that a multi-buffer backend
+// set with SetHashBackend is fed with chunk pairs from different objects at once:
+//
+//  roots, err := ssz.HashTreeRootBatch([]interface{}{att1, att2, att3})
+//  if err != nil {
+//      return errors.Wrap(err, "could not compute attestation roots")
+//  }
+//
+// Roots are returned in the order of vals, and are identical to the ones of HashTreeRoot.
+// The hash tree root caches are bypassed.
+func HashTreeRootBatch(vals []interface{}) ([][32]byte, error) {
+	end := startSpan("hash_tree_root_batch", reflect.TypeOf(vals))
+	roots, err := hashTreeRootBatch(vals)
+	end(err)
+	if err != nil {
+		return nil, err
+	}
+	reportRoots(uint64(len(roots)))
+	return roots, nil
+}
+
+func hashTreeRootBatch(vals []interface{}) ([][32]byte, error) {
+	roots := make([][32]byte, len(vals))
+	// Objects implementing fastssz compute their own roots, the others being hashed
+	// together by a Hasher.
+	var rvals []reflect.Value
+	var indices []int
+	for i, val := range vals {
+		if val == nil {
+			return nil, errors.Errorf("untyped nil is not supported, value %d", i)
+		}
+		if root, ok, err := fastsszRoot(val); ok {
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not compute root of value %d", i)
+			}
+			roots[i] = root
+			continue
+		}
+		rval := reflect.ValueOf(val)
+		if err := types.CheckDepth(rval.Type()); err != nil {
+			return nil, errors.Wrapf(err, "could not compute root for type: %v", rval.Type())
+		}
+		rvals = append(rvals, rval)
+		indices = append(indices, i)
+	}
+	if len(rvals) == 0 {
+		return roots, nil
+	}
+	h := &Hasher{}
+	batchRoots, err := h.Roots(rvals)
+	if err != nil {
+		return nil, err
+	}
+	for k, i := range indices {
+		roots[i] = batchRoots[k]
+	}
+	return roots, nil
+}
+
 // HashTreeRootBitfield determines the root hash of a bitfield type using SSZ's Merkleization.
 func HashTreeRootBitfield(bfield bitfield.Bitfield, maxCapacity uint64) ([32]byte, error) {
 	if b, ok := bfield.(bitfield.Bitvector4); ok {
 		return types.Bitvector4Root(b, 4)
 	}
+	if n, ok := types.BitvectorLength(reflect.TypeOf(bfield)); ok {
+		return types.BitvectorRoot(reflect.ValueOf(bfield).Bytes(), n)
+	}
 	return types.BitlistRoot(bfield, maxCapacity)
 }
 
 // HashTreeRootWithCapacity determines the root hash of a dynamic list
 // using SSZ's Merkleization and applies a max capacity value when computing the root.
-// If the input is not a slice, the function returns an error.
+// Bitlists are hashed with a capacity in bits. If the input is not a slice, the
+// function returns an error.
 //
 //  accountBalances := []uint64{1, 2, 3, 4}
 //  root, err := HashTreeRootWithCapacity(accountBalances, 100) // Max 100 accounts.
@@ -167,6 +433,9 @@ func HashTreeRootWithCapacity(val interface{}, maxCapacity uint64) ([32]byte, er
 	if val == nil {
 		return [32]byte{}, errors.New("untyped nil is not supported")
 	}
+	if b, ok := val.(bitfield.Bitlist); ok {
+		return types.BitlistRoot(b, maxCapacity)
+	}
 	rval := reflect.ValueOf(val)
 	if rval.Kind() != reflect.Slice {
 		return [32]byte{}, fmt.Errorf("expected slice-kind input, received %v", rval.Kind())
@@ -178,11 +447,59 @@ func HashTreeRootWithCapacity(val interface{}, maxCapacity uint64) ([32]byte, er
 	return factory.Root(rval, rval.Type(), "", maxCapacity)
 }
 
+// FieldRoots returns the hash tree roots of the fields of a container, in order and
+// skipped fields aside, which are the leaves of the Merkle tree of the container. Two
+// versions of a state can be compared field by field to find out which ones changed:
+//
+//  pre, err := ssz.FieldRoots(preState)
+//  if err != nil {
+//      return err
+//  }
+//  post, err := ssz.FieldRoots(postState)
+//  if err != nil {
+//      return err
+//  }
+//  for i := range pre {
+//      if pre[i] != post[i] {
+//          fmt.Printf("field %d changed\n", i)
+//      }
+//  }
+func FieldRoots(obj interface{}) ([][32]byte, error) {
+	if obj == nil {
+		return nil, errors.New("untyped nil is not supported")
+	}
+	val := reflect.ValueOf(obj)
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			val = reflect.New(val.Type().Elem())
+		}
+		val = val.Elem()
+	}
+	if val.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("expected a container, received %v", val.Type())
+	}
+	typ := val.Type()
+	roots := make([][32]byte, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(typ.Field(i)) {
+			continue
+		}
+		r, err := fieldRoot(val, typ, i)
+		if err != nil {
+			return nil, errors.Wrapf(err, "%s.%s", typ.Name(), typ.Field(i).Name)
+		}
+		roots = append(roots, r)
+	}
+	return roots, nil
+}
+
 // SigningRoot truncates the last property of the struct passed in
 // and returns its tree hash. This is done because the last property
 // usually contains the signature that which this data is the root for.
 //
-// Deprecated: Prefer signed container objects rather than using signing root.
+// Deprecated: Prefer signed container objects rather than using signing root, and
+// ComputeSigningRoot for the root signed in a domain.
 func SigningRoot(val interface{}) ([32]byte, error) {
 	if val == nil {
 		return [32]byte{}, errors.New("value cannot be nil")
@@ -196,8 +513,8 @@ func SigningRoot(val interface{}) ([32]byte, error) {
 		elemType := valObj.Elem().Type()
 		totalFields := 0
 		for i := 0; i < elemType.NumField(); i++ {
-			// We skip protobuf related metadata fields.
-			if strings.Contains(elemType.Field(i).Name, "XXX_") {
+			// We skip protobuf related metadata fields and fields tagged ssz:"-".
+			if types.SkipField(elemType.Field(i)) {
 				continue
 			}
 			totalFields++
@@ -206,11 +523,33 @@ func SigningRoot(val interface{}) ([32]byte, error) {
 	}
 	totalFields := 0
 	for i := 0; i < valObj.Type().NumField(); i++ {
-		// We skip protobuf related metadata fields.
-		if strings.Contains(valObj.Type().Field(i).Name, "XXX_") {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(valObj.Type().Field(i)) {
 			continue
 		}
 		totalFields++
 	}
 	return types.StructFactory.FieldsHasher(valObj, valObj.Type(), totalFields-1)
 }
+
+// PinRoot records root as the hash tree root of the object pointed to by val. Callers
+// which cache roots of mutable objects can pin them so that HashTreeRoot returns the
+// pinned root without hashing, whether the object is hashed on its own or as a field of
+// another object. The pin remains in place, and keeps the object alive, until the object
+// is passed to InvalidateCache.
+func PinRoot(val interface{}, root [32]byte) error {
+	if val == nil {
+		return errors.New("untyped nil is not supported")
+	}
+	return types.PinRoot(reflect.ValueOf(val), root)
+}
+
+// InvalidateCache drops the pinned root of the object pointed to by val, as well as any
+// cached intermediate Merkle layers of its fields. It must be called after mutating
+// an object in place whose root was pinned or previously computed with caching enabled.
+func InvalidateCache(val interface{}) {
+	if val == nil {
+		return
+	}
+	types.InvalidateRoot(reflect.ValueOf(val))
+}
@@ -2,7 +2,12 @@ package ssz
 
 import (
 	"bytes"
+	"context"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
 	"encoding/hex"
+	"math/big"
 	"reflect"
 	"strconv"
 	"sync"
@@ -502,6 +507,48 @@ func TestNilInstantiationMarshalEquality(t *testing.T) {
 	}
 }
 
+func TestNilAuditHook_ReportsSubstitutedFields(t *testing.T) {
+	type exampleBody struct {
+		Epoch uint64
+	}
+	type example struct {
+		Slot  uint64
+		Root  []byte `ssz-size:"32"`
+		Body  *exampleBody
+		Forks []*fork
+	}
+	var reported []types.NilSubstitution
+	types.SetNilAuditHook(func(n types.NilSubstitution) {
+		reported = append(reported, n)
+	})
+	defer types.SetNilAuditHook(nil)
+
+	item := &example{
+		Slot:  5,
+		Forks: []*fork{{}, nil},
+	}
+	if _, err := HashTreeRoot(item); err != nil {
+		t.Fatal(err)
+	}
+	wanted := []string{"example.Root", "example.Body", "example.Forks[1]"}
+	if len(reported) != len(wanted) {
+		t.Fatalf("Wanted %d substitutions, received %v", len(wanted), reported)
+	}
+	for i, path := range wanted {
+		if reported[i].Path != path || reported[i].Operation != "root" {
+			t.Errorf("Wanted root substitution at %s, received %v", path, reported[i])
+		}
+	}
+
+	reported = nil
+	if _, err := Marshal(item); err != nil {
+		t.Fatal(err)
+	}
+	if len(reported) != 2 || reported[0].Path != "example.Root" || reported[1].Path != "example.Body" {
+		t.Errorf("Unexpected marshal substitutions %v", reported)
+	}
+}
+
 func TestEmptyDataUnmarshal(t *testing.T) {
 	msg := &simpleProtoMessage{}
 	if err := Unmarshal([]byte{}, msg); err == nil {
@@ -798,3 +845,1585 @@ func hexDecodeOrDie(t *testing.T, s string) []byte {
 	}
 	return res
 }
+
+// Integers must be serialized little-endian on every platform, including big-endian
+// hosts such as s390x, so we compare against explicit byte layouts rather than values
+// produced with the host byte order.
+func TestMarshal_ExplicitByteOrder(t *testing.T) {
+	tests := []struct {
+		name   string
+		input  interface{}
+		output string
+	}{
+		{name: "uint16", input: uint16(0x0102), output: "0201"},
+		{name: "int32", input: int32(-2), output: "feffffff"},
+		{name: "uint32", input: uint32(0x01020304), output: "04030201"},
+		{name: "uint64", input: uint64(0x0102030405060708), output: "0807060504030201"},
+		{name: "uint16 array", input: [2]uint16{0x0102, 0x0304}, output: "02010403"},
+		{name: "uint32 list", input: []uint32{0x01020304, 0x05060708}, output: "0403020108070605"},
+		{name: "offsets", input: struct {
+			A []byte
+			B uint16
+		}{A: []byte{0xaa}, B: 0x0102}, output: "060000000201aa"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output, err := Marshal(test.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if hex.EncodeToString(output) != test.output {
+				t.Fatalf("Wanted %s, received %#x", test.output, output)
+			}
+			dec := reflect.New(reflect.TypeOf(test.input))
+			if err := Unmarshal(output, dec.Interface()); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(dec.Elem().Interface(), test.input) {
+				t.Errorf("Wanted %v, received %v", test.input, dec.Elem().Interface())
+			}
+		})
+	}
+}
+
+func TestHashTreeRoot_LengthMixInByteOrder(t *testing.T) {
+	root, err := HashTreeRootWithCapacity([]uint64{}, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// hash(zero chunk, uint256(0) little-endian) for an empty list of a single chunk.
+	want := HashPair([32]byte{}, [32]byte{})
+	if root != want {
+		t.Errorf("Wanted %#x, received %#x", want, root)
+	}
+	root, err = HashTreeRootWithCapacity(make([]uint64, 0x0102), 0x0102)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var length [32]byte
+	length[0], length[1] = 0x02, 0x01
+	want = HashPair(ZeroHash(7), length)
+	if root != want {
+		t.Errorf("Wanted %#x, received %#x", want, root)
+	}
+}
+
+func TestSelfTest(t *testing.T) {
+	if err := SelfTest(); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestPinRoot_InvalidateCache(t *testing.T) {
+	type container struct {
+		Slot uint64
+		Fork *fork
+	}
+	f := &fork{Epoch: 5}
+	item := &container{Slot: 1, Fork: f}
+	want, err := HashTreeRoot(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forkRoot, err := HashTreeRoot(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := PinRoot(f, forkRoot); err != nil {
+		t.Fatal(err)
+	}
+	// Mutating the object in place without invalidating keeps the pinned root.
+	f.Epoch = 6
+	got, err := HashTreeRoot(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Expected pinned root to be used, wanted %#x, received %#x", want, got)
+	}
+	InvalidateCache(f)
+	got, err = HashTreeRoot(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got == want {
+		t.Error("Expected root to change after invalidating the mutated object")
+	}
+	if err := PinRoot(fork{}, [32]byte{}); err == nil {
+		t.Error("Expected error when pinning a non-pointer value")
+	}
+}
+
+func TestHashTreeRoot_ContainerElementCache(t *testing.T) {
+	type summary struct {
+		BlockRoot [32]byte
+		StateRoot [32]byte
+	}
+	type state struct {
+		Summaries []*summary `ssz-max:"16777216"`
+		Forks     [4]fork
+	}
+	item := &state{Summaries: []*summary{{BlockRoot: [32]byte{1}}}}
+	mutations := []func(){
+		func() {},
+		func() { item.Summaries = append(item.Summaries, &summary{StateRoot: [32]byte{2}}) },
+		func() { item.Summaries = append(item.Summaries, &summary{}, &summary{BlockRoot: [32]byte{3}}) },
+		func() { item.Summaries[0].StateRoot = [32]byte{4} },
+		func() { item.Forks[2].Epoch = 10 },
+		func() { item.Summaries = item.Summaries[:1] },
+	}
+	for i, mutate := range mutations {
+		mutate()
+		types.ToggleCache(false)
+		want, err := HashTreeRoot(item)
+		if err != nil {
+			t.Fatal(err)
+		}
+		types.ToggleCache(true)
+		got, err := HashTreeRoot(item)
+		types.ToggleCache(false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Mutation %d: wanted root %#x, received %#x", i, want, got)
+		}
+	}
+}
+
+func TestMapCodec(t *testing.T) {
+	type balanceEntry struct {
+		Key   uint64
+		Value []uint64
+	}
+	type withMap struct {
+		Slot     uint64
+		Balances map[uint64][]uint64 `ssz-max:"1024"`
+	}
+	type withList struct {
+		Slot     uint64
+		Balances []balanceEntry `ssz-max:"1024"`
+	}
+	item := &withMap{Slot: 3, Balances: map[uint64][]uint64{9: {1, 2}, 2: {3}, 5: {7}}}
+	if _, err := Marshal(item); err == nil {
+		t.Error("Expected error marshaling a map while the map codec is disabled")
+	}
+	types.ToggleMapCodec(true)
+	defer types.ToggleMapCodec(false)
+
+	equivalent := &withList{Slot: 3, Balances: []balanceEntry{{2, []uint64{3}}, {5, []uint64{7}}, {9, []uint64{1, 2}}}}
+	enc, err := Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantEnc, err := Marshal(equivalent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc, wantEnc) {
+		t.Errorf("Wanted encoding %#x, received %#x", wantEnc, enc)
+	}
+	root, err := HashTreeRoot(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wantRoot, err := HashTreeRoot(equivalent)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != wantRoot {
+		t.Errorf("Wanted root %#x, received %#x", wantRoot, root)
+	}
+	decoded := &withMap{}
+	if err := Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, item) {
+		t.Errorf("Wanted %v, received %v", item, decoded)
+	}
+
+	unsorted := &withList{Balances: []balanceEntry{{5, []uint64{}}, {2, []uint64{3}}}}
+	enc, err = Marshal(unsorted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Unmarshal(enc, &withMap{}); err == nil {
+		t.Error("Expected error decoding map entries out of key order")
+	}
+}
+
+func TestUnsupportedTypeError(t *testing.T) {
+	type inner struct {
+		Foo   uint64
+		Price float64
+	}
+	type outer struct {
+		Slot  uint64
+		Inner *inner
+	}
+	_, err := HashTreeRoot(&outer{Inner: &inner{}})
+	unsupported, ok := errors.Cause(err).(*types.UnsupportedTypeError)
+	if !ok {
+		t.Fatalf("Expected *types.UnsupportedTypeError, received %v", err)
+	}
+	if unsupported.Path != "Inner.Price" || unsupported.Type.Kind() != reflect.Float64 {
+		t.Errorf("Wanted float64 at Inner.Price, received %v at %s", unsupported.Type, unsupported.Path)
+	}
+	if unsupported.Suggestion == "" {
+		t.Error("Expected a suggestion")
+	}
+	if _, err := Marshal(&outer{Inner: &inner{}}); err == nil || errors.Cause(err).(*types.UnsupportedTypeError).Path != "Inner.Price" {
+		t.Errorf("Expected unsupported type error at Inner.Price when marshaling, received %v", err)
+	}
+}
+
+type testUnion struct {
+	None  *types.UnionNone
+	Fork  *fork
+	Bytes *[]byte
+}
+
+func (testUnion) SSZUnion() {}
+
+type unionContainer struct {
+	Slot    uint64
+	Payload testUnion
+}
+
+func TestUnion(t *testing.T) {
+	f := &fork{PreviousVersion: [4]byte{1}, CurrentVersion: [4]byte{2}, Epoch: 3}
+	data := []byte{0xaa, 0xbb}
+	tests := []struct {
+		name     string
+		input    testUnion
+		selector byte
+		variant  interface{}
+	}{
+		{name: "None", input: testUnion{}, selector: 0},
+		{name: "Fork", input: testUnion{Fork: f}, selector: 1, variant: f},
+		{name: "Bytes", input: testUnion{Bytes: &data}, selector: 2, variant: data},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			item := &unionContainer{Slot: 7, Payload: tt.input}
+			enc, err := Marshal(item)
+			if err != nil {
+				t.Fatal(err)
+			}
+			wantEnc := []byte{7, 0, 0, 0, 0, 0, 0, 0, 12, 0, 0, 0, tt.selector}
+			var variantRoot [32]byte
+			if tt.variant != nil {
+				variantEnc, err := Marshal(tt.variant)
+				if err != nil {
+					t.Fatal(err)
+				}
+				wantEnc = append(wantEnc, variantEnc...)
+				if variantRoot, err = HashTreeRoot(tt.variant); err != nil {
+					t.Fatal(err)
+				}
+			}
+			if !bytes.Equal(enc, wantEnc) {
+				t.Errorf("Wanted encoding %#x, received %#x", wantEnc, enc)
+			}
+			decoded := &unionContainer{}
+			if err := Unmarshal(enc, decoded); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(decoded, item) {
+				t.Errorf("Wanted %+v, received %+v", item, decoded)
+			}
+			root, err := HashTreeRoot(tt.input)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if want := HashPair(variantRoot, [32]byte{tt.selector}); root != want {
+				t.Errorf("Wanted root %#x, received %#x", want, root)
+			}
+			if withRoot, err := HashTreeRootWith(tt.input, &Hasher{}); err != nil || withRoot != root {
+				t.Errorf("Wanted root %#x, received %#x (%v)", root, withRoot, err)
+			}
+		})
+	}
+	if _, err := Marshal(testUnion{Fork: f, Bytes: &data}); err == nil {
+		t.Error("Expected error marshaling a union with two variants set")
+	}
+	if err := Unmarshal([]byte{3}, &testUnion{}); err == nil {
+		t.Error("Expected error for a selector out of range")
+	}
+	if err := Unmarshal([]byte{0, 1}, &testUnion{}); err == nil {
+		t.Error("Expected error for a none variant followed by data")
+	}
+	if err := Unmarshal([]byte{1, 1, 2}, &testUnion{}); err == nil {
+		t.Error("Expected error for a truncated fixed-size variant")
+	}
+}
+
+type optionalFork struct {
+	Value *fork
+}
+
+func (optionalFork) SSZOptional() {}
+
+type optionalContainer struct {
+	Current  optionalFork
+	Slot     uint64
+	Previous optionalFork
+}
+
+func TestOptional(t *testing.T) {
+	f := &fork{PreviousVersion: [4]byte{1}, CurrentVersion: [4]byte{2}, Epoch: 3}
+	forkEnc, err := Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	forkRoot, err := HashTreeRoot(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	item := &optionalContainer{Previous: optionalFork{Value: f}, Slot: 5}
+	enc, err := Marshal(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Offsets of both optionals point past the fixed part, the first one being empty.
+	wantEnc := append([]byte{16, 0, 0, 0, 5, 0, 0, 0, 0, 0, 0, 0, 16, 0, 0, 0, 1}, forkEnc...)
+	if !bytes.Equal(enc, wantEnc) {
+		t.Errorf("Wanted encoding %#x, received %#x", wantEnc, enc)
+	}
+	if size, err := Size(item); err != nil || size != uint64(len(enc)) {
+		t.Errorf("Wanted size %d, received %d (%v)", len(enc), size, err)
+	}
+	decoded := &optionalContainer{Current: optionalFork{Value: f}}
+	if err := Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, item) {
+		t.Errorf("Wanted %+v, received %+v", item, decoded)
+	}
+	root, err := HashTreeRoot(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var slot [32]byte
+	slot[0] = 5
+	want := HashPair(
+		HashPair(MixInLength([32]byte{}, 0), slot),
+		HashPair(MixInLength(forkRoot, 1), [32]byte{}),
+	)
+	if root != want {
+		t.Errorf("Wanted root %#x, received %#x", want, root)
+	}
+	if withRoot, err := HashTreeRootWith(item, &Hasher{}); err != nil || withRoot != root {
+		t.Errorf("Wanted root %#x, received %#x (%v)", root, withRoot, err)
+	}
+	if err := Unmarshal([]byte{16, 0, 0, 0, 5, 0, 0, 0, 0, 0, 0, 0, 16, 0, 0, 0, 2}, &optionalContainer{}); err == nil {
+		t.Error("Expected error for an invalid presence byte")
+	}
+}
+
+type stableShape struct {
+	_      struct{} `ssz:"stable,max=4"`
+	Side   *uint16
+	Color  *uint8
+	Radius *uint16
+}
+
+type stableShapeV2 struct {
+	_      struct{} `ssz:"stable,max=4"`
+	Side   *uint16
+	Color  *uint8
+	Radius *uint16
+	Label  []byte `ssz-max:"16"`
+}
+
+type stableBody struct {
+	_        struct{} `ssz:"stable,max=16"`
+	Fork     *fork
+	Balances []uint64 `ssz-max:"8"`
+	Slot     *uint64
+	Roots    [][]byte `ssz-size:"2,32"`
+}
+
+type profileSquare struct {
+	_     *stableShape `ssz:"profile"`
+	Side  uint16
+	Color uint8
+}
+
+type profileCircle struct {
+	_      *stableShape `ssz:"profile"`
+	Color  uint8
+	Radius uint16
+}
+
+type profileAnyShape struct {
+	_      *stableShape `ssz:"profile"`
+	Side   *uint16      `ssz:"optional"`
+	Color  uint8
+	Radius *uint16 `ssz:"optional"`
+}
+
+type profileHolder struct {
+	Square profileSquare
+	Slot   uint64
+}
+
+func TestStableContainer(t *testing.T) {
+	side, color, radius := uint16(0x42), uint8(1), uint16(0x42)
+	tests := []struct {
+		shape   *stableShape
+		wantEnc string
+		root    string
+	}{
+		{
+			shape:   &stableShape{Side: &side, Color: &color},
+			wantEnc: "03420001",
+			root:    "bfdb6fda9d02805e640c0f5767b8d1bb9ff4211498a5e2d7c0f36e1b88ce57ff",
+		},
+		{
+			shape:   &stableShape{Color: &color, Radius: &radius},
+			wantEnc: "06014200",
+			root:    "f66d2c38c8d2afbd409e86c529dff728e9a4208215ca20ee44e49c3d11e145d8",
+		},
+	}
+	for _, tt := range tests {
+		enc, err := Marshal(tt.shape)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(enc) != tt.wantEnc {
+			t.Errorf("Wanted encoding %s, received %#x", tt.wantEnc, enc)
+		}
+		decoded := &stableShape{}
+		if err := Unmarshal(enc, decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(decoded, tt.shape) {
+			t.Errorf("Wanted %+v, received %+v", tt.shape, decoded)
+		}
+		root, err := HashTreeRoot(tt.shape)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(root[:]) != tt.root {
+			t.Errorf("Wanted root %s, received %#x", tt.root, root)
+		}
+		if withRoot, err := HashTreeRootWith(tt.shape, &Hasher{}); err != nil || withRoot != root {
+			t.Errorf("Wanted root %#x, received %#x (%v)", root, withRoot, err)
+		}
+		// Appending a field which is absent leaves both the encoding and the root unchanged.
+		v2 := &stableShapeV2{Side: tt.shape.Side, Color: tt.shape.Color, Radius: tt.shape.Radius}
+		if v2Root, err := HashTreeRoot(v2); err != nil || v2Root != root {
+			t.Errorf("Wanted root %#x after adding a field, received %#x (%v)", root, v2Root, err)
+		}
+	}
+
+	slot := uint64(9)
+	body := &stableBody{
+		Fork:     &fork{Epoch: 3},
+		Balances: []uint64{},
+		Slot:     &slot,
+		Roots:    [][]byte{make([]byte, 32), bytes.Repeat([]byte{1}, 32)},
+	}
+	enc, err := Marshal(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if size, err := Size(body); err != nil || size != uint64(len(enc)) {
+		t.Errorf("Wanted size %d, received %d (%v)", len(enc), size, err)
+	}
+	decoded := &stableBody{}
+	if err := Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, body) {
+		t.Errorf("Wanted %+v, received %+v", body, decoded)
+	}
+	root, err := HashTreeRoot(body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withRoot, err := HashTreeRootWith(body, &Hasher{}); err != nil || withRoot != root {
+		t.Errorf("Wanted root %#x, received %#x (%v)", root, withRoot, err)
+	}
+
+	if err := Unmarshal([]byte{0x08}, &stableShape{}); err == nil {
+		t.Error("Expected error for an active bit past the last field")
+	}
+	if err := Unmarshal([]byte{0x01, 0x42}, &stableShape{}); err == nil {
+		t.Error("Expected error for a truncated field")
+	}
+	if err := Unmarshal([]byte{0x01, 0x42, 0x00, 0x00}, &stableShape{}); err == nil {
+		t.Error("Expected error for trailing bytes")
+	}
+	if _, err := Marshal(&struct {
+		_    struct{} `ssz:"stable,max=4"`
+		Slot uint64
+	}{}); err == nil {
+		t.Error("Expected error for a field which cannot be absent")
+	}
+}
+
+func TestWithStats(t *testing.T) {
+	type statsSummary struct {
+		BlockRoot [32]byte
+		StateRoot [32]byte
+	}
+	type statsState struct {
+		Slot      uint64
+		Summaries []*statsSummary `ssz-max:"1024"`
+	}
+	item := &statsState{Slot: 1}
+	for i := 0; i < 8; i++ {
+		item.Summaries = append(item.Summaries, &statsSummary{BlockRoot: [32]byte{byte(i)}})
+	}
+	var encStats Stats
+	if _, err := Marshal(item, WithStats(&encStats)); err != nil {
+		t.Fatal(err)
+	}
+	if encStats.BytesHashed != 0 || encStats.Allocations == 0 {
+		t.Errorf("Expected allocations and no hashing while marshaling, received %+v", encStats)
+	}
+
+	types.ToggleCache(true)
+	defer types.ToggleCache(false)
+	var first, second Stats
+	want, err := HashTreeRoot(item, WithStats(&first))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := HashTreeRoot(item, WithStats(&second))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+	if first.ChunksHashed == 0 || first.BytesHashed < 32*first.ChunksHashed-31 {
+		t.Errorf("Unexpected hashing statistics %+v", first)
+	}
+	// Every element root is served by the element roots cache on the second call.
+	if second.CacheHits < 8 || second.ChunksHashed >= first.ChunksHashed {
+		t.Errorf("Expected cached roots to save hashing, first call %+v, second call %+v", first, second)
+	}
+}
+
+type cachedSummary struct {
+	BlockRoot [32]byte
+	StateRoot [32]byte
+}
+
+type cachedHistory struct {
+	Slot      uint64
+	Summaries []*cachedSummary `ssz-max:"1024"`
+}
+
+func TestNoCache(t *testing.T) {
+	item := &cachedHistory{Slot: 1}
+	for i := 0; i < 8; i++ {
+		item.Summaries = append(item.Summaries, &cachedSummary{BlockRoot: [32]byte{byte(i)}})
+	}
+	types.ToggleCache(true)
+	defer types.ToggleCache(false)
+	want, err := HashTreeRoot(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var stats Stats
+	got, err := HashTreeRoot(item, NoCache(), WithStats(&stats))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+	if stats.CacheHits != 0 {
+		t.Errorf("Expected no cache hits, received %d", stats.CacheHits)
+	}
+}
+
+func TestCacheFor(t *testing.T) {
+	item := &cachedHistory{Slot: 1}
+	for i := 0; i < 8; i++ {
+		item.Summaries = append(item.Summaries, &cachedSummary{BlockRoot: [32]byte{byte(i)}})
+	}
+	types.ToggleCache(true)
+	defer types.ToggleCache(false)
+	handle := CacheFor(reflect.TypeOf(item))
+	defer handle.SetEnabled(true)
+	if !handle.Enabled() {
+		t.Fatal("Expected caching to be enabled by default")
+	}
+	want, err := HashTreeRoot(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var cached, uncached Stats
+	if _, err := HashTreeRoot(item, WithStats(&cached)); err != nil {
+		t.Fatal(err)
+	}
+	handle.SetEnabled(false)
+	if handle.Enabled() {
+		t.Error("Expected caching to be disabled for the type")
+	}
+	got, err := HashTreeRoot(item, WithStats(&uncached))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+	// The element roots of the summaries are no longer served by the element roots cache
+	// and are hashed again.
+	if uncached.ChunksHashed <= cached.ChunksHashed {
+		t.Errorf("Expected more hashing without caching, %d chunks with caching and %d without", cached.ChunksHashed, uncached.ChunksHashed)
+	}
+}
+
+// countdownContext is cancelled once its error has been checked a number of times.
+type countdownContext struct {
+	context.Context
+	checks int
+}
+
+func (c *countdownContext) Err() error {
+	if c.checks == 0 {
+		return context.Canceled
+	}
+	c.checks--
+	return nil
+}
+
+func TestHashTreeRootCtx(t *testing.T) {
+	item := &cachedHistory{Slot: 1}
+	for i := 0; i < 1024; i++ {
+		item.Summaries = append(item.Summaries, &cachedSummary{BlockRoot: [32]byte{byte(i)}})
+	}
+	want, err := HashTreeRoot(item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := HashTreeRootCtx(context.Background(), item)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := HashTreeRootCtx(ctx, item); errors.Cause(err) != context.Canceled {
+		t.Errorf("Expected %v, received %v", context.Canceled, err)
+	}
+	// The context is checked again while hashing the summaries.
+	ctx = &countdownContext{Context: context.Background(), checks: 2}
+	if _, err := HashTreeRootCtx(ctx, item); errors.Cause(err) != context.Canceled {
+		t.Errorf("Expected %v, received %v", context.Canceled, err)
+	}
+}
+
+// pairsBackend is a sha256 backend hashing pairs of chunks in batches.
+type pairsBackend struct {
+	calls int
+	pairs int
+}
+
+func (*pairsBackend) Name() string {
+	return "pairs"
+}
+
+func (*pairsBackend) Sum256(data []byte) [32]byte {
+	return sha256.Sum256(data)
+}
+
+func (b *pairsBackend) HashPairs(dst [][32]byte, pairs [][32]byte) {
+	b.calls++
+	b.pairs += len(dst)
+	for i := range dst {
+		dst[i] = sha256.Sum256(append(pairs[2*i][:], pairs[2*i+1][:]...))
+	}
+}
+
+func TestHashTreeRootBatch(t *testing.T) {
+	prev := CurrentHashBackend()
+	defer func() {
+		if err := SetHashBackend(prev); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	summaries := []*cachedSummary{{BlockRoot: [32]byte{1}}, {StateRoot: [32]byte{2}}}
+	vals := []interface{}{
+		&fork{Epoch: 3},
+		&cachedHistory{Slot: 1, Summaries: summaries},
+		uint64(5),
+		(*fork)(nil),
+		fork{PreviousVersion: [4]byte{1}},
+		summaries,
+		struct{}{},
+	}
+	want := make([][32]byte, len(vals))
+	for i, val := range vals {
+		root, err := HashTreeRoot(val)
+		if err != nil {
+			t.Fatal(err)
+		}
+		want[i] = root
+	}
+	b := &pairsBackend{}
+	if err := SetHashBackend(b); err != nil {
+		t.Fatal(err)
+	}
+	b.calls, b.pairs = 0, 0
+	roots, err := HashTreeRootBatch(vals)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roots, want) {
+		t.Errorf("Wanted roots %#x, received %#x", want, roots)
+	}
+	// Each of the two levels of the trees of the three forks and the history is hashed
+	// in a single call. The trees of the two summaries of each list of summaries, in the
+	// history and on its own, are hashed together in a call of their own.
+	if b.calls != 2+2 || b.pairs != 3*2+1+3+2*2 {
+		t.Errorf("Expected pairs of chunks to be hashed by the backend, received %d pairs in %d calls", b.pairs, b.calls)
+	}
+	if _, err := HashTreeRootBatch([]interface{}{&fork{}, nil}); err == nil {
+		t.Error("Expected error for an untyped nil")
+	}
+	if _, err := HashTreeRootBatch([]interface{}{&fork{}, make(chan int)}); err == nil {
+		t.Error("Expected error for an unsupported type")
+	}
+}
+
+func TestListRoot(t *testing.T) {
+	summaries := []*cachedSummary{{BlockRoot: [32]byte{1}}, {StateRoot: [32]byte{2}}, {BlockRoot: [32]byte{3}}}
+	roots := make([][32]byte, len(summaries))
+	for i, s := range summaries {
+		root, err := HashTreeRoot(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots[i] = root
+	}
+	want, err := HashTreeRootWithCapacity(summaries, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	leaves := append([][32]byte{}, roots...)
+	if root, err := ListRoot(roots, 1024); err != nil || root != want {
+		t.Errorf("Wanted root %#x, received %#x: %v", want, root, err)
+	}
+	if !reflect.DeepEqual(roots, leaves) {
+		t.Error("Roots were modified")
+	}
+	vectorRoot, err := Merkleize(roots, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := HashPair(HashPair(roots[0], roots[1]), HashPair(roots[2], [32]byte{})); vectorRoot != want {
+		t.Errorf("Wanted root %#x, received %#x", want, vectorRoot)
+	}
+	if root, err := ListRoot(nil, 16); err != nil || root != MixInLength(ZeroHash(4), 0) {
+		t.Errorf("Wanted root of empty list, received %#x: %v", root, err)
+	}
+	if _, err := ListRoot(roots, 2); err == nil {
+		t.Error("Expected error for a list over its limit")
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	type statsHistory struct {
+		Slot       uint64
+		BlockRoots [][]byte         `ssz-size:"8,32"`
+		Summaries  []*cachedSummary `ssz-max:"16"`
+	}
+	item := &statsHistory{Slot: 1, BlockRoots: make([][]byte, 8)}
+	for i := range item.BlockRoots {
+		item.BlockRoots[i] = make([]byte, 32)
+		item.BlockRoots[i][0] = byte(i + 1)
+		item.Summaries = append(item.Summaries, &cachedSummary{BlockRoot: [32]byte{byte(i)}})
+	}
+	types.ToggleCache(true)
+	defer types.ToggleCache(false)
+	before := CacheStats()
+	for i := 0; i < 2; i++ {
+		if _, err := HashTreeRoot(item); err != nil {
+			t.Fatal(err)
+		}
+	}
+	after := CacheStats()
+	for i, c := range after.Roots {
+		if c.Name == "" || c.Hits < before.Roots[i].Hits || c.Entries > c.Capacity {
+			t.Errorf("Unexpected statistics of cache %+v", c)
+		}
+	}
+	var found bool
+	for _, typ := range after.Types {
+		if typ.Type == "statsHistory" {
+			found = true
+			if typ.Fields != 2 || typ.Bytes == 0 {
+				t.Errorf("Unexpected statistics of type %+v", typ)
+			}
+		}
+	}
+	if !found {
+		t.Errorf("Expected cached fields of statsHistory, received %+v", after.Types)
+	}
+	if after.TypeInfo == 0 {
+		t.Error("Expected cached type layouts")
+	}
+}
+
+func TestProfile(t *testing.T) {
+	side, radius := uint16(0x42), uint16(0x42)
+	tests := []struct {
+		profile interface{}
+		decoded interface{}
+		wantEnc string
+		base    *stableShape
+	}{
+		{
+			profile: &profileSquare{Side: 0x42, Color: 1},
+			decoded: &profileSquare{},
+			wantEnc: "420001",
+			base:    &stableShape{Side: &side, Color: new(uint8)},
+		},
+		{
+			profile: &profileCircle{Color: 1, Radius: 0x42},
+			decoded: &profileCircle{},
+			wantEnc: "014200",
+			base:    &stableShape{Color: new(uint8), Radius: &radius},
+		},
+		{
+			profile: &profileAnyShape{Color: 1, Radius: &radius},
+			decoded: &profileAnyShape{},
+			wantEnc: "02014200",
+			base:    &stableShape{Color: new(uint8), Radius: &radius},
+		},
+	}
+	for _, tt := range tests {
+		*tt.base.Color = 1
+		enc, err := Marshal(tt.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if hex.EncodeToString(enc) != tt.wantEnc {
+			t.Errorf("Wanted encoding %s, received %#x", tt.wantEnc, enc)
+		}
+		if err := Unmarshal(enc, tt.decoded); err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(tt.decoded, tt.profile) {
+			t.Errorf("Wanted %+v, received %+v", tt.profile, tt.decoded)
+		}
+		// A profile hashes as its stable container holding the same values.
+		want, err := HashTreeRoot(tt.base)
+		if err != nil {
+			t.Fatal(err)
+		}
+		root, err := HashTreeRoot(tt.profile)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != want {
+			t.Errorf("Wanted root %#x, received %#x", want, root)
+		}
+		if withRoot, err := HashTreeRootWith(tt.profile, &Hasher{}); err != nil || withRoot != root {
+			t.Errorf("Wanted root %#x, received %#x (%v)", root, withRoot, err)
+		}
+	}
+
+	// Profiles without optional fields nor variable-size fields are fixed-size.
+	holder := &profileHolder{Square: profileSquare{Side: 0x42, Color: 1}, Slot: 2}
+	enc, err := Marshal(holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := "4200010200000000000000"; hex.EncodeToString(enc) != want {
+		t.Errorf("Wanted encoding %s, received %#x", want, enc)
+	}
+	decoded := &profileHolder{}
+	if err := Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(decoded, holder) {
+		t.Errorf("Wanted %+v, received %+v", holder, decoded)
+	}
+
+	if err := Unmarshal([]byte{0x04, 0x01}, &profileAnyShape{}); err == nil {
+		t.Error("Expected error for an active bit past the optional fields")
+	}
+	if _, err := Marshal(&struct {
+		_     *stableShape `ssz:"profile"`
+		Color uint8
+		Side  uint16
+	}{}); err == nil {
+		t.Error("Expected error for fields out of the order of the stable container")
+	}
+	if _, err := Marshal(&struct {
+		_     *stableShape `ssz:"profile"`
+		Color uint32
+	}{}); err == nil {
+		t.Error("Expected error for a field type differing from the stable container")
+	}
+}
+
+type wideUints struct {
+	BaseFee types.Uint256
+	Value   types.Uint128
+	Limbs   [4]uint64
+}
+
+func TestWideUints(t *testing.T) {
+	// 2^64 + 5, whose second 64-bit word is 1.
+	n := new(big.Int).Add(new(big.Int).Lsh(big.NewInt(1), 64), big.NewInt(5))
+	baseFee, err := types.NewUint256(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, err := types.NewUint128(n)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if baseFee.String() != n.String() || value.Big().Cmp(n) != 0 {
+		t.Errorf("Wanted %v, received %v and %v", n, baseFee, value)
+	}
+	obj := &wideUints{BaseFee: baseFee, Value: value, Limbs: [4]uint64{5, 1}}
+	enc, err := Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := make([]byte, 32+16+32)
+	want[0], want[8] = 5, 1
+	want[32], want[40] = 5, 1
+	want[48], want[56] = 5, 1
+	if !bytes.Equal(enc, want) {
+		t.Errorf("Wanted encoding %#x, received %#x", want, enc)
+	}
+	decoded := &wideUints{}
+	if err := Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(obj, decoded) {
+		t.Errorf("Wanted %+v, received %+v", obj, decoded)
+	}
+	// Every field is a single chunk holding its serialization.
+	var chunk [32]byte
+	copy(chunk[:], value[:])
+	root, err := HashTreeRoot(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wantRoot := HashPair(HashPair(baseFee, chunk), HashPair(baseFee, ZeroHash(0))); root != wantRoot {
+		t.Errorf("Wanted root %#x, received %#x", wantRoot, root)
+	}
+	if _, err := types.NewUint128(new(big.Int).Lsh(big.NewInt(1), 128)); err == nil {
+		t.Error("Expected error for overflowing uint128")
+	}
+	if _, err := types.NewUint256(big.NewInt(-1)); err == nil {
+		t.Error("Expected error for negative uint256")
+	}
+}
+
+type bitfields struct {
+	Justification bitfield.Bitvector4
+	Aggregation   bitfield.Bitlist `ssz-max:"16"`
+}
+
+func TestBitfields(t *testing.T) {
+	obj := &bitfields{Justification: bitfield.Bitvector4{0x05}, Aggregation: bitfield.Bitlist{0x0d}}
+	enc, err := Marshal(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The bitvector needs no ssz-size tag to be a fixed-size field.
+	if want := []byte{0x05, 0x05, 0x00, 0x00, 0x00, 0x0d}; !bytes.Equal(enc, want) {
+		t.Errorf("Wanted encoding %#x, received %#x", want, enc)
+	}
+	decoded := &bitfields{}
+	if err := Unmarshal(enc, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(obj, decoded) {
+		t.Errorf("Wanted %+v, received %+v", obj, decoded)
+	}
+	bitlistRoot, err := HashTreeRootWithCapacity(obj.Aggregation, 16)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want, err := types.BitlistRoot(obj.Aggregation, 16); err != nil || bitlistRoot != want {
+		t.Errorf("Wanted bitlist root %#x, received %#x", want, bitlistRoot)
+	}
+	bitvectorRoot, err := HashTreeRoot(obj.Justification)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bitvectorRoot != [32]byte{0x05} {
+		t.Errorf("Unexpected bitvector root %#x", bitvectorRoot)
+	}
+	root, err := HashTreeRoot(obj)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := HashPair(bitvectorRoot, bitlistRoot); root != want {
+		t.Errorf("Wanted root %#x, received %#x", want, root)
+	}
+
+	enc[0] = 0x15
+	if err := Unmarshal(enc, &bitfields{}); err == nil {
+		t.Error("Expected error for bitvector with padding bits set")
+	}
+	if _, err := Marshal(&bitfields{Justification: bitfield.Bitvector4{0x10}}); err == nil {
+		t.Error("Expected error for bitvector with padding bits set")
+	}
+}
+
+func TestBlockRoots(t *testing.T) {
+	var encoded [][]byte
+	var want [][32]byte
+	for i := 0; i < 6; i++ {
+		// The last block is a duplicate of the first one.
+		state := &proofState{Slot: uint64(i % 5), BlockRoots: make([][]byte, 8), Balances: []uint64{uint64(i % 5)}}
+		for j := range state.BlockRoots {
+			state.BlockRoots[j] = make([]byte, 32)
+		}
+		enc, err := Marshal(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		decoded := &proofState{}
+		if err := Unmarshal(enc, decoded); err != nil {
+			t.Fatal(err)
+		}
+		root, err := HashTreeRoot(decoded)
+		if err != nil {
+			t.Fatal(err)
+		}
+		encoded = append(encoded, enc)
+		want = append(want, root)
+	}
+	for _, workers := range []int{0, 1, 3} {
+		roots, err := BlockRoots(encoded, (*proofState)(nil), workers)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(roots, want) {
+			t.Errorf("Wanted roots %#x with %d workers, received %#x", want, workers, roots)
+		}
+	}
+	if _, err := BlockRoots(append(encoded, []byte{0x01}), (*proofState)(nil), 2); err == nil {
+		t.Error("Expected error for invalid encoding")
+	}
+}
+
+type sizedVectors struct {
+	Root    []byte   `ssz-size:"4"`
+	Words   []uint64 `ssz-size:"2"`
+	Nested  [][]byte `ssz-size:"2,3"`
+	Entries [][]byte `ssz-size:"?,4"`
+}
+
+func TestSizedVectors(t *testing.T) {
+	full := &sizedVectors{
+		Root:    []byte{1, 2, 0, 0},
+		Words:   []uint64{1, 0},
+		Nested:  [][]byte{{1, 0, 0}, {0, 0, 0}},
+		Entries: [][]byte{{1, 0, 0, 0}},
+	}
+	short := &sizedVectors{
+		Root:    []byte{1, 2},
+		Words:   []uint64{1},
+		Nested:  [][]byte{{1}},
+		Entries: [][]byte{{1}},
+	}
+	want, err := Marshal(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(want) != 4+16+6+4+4 {
+		t.Fatalf("Unexpected encoding %#x", want)
+	}
+	wantRoot, err := HashTreeRoot(full)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Vectors are fixed-size whatever the length of the slices holding them.
+	enc, err := Marshal(short)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(enc, want) {
+		t.Errorf("Wanted encoding %#x, received %#x", want, enc)
+	}
+	if size, err := Size(short); err != nil || size != uint64(len(want)) {
+		t.Errorf("Wanted size %d, received %d: %v", len(want), size, err)
+	}
+	root, err := HashTreeRoot(short)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withRoot, err := HashTreeRootWith(short, &Hasher{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root != wantRoot || withRoot != wantRoot {
+		t.Errorf("Wanted root %#x, received %#x and %#x", wantRoot, root, withRoot)
+	}
+	if enc, err := Marshal(&sizedVectors{}); err != nil || len(enc) != 4+16+6+4 {
+		t.Errorf("Unexpected encoding of zero value %#x: %v", enc, err)
+	}
+
+	long := &sizedVectors{Nested: [][]byte{{1, 2, 3, 4}}}
+	if _, err := Marshal(long); err == nil {
+		t.Error("Expected error for vector longer than its declared length")
+	}
+	if _, err := HashTreeRoot(long); err == nil {
+		t.Error("Expected error for vector longer than its declared length")
+	}
+}
+
+func TestUnmarshalVerified(t *testing.T) {
+	f := &fork{PreviousVersion: [4]byte{1}, CurrentVersion: [4]byte{2}, Epoch: 5}
+	enc, err := Marshal(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := HashTreeRoot(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decoded := &fork{}
+	if err := UnmarshalVerified(enc, decoded, root); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(f, decoded) {
+		t.Errorf("Wanted %+v, received %+v", f, decoded)
+	}
+
+	untouched := &fork{Epoch: 1}
+	err = UnmarshalVerified(enc, untouched, [32]byte{1})
+	mismatch, ok := err.(*RootMismatchError)
+	if !ok || mismatch.Actual != root {
+		t.Fatalf("Expected root mismatch error, received %v", err)
+	}
+	if untouched.Epoch != 1 {
+		t.Errorf("Unverified data was written to the target: %+v", untouched)
+	}
+	if err := UnmarshalVerified(enc[:3], &fork{}, root); err == nil {
+		t.Error("Expected error for truncated input")
+	}
+}
+
+type limitedLists struct {
+	Items []uint64 `ssz-max:"2"`
+	Names [][]byte `ssz-max:"1"`
+}
+
+func TestLimitExceeded(t *testing.T) {
+	for _, obj := range []*limitedLists{
+		{Items: []uint64{1, 2, 3}},
+		{Names: [][]byte{{1}, {2}}},
+	} {
+		// Encodings over the limit are what an attacker would send.
+		enc, err := Marshal(obj)
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = Unmarshal(enc, &limitedLists{})
+		if limitErr, ok := errors.Cause(err).(*LimitExceededError); !ok || limitErr.Length <= limitErr.Limit {
+			t.Errorf("Expected limit exceeded error on decode, received %v", err)
+		}
+		_, err = HashTreeRoot(obj)
+		if _, ok := errors.Cause(err).(*LimitExceededError); !ok {
+			t.Errorf("Expected limit exceeded error on hash, received %v", err)
+		}
+		_, err = HashTreeRootWith(obj, &Hasher{})
+		if _, ok := errors.Cause(err).(*LimitExceededError); !ok {
+			t.Errorf("Expected limit exceeded error on hash, received %v", err)
+		}
+	}
+	err := Unmarshal(mustMarshal(t, &limitedLists{Items: []uint64{1, 2, 3}}), &limitedLists{})
+	if limitErr, ok := errors.Cause(err).(*LimitExceededError); !ok || limitErr.Path != "Items" || limitErr.Length != 3 {
+		t.Errorf("Unexpected error %v", err)
+	}
+}
+
+func mustMarshal(t *testing.T, val interface{}) []byte {
+	enc, err := Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return enc
+}
+
+type plainRecord struct {
+	Slot  uint64
+	Roots [][]byte `ssz-size:"?,32"`
+	Epoch uint64
+}
+
+type cachedRecord struct {
+	Slot  uint64
+	Cache map[uint64][32]byte `ssz:"-"`
+	Roots [][]byte            `ssz-size:"?,32"`
+	Dirty bool                `ssz:"-"`
+	Epoch uint64
+}
+
+func TestSkippedFields(t *testing.T) {
+	plain := &plainRecord{Slot: 3, Roots: [][]byte{make([]byte, 32)}, Epoch: 7}
+	cached := &cachedRecord{
+		Slot:  3,
+		Cache: map[uint64][32]byte{1: {2}},
+		Roots: [][]byte{make([]byte, 32)},
+		Dirty: true,
+		Epoch: 7,
+	}
+	want := mustMarshal(t, plain)
+	if enc := mustMarshal(t, cached); !bytes.Equal(enc, want) {
+		t.Errorf("Wanted encoding %#x, received %#x", want, enc)
+	}
+	if size, err := Size(cached); err != nil || size != uint64(len(want)) {
+		t.Errorf("Wanted size %d, received %d: %v", len(want), size, err)
+	}
+	wantRoot, err := HashTreeRoot(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root, err := HashTreeRoot(cached); err != nil || root != wantRoot {
+		t.Errorf("Wanted root %#x, received %#x: %v", wantRoot, root, err)
+	}
+	if root, err := HashTreeRootWith(cached, &Hasher{}); err != nil || root != wantRoot {
+		t.Errorf("Wanted root %#x, received %#x: %v", wantRoot, root, err)
+	}
+	wantSigning, err := SigningRoot(plain)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root, err := SigningRoot(cached); err != nil || root != wantSigning {
+		t.Errorf("Wanted signing root %#x, received %#x: %v", wantSigning, root, err)
+	}
+
+	// Skipped fields are left untouched on decode.
+	decoded := &cachedRecord{Dirty: true}
+	if err := Unmarshal(want, decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Slot != 3 || decoded.Epoch != 7 || len(decoded.Roots) != 1 || !decoded.Dirty || decoded.Cache != nil {
+		t.Errorf("Unexpected decoded value %+v", decoded)
+	}
+}
+
+// generatedCheckpoint mimics a type with fastssz generated methods.
+type generatedCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+	calls int `ssz:"-"`
+}
+
+func (c *generatedCheckpoint) SizeSSZ() int {
+	return 40
+}
+
+func (c *generatedCheckpoint) MarshalSSZTo(buf []byte) ([]byte, error) {
+	c.calls++
+	buf = append(buf, make([]byte, 8)...)
+	binary.LittleEndian.PutUint64(buf[len(buf)-8:], c.Epoch)
+	return append(buf, c.Root[:]...), nil
+}
+
+func (c *generatedCheckpoint) UnmarshalSSZ(buf []byte) error {
+	c.calls++
+	if len(buf) != 40 {
+		return errors.New("incorrect size")
+	}
+	c.Epoch = binary.LittleEndian.Uint64(buf)
+	copy(c.Root[:], buf[8:])
+	return nil
+}
+
+func (c *generatedCheckpoint) HashTreeRoot() ([32]byte, error) {
+	c.calls++
+	var epoch [32]byte
+	binary.LittleEndian.PutUint64(epoch[:], c.Epoch)
+	return HashPair(epoch, c.Root), nil
+}
+
+// HashTreeRootWith takes a fastssz hasher in generated code.
+func (c *generatedCheckpoint) HashTreeRootWith(hh interface{}) error {
+	return nil
+}
+
+// wrappedCheckpoint implements HashTreeRoot through this package, as types without
+// generated methods commonly do.
+type wrappedCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+func (c *wrappedCheckpoint) HashTreeRoot() ([32]byte, error) {
+	return HashTreeRoot(*c)
+}
+
+func TestFastSSZ(t *testing.T) {
+	plain := &wrappedCheckpoint{Epoch: 9, Root: [32]byte{1, 2}}
+	c := &generatedCheckpoint{Epoch: 9, Root: [32]byte{1, 2}}
+	want := mustMarshal(t, plain)
+	if enc := mustMarshal(t, c); !bytes.Equal(enc, want) || c.calls != 1 {
+		t.Errorf("Wanted encoding %#x through generated methods, received %#x", want, enc)
+	}
+	wantRoot, err := plain.HashTreeRoot()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if root, err := HashTreeRoot(c); err != nil || root != wantRoot || c.calls != 2 {
+		t.Errorf("Wanted root %#x through generated methods, received %#x: %v", wantRoot, root, err)
+	}
+	if root, err := HashTreeRootWith(c, &Hasher{}); err != nil || root != wantRoot || c.calls != 3 {
+		t.Errorf("Wanted root %#x through generated methods, received %#x: %v", wantRoot, root, err)
+	}
+	decoded := &generatedCheckpoint{}
+	if err := Unmarshal(want, decoded); err != nil || decoded.Epoch != 9 || decoded.calls != 1 {
+		t.Errorf("Unexpected decoded value %+v: %v", decoded, err)
+	}
+	if err := Unmarshal(want[:8], decoded); err == nil {
+		t.Error("Expected error from generated methods")
+	}
+	// Nil pointers are handled by the reflection path rather than generated methods.
+	if enc, err := Marshal((*generatedCheckpoint)(nil)); err != nil || len(enc) != 40 {
+		t.Errorf("Unexpected encoding %#x of nil value: %v", enc, err)
+	}
+}
+
+func TestUnmarshal_Options(t *testing.T) {
+	want := mustMarshal(t, &wrappedCheckpoint{Epoch: 9, Root: [32]byte{1, 2}})
+	decoded := &generatedCheckpoint{}
+	if err := Unmarshal(want, decoded, WithMaxSize(39)); err == nil {
+		t.Error("Expected error when input exceeds the maximum size")
+	}
+	if err := Unmarshal(want, decoded, WithMaxSize(40), WithStrictOffsets()); err != nil {
+		t.Fatal(err)
+	}
+	// Strict offsets bypass the generated methods.
+	if decoded.Epoch != 9 || decoded.Root != [32]byte{1, 2} || decoded.calls != 0 {
+		t.Errorf("Unexpected decoded value %+v", decoded)
+	}
+}
+
+func TestUnmarshal_ZeroCopy(t *testing.T) {
+	type record struct {
+		Slot  uint64
+		Roots [][]byte `ssz-size:"2,32"`
+	}
+	newInput := func() []byte {
+		return mustMarshal(t, &record{Slot: 1, Roots: [][]byte{make([]byte, 32), make([]byte, 32)}})
+	}
+
+	input := newInput()
+	copied := &record{}
+	if err := Unmarshal(input, copied); err != nil {
+		t.Fatal(err)
+	}
+	aliased := &record{}
+	if err := Unmarshal(input, aliased, WithZeroCopy()); err != nil {
+		t.Fatal(err)
+	}
+	// Recycling the input buffer only shows through the value decoded without a copy.
+	for i := range input {
+		input[i] = 0xff
+	}
+	if copied.Roots[0][0] != 0 || copied.Roots[1][31] != 0 {
+		t.Errorf("Decoded value changed along with its input: %+v", copied)
+	}
+	if aliased.Roots[0][0] != 0xff || aliased.Roots[1][31] != 0xff {
+		t.Errorf("Expected decoded value to alias its input: %+v", aliased)
+	}
+
+	// Appending to a decoded root does not overwrite the next one.
+	for _, opts := range [][]DecodeOption{nil, {WithZeroCopy()}} {
+		decoded := &record{}
+		if err := Unmarshal(newInput(), decoded, opts...); err != nil {
+			t.Fatal(err)
+		}
+		_ = append(decoded.Roots[0], 9)
+		if decoded.Roots[1][0] != 0 {
+			t.Errorf("Appending to a root overwrote the next one: %+v", decoded)
+		}
+	}
+}
+
+type codecCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type codecHolder struct {
+	Slot        uint64
+	Checkpoint  *codecCheckpoint
+	Checkpoints []codecCheckpoint `ssz-max:"4"`
+}
+
+func TestRegisterCodec(t *testing.T) {
+	holder := &codecHolder{
+		Slot:        1,
+		Checkpoint:  &codecCheckpoint{Epoch: 2, Root: [32]byte{3}},
+		Checkpoints: []codecCheckpoint{{Epoch: 4}, {Epoch: 5, Root: [32]byte{6}}},
+	}
+	want := mustMarshal(t, holder)
+	wantRoot, err := HashTreeRoot(holder)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	calls := 0
+	typ := reflect.TypeOf(codecCheckpoint{})
+	err = RegisterCodec(typ, &Codec{
+		MarshalTo: func(val interface{}, dst []byte) ([]byte, error) {
+			calls++
+			c := val.(*codecCheckpoint)
+			dst = append(dst, make([]byte, 8)...)
+			binary.LittleEndian.PutUint64(dst[len(dst)-8:], c.Epoch)
+			return append(dst, c.Root[:]...), nil
+		},
+		Unmarshal: func(buf []byte, val interface{}) error {
+			calls++
+			c := val.(*codecCheckpoint)
+			c.Epoch = binary.LittleEndian.Uint64(buf)
+			copy(c.Root[:], buf[8:])
+			return nil
+		},
+		HashTreeRoot: func(val interface{}) ([32]byte, error) {
+			calls++
+			c := val.(*codecCheckpoint)
+			var epoch [32]byte
+			binary.LittleEndian.PutUint64(epoch[:], c.Epoch)
+			return HashPair(epoch, c.Root), nil
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := RegisterCodec(typ, nil); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	if enc := mustMarshal(t, holder); !bytes.Equal(enc, want) || calls != 3 {
+		t.Errorf("Wanted encoding %#x with 3 codec calls, received %#x with %d", want, enc, calls)
+	}
+	calls = 0
+	if root, err := HashTreeRoot(holder); err != nil || root != wantRoot || calls != 3 {
+		t.Errorf("Wanted root %#x with 3 codec calls, received %#x with %d: %v", wantRoot, root, calls, err)
+	}
+	calls = 0
+	if root, err := HashTreeRootWith(holder, &Hasher{}); err != nil || root != wantRoot || calls != 3 {
+		t.Errorf("Wanted root %#x with 3 codec calls, received %#x with %d: %v", wantRoot, root, calls, err)
+	}
+	calls = 0
+	decoded := &codecHolder{}
+	if err := Unmarshal(want, decoded); err != nil || !reflect.DeepEqual(decoded, holder) || calls != 3 {
+		t.Errorf("Wanted %+v with 3 codec calls, received %+v with %d: %v", holder, decoded, calls, err)
+	}
+
+	if err := RegisterCodec(reflect.TypeOf(&codecCheckpoint{}), &Codec{}); err == nil {
+		t.Error("Expected error registering a pointer type")
+	}
+	if err := RegisterCodec(typ, &Codec{}); err == nil {
+		t.Error("Expected error registering an incomplete codec")
+	}
+}
+
+type brokenBackend struct{}
+
+func (brokenBackend) Name() string {
+	return "broken"
+}
+
+func (brokenBackend) Sum256(data []byte) [32]byte {
+	return [32]byte{1}
+}
+
+func TestSetHashBackend(t *testing.T) {
+	prev := CurrentHashBackend()
+	defer func() {
+		if err := SetHashBackend(prev); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	want, err := HashTreeRoot(&fork{Epoch: 3})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"sha256", "sha256-simd", "auto"} {
+		b, err := HashBackendByName(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := SetHashBackend(b); err != nil {
+			t.Fatal(err)
+		}
+		if CurrentHashBackend() != b {
+			t.Errorf("Wanted backend %s, received %s", b.Name(), CurrentHashBackend().Name())
+		}
+		if root, err := HashTreeRoot(&fork{Epoch: 3}); err != nil || root != want {
+			t.Errorf("Wanted root %#x with backend %s, received %#x: %v", want, name, root, err)
+		}
+		if err := SelfTest(); err != nil {
+			t.Errorf("Self test failed with backend %s: %v", name, err)
+		}
+	}
+	if _, err := HashBackendByName("md5"); err == nil {
+		t.Error("Expected error for unknown backend")
+	}
+	if err := SetHashBackend(brokenBackend{}); err == nil {
+		t.Error("Expected error for broken backend")
+	}
+	if CurrentHashBackend().Name() == "broken" {
+		t.Error("Broken backend was installed")
+	}
+}
+
+func TestSetTreeHash(t *testing.T) {
+	prev := CurrentHashBackend()
+	defer func() {
+		if err := SetHashBackend(prev); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	f := &fork{PreviousVersion: [4]byte{1}, CurrentVersion: [4]byte{2}, Epoch: 3}
+	sha256Root, err := HashTreeRoot(f)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum := func(data []byte) [32]byte {
+		return sha512.Sum512_256(data)
+	}
+	if err := SetTreeHash(NewHashBackend("sha512/256", sum)); err != nil {
+		t.Fatal(err)
+	}
+	pair := func(a, b [32]byte) [32]byte {
+		return sum(append(a[:], b[:]...))
+	}
+	var prevChunk, curChunk, epochChunk [32]byte
+	prevChunk[0], curChunk[0], epochChunk[0] = 1, 2, 3
+	want := pair(pair(prevChunk, curChunk), pair(epochChunk, [32]byte{}))
+	for _, hash := range []func() ([32]byte, error){
+		func() ([32]byte, error) { return HashTreeRoot(f) },
+		func() ([32]byte, error) { return HashTreeRootWith(f, &Hasher{}) },
+	} {
+		if root, err := hash(); err != nil || root != want {
+			t.Errorf("Wanted root %#x, received %#x: %v", want, root, err)
+		}
+	}
+	if z := ZeroHash(1); z != pair([32]byte{}, [32]byte{}) {
+		t.Errorf("Zero hashes were not computed with the tree hash: %#x", z)
+	}
+
+	if err := SetHashBackend(prev); err != nil {
+		t.Fatal(err)
+	}
+	if root, err := HashTreeRoot(f); err != nil || root != sha256Root {
+		t.Errorf("Wanted sha256 root %#x, received %#x: %v", sha256Root, root, err)
+	}
+}
@@ -0,0 +1,119 @@
+package sszcli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/golang/snappy"
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/sszjson"
+)
+
+func genVectorsFlags(flags *flag.FlagSet) {
+	flags.Uint64("count", 10, "number of cases per type")
+	flags.Int64("seed", 0, "seed of the first case, incremented for each case")
+	flags.Uint64("max-list", ssz.DefaultRandomListLength, "maximum length of generated lists")
+}
+
+// runGenVectors writes random values of the given types, with their serialization and
+// root, in the layout of the ssz_static tests of the consensus specification:
+//
+//  dir/BeaconBlock/ssz_random/case_0/serialized.ssz_snappy
+//  dir/BeaconBlock/ssz_random/case_0/value.yaml
+//  dir/BeaconBlock/ssz_random/case_0/roots.yaml
+//
+// The value of case_i is generated from the seed given by --seed plus i, so that the
+// same vectors are generated again from the same arguments.
+func runGenVectors(flags *flag.FlagSet, stdin io.Reader, stdout io.Writer) error {
+	names := flags.Lookup("type").Value.String()
+	if names == "" {
+		return errors.New("--type is required")
+	}
+	if flags.NArg() != 1 {
+		return errors.New("expected an output directory")
+	}
+	count, err := strconv.ParseUint(flags.Lookup("count").Value.String(), 10, 64)
+	if err != nil {
+		return err
+	}
+	seed, err := strconv.ParseInt(flags.Lookup("seed").Value.String(), 10, 64)
+	if err != nil {
+		return err
+	}
+	maxList, err := strconv.ParseUint(flags.Lookup("max-list").Value.String(), 10, 64)
+	if err != nil {
+		return err
+	}
+	for _, name := range strings.Split(names, ",") {
+		val, err := newValue(name)
+		if err != nil {
+			return err
+		}
+		typ := reflect.TypeOf(val).Elem()
+		for i := uint64(0); i < count; i++ {
+			rng := rand.New(rand.NewSource(seed + int64(i)))
+			val, err := ssz.Random(typ, rng, ssz.WithMaxListLength(maxList))
+			if err != nil {
+				return err
+			}
+			dir := filepath.Join(flags.Arg(0), name, "ssz_random", fmt.Sprintf("case_%d", i))
+			root, err := writeVector(dir, val)
+			if err != nil {
+				return fmt.Errorf("could not write %s: %v", dir, err)
+			}
+			if _, err := fmt.Fprintf(stdout, "%s 0x%s\n", dir, hex.EncodeToString(root[:])); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// writeVector writes the serialization, value and root of val to the case directory
+// dir, and returns the root.
+func writeVector(dir string, val interface{}) ([32]byte, error) {
+	enc, err := ssz.Marshal(val)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	root, err := ssz.HashTreeRoot(val)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	data, err := sszjson.Marshal(val)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	// JSON is valid YAML, which spares a YAML encoder.
+	var value bytes.Buffer
+	if err := json.Indent(&value, data, "", "  "); err != nil {
+		return [32]byte{}, err
+	}
+	value.WriteByte('\n')
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return [32]byte{}, err
+	}
+	files := map[string][]byte{
+		"serialized.ssz_snappy": snappy.Encode(nil, enc),
+		"value.yaml":            value.Bytes(),
+		"roots.yaml":            []byte(fmt.Sprintf("{\"root\": \"0x%s\"}\n", hex.EncodeToString(root[:]))),
+	}
+	for name, content := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), content, 0644); err != nil {
+			return [32]byte{}, err
+		}
+	}
+	return root, nil
+}
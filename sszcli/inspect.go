@@ -0,0 +1,89 @@
+package sszcli
+
+import (
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"reflect"
+	"text/tabwriter"
+
+	"github.com/pkg/errors"
+	ssz "github.com/prysmaticlabs/go-ssz"
+)
+
+// maxValueBytes is the number of bytes of byte lists and vectors shown by inspect.
+const maxValueBytes = 8
+
+func runInspect(flags *flag.FlagSet, stdin io.Reader, stdout io.Writer) error {
+	val, data, err := input(flags, stdin)
+	if err != nil {
+		return err
+	}
+	typ := reflect.TypeOf(val).Elem()
+	if typ.Kind() != reflect.Struct {
+		return fmt.Errorf("cannot inspect %v, which is not a container", typ)
+	}
+	if err := ssz.Unmarshal(data, val); err != nil {
+		return err
+	}
+	ranges, err := ssz.FieldRanges(data, typ)
+	if err != nil {
+		return err
+	}
+	roots, err := ssz.FieldRoots(val)
+	if err != nil {
+		return err
+	}
+	root, err := ssz.HashTreeRoot(val)
+	if err != nil {
+		return err
+	}
+	w := tabwriter.NewWriter(stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintf(w, "type\t%s\n", typ)
+	fmt.Fprintf(w, "size\t%d bytes\n", len(data))
+	fmt.Fprintf(w, "root\t0x%s\n\n", hex.EncodeToString(root[:]))
+	fmt.Fprintln(w, "FIELD\tTYPE\tBYTES\tOFFSET\tROOT\tVALUE")
+	rval := reflect.ValueOf(val).Elem()
+	for i, r := range ranges {
+		field, _ := typ.FieldByName(r.Name)
+		offset := "-"
+		if r.Variable {
+			offset = fmt.Sprintf("@%d", r.OffsetPosition)
+		}
+		fmt.Fprintf(w, "%s\t%s\t[%d, %d)\t%s\t0x%s\t%s\n",
+			r.Name, field.Type, r.Start, r.End, offset, hex.EncodeToString(roots[i][:]), summary(rval.FieldByName(r.Name)))
+	}
+	return errors.Wrap(w.Flush(), "could not write fields")
+}
+
+// summary describes a field value in a few words: basic values are printed, byte lists
+// and vectors are printed in hex up to a few bytes, and other values by their length.
+func summary(val reflect.Value) string {
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return "nil"
+		}
+		val = val.Elem()
+	}
+	switch val.Kind() {
+	case reflect.Bool, reflect.Int32, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return fmt.Sprint(val.Interface())
+	case reflect.String:
+		return fmt.Sprintf("%q", val.String())
+	case reflect.Slice, reflect.Array:
+		if val.Type().Elem().Kind() != reflect.Uint8 {
+			return fmt.Sprintf("%d elements", val.Len())
+		}
+		b := make([]byte, val.Len())
+		reflect.Copy(reflect.ValueOf(b), val)
+		if len(b) > maxValueBytes {
+			return fmt.Sprintf("0x%s... (%d bytes)", hex.EncodeToString(b[:maxValueBytes]), len(b))
+		}
+		return "0x" + hex.EncodeToString(b)
+	case reflect.Struct:
+		return fmt.Sprintf("%d fields", val.NumField())
+	default:
+		return val.Kind().String()
+	}
+}
@@ -0,0 +1,248 @@
+// Package sszcli implements the ssz command, which hashes, encodes and decodes files
+// holding values of registered types. The types of a project are registered by a main
+// package of its own, which then runs the command:
+//
+//  func main() {
+//      sszcli.Register("BeaconState", (*pb.BeaconState)(nil))
+//      sszcli.Register("SignedBeaconBlock", (*pb.SignedBeaconBlock)(nil))
+//      if err := sszcli.Run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+//          fmt.Fprintf(os.Stderr, "ssz: %v\n", err)
+//          os.Exit(1)
+//      }
+//  }
+//
+// such that operators can check a genesis file with
+//
+//  ssz root --type BeaconState genesis.ssz
+//
+// and find the fields of a state whose roots differ between clients with
+//
+//  ssz inspect --type BeaconState state.ssz
+package sszcli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"sort"
+	"sync"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/sszjson"
+)
+
+var (
+	registryLock sync.RWMutex
+	registry     = make(map[string]reflect.Type)
+)
+
+// Register makes the type of val, usually a nil pointer to the type, available to the
+// command under the given name. Registering a name again replaces its type.
+func Register(name string, val interface{}) {
+	typ := reflect.TypeOf(val)
+	for typ != nil && typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	registryLock.Lock()
+	defer registryLock.Unlock()
+	registry[name] = typ
+}
+
+// Types returns the names of the registered types, sorted.
+func Types() []string {
+	registryLock.RLock()
+	defer registryLock.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// newValue returns a pointer to a new value of the type registered under name.
+func newValue(name string) (interface{}, error) {
+	registryLock.RLock()
+	typ, ok := registry[name]
+	registryLock.RUnlock()
+	if !ok || typ == nil {
+		return nil, fmt.Errorf("unknown type %q, run \"ssz types\" for the registered types", name)
+	}
+	return reflect.New(typ).Interface(), nil
+}
+
+type command struct {
+	usage string
+	run   func(flags *flag.FlagSet, stdin io.Reader, stdout io.Writer) error
+	// flags defines the flags of the command besides --type and -o.
+	flags func(flags *flag.FlagSet)
+}
+
+var commands = map[string]command{
+	"root": {
+		usage: "root --type T [file]: print the hash tree root of an SSZ-encoded value",
+		run:   runRoot,
+	},
+	"encode": {
+		usage: "encode --type T [file]: encode a JSON value to SSZ",
+		run:   runEncode,
+	},
+	"genvectors": {
+		usage: "genvectors --type T[,T...] [--count N] [--seed S] [--max-list L] dir: write random test vectors of the types to dir",
+		run:   runGenVectors,
+		flags: genVectorsFlags,
+	},
+	"decode": {
+		usage: "decode --type T [file]: print an SSZ-encoded value as JSON",
+		run:   runDecode,
+	},
+	"inspect": {
+		usage: "inspect --type T [file]: print the fields of an SSZ-encoded container with their byte ranges, offsets and roots",
+		run:   runInspect,
+	},
+	"types": {
+		usage: "types: list the registered types",
+		run:   runTypes,
+	},
+}
+
+// Run runs the command given by args, without the program name. Files default to
+// stdin, or are read from it when given as "-".
+func Run(args []string, stdin io.Reader, stdout io.Writer) error {
+	if len(args) == 0 {
+		return errors.New(usage())
+	}
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return fmt.Errorf("unknown command %q\n%s", args[0], usage())
+	}
+	flags := flag.NewFlagSet(args[0], flag.ContinueOnError)
+	flags.SetOutput(ioutil.Discard)
+	flags.String("type", "", "name of the registered type of the value")
+	flags.String("o", "", "output file, defaults to standard output")
+	if cmd.flags != nil {
+		cmd.flags(flags)
+	}
+	if err := flags.Parse(args[1:]); err != nil {
+		return fmt.Errorf("%v\nusage: ssz %s", err, cmd.usage)
+	}
+	if out := flags.Lookup("o").Value.String(); out != "" {
+		f, err := os.Create(out)
+		if err != nil {
+			return err
+		}
+		if err := cmd.run(flags, stdin, f); err != nil {
+			f.Close()
+			return err
+		}
+		return f.Close()
+	}
+	return cmd.run(flags, stdin, stdout)
+}
+
+func usage() string {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	s := "usage: ssz <command> [flags] [file]\ncommands:"
+	for _, name := range names {
+		s += "\n  " + commands[name].usage
+	}
+	return s
+}
+
+// input returns the registered value named by the type flag, along with the content of
+// the file given as argument.
+func input(flags *flag.FlagSet, stdin io.Reader) (interface{}, []byte, error) {
+	name := flags.Lookup("type").Value.String()
+	if name == "" {
+		return nil, nil, errors.New("--type is required")
+	}
+	val, err := newValue(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	if flags.NArg() > 1 {
+		return nil, nil, errors.New("expected a single file")
+	}
+	var data []byte
+	if flags.NArg() == 0 || flags.Arg(0) == "-" {
+		data, err = ioutil.ReadAll(stdin)
+	} else {
+		data, err = ioutil.ReadFile(flags.Arg(0))
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	return val, data, nil
+}
+
+func runRoot(flags *flag.FlagSet, stdin io.Reader, stdout io.Writer) error {
+	val, data, err := input(flags, stdin)
+	if err != nil {
+		return err
+	}
+	if err := ssz.Unmarshal(data, val); err != nil {
+		return err
+	}
+	root, err := ssz.HashTreeRoot(val)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "0x%s\n", hex.EncodeToString(root[:]))
+	return err
+}
+
+func runEncode(flags *flag.FlagSet, stdin io.Reader, stdout io.Writer) error {
+	val, data, err := input(flags, stdin)
+	if err != nil {
+		return err
+	}
+	if err := sszjson.Unmarshal(data, val); err != nil {
+		return err
+	}
+	enc, err := ssz.Marshal(val)
+	if err != nil {
+		return err
+	}
+	_, err = stdout.Write(enc)
+	return err
+}
+
+func runDecode(flags *flag.FlagSet, stdin io.Reader, stdout io.Writer) error {
+	val, data, err := input(flags, stdin)
+	if err != nil {
+		return err
+	}
+	if err := ssz.Unmarshal(data, val); err != nil {
+		return err
+	}
+	data, err = sszjson.Marshal(val)
+	if err != nil {
+		return err
+	}
+	var out bytes.Buffer
+	if err := json.Indent(&out, data, "", "  "); err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(stdout, "%s\n", out.Bytes())
+	return err
+}
+
+func runTypes(flags *flag.FlagSet, stdin io.Reader, stdout io.Writer) error {
+	for _, name := range Types() {
+		if _, err := fmt.Fprintln(stdout, name); err != nil {
+			return err
+		}
+	}
+	return nil
+}
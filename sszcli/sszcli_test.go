@@ -0,0 +1,181 @@
+package sszcli
+
+import (
+	"bytes"
+	"encoding/hex"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/sszvec"
+)
+
+type testCheckpoint struct {
+	Epoch uint64
+	Root  []byte `ssz-size:"32"`
+}
+
+func TestRun(t *testing.T) {
+	Register("Checkpoint", (*testCheckpoint)(nil))
+	cp := &testCheckpoint{Epoch: 7, Root: bytes.Repeat([]byte{0xab}, 32)}
+	enc, err := ssz.Marshal(cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := ssz.HashTreeRoot(cp)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir, err := ioutil.TempDir("", "sszcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	file := filepath.Join(dir, "checkpoint.ssz")
+	if err := ioutil.WriteFile(file, enc, 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	if err := Run([]string{"root", "--type", "Checkpoint", file}, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if want := "0x" + hex.EncodeToString(root[:]) + "\n"; out.String() != want {
+		t.Errorf("Wanted root %q, received %q", want, out.String())
+	}
+
+	out.Reset()
+	json := `{"epoch": "7", "root": "0x` + strings.Repeat("ab", 32) + `"}`
+	if err := Run([]string{"encode", "--type", "Checkpoint", "-"}, strings.NewReader(json), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(out.Bytes(), enc) {
+		t.Errorf("Wanted encoding %#x, received %#x", enc, out.Bytes())
+	}
+
+	out.Reset()
+	if err := Run([]string{"decode", "--type", "Checkpoint"}, bytes.NewReader(enc), &out); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(out.String(), `"epoch": "7"`) {
+		t.Errorf("Unexpected decoded value %s", out.String())
+	}
+
+	outFile := filepath.Join(dir, "root.txt")
+	if err := Run([]string{"root", "--type", "Checkpoint", "-o", outFile, file}, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if got, err := ioutil.ReadFile(outFile); err != nil || !strings.HasPrefix(string(got), "0x") {
+		t.Errorf("Expected the root written to the output file, received %q: %v", got, err)
+	}
+
+	out.Reset()
+	if err := Run([]string{"types"}, nil, &out); err != nil || !strings.Contains(out.String(), "Checkpoint\n") {
+		t.Errorf("Expected Checkpoint among the registered types, received %q: %v", out.String(), err)
+	}
+
+	errorCases := [][]string{
+		nil,
+		{"hash"},
+		{"root", file},
+		{"root", "--type", "Unknown", file},
+		{"root", "--type", "Checkpoint", filepath.Join(dir, "missing.ssz")},
+	}
+	for _, args := range errorCases {
+		if err := Run(args, nil, &out); err == nil {
+			t.Errorf("Run(%q): expected an error", args)
+		}
+	}
+}
+
+type testState struct {
+	Slot        uint64
+	Checkpoints []*testCheckpoint `ssz-max:"16"`
+	Graffiti    []byte            `ssz-max:"32"`
+}
+
+func TestInspect(t *testing.T) {
+	Register("State", (*testState)(nil))
+	state := &testState{
+		Slot:        12,
+		Checkpoints: []*testCheckpoint{{Epoch: 1, Root: make([]byte, 32)}},
+		Graffiti:    []byte("0123456789"),
+	}
+	enc, err := ssz.Marshal(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	roots, err := ssz.FieldRoots(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := Run([]string{"inspect", "--type", "State"}, bytes.NewReader(enc), &out); err != nil {
+		t.Fatal(err)
+	}
+	want := []string{
+		"size  66 bytes",
+		"[0, 8)    -",
+		"[16, 56)  @8",
+		"[56, 66)  @12",
+		"0x" + hex.EncodeToString(roots[1][:]),
+		"1 elements",
+		"0x3031323334353637... (10 bytes)",
+	}
+	for _, w := range want {
+		if !strings.Contains(out.String(), w) {
+			t.Errorf("Expected output to contain %q:\n%s", w, out.String())
+		}
+	}
+	if err := Run([]string{"inspect", "--type", "Bytes"}, bytes.NewReader(enc), &out); err == nil {
+		t.Error("Expected an error for an unregistered type")
+	}
+}
+
+func TestGenVectors(t *testing.T) {
+	Register("Checkpoint", (*testCheckpoint)(nil))
+	Register("State", (*testState)(nil))
+	dir, err := ioutil.TempDir("", "sszcli")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	var out bytes.Buffer
+	args := []string{"genvectors", "--type", "Checkpoint,State", "--count", "3", "--seed", "5", dir}
+	if err := Run(args, nil, &out); err != nil {
+		t.Fatal(err)
+	}
+	if lines := strings.Split(strings.TrimSpace(out.String()), "\n"); len(lines) != 6 {
+		t.Fatalf("Expected 6 cases, received %q", out.String())
+	}
+	for _, name := range []string{"Checkpoint", "State"} {
+		for _, cs := range []string{"case_0", "case_1", "case_2"} {
+			val, err := newValue(name)
+			if err != nil {
+				t.Fatal(err)
+			}
+			loaded, err := sszvec.LoadCase(filepath.Join(dir, name, "ssz_random", cs), val)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := loaded.Check(); err != nil {
+				t.Errorf("Case %s of %s does not check: %v", cs, name, err)
+			}
+		}
+	}
+
+	// The same arguments give the same vectors.
+	var again bytes.Buffer
+	if err := Run(args, nil, &again); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != out.String() {
+		t.Errorf("Expected the same roots, received %q and %q", out.String(), again.String())
+	}
+	if err := Run([]string{"genvectors", "--type", "Checkpoint"}, nil, &out); err == nil {
+		t.Error("Expected error without an output directory")
+	}
+}
@@ -0,0 +1,217 @@
+// Package sszjson converts between the JSON representation used by the consensus
+// specification and the Beacon API, and the Go types used for SSZ. Byte lists and
+// vectors are 0x-prefixed hex strings, unsigned integers, including types.Uint128 and
+// types.Uint256, are decimal strings, and container fields are keyed by their
+// snake_case names, or by the name given in their json tag when present.
+package sszjson
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Unmarshal decodes JSON data into the object pointed to by val.
+func Unmarshal(data []byte, val interface{}) error {
+	if val == nil {
+		return errors.New("cannot unmarshal into untyped, nil value")
+	}
+	rval := reflect.ValueOf(val)
+	if rval.Kind() != reflect.Ptr || rval.IsNil() {
+		return errors.New("can only unmarshal into a non-nil pointer target")
+	}
+	if err := decodeValue(json.RawMessage(data), rval.Elem(), rval.Elem().Type(), "$"); err != nil {
+		return errors.Wrapf(err, "could not unmarshal JSON into type: %v", rval.Elem().Type())
+	}
+	return nil
+}
+
+func decodeValue(data json.RawMessage, val reflect.Value, typ reflect.Type, path string) error {
+	if string(data) == "null" {
+		return nil
+	}
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			val.Set(reflect.New(typ.Elem()))
+		}
+		return decodeValue(data, val.Elem(), typ.Elem(), path)
+	case reflect.Bool:
+		var b bool
+		if err := json.Unmarshal(data, &b); err != nil {
+			return fmt.Errorf("%s: expected boolean: %v", path, err)
+		}
+		val.SetBool(b)
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := parseUint(data, typ.Bits())
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		val.SetUint(n)
+	case reflect.String:
+		var s string
+		if err := json.Unmarshal(data, &s); err != nil {
+			return fmt.Errorf("%s: expected string: %v", path, err)
+		}
+		val.SetString(s)
+	case reflect.Slice, reflect.Array:
+		if types.IsWideUint(typ) {
+			// Decimal strings are also accepted unquoted, as in YAML test vectors.
+			s := string(data)
+			if strings.HasPrefix(s, `"`) {
+				if err := json.Unmarshal(data, &s); err != nil {
+					return fmt.Errorf("%s: expected decimal string: %v", path, err)
+				}
+			}
+			if err := types.SetWideUint(val, s); err != nil {
+				return fmt.Errorf("%s: %v", path, err)
+			}
+			return nil
+		}
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return decodeBytes(data, val, typ, path)
+		}
+		return decodeSequence(data, val, typ, path)
+	case reflect.Struct:
+		return decodeStruct(data, val, typ, path)
+	default:
+		return fmt.Errorf("%s: unsupported kind: %v", path, typ.Kind())
+	}
+	return nil
+}
+
+// parseUint accepts both decimal strings, as mandated for uint64 values, and plain
+// JSON numbers.
+func parseUint(data json.RawMessage, bits int) (uint64, error) {
+	s := string(data)
+	if strings.HasPrefix(s, `"`) {
+		if err := json.Unmarshal(data, &s); err != nil {
+			return 0, err
+		}
+	}
+	n, err := strconv.ParseUint(s, 10, bits)
+	if err != nil {
+		return 0, fmt.Errorf("expected uint%d: %v", bits, err)
+	}
+	return n, nil
+}
+
+func decodeBytes(data json.RawMessage, val reflect.Value, typ reflect.Type, path string) error {
+	// Vectors of uint8 are given as arrays of numbers in the YAML of the spec tests.
+	if strings.HasPrefix(string(data), "[") {
+		return decodeSequence(data, val, typ, path)
+	}
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("%s: expected hex string: %v", path, err)
+	}
+	if !strings.HasPrefix(s, "0x") {
+		return fmt.Errorf("%s: hex string %q is missing the 0x prefix", path, s)
+	}
+	b, err := hex.DecodeString(s[2:])
+	if err != nil {
+		return fmt.Errorf("%s: %v", path, err)
+	}
+	if typ.Kind() == reflect.Array {
+		if len(b) != typ.Len() {
+			return fmt.Errorf("%s: expected %d bytes, received %d", path, typ.Len(), len(b))
+		}
+		if val.Kind() == reflect.Array {
+			reflect.Copy(val, reflect.ValueOf(b))
+			return nil
+		}
+	}
+	val.SetBytes(b)
+	return nil
+}
+
+func decodeSequence(data json.RawMessage, val reflect.Value, typ reflect.Type, path string) error {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return fmt.Errorf("%s: expected array: %v", path, err)
+	}
+	if typ.Kind() == reflect.Array && len(items) != typ.Len() {
+		return fmt.Errorf("%s: expected %d elements, received %d", path, typ.Len(), len(items))
+	}
+	if val.Kind() == reflect.Slice {
+		val.Set(reflect.MakeSlice(val.Type(), len(items), len(items)))
+	}
+	for i, item := range items {
+		if err := decodeValue(item, val.Index(i), val.Type().Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func decodeStruct(data json.RawMessage, val reflect.Value, typ reflect.Type, path string) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return fmt.Errorf("%s: expected object: %v", path, err)
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(field) || field.PkgPath != "" {
+			continue
+		}
+		raw, ok := fields[FieldName(field)]
+		if !ok {
+			continue
+		}
+		fType, err := types.FieldType(field)
+		if err != nil {
+			return err
+		}
+		if err := decodeValue(raw, val.Field(i), fieldValueType(field.Type, fType), path+"."+FieldName(field)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fieldValueType returns the type to decode a field as. Slices tagged with ssz-size
+// are decoded as slices whose length is checked against the tag.
+func fieldValueType(declared reflect.Type, sszType reflect.Type) reflect.Type {
+	if declared.Kind() == reflect.Slice && sszType.Kind() == reflect.Array && declared.Elem().Kind() == reflect.Uint8 {
+		return sszType
+	}
+	return declared
+}
+
+// FieldName returns the JSON key of a struct field: the name in its json tag if any,
+// and its name converted to snake_case otherwise.
+func FieldName(field reflect.StructField) string {
+	if tag, ok := field.Tag.Lookup("json"); ok {
+		name := strings.Split(tag, ",")[0]
+		if name != "" && name != "-" {
+			return name
+		}
+	}
+	return toSnakeCase(field.Name)
+}
+
+// toSnakeCase converts a Go identifier such as BLSToExecutionChanges into
+// bls_to_execution_changes.
+func toSnakeCase(name string) string {
+	runes := []rune(name)
+	var b strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				b.WriteByte('_')
+			}
+		}
+		b.WriteRune(unicode.ToLower(r))
+	}
+	return b.String()
+}
@@ -0,0 +1,146 @@
+package sszjson
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Marshal returns the JSON representation of val, following the same conventions as
+// Unmarshal: unsigned integers are decimal strings, byte lists and vectors are 0x-prefixed
+// hex strings, and container fields are keyed by their JSON names, in declaration order.
+// Nil pointers and vectors are encoded as the zero values they are serialized as:
+//
+//  data, err := sszjson.Marshal(block)
+//  if err != nil {
+//      return err
+//  }
+//  w.Header().Set("Content-Type", "application/json")
+//  w.Write(data)
+func Marshal(val interface{}) ([]byte, error) {
+	if val == nil {
+		return nil, errors.New("untyped-value nil cannot be marshaled")
+	}
+	rval := reflect.ValueOf(val)
+	var buf bytes.Buffer
+	if err := encodeValue(&buf, rval, rval.Type(), "$"); err != nil {
+		return nil, errors.Wrapf(err, "could not marshal JSON for type: %v", rval.Type())
+	}
+	return buf.Bytes(), nil
+}
+
+// encodeValue writes val as the SSZ type typ, which differs from the type of val for
+// slices holding vectors.
+func encodeValue(buf *bytes.Buffer, val reflect.Value, typ reflect.Type, path string) error {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			val = reflect.New(typ.Elem())
+		}
+		return encodeValue(buf, val.Elem(), typ.Elem(), path)
+	case reflect.Bool:
+		buf.WriteString(strconv.FormatBool(val.Bool()))
+	case reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		buf.WriteByte('"')
+		buf.WriteString(strconv.FormatUint(val.Uint(), 10))
+		buf.WriteByte('"')
+	case reflect.String:
+		s, err := json.Marshal(val.String())
+		if err != nil {
+			return fmt.Errorf("%s: %v", path, err)
+		}
+		buf.Write(s)
+	case reflect.Slice, reflect.Array:
+		if types.IsWideUint(val.Type()) {
+			s, ok := val.Interface().(fmt.Stringer)
+			if !ok {
+				return fmt.Errorf("%s: unsupported wide integer %v", path, val.Type())
+			}
+			buf.WriteString(strconv.Quote(s.String()))
+			return nil
+		}
+		if typ.Elem().Kind() == reflect.Uint8 {
+			return encodeBytes(buf, val, typ, path)
+		}
+		return encodeSequence(buf, val, typ, path)
+	case reflect.Struct:
+		return encodeStruct(buf, val, typ, path)
+	default:
+		return fmt.Errorf("%s: unsupported kind: %v", path, typ.Kind())
+	}
+	return nil
+}
+
+func encodeBytes(buf *bytes.Buffer, val reflect.Value, typ reflect.Type, path string) error {
+	n := val.Len()
+	if typ.Kind() == reflect.Array {
+		if n != 0 && n != typ.Len() {
+			return fmt.Errorf("%s: expected %d bytes, received %d", path, typ.Len(), n)
+		}
+		n = typ.Len()
+	}
+	b := make([]byte, n)
+	reflect.Copy(reflect.ValueOf(b), val)
+	buf.WriteString(`"0x`)
+	buf.WriteString(hex.EncodeToString(b))
+	buf.WriteByte('"')
+	return nil
+}
+
+func encodeSequence(buf *bytes.Buffer, val reflect.Value, typ reflect.Type, path string) error {
+	n := val.Len()
+	if typ.Kind() == reflect.Array {
+		if n != 0 && n != typ.Len() {
+			return fmt.Errorf("%s: expected %d elements, received %d", path, typ.Len(), n)
+		}
+		n = typ.Len()
+	}
+	buf.WriteByte('[')
+	for i := 0; i < n; i++ {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		elem := reflect.New(val.Type().Elem()).Elem()
+		if i < val.Len() {
+			elem = val.Index(i)
+		}
+		if err := encodeValue(buf, elem, typ.Elem(), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte(']')
+	return nil
+}
+
+func encodeStruct(buf *bytes.Buffer, val reflect.Value, typ reflect.Type, path string) error {
+	buf.WriteByte('{')
+	first := true
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if types.SkipField(field) || field.PkgPath != "" {
+			continue
+		}
+		fType, err := types.FieldType(field)
+		if err != nil {
+			return err
+		}
+		if !first {
+			buf.WriteByte(',')
+		}
+		first = false
+		buf.WriteString(strconv.Quote(FieldName(field)))
+		buf.WriteByte(':')
+		if err := encodeValue(buf, val.Field(i), fType, path+"."+FieldName(field)); err != nil {
+			return err
+		}
+	}
+	buf.WriteByte('}')
+	return nil
+}
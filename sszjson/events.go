@@ -0,0 +1,119 @@
+package sszjson
+
+import (
+	"bufio"
+	"bytes"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Topics of the Beacon API event stream with payload types defined in this package.
+const (
+	HeadTopic                = "head"
+	FinalizedCheckpointTopic = "finalized_checkpoint"
+)
+
+// HeadEvent is the payload of the head topic of the Beacon API event stream.
+type HeadEvent struct {
+	Slot                      uint64
+	Block                     []byte `ssz-size:"32"`
+	State                     []byte `ssz-size:"32"`
+	EpochTransition           bool
+	PreviousDutyDependentRoot []byte `ssz-size:"32"`
+	CurrentDutyDependentRoot  []byte `ssz-size:"32"`
+	ExecutionOptimistic       bool
+}
+
+// FinalizedCheckpointEvent is the payload of the finalized_checkpoint topic of the
+// Beacon API event stream.
+type FinalizedCheckpointEvent struct {
+	Block               []byte `ssz-size:"32"`
+	State               []byte `ssz-size:"32"`
+	Epoch               uint64
+	ExecutionOptimistic bool
+}
+
+// Event is a single server-sent event read from the Beacon API event stream.
+type Event struct {
+	Topic string
+	Data  []byte
+}
+
+// Decode unmarshals the JSON payload of the event into the object pointed to by val.
+func (e *Event) Decode(val interface{}) error {
+	return Unmarshal(e.Data, val)
+}
+
+// EventReader reads server-sent events from a Beacon API event stream.
+//
+//  resp, err := http.Get(node + "/eth/v1/events?topics=head")
+//  if err != nil {
+//      return err
+//  }
+//  events := sszjson.NewEventReader(resp.Body)
+//  for {
+//      ev, err := events.Next()
+//      if err != nil {
+//          return err
+//      }
+//      head := &sszjson.HeadEvent{}
+//      if err := ev.Decode(head); err != nil {
+//          return err
+//      }
+//  }
+type EventReader struct {
+	r *bufio.Reader
+}
+
+// NewEventReader returns a reader of the events sent over r.
+func NewEventReader(r io.Reader) *EventReader {
+	return &EventReader{r: bufio.NewReader(r)}
+}
+
+// Next blocks until a complete event is received and returns it. Comments and fields
+// other than event and data are skipped. It returns io.EOF once the stream ends.
+func (e *EventReader) Next() (*Event, error) {
+	ev := &Event{}
+	var data bytes.Buffer
+	hasData := false
+	for {
+		line, err := e.r.ReadString('\n')
+		if err != nil && (err != io.EOF || line == "") {
+			if err == io.EOF && hasData {
+				break
+			}
+			if err == io.EOF {
+				return nil, io.EOF
+			}
+			return nil, errors.Wrap(err, "could not read event stream")
+		}
+		line = strings.TrimRight(line, "\r\n")
+		if line == "" {
+			if hasData {
+				break
+			}
+			continue
+		}
+		if strings.HasPrefix(line, ":") {
+			continue
+		}
+		name, value := line, ""
+		if i := strings.Index(line, ":"); i >= 0 {
+			name, value = line[:i], strings.TrimPrefix(line[i+1:], " ")
+		}
+		switch name {
+		case "event":
+			ev.Topic = value
+		case "data":
+			if hasData {
+				data.WriteByte('\n')
+			}
+			data.WriteString(value)
+			hasData = true
+		}
+	}
+	ev.Data = data.Bytes()
+	return ev, nil
+}
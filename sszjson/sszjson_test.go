@@ -0,0 +1,175 @@
+package sszjson
+
+import (
+	"bytes"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+func TestUnmarshal_SpecConventions(t *testing.T) {
+	type checkpoint struct {
+		Epoch uint64
+		Root  [32]byte
+	}
+	type attestation struct {
+		AggregationBits bitfield.Bitlist
+		CommitteeIndex  uint16
+		Source          *checkpoint
+		Roots           [][]byte `ssz-size:"?,32"`
+		Renamed         []uint64 `json:"balances"`
+	}
+	data := []byte(`{
+		"aggregation_bits": "0x0b",
+		"committee_index": 7,
+		"source": {"epoch": "18446744073709551615", "root": "0x` + strings.Repeat("ab", 32) + `"},
+		"roots": ["0x01", "0x02"],
+		"balances": ["1", "2"],
+		"unknown": true
+	}`)
+	a := &attestation{}
+	if err := Unmarshal(data, a); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(a.AggregationBits, []byte{0x0b}) || a.CommitteeIndex != 7 {
+		t.Errorf("Unexpected decoded attestation %+v", a)
+	}
+	if a.Source.Epoch != 18446744073709551615 || a.Source.Root[31] != 0xab {
+		t.Errorf("Unexpected decoded checkpoint %+v", a.Source)
+	}
+	if len(a.Roots) != 2 || len(a.Renamed) != 2 || a.Renamed[1] != 2 {
+		t.Errorf("Unexpected decoded lists %+v", a)
+	}
+	if err := Unmarshal([]byte(`{"source": {"root": "0x01"}}`), &attestation{}); err == nil {
+		t.Error("Expected error for vector of the wrong length")
+	}
+	if err := Unmarshal([]byte(`{"committee_index": "65536"}`), &attestation{}); err == nil {
+		t.Error("Expected error for overflowing integer")
+	}
+	payload := &struct{ BaseFeePerGas types.Uint256 }{}
+	if err := Unmarshal([]byte(`{"base_fee_per_gas": "18446744073709551621"}`), payload); err != nil {
+		t.Fatal(err)
+	}
+	if payload.BaseFeePerGas[0] != 5 || payload.BaseFeePerGas[8] != 1 {
+		t.Errorf("Unexpected decoded base fee %v", payload.BaseFeePerGas)
+	}
+	vec := [3]uint8{}
+	if err := Unmarshal([]byte(`[1, 2, 255]`), &vec); err != nil {
+		t.Fatal(err)
+	}
+	if vec != [3]uint8{1, 2, 255} {
+		t.Errorf("Unexpected decoded vector %v", vec)
+	}
+}
+
+func TestMarshal_SpecConventions(t *testing.T) {
+	type checkpoint struct {
+		Epoch uint64
+		Root  []byte `ssz-size:"32"`
+	}
+	type block struct {
+		Slot        uint64
+		Slashed     bool
+		Source      *checkpoint
+		Roots       [][]byte `ssz-size:"2,4"`
+		Indices     []uint16 `json:"attesting_indices"`
+		Graffiti    string
+		BaseFee     types.Uint256
+		Bits        bitfield.Bitlist
+		XXX_unknown []byte
+	}
+	b := &block{
+		Slot:    18446744073709551615,
+		Slashed: true,
+		Roots:   [][]byte{{1, 2, 3, 4}, {5, 6, 7, 8}},
+		Indices: []uint16{3, 4},
+		Bits:    bitfield.Bitlist{0x0b},
+	}
+	b.BaseFee[0] = 5
+	data, err := Marshal(b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"slot":"18446744073709551615","slashed":true,` +
+		`"source":{"epoch":"0","root":"0x` + strings.Repeat("00", 32) + `"},` +
+		`"roots":["0x01020304","0x05060708"],"attesting_indices":["3","4"],` +
+		`"graffiti":"","base_fee":"5","bits":"0x0b"}`
+	if string(data) != want {
+		t.Errorf("Wanted %s, received %s", want, data)
+	}
+	decoded := &block{}
+	if err := Unmarshal(data, decoded); err != nil {
+		t.Fatal(err)
+	}
+	again, err := Marshal(decoded)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(again, data) {
+		t.Errorf("Round trip changed %s into %s", data, again)
+	}
+
+	b.Roots = b.Roots[:1]
+	if _, err := Marshal(b); err == nil {
+		t.Error("Expected an error for a vector of the wrong length")
+	}
+}
+
+func TestToSnakeCase(t *testing.T) {
+	tests := map[string]string{
+		"Slot":                  "slot",
+		"ParentRoot":            "parent_root",
+		"BLSToExecutionChanges": "bls_to_execution_changes",
+		"Eth1Data":              "eth1_data",
+	}
+	for in, want := range tests {
+		if got := toSnakeCase(in); got != want {
+			t.Errorf("toSnakeCase(%s): wanted %s, received %s", in, want, got)
+		}
+	}
+}
+
+func TestEventReader(t *testing.T) {
+	root := "0x" + strings.Repeat("cd", 32)
+	stream := ": keepalive\n\n" +
+		"event: head\n" +
+		`data: {"slot":"10", "block":"` + root + `", "state":"` + root + `", "epoch_transition":false,` +
+		`"previous_duty_dependent_root":"` + root + `", "current_duty_dependent_root":"` + root + `", "execution_optimistic": false}` + "\n\n" +
+		"event: finalized_checkpoint\r\n" +
+		`data: {"block":"` + root + `", "state":"` + root + `", "epoch":"2", "execution_optimistic": true}` + "\r\n"
+	events := NewEventReader(strings.NewReader(stream))
+	ev, err := events.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	head := &HeadEvent{}
+	if ev.Topic != HeadTopic {
+		t.Fatalf("Wanted topic %s, received %s", HeadTopic, ev.Topic)
+	}
+	if err := ev.Decode(head); err != nil {
+		t.Fatal(err)
+	}
+	if head.Slot != 10 || head.Block[0] != 0xcd {
+		t.Errorf("Unexpected head event %+v", head)
+	}
+	ev, err = events.Next()
+	if err != nil {
+		t.Fatal(err)
+	}
+	finalized := &FinalizedCheckpointEvent{}
+	if ev.Topic != FinalizedCheckpointTopic {
+		t.Fatalf("Wanted topic %s, received %s", FinalizedCheckpointTopic, ev.Topic)
+	}
+	if err := ev.Decode(finalized); err != nil {
+		t.Fatal(err)
+	}
+	if finalized.Epoch != 2 || !finalized.ExecutionOptimistic {
+		t.Errorf("Unexpected finalized checkpoint event %+v", finalized)
+	}
+	if _, err := events.Next(); err != io.EOF {
+		t.Errorf("Wanted io.EOF, received %v", err)
+	}
+}
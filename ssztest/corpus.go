@@ -0,0 +1,315 @@
+package ssztest
+
+// corpus holds objects spanning every fork, serialized and hashed by an independent
+// implementation of the specification. Entries must never be modified, only added.
+var corpus = []Entry{
+	{
+		Name:       "phase0/Fork",
+		New:        func() interface{} { return &fork{} },
+		Serialized: "a6e7943d328300396061b0c8d1000000",
+		Root:       "4e4a622c768549e96e0e87352b1fd3c10fc620c5634fc6c70e56b8ebb916c426",
+	},
+	{
+		Name:       "phase0/Checkpoint",
+		New:        func() interface{} { return &checkpoint{} },
+		Serialized: "539b26ad02000000494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b0",
+		Root:       "8bdace087f97b5753973166c0c219598e97536e088ed50df996ae9890756faad",
+	},
+	{
+		Name: "phase0/AttestationData",
+		New:  func() interface{} { return &attestationData{} },
+		Serialized: "006797de2b000000201ce6724e000000ac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f" +
+			"485942be300000006a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c354863debd00000000" +
+			"58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1b",
+		Root: "59afd04637d04b13cc84a4756901adacef09845506451db8bf0e1accafdd805d",
+	},
+	{
+		Name: "phase0/Attestation",
+		New:  func() interface{} { return &attestation{} },
+		Serialized: "e4000000b8cf82de120000007884221d8f000000d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7" +
+			"c4ade273e065b9d2fc0000002ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d42539089f09cfb22" +
+			"b1000000bc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd5223" +
+			"20d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3" +
+			"b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f20010241",
+		Root: "ac66e43465892739be12409eea3cb860b822ae94d746083545b40116c57b3edb",
+	},
+	{
+		Name: "phase0/Validator",
+		New:  func() interface{} { return &validator{} },
+		Serialized: "5a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c55" +
+			"6a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35389c67e37300000000576697a3a00000" +
+			"006b0d7737050100000f9a58a627000000b3b0c42587000000",
+		Root: "c62c7d1a8cb857f54306fccce833e22ac5b0f57edc8658375b941cb4fc340797",
+	},
+	{
+		Name: "phase0/DepositData",
+		New:  func() interface{} { return &depositData{} },
+		Serialized: "c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd83116" +
+			"97846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117693a9fbd4ca0000004d021350494e6f7c" +
+			"055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c" +
+			"556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704",
+		Root: "fbc7806c9f349a350b7713e3242ae0f9caca223262b7d0b08805d0e8938e5e58",
+	},
+	{
+		Name: "phase0/Eth1Data",
+		New:  func() interface{} { return &eth1Data{} },
+		Serialized: "345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7b8ebce785d010000328300397edf2cf5" +
+			"8afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d",
+		Root: "70fdd27f8e10a3f4a8c206e944b01c41fdfd9fc4c2a0b279ebde56be97c49a63",
+	},
+	{
+		Name: "phase0/BeaconBlockHeader",
+		New:  func() interface{} { return &beaconBlockHeader{} },
+		Serialized: "17db1dbe340000003baba0f747000000dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714" +
+			"bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a4" +
+			"0dc2d310090e2f3cc51a4b2841e627d4",
+		Root: "6a6b8e4b774b32ed7464d76b3ea701de658b33089ac94d7f5f581bc6d38dd4f5",
+	},
+	{
+		Name: "phase0/HistoricalBatch",
+		New:  func() interface{} { return &historicalBatch{} },
+		Serialized: "0e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a059" +
+			"1effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a9" +
+			"2ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f9" +
+			"3e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d02135049" +
+			"4e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e099" +
+			"5e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e9" +
+			"6e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d32830039" +
+			"7edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d42539089" +
+			"8eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d9" +
+			"9e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029" +
+			"ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079" +
+			"be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9" +
+			"ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019" +
+			"debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069" +
+			"ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9" +
+			"fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d31009" +
+			"0e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a059" +
+			"1effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a9" +
+			"2ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f9" +
+			"3e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d02135049" +
+			"4e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e099" +
+			"5e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e9" +
+			"6e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d32830039" +
+			"7edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d42539089" +
+			"8eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d9" +
+			"9e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029" +
+			"ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079" +
+			"be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9" +
+			"ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019" +
+			"debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069" +
+			"ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9" +
+			"fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d31009" +
+			"0e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a059" +
+			"1effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a9" +
+			"2ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f9" +
+			"3e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d02135049" +
+			"4e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e099" +
+			"5e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e9" +
+			"6e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d32830039" +
+			"7edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d42539089" +
+			"8eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d9" +
+			"9e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029" +
+			"ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079" +
+			"be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9" +
+			"ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019" +
+			"debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069" +
+			"ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9" +
+			"fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d31009" +
+			"0e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a059" +
+			"1effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a9" +
+			"2ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f9" +
+			"3e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d02135049" +
+			"4e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e099" +
+			"5e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e9" +
+			"6e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d32830039" +
+			"7edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d42539089" +
+			"8eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d9" +
+			"9e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029" +
+			"ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079" +
+			"be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9" +
+			"ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019" +
+			"debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069" +
+			"ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9" +
+			"fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d31009" +
+			"0e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a059" +
+			"1effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a9" +
+			"2ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f9" +
+			"3e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d02135049" +
+			"4e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e099" +
+			"5e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e9" +
+			"6e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d32830039" +
+			"7edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d42539089" +
+			"8eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d9" +
+			"9e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029" +
+			"ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079" +
+			"be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9" +
+			"ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019" +
+			"debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069" +
+			"ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9" +
+			"fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d31009" +
+			"0e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a059" +
+			"1effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a9" +
+			"2ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f9" +
+			"3e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d02135049" +
+			"4e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e099" +
+			"5e3f0c556a5bf8d13637a40dc2d31009",
+		Root: "c858af02fd39e865514ead92a269b1181ebc8bfaa823a13bb932ffb2b681df4d",
+	},
+	{
+		Name:       "phase0/Balances",
+		New:        func() interface{} { return &balances{} },
+		Serialized: "04000000307e13d374010000606e7d2336000000f8807f3403000000",
+		Root:       "a5cdc203fda2fbeafbabbc932c4c1d06e46a03071094134d04eb68d7f2b595a9",
+	},
+	{
+		Name: "altair/SyncAggregate",
+		New:  func() interface{} { return &syncAggregate{} },
+		Serialized: "e801a6e7943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb" +
+			"7851b6b7248d425390898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab" +
+			"08a1c687b4dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b" +
+			"98f1d657442d62f3b029ae4fdce5ba6b",
+		Root: "d14b2ffaee5398bbff3337c1e641fc275d225ce85941e4d32308cb78b6e32723",
+	},
+	{
+		Name: "altair/SyncCommitteeContribution",
+		New:  func() interface{} { return &syncCommitteeContribution{} },
+		Serialized: "d757733b0a0000005bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca93d8e026b4000000" +
+			"b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be8" +
+			"01a6e7943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb78" +
+			"51b6b7248d425390898eafbc459acba8",
+		Root: "f4a2df1e551a5b871d6e470edcdee2b0fc3aa210a4403cf7f09d1a3032a6463d",
+	},
+	{
+		Name: "bellatrix/ExecutionPayloadHeaderBellatrix",
+		New:  func() interface{} { return &executionPayloadHeaderBellatrix{} },
+		Serialized: "c2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345d" +
+			"d2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ad" +
+			"e27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754fd" +
+			"f243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d" +
+			"021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d" +
+			"12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed" +
+			"22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d" +
+			"328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d" +
+			"425390898eafbc459acba8c166a754fdf243c0f9c0067bb0210000004827afa328010000f8c5fa7e94000000b0fa88cf" +
+			"04000000180200009e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1" +
+			"d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841" +
+			"e627d47d72c3407997846da233f069ee8f1c25faab08a1c687b4dd522320d9",
+		Root: "d2fb8b3aa79a876eb9dd1daa8eaa2c9e6d228c92fe7db68adb7192f72ee7f33d",
+	},
+	{
+		Name:       "capella/Withdrawal",
+		New:        func() interface{} { return &withdrawal{} },
+		Serialized: "2b648acf44000000872b7442270000004b2841e627d47d72c34079be1f6c35ca3b58b196b396b61a0c000000",
+		Root:       "847ca703556ecb8356d063478d3db14320b7f926ba191a129620034bc77a2eb7",
+	},
+	{
+		Name: "capella/BLSToExecutionChange",
+		New:  func() interface{} { return &blsToExecutionChange{} },
+		Serialized: "284d8700f00000007a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d" +
+			"328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e1",
+		Root: "c98e2e796196741ab3ab164a813ebffa5a8ea0e02378e72ff964add1fabe68fc",
+	},
+	{
+		Name: "capella/HistoricalSummary",
+		New:  func() interface{} { return &historicalSummary{} },
+		Serialized: "090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0" +
+			"591effcc152a1bb891f6f764cd8293d0",
+		Root: "a6f5ea07ebc325c347f97763a610c78d2f450c45e0515683ec02344c11b52810",
+	},
+	{
+		Name: "capella/ExecutionPayloadWithdrawals",
+		New:  func() interface{} { return &executionPayloadWithdrawals{} },
+		Serialized: "040000007be811530700000057dac9d0750000001350494e6f7c055a8b6881266714bdb20380b9fea3106c0288000000" +
+			"6f43f680ed0000008393454ec3000000f1d657442d62f3b029ae4fdce5ba6bc8618647745f151a6c2300000043e9dc75" +
+			"850100007fbdf04b050000003f0c556a5bf8d13637a40dc2d310090e2f3cc51a8b3ab3ad30000000",
+		Root: "c0b5d4b3a148011c0ba15c0939ed085dc50d6daf4412e28de64e505caac4c348",
+	},
+	{
+		Name:       "deneb/BlobIdentifier",
+		New:        func() interface{} { return &blobIdentifier{} },
+		Serialized: "e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72cb8dcbb89a000000",
+		Root:       "19345b6aaf8601fe3ad0e3efa4ab50cebc9b4ea4b78d326195f1924d32d20317",
+	},
+	{
+		Name: "deneb/ExecutionPayloadHeaderDeneb",
+		New:  func() interface{} { return &executionPayloadHeaderDeneb{} },
+		Serialized: "50494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3" +
+			"e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b3" +
+			"70e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d3283" +
+			"00397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d4253" +
+			"90898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd5223" +
+			"20d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3" +
+			"b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c3" +
+			"4079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293" +
+			"d0c9ceeffc85da0be801a6e7943d328300397edf28ac61a27200000028711f9d36000000b01dc9221c000000b01babe7" +
+			"6a000000480200002ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c1" +
+			"66a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b3811" +
+			"7677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029f0c32c68fb000000" +
+			"68fca3160e000000ade27330a9",
+		Root: "1931e9943119cf3c9d6148439ea0eaffa30dc42a3c5d468469ccfc5cece14dd3",
+	},
+	{
+		Name: "deneb/BlobKZGCommitments",
+		New:  func() interface{} { return &blobKZGCommitments{} },
+		Serialized: "04000000b20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d1" +
+			"3637a40dc2d310090e2f3cc51a4b2841e627d47d72c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b8821" +
+			"4607345d",
+		Root: "c47dc51640ae625840f3be27f6ad6a40a9521069d6f6046ef6bb35205ebde02f",
+	},
+	{
+		Name: "electra/DepositRequest",
+		New:  func() interface{} { return &depositRequest{} },
+		Serialized: "2a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c65" +
+			"3aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc457897a48259000000a8c166a754fdf243" +
+			"c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d0213" +
+			"50494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6b108d47c39a000000",
+		Root: "177141c1a987de9205e4942ae225a06dc0e13a9982fbcdbad6fe3c836f7a1faf",
+	},
+	{
+		Name: "electra/WithdrawalRequest",
+		New:  func() interface{} { return &withdrawalRequest{} },
+		Serialized: "97846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b688126" +
+			"6714bdb20380b9fe5fac750a7b98f1d657442d627bb2fb0a44000000",
+		Root: "4b380cf759e8024912173b31795332b5a6c41d8f6de542ff1bf009d2b21daa3e",
+	},
+	{
+		Name: "electra/ConsolidationRequest",
+		New:  func() interface{} { return &consolidationRequest{} },
+		Serialized: "04ed22b370e96e0f9ca57a2b88214607345dd2a3a0591effcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7" +
+			"943d328300397edf2cf58afb187156d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7" +
+			"248d425390898eafbc459acba8c166a754fdf243",
+		Root: "c9adecd85f1e6462da4d91e1ab062fa8464e951aace072aeae2eb4745934998d",
+	},
+	{
+		Name: "electra/ExecutionRequests",
+		New:  func() interface{} { return &executionRequests{} },
+		Serialized: "0c000000cc0000006401000056d7c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d" +
+			"425390898eafbc459acba8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a160111643" +
+			"2b010000b4dd522320d99e7f4c95aa9b38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b" +
+			"98f1d657442d62f3b029ae4fdce5ba6bc8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b" +
+			"2841e62778e593fa0e000000c34079be1f6c35ca3b58b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591e" +
+			"ffcc152a1bb891f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397ed3d83cecb2000000f58afb187156d7c4" +
+			"ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acba8c166a754" +
+			"fdf243c0f93e9fecb54abbd857a5eb2bfa000000846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b" +
+			"38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4fdce5ba6b" +
+			"c8618647749d12e3e0995e3f0c556a5bf8d13637a40dc2d310090e2f3cc51a4b2841e627d47d72c3",
+		Root: "9f09344dce04cd6ddeafb36e20da813c22e53ab8350dd60c83847a9be6d47475",
+	},
+	{
+		Name:       "electra/PendingPartialWithdrawal",
+		New:        func() interface{} { return &pendingPartialWithdrawal{} },
+		Serialized: "400fe35e01010000c8becde83a000000e830ff4e02000000",
+		Root:       "f5ad2e4e505be1d5e49d50d3ba7a6f2cf948f3aeb0fdd6ba9c7df94d27b25809",
+	},
+	{
+		Name: "electra/AttestationElectra",
+		New:  func() interface{} { return &attestationElectra{} },
+		Serialized: "ec000000281fa0c9170000002874f4cf0b00000058b1961704ed22b370e96e0f9ca57a2b88214607345dd2a3a0591eff" +
+			"cc152a1b3092c75f04000000f6f764cd8293d0c9ceeffc85da0be801a6e7943d328300397edf2cf58afb1871c056176e" +
+			"30000000c4ade27330a92ecf5c653aeb48e106c7f41d92636019debf8cd5eadb7851b6b7248d425390898eafbc459acb" +
+			"a8c166a754fdf243c0f93e9fecb54abbd8311697846da233f069ee8f1c25faab08a1c687b4dd522320d99e7f4c95aa9b" +
+			"38117677e44d021350494e6f7c055a8b6881266714bdb20380b9fe5fac750a7b98f1d657442d62f3b029ae4f0a1a",
+		Root: "47595f513620981c30cdfae9282310299b71c76554e86caa7c52341d5009e69b",
+	},
+}
@@ -0,0 +1,85 @@
+// Package ssztest provides a golden regression corpus of serialized objects and their
+// hash tree roots, spanning every fork from phase0 onwards. Alternative codecs and
+// rewrites of the hashing backend can be validated against known-good roots with a
+// single call in their tests:
+//
+//  func TestCodec(t *testing.T) {
+//      ssztest.CheckCorpus(t, myCodec)
+//  }
+package ssztest
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+)
+
+// Codec is the interface checked against the corpus.
+type Codec interface {
+	Marshal(val interface{}) ([]byte, error)
+	Unmarshal(data []byte, val interface{}) error
+	HashTreeRoot(val interface{}) ([32]byte, error)
+}
+
+// Entry is a single object of the corpus.
+type Entry struct {
+	// Name is the fork and container name of the object, such as "phase0/Checkpoint".
+	Name string
+	// New returns a pointer to an empty value of the object type.
+	New func() interface{}
+	// Serialized is the hex encoded serialization of the object.
+	Serialized string
+	// Root is the hex encoded hash tree root of the object.
+	Root string
+}
+
+type defaultCodec struct{}
+
+func (defaultCodec) Marshal(val interface{}) ([]byte, error)        { return ssz.Marshal(val) }
+func (defaultCodec) Unmarshal(data []byte, val interface{}) error   { return ssz.Unmarshal(data, val) }
+func (defaultCodec) HashTreeRoot(val interface{}) ([32]byte, error) { return ssz.HashTreeRoot(val) }
+
+// DefaultCodec is the reflection-based codec of the ssz package.
+var DefaultCodec Codec = defaultCodec{}
+
+// Corpus returns a copy of the entries of the corpus.
+func Corpus() []Entry {
+	entries := make([]Entry, len(corpus))
+	copy(entries, corpus)
+	return entries
+}
+
+// CheckCorpus decodes every object of the corpus with codec, then checks that
+// re-encoding it yields the same bytes and that its hash tree root matches the
+// expected one. Every entry is run as a subtest named after the entry.
+func CheckCorpus(t *testing.T, codec Codec) {
+	for _, entry := range corpus {
+		entry := entry
+		t.Run(entry.Name, func(t *testing.T) {
+			serialized, err := hex.DecodeString(entry.Serialized)
+			if err != nil {
+				t.Fatal(err)
+			}
+			val := entry.New()
+			if err := codec.Unmarshal(serialized, val); err != nil {
+				t.Fatalf("Failed to unmarshal: %v", err)
+			}
+			enc, err := codec.Marshal(val)
+			if err != nil {
+				t.Fatalf("Failed to marshal: %v", err)
+			}
+			if !bytes.Equal(enc, serialized) {
+				t.Errorf("Wanted serialization %#x, received %#x", serialized, enc)
+			}
+			root, err := codec.HashTreeRoot(val)
+			if err != nil {
+				t.Fatalf("Failed to compute root: %v", err)
+			}
+			if hex.EncodeToString(root[:]) != entry.Root {
+				t.Errorf("Wanted root %s, received %#x", entry.Root, root)
+			}
+		})
+	}
+}
@@ -0,0 +1,24 @@
+package ssztest
+
+import (
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+)
+
+type hasherCodec struct {
+	defaultCodec
+	h ssz.Hasher
+}
+
+func (c *hasherCodec) HashTreeRoot(val interface{}) ([32]byte, error) {
+	return ssz.HashTreeRootWith(val, &c.h)
+}
+
+func TestCheckCorpus_DefaultCodec(t *testing.T) {
+	CheckCorpus(t, DefaultCodec)
+}
+
+func TestCheckCorpus_Hasher(t *testing.T) {
+	CheckCorpus(t, &hasherCodec{})
+}
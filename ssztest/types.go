@@ -0,0 +1,194 @@
+package ssztest
+
+import (
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+// The containers below follow the consensus specification, with the minimal preset
+// used for vector lengths which would otherwise make the corpus large.
+
+type fork struct {
+	PreviousVersion []byte `ssz-size:"4"`
+	CurrentVersion  []byte `ssz-size:"4"`
+	Epoch           uint64
+}
+
+type checkpoint struct {
+	Epoch uint64
+	Root  []byte `ssz-size:"32"`
+}
+
+type attestationData struct {
+	Slot            uint64
+	Index           uint64
+	BeaconBlockRoot []byte `ssz-size:"32"`
+	Source          *checkpoint
+	Target          *checkpoint
+}
+
+type attestation struct {
+	AggregationBits bitfield.Bitlist `ssz-max:"2048"`
+	Data            *attestationData
+	Signature       []byte `ssz-size:"96"`
+}
+
+type validator struct {
+	Pubkey                     []byte `ssz-size:"48"`
+	WithdrawalCredentials      []byte `ssz-size:"32"`
+	EffectiveBalance           uint64
+	Slashed                    bool
+	ActivationEligibilityEpoch uint64
+	ActivationEpoch            uint64
+	ExitEpoch                  uint64
+	WithdrawableEpoch          uint64
+}
+
+type depositData struct {
+	Pubkey                []byte `ssz-size:"48"`
+	WithdrawalCredentials []byte `ssz-size:"32"`
+	Amount                uint64
+	Signature             []byte `ssz-size:"96"`
+}
+
+type eth1Data struct {
+	DepositRoot  []byte `ssz-size:"32"`
+	DepositCount uint64
+	BlockHash    []byte `ssz-size:"32"`
+}
+
+type beaconBlockHeader struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    []byte `ssz-size:"32"`
+	StateRoot     []byte `ssz-size:"32"`
+	BodyRoot      []byte `ssz-size:"32"`
+}
+
+type historicalBatch struct {
+	BlockRoots [][]byte `ssz-size:"64,32"`
+	StateRoots [][]byte `ssz-size:"64,32"`
+}
+
+type balances struct {
+	Balances []uint64 `ssz-max:"1099511627776"`
+}
+
+type syncAggregate struct {
+	SyncCommitteeBits      []byte `ssz-size:"64"`
+	SyncCommitteeSignature []byte `ssz-size:"96"`
+}
+
+type syncCommitteeContribution struct {
+	Slot              uint64
+	BeaconBlockRoot   []byte `ssz-size:"32"`
+	SubcommitteeIndex uint64
+	AggregationBits   []byte `ssz-size:"16"`
+	Signature         []byte `ssz-size:"96"`
+}
+
+type executionPayloadHeaderBellatrix struct {
+	ParentHash       []byte `ssz-size:"32"`
+	FeeRecipient     []byte `ssz-size:"20"`
+	StateRoot        []byte `ssz-size:"32"`
+	ReceiptsRoot     []byte `ssz-size:"32"`
+	LogsBloom        []byte `ssz-size:"256"`
+	PrevRandao       []byte `ssz-size:"32"`
+	BlockNumber      uint64
+	GasLimit         uint64
+	GasUsed          uint64
+	Timestamp        uint64
+	ExtraData        []byte `ssz-max:"32"`
+	BaseFeePerGas    []byte `ssz-size:"32"`
+	BlockHash        []byte `ssz-size:"32"`
+	TransactionsRoot []byte `ssz-size:"32"`
+}
+
+type withdrawal struct {
+	Index          uint64
+	ValidatorIndex uint64
+	Address        []byte `ssz-size:"20"`
+	Amount         uint64
+}
+
+type blsToExecutionChange struct {
+	ValidatorIndex     uint64
+	FromBlsPubkey      []byte `ssz-size:"48"`
+	ToExecutionAddress []byte `ssz-size:"20"`
+}
+
+type historicalSummary struct {
+	BlockSummaryRoot []byte `ssz-size:"32"`
+	StateSummaryRoot []byte `ssz-size:"32"`
+}
+
+type executionPayloadWithdrawals struct {
+	Withdrawals []*withdrawal `ssz-max:"16"`
+}
+
+type blobIdentifier struct {
+	BlockRoot []byte `ssz-size:"32"`
+	Index     uint64
+}
+
+type executionPayloadHeaderDeneb struct {
+	ParentHash       []byte `ssz-size:"32"`
+	FeeRecipient     []byte `ssz-size:"20"`
+	StateRoot        []byte `ssz-size:"32"`
+	ReceiptsRoot     []byte `ssz-size:"32"`
+	LogsBloom        []byte `ssz-size:"256"`
+	PrevRandao       []byte `ssz-size:"32"`
+	BlockNumber      uint64
+	GasLimit         uint64
+	GasUsed          uint64
+	Timestamp        uint64
+	ExtraData        []byte `ssz-max:"32"`
+	BaseFeePerGas    []byte `ssz-size:"32"`
+	BlockHash        []byte `ssz-size:"32"`
+	TransactionsRoot []byte `ssz-size:"32"`
+	WithdrawalsRoot  []byte `ssz-size:"32"`
+	BlobGasUsed      uint64
+	ExcessBlobGas    uint64
+}
+
+type blobKZGCommitments struct {
+	Commitments [][]byte `ssz-size:"?,48" ssz-max:"4096"`
+}
+
+type depositRequest struct {
+	Pubkey                []byte `ssz-size:"48"`
+	WithdrawalCredentials []byte `ssz-size:"32"`
+	Amount                uint64
+	Signature             []byte `ssz-size:"96"`
+	Index                 uint64
+}
+
+type withdrawalRequest struct {
+	SourceAddress   []byte `ssz-size:"20"`
+	ValidatorPubkey []byte `ssz-size:"48"`
+	Amount          uint64
+}
+
+type consolidationRequest struct {
+	SourceAddress []byte `ssz-size:"20"`
+	SourcePubkey  []byte `ssz-size:"48"`
+	TargetPubkey  []byte `ssz-size:"48"`
+}
+
+type executionRequests struct {
+	Deposits       []*depositRequest       `ssz-max:"8192"`
+	Withdrawals    []*withdrawalRequest    `ssz-max:"16"`
+	Consolidations []*consolidationRequest `ssz-max:"2"`
+}
+
+type pendingPartialWithdrawal struct {
+	ValidatorIndex    uint64
+	Amount            uint64
+	WithdrawableEpoch uint64
+}
+
+type attestationElectra struct {
+	AggregationBits bitfield.Bitlist `ssz-max:"131072"`
+	Data            *attestationData
+	Signature       []byte `ssz-size:"96"`
+	CommitteeBits   []byte `ssz-size:"8"`
+}
@@ -0,0 +1,193 @@
+// Package sszvec loads the test vectors of the consensus specification tests into Go
+// values, so that projects check their own types against the upstream vectors. A case
+// of the ssz_static tests is a directory holding the serialization of a value, the value
+// itself in YAML, and its root:
+//
+//  cs, err := sszvec.LoadCase("tests/mainnet/deneb/ssz_static/BeaconBlock/ssz_random/case_0", &pb.BeaconBlock{})
+//  if err != nil {
+//      return err
+//  }
+//  if err := cs.Check(); err != nil {
+//      return err
+//  }
+package sszvec
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"github.com/golang/snappy"
+	"github.com/pkg/errors"
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/sszjson"
+	"gopkg.in/yaml.v2"
+)
+
+// Unmarshal decodes a YAML value file into the object pointed to by val. Values follow
+// the conventions of sszjson, with unsigned integers also given as plain numbers.
+func Unmarshal(data []byte, val interface{}) error {
+	var v yamlValue
+	if err := yaml.Unmarshal(data, &v); err != nil {
+		return errors.Wrap(err, "could not parse YAML")
+	}
+	j, err := json.Marshal(&v)
+	if err != nil {
+		return errors.Wrap(err, "could not convert YAML to JSON")
+	}
+	return sszjson.Unmarshal(j, val)
+}
+
+// yamlValue holds a YAML value in the form encoding/json marshals. Integers too large
+// for 64 bits, which YAML parsers resolve to floats, keep their decimal text so that
+// uint128 and uint256 values reach sszjson exactly.
+type yamlValue struct {
+	v interface{}
+}
+
+// UnmarshalYAML decodes a mapping, a sequence or a scalar.
+func (y *yamlValue) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var m map[string]*yamlValue
+	if err := unmarshal(&m); err == nil {
+		y.v = m
+		return nil
+	}
+	var s []*yamlValue
+	if err := unmarshal(&s); err == nil {
+		y.v = s
+		return nil
+	}
+	if err := unmarshal(&y.v); err != nil {
+		return err
+	}
+	if _, ok := y.v.(float64); ok {
+		var text string
+		if err := unmarshal(&text); err != nil {
+			return err
+		}
+		if isDecimal(text) {
+			y.v = json.Number(text)
+		}
+	}
+	return nil
+}
+
+// MarshalJSON encodes the decoded value.
+func (y *yamlValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(y.v)
+}
+
+func isDecimal(text string) bool {
+	if text == "" {
+		return false
+	}
+	for _, c := range text {
+		if c < '0' || c > '9' {
+			return false
+		}
+	}
+	return true
+}
+
+// Case is a test case of the ssz_static or ssz_generic tests.
+type Case struct {
+	// Value is the value of the case, decoded from value.yaml.
+	Value interface{}
+	// Serialized is the serialization of the value, from serialized.ssz_snappy or
+	// serialized.ssz.
+	Serialized []byte
+	// Root is the hash tree root of the value, from roots.yaml.
+	Root [32]byte
+}
+
+// LoadCase loads the test case held in dir, decoding its value into the object pointed
+// to by val.
+func LoadCase(dir string, val interface{}) (*Case, error) {
+	serialized, err := ReadSerialized(dir)
+	if err != nil {
+		return nil, err
+	}
+	value, err := ioutil.ReadFile(filepath.Join(dir, "value.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	if err := Unmarshal(value, val); err != nil {
+		return nil, errors.Wrapf(err, "could not decode %s", filepath.Join(dir, "value.yaml"))
+	}
+	root, err := readRoot(filepath.Join(dir, "roots.yaml"))
+	if err != nil {
+		return nil, err
+	}
+	return &Case{Value: val, Serialized: serialized, Root: root}, nil
+}
+
+// ReadSerialized reads the serialization of the value of the case held in dir, which
+// recent releases of the tests compress with snappy.
+func ReadSerialized(dir string) ([]byte, error) {
+	data, err := ioutil.ReadFile(filepath.Join(dir, "serialized.ssz_snappy"))
+	if os.IsNotExist(err) {
+		return ioutil.ReadFile(filepath.Join(dir, "serialized.ssz"))
+	}
+	if err != nil {
+		return nil, err
+	}
+	dec, err := snappy.Decode(nil, data)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not decompress serialized value")
+	}
+	return dec, nil
+}
+
+func readRoot(file string) ([32]byte, error) {
+	data, err := ioutil.ReadFile(file)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	var roots struct {
+		Root string `yaml:"root"`
+	}
+	if err := yaml.Unmarshal(data, &roots); err != nil {
+		return [32]byte{}, errors.Wrapf(err, "could not decode %s", file)
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(roots.Root, "0x"))
+	if err != nil || len(b) != 32 {
+		return [32]byte{}, fmt.Errorf("invalid root %q in %s", roots.Root, file)
+	}
+	var root [32]byte
+	copy(root[:], b)
+	return root, nil
+}
+
+// Check verifies that the value of the case serializes to the serialization of the case,
+// that the serialization decodes back to the value, and that the value has the root of
+// the case.
+func (c *Case) Check() error {
+	enc, err := ssz.Marshal(c.Value)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal value")
+	}
+	if !bytes.Equal(enc, c.Serialized) {
+		return fmt.Errorf("value serializes to %#x, wanted %#x", enc, c.Serialized)
+	}
+	decoded := reflect.New(reflect.TypeOf(c.Value).Elem()).Interface()
+	if err := ssz.Unmarshal(c.Serialized, decoded); err != nil {
+		return errors.Wrap(err, "could not unmarshal serialization")
+	}
+	if !ssz.DeepEqual(decoded, c.Value) {
+		return errors.New("serialization decodes to a different value")
+	}
+	root, err := ssz.HashTreeRoot(c.Value)
+	if err != nil {
+		return errors.Wrap(err, "could not compute root")
+	}
+	if root != c.Root {
+		return fmt.Errorf("value has root %#x, wanted %#x", root, c.Root)
+	}
+	return nil
+}
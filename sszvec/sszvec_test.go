@@ -0,0 +1,122 @@
+package sszvec_test
+
+import (
+	"encoding/hex"
+	"io/ioutil"
+	"math/big"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/golang/snappy"
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/sszvec"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+type checkpoint struct {
+	Epoch uint64
+	Root  []byte `ssz-size:"32"`
+}
+
+type balance struct {
+	Amount types.Uint256
+}
+
+func writeCase(t *testing.T, dir string, files map[string][]byte) {
+	for name, data := range files {
+		if err := ioutil.WriteFile(filepath.Join(dir, name), data, 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestUnmarshal(t *testing.T) {
+	var b balance
+	if err := sszvec.Unmarshal([]byte(`{"amount": 340282366920938463463374607431768211456}`), &b); err != nil {
+		t.Fatal(err)
+	}
+	want, err := types.NewUint256(new(big.Int).Lsh(big.NewInt(1), 128))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b.Amount != want {
+		t.Errorf("decoded %s, wanted %s", b.Amount.String(), want.String())
+	}
+}
+
+func TestUnmarshal_Uint256(t *testing.T) {
+	type account struct {
+		Balances []types.Uint256 `ssz-max:"4"`
+		Nonce    uint64
+	}
+	max := "115792089237316195423570985008687907853269984665640564039457584007913129639935"
+	var a account
+	if err := sszvec.Unmarshal([]byte("balances:\n- "+max+"\n- 18446744073709551617\n- '5'\nnonce: 18446744073709551615\n"), &a); err != nil {
+		t.Fatal(err)
+	}
+	for i, want := range []string{max, "18446744073709551617", "5"} {
+		if len(a.Balances) != 3 || a.Balances[i].String() != want {
+			t.Fatalf("Balance %d: decoded %v, wanted %s", i, a.Balances, want)
+		}
+	}
+	if a.Nonce != 1<<64-1 {
+		t.Errorf("Decoded nonce %d, wanted %d", a.Nonce, uint64(1<<64-1))
+	}
+	if err := sszvec.Unmarshal([]byte("balances: [1"+max+"]\n"), &a); err == nil {
+		t.Error("Expected an error for a balance above 2^256")
+	}
+}
+
+func TestLoadCase(t *testing.T) {
+	want := &checkpoint{Epoch: 7, Root: make([]byte, 32)}
+	want.Root[0] = 0xaa
+	enc, err := ssz.Marshal(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root, err := ssz.HashTreeRoot(want)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value := []byte(`{"epoch": 7, "root": "0xaa00000000000000000000000000000000000000000000000000000000000000"}`)
+
+	dir, err := ioutil.TempDir("", "sszvec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	writeCase(t, dir, map[string][]byte{
+		"serialized.ssz_snappy": snappy.Encode(nil, enc),
+		"value.yaml":            value,
+		"roots.yaml":            []byte(`{"root": "0x` + hex.EncodeToString(root[:]) + `"}`),
+	})
+	cs, err := sszvec.LoadCase(dir, &checkpoint{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ssz.DeepEqual(cs.Value, want) {
+		t.Errorf("loaded %+v, wanted %+v", cs.Value, want)
+	}
+	if err := cs.Check(); err != nil {
+		t.Error(err)
+	}
+
+	// A case whose root does not match its value fails the check.
+	dir, err = ioutil.TempDir("", "sszvec")
+	if err != nil {
+		t.Fatal(err)
+	}
+	root[31] ^= 1
+	writeCase(t, dir, map[string][]byte{
+		"serialized.ssz": enc,
+		"value.yaml":     value,
+		"roots.yaml":     []byte(`{"root": "0x` + hex.EncodeToString(root[:]) + `"}`),
+	})
+	cs, err = sszvec.LoadCase(dir, &checkpoint{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cs.Check(); err == nil || !strings.Contains(err.Error(), "root") {
+		t.Errorf("expected root mismatch, received %v", err)
+	}
+}
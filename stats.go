@@ -0,0 +1,106 @@
+package ssz
+
+import (
+	"runtime"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Stats describes the work done by a single call to HashTreeRoot or Marshal.
+type Stats struct {
+	// BytesHashed is the number of bytes fed to the hash function.
+	BytesHashed uint64
+	// ChunksHashed is the number of 32-byte chunks fed to the hash function, which is
+	// twice the number of Merkle tree nodes hashed.
+	ChunksHashed uint64
+	// Allocations is the number of heap objects allocated.
+	Allocations uint64
+	// CacheHits is the number of roots served by the hash tree root caches rather than
+	// computed.
+	CacheHits uint64
+}
+
+// CacheStats returns the hit and miss counts of the hash tree root caches along with
+// estimates of their memory use, by cache and by struct type, so that caches can be
+// sized from production statistics:
+//
+//  for _, c := range ssz.CacheStats().Roots {
+//      log.WithFields(logrus.Fields{"hits": c.Hits, "misses": c.Misses, "bytes": c.Bytes}).Info(c.Name)
+//  }
+func CacheStats() types.CacheStats {
+	return types.ReadCacheStats()
+}
+
+// Option configures a single call to HashTreeRoot or Marshal.
+type Option func(*callConfig)
+
+type callConfig struct {
+	stats   *Stats
+	noCache bool
+}
+
+// WithStats fills s with the statistics of the call, so that its cost can be
+// attributed to the shape of the value without running a profiler:
+//
+//  var stats ssz.Stats
+//  root, err := ssz.HashTreeRoot(state, ssz.WithStats(&stats))
+//  if err != nil {
+//      return errors.Wrap(err, "could not compute root")
+//  }
+//  log.WithField("chunks", stats.ChunksHashed).Debug("Computed state root")
+//
+// Counters are process-wide while a call collecting statistics is in progress, so
+// work done concurrently by other goroutines is included. Collecting statistics stops
+// the world briefly to read the allocation count, and is best kept to sampled calls.
+func WithStats(s *Stats) Option {
+	return func(c *callConfig) {
+		c.stats = s
+	}
+}
+
+// NoCache computes the root of a single call to HashTreeRoot without reading or filling
+// the hash tree root caches, whatever the package-level option set by ToggleCache, such
+// as to hash a one-off value which would only evict useful entries:
+//
+//  root, err := ssz.HashTreeRoot(block, ssz.NoCache())
+//
+// Caching can be disabled for the fields of a given type with CacheFor.
+func NoCache() Option {
+	return func(c *callConfig) {
+		c.noCache = true
+	}
+}
+
+func newCallConfig(opts []Option) *callConfig {
+	c := &callConfig{}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// collect starts counting the work done by the call if statistics were requested, and
+// returns the function recording them once the call is done.
+func (c *callConfig) collect() func() {
+	if c.stats == nil {
+		return func() {}
+	}
+	var mem runtime.MemStats
+	hashing.StartCounting()
+	bytesBefore, chunksBefore := hashing.Counters()
+	hitsBefore := types.CacheHits()
+	runtime.ReadMemStats(&mem)
+	mallocsBefore := mem.Mallocs
+	return func() {
+		runtime.ReadMemStats(&mem)
+		bytesAfter, chunksAfter := hashing.Counters()
+		hashing.StopCounting()
+		*c.stats = Stats{
+			BytesHashed:  bytesAfter - bytesBefore,
+			ChunksHashed: chunksAfter - chunksBefore,
+			Allocations:  mem.Mallocs - mallocsBefore,
+			CacheHits:    types.CacheHits() - hitsBefore,
+		}
+	}
+}
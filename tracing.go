@@ -0,0 +1,103 @@
+package ssz
+
+import (
+	"reflect"
+	"sync/atomic"
+
+	"github.com/prysmaticlabs/go-ssz/types"
+)
+
+// Span describes an operation traced by a Tracer.
+type Span struct {
+	// Op is "marshal", "unmarshal" or "hash_tree_root".
+	Op string
+	// Type is the type of the value.
+	Type reflect.Type
+	// Field is the name of the field of a container whose root is computed, such as
+	// "Validators", in the spans nested within the span of the hash tree root of the
+	// container. It is empty otherwise.
+	Field string
+}
+
+// Tracer is notified of the start and end of operations, such as to record them as
+// OpenTelemetry spans, so that long hash tree root computations of large states can be
+// attributed to the fields which took the most time:
+//
+//  type otelTracer struct {
+//      tracer trace.Tracer
+//  }
+//
+//  func (t *otelTracer) Start(s ssz.Span) func(error) {
+//      name := "ssz." + s.Op
+//      if s.Field != "" {
+//          name += "." + s.Field
+//      }
+//      _, span := t.tracer.Start(context.Background(), name, trace.WithAttributes(
+//          attribute.String("ssz.type", s.Type.String())))
+//      return func(err error) {
+//          if err != nil {
+//              span.RecordError(err)
+//          }
+//          span.End()
+//      }
+//  }
+//
+// Methods are called from the goroutines calling the package, and must be safe for
+// concurrent use.
+type Tracer interface {
+	// Start is called when an operation starts, and returns the function called with
+	// its error, if any, when it ends.
+	Start(span Span) func(err error)
+}
+
+// tracerHolder wraps the tracer, which may be nil, for storage in an atomic.Value.
+type tracerHolder struct {
+	t Tracer
+}
+
+var tracer atomic.Value
+
+func init() {
+	tracer.Store(tracerHolder{})
+}
+
+// SetTracer sets the tracer of the operations of the package, or removes it if t is nil.
+func SetTracer(t Tracer) {
+	tracer.Store(tracerHolder{t: t})
+}
+
+func currentTracer() Tracer {
+	return tracer.Load().(tracerHolder).t
+}
+
+// startSpan starts tracing an operation on a value of type typ, and returns the function
+// ending it, which does nothing if no tracer is set.
+func startSpan(op string, typ reflect.Type) func(error) {
+	t := currentTracer()
+	if t == nil {
+		return func(error) {}
+	}
+	return t.Start(Span{Op: op, Type: typ})
+}
+
+// tracedRoot computes the root of a container with a span for each of its fields, if
+// a tracer is set. It returns false for values other than containers.
+func tracedRoot(val interface{}) ([32]byte, bool, error) {
+	t := currentTracer()
+	if t == nil {
+		return [32]byte{}, false, nil
+	}
+	rval := reflect.ValueOf(val)
+	typ := rval.Type()
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	factory, err := types.SSZFactory(reflect.New(typ).Elem(), typ)
+	if err != nil || factory != types.SSZAble(types.StructFactory) {
+		return [32]byte{}, false, nil
+	}
+	root, err := types.StructFactory.TracedRoot(rval, rval.Type(), func(field reflect.StructField) func(error) {
+		return t.Start(Span{Op: "hash_tree_root", Type: field.Type, Field: field.Name})
+	})
+	return root, true, err
+}
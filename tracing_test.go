@@ -0,0 +1,75 @@
+package ssz
+
+import (
+	"reflect"
+	"sync"
+	"testing"
+)
+
+type recordingTracer struct {
+	lock  sync.Mutex
+	spans []Span
+	errs  []error
+}
+
+func (r *recordingTracer) Start(s Span) func(error) {
+	return func(err error) {
+		r.lock.Lock()
+		defer r.lock.Unlock()
+		r.spans = append(r.spans, s)
+		r.errs = append(r.errs, err)
+	}
+}
+
+func TestSetTracer(t *testing.T) {
+	type traced struct {
+		Slot     uint64
+		Balances []uint64 `ssz-max:"16"`
+		Fork     *fork
+	}
+	val := &traced{Slot: 1, Balances: []uint64{2, 3}, Fork: &fork{Epoch: 4}}
+	want, err := HashTreeRoot(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := &recordingTracer{}
+	SetTracer(r)
+	defer SetTracer(nil)
+	got, err := HashTreeRoot(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+	var fields []string
+	for _, s := range r.spans {
+		if s.Op != "hash_tree_root" {
+			t.Errorf("Unexpected span %+v", s)
+		}
+		fields = append(fields, s.Field)
+	}
+	// Field spans end before the span of the container.
+	if !reflect.DeepEqual(fields, []string{"Slot", "Balances", "Fork", ""}) {
+		t.Errorf("Unexpected spans of fields %q", fields)
+	}
+	if r.spans[3].Type != reflect.TypeOf(val) {
+		t.Errorf("Unexpected type %v of container span", r.spans[3].Type)
+	}
+
+	r.spans, r.errs = nil, nil
+	enc, err := Marshal(val)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := Unmarshal(enc[:4], &traced{}); err == nil {
+		t.Fatal("Expected error")
+	}
+	if len(r.spans) != 2 || r.spans[0].Op != "marshal" || r.spans[1].Op != "unmarshal" {
+		t.Fatalf("Unexpected spans %+v", r.spans)
+	}
+	if r.errs[0] != nil || r.errs[1] == nil {
+		t.Errorf("Expected the error of the failed call only, received %v", r.errs)
+	}
+}
@@ -0,0 +1,166 @@
+package tree
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// Trees are encoded after a magic prefix and a version byte, node by node in pre-order.
+// Each node starts with a tag: a leaf is followed by its chunk, a branch by its root then
+// its left and right subtrees, and a zero subtree by its depth only. Roots of branches
+// are stored so that decoding a tree does not hash it again.
+var encodingMagic = []byte("SSZT")
+
+const encodingVersion = 1
+
+const (
+	tagLeaf byte = iota
+	tagBranch
+	tagZero
+)
+
+// Encode writes every node of the tree to w, so that a hashed tree, such as the one of
+// a finalized state, can be persisted and restored without hashing it again:
+//
+//  if err := node.Encode(f); err != nil {
+//      return errors.Wrap(err, "could not persist state tree")
+//  }
+func (n *Node) Encode(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	if _, err := bw.Write(encodingMagic); err != nil {
+		return err
+	}
+	if err := bw.WriteByte(encodingVersion); err != nil {
+		return err
+	}
+	if err := n.encode(bw); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+func (n *Node) encode(w *bufio.Writer) error {
+	if d, ok := zeroDepths[n]; ok {
+		_, err := w.Write([]byte{tagZero, d})
+		return err
+	}
+	tag := tagBranch
+	if n.IsLeaf() {
+		tag = tagLeaf
+	}
+	if err := w.WriteByte(tag); err != nil {
+		return err
+	}
+	if _, err := w.Write(n.root[:]); err != nil {
+		return err
+	}
+	if tag == tagLeaf {
+		return nil
+	}
+	if err := n.left.encode(w); err != nil {
+		return err
+	}
+	return n.right.encode(w)
+}
+
+// MarshalBinary encodes the tree as Encode does.
+func (n *Node) MarshalBinary() ([]byte, error) {
+	var buf bytes.Buffer
+	if err := n.Encode(&buf); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// Decode reads a tree written by Encode. The roots of branches are read rather than
+// computed, so a tree read from an untrusted source should be checked with Verify.
+func Decode(r io.Reader) (*Node, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, len(encodingMagic)+1)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return nil, fmt.Errorf("could not read tree header: %v", err)
+	}
+	if !bytes.Equal(header[:len(encodingMagic)], encodingMagic) {
+		return nil, errors.New("not an encoded tree")
+	}
+	if v := header[len(encodingMagic)]; v != encodingVersion {
+		return nil, fmt.Errorf("unsupported tree encoding version %d", v)
+	}
+	return decode(br, 0)
+}
+
+func decode(r *bufio.Reader, depth uint8) (*Node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("tree deeper than %d", maxDepth)
+	}
+	tag, err := r.ReadByte()
+	if err != nil {
+		return nil, unexpectedEOF(err)
+	}
+	switch tag {
+	case tagZero:
+		d, err := r.ReadByte()
+		if err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if d > maxDepth {
+			return nil, fmt.Errorf("zero subtree of depth %d exceeds the maximum of %d", d, maxDepth)
+		}
+		return ZeroNode(d), nil
+	case tagLeaf, tagBranch:
+		n := &Node{}
+		if _, err := io.ReadFull(r, n.root[:]); err != nil {
+			return nil, unexpectedEOF(err)
+		}
+		if tag == tagLeaf {
+			return n, nil
+		}
+		if n.left, err = decode(r, depth+1); err != nil {
+			return nil, err
+		}
+		if n.right, err = decode(r, depth+1); err != nil {
+			return nil, err
+		}
+		return n, nil
+	default:
+		return nil, fmt.Errorf("unknown node tag %d", tag)
+	}
+}
+
+// UnmarshalNode decodes a tree encoded by MarshalBinary.
+func UnmarshalNode(data []byte) (*Node, error) {
+	return Decode(bytes.NewReader(data))
+}
+
+// Verify hashes the tree again and returns an error if the root of any branch differs
+// from the hash of its children.
+func (n *Node) Verify() error {
+	if n.IsLeaf() {
+		return nil
+	}
+	if _, ok := zeroDepths[n]; ok {
+		return nil
+	}
+	if err := n.left.Verify(); err != nil {
+		return err
+	}
+	if err := n.right.Verify(); err != nil {
+		return err
+	}
+	if hashing.HashPair(n.left.root, n.right.root) != n.root {
+		return fmt.Errorf("branch root %#x does not match its children", n.root)
+	}
+	return nil
+}
+
+func unexpectedEOF(err error) error {
+	if err == io.EOF {
+		return io.ErrUnexpectedEOF
+	}
+	return err
+}
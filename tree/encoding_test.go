@@ -0,0 +1,81 @@
+package tree_test
+
+import (
+	"bytes"
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+func TestEncode_RoundTrip(t *testing.T) {
+	state := &viewState{
+		Slot:       7,
+		BlockRoots: make([][]byte, 4),
+		Validators: []*viewValidator{{Pubkey: make([]byte, 48), EffectiveBalance: 32}},
+		Balances:   []uint64{1, 2, 3},
+	}
+	node, err := ssz.BackingTree(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := node.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Zero subtrees padding lists to their limit are encoded by their depth alone.
+	if len(data) > 2048 {
+		t.Errorf("Encoded tree takes up %d bytes", len(data))
+	}
+	decoded, err := tree.UnmarshalNode(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Root() != node.Root() {
+		t.Errorf("Wanted root %#x, received %#x", node.Root(), decoded.Root())
+	}
+	if err := decoded.Verify(); err != nil {
+		t.Error(err)
+	}
+	view, err := tree.NewContainerView(decoded, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	balances, err := view.FieldList(3, 8, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := balances.Uint64(2); err != nil || b != 3 {
+		t.Errorf("Wanted balance 3, received %d: %v", b, err)
+	}
+}
+
+func TestDecode_Invalid(t *testing.T) {
+	node, err := tree.FromChunks([][32]byte{{1}, {2}, {3}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := node.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < len(data); i++ {
+		if _, err := tree.UnmarshalNode(data[:i]); err == nil {
+			t.Fatalf("Expected error decoding %d of %d bytes", i, len(data))
+		}
+	}
+	if _, err := tree.Decode(bytes.NewReader([]byte("nope!"))); err == nil {
+		t.Error("Expected error for missing magic")
+	}
+
+	// The root of the left child of the root is tampered with.
+	tampered := append([]byte(nil), data...)
+	tampered[5+1+32+1] ^= 1
+	decoded, err := tree.UnmarshalNode(tampered)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := decoded.Verify(); err == nil {
+		t.Error("Expected error verifying a tampered tree")
+	}
+}
@@ -0,0 +1,109 @@
+package tree
+
+import (
+	"math/bits"
+	"sort"
+)
+
+// Generalized indices number the nodes of a binary Merkle tree from the root, which is
+// 1, the children of node g being 2g and 2g+1. A node at depth d holds its index among
+// the nodes of its layer in the d lower bits of its generalized index, and 1 in bit d.
+
+// Parent returns the generalized index of the parent of a node.
+func Parent(gindex uint64) uint64 {
+	return gindex / 2
+}
+
+// LeftChild returns the generalized index of the left child of a node.
+func LeftChild(gindex uint64) uint64 {
+	return gindex * 2
+}
+
+// RightChild returns the generalized index of the right child of a node.
+func RightChild(gindex uint64) uint64 {
+	return gindex*2 + 1
+}
+
+// Sibling returns the generalized index of the other child of the parent of a node.
+func Sibling(gindex uint64) uint64 {
+	return gindex ^ 1
+}
+
+// GindexDepth returns the depth of a node below the root, that is, the position of the
+// most significant bit of its generalized index. This is get_generalized_index_length
+// from the consensus specification.
+func GindexDepth(gindex uint64) uint8 {
+	if gindex == 0 {
+		return 0
+	}
+	return uint8(bits.Len64(gindex) - 1)
+}
+
+// GindexAt returns the generalized index of the node at the given index of the layer at
+// the given depth, such as the leaf holding the chunk at that index in a tree of that
+// depth. Depths of 64 or more do not fit generalized indices and overflow.
+func GindexAt(depth uint8, index uint64) uint64 {
+	return uint64(1)<<depth | index
+}
+
+// DepthForLimit returns the depth of a tree with room for limit leaves, the limit being
+// rounded up to a power of two.
+func DepthForLimit(limit uint64) uint8 {
+	if limit <= 1 {
+		return 0
+	}
+	return uint8(bits.Len64(limit - 1))
+}
+
+// NextPowerOfTwo returns the smallest power of two which is greater than or equal to n,
+// and 1 for 0. This is get_power_of_two_ceil from the consensus specification. Values
+// over 1<<63 overflow to 0.
+func NextPowerOfTwo(n uint64) uint64 {
+	if n <= 1 {
+		return 1
+	}
+	return uint64(1) << bits.Len64(n-1)
+}
+
+// SubtreeRange returns the generalized indices of the first and last nodes, inclusive,
+// which are depth levels below a node, such as the leaves of the subtree holding a field
+// of a container.
+func SubtreeRange(gindex uint64, depth uint8) (first uint64, last uint64) {
+	first = gindex << depth
+	return first, first | (uint64(1)<<depth - 1)
+}
+
+// ConcatGindices returns the generalized index of a node given the generalized indices
+// of the path to it, each of them relative to the root of the subtree of the previous
+// one. This is concat_generalized_indices from the consensus specification.
+func ConcatGindices(gindices ...uint64) uint64 {
+	result := uint64(1)
+	for _, g := range gindices {
+		depth := GindexDepth(g)
+		result = result<<depth | g&(uint64(1)<<depth-1)
+	}
+	return result
+}
+
+// HelperIndices returns the generalized indices of the nodes needed to prove the nodes
+// at the given generalized indices, sorted in decreasing order. Nodes on the path of
+// another proven node are left out, since their roots can be computed from the leaves.
+// This is get_helper_indices from the consensus specification.
+func HelperIndices(indices []uint64) []uint64 {
+	helpers := make(map[uint64]bool)
+	paths := make(map[uint64]bool)
+	for _, g := range indices {
+		for ; g > 1; g /= 2 {
+			helpers[g^1] = true
+			paths[g] = true
+		}
+	}
+	result := make([]uint64, 0, len(helpers))
+	for g := range helpers {
+		if !paths[g] {
+			result = append(result, g)
+		}
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i] > result[j] })
+	return result
+}
@@ -0,0 +1,60 @@
+package tree_test
+
+import (
+	"testing"
+
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+func TestGindexMath(t *testing.T) {
+	// Node 6 is the third node at depth 2.
+	if p := tree.Parent(6); p != 3 {
+		t.Errorf("Wanted parent 3, received %d", p)
+	}
+	if l, r := tree.LeftChild(6), tree.RightChild(6); l != 12 || r != 13 {
+		t.Errorf("Wanted children 12 and 13, received %d and %d", l, r)
+	}
+	if s := tree.Sibling(6); s != 7 {
+		t.Errorf("Wanted sibling 7, received %d", s)
+	}
+	if d := tree.GindexDepth(6); d != 2 {
+		t.Errorf("Wanted depth 2, received %d", d)
+	}
+	if g := tree.GindexAt(2, 2); g != 6 {
+		t.Errorf("Wanted generalized index 6, received %d", g)
+	}
+	if first, last := tree.SubtreeRange(6, 3); first != 48 || last != 55 {
+		t.Errorf("Wanted range [48, 55], received [%d, %d]", first, last)
+	}
+	if first, last := tree.SubtreeRange(6, 0); first != 6 || last != 6 {
+		t.Errorf("Wanted range [6, 6], received [%d, %d]", first, last)
+	}
+	// The leaf of the chunk 5 of a list field at index 2 of a container of 4 fields.
+	if g := tree.ConcatGindices(tree.GindexAt(2, 2), 2, tree.GindexAt(3, 5)); g != 6<<4|5 {
+		t.Errorf("Wanted generalized index %d, received %d", 6<<4|5, g)
+	}
+}
+
+func TestDepthForLimit(t *testing.T) {
+	for _, tt := range []struct {
+		limit uint64
+		depth uint8
+		pow   uint64
+	}{
+		{0, 0, 1},
+		{1, 0, 1},
+		{2, 1, 2},
+		{3, 2, 4},
+		{4, 2, 4},
+		{5, 3, 8},
+		{1 << 40, 40, 1 << 40},
+		{1<<40 + 1, 41, 1 << 41},
+	} {
+		if d := tree.DepthForLimit(tt.limit); d != tt.depth {
+			t.Errorf("Limit %d: wanted depth %d, received %d", tt.limit, tt.depth, d)
+		}
+		if p := tree.NextPowerOfTwo(tt.limit); p != tt.pow {
+			t.Errorf("Limit %d: wanted power of two %d, received %d", tt.limit, tt.pow, p)
+		}
+	}
+}
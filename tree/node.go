@@ -0,0 +1,186 @@
+package tree
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// maxDepth is the deepest tree for which zero subtrees are precomputed.
+const maxDepth = 64
+
+var zeroNodes = make([]*Node, maxDepth+1)
+
+// zeroDepths maps the precomputed zero subtrees to their depth.
+var zeroDepths = make(map[*Node]uint8, maxDepth+1)
+
+func init() {
+	zeroNodes[0] = &Node{}
+	for i := 1; i <= maxDepth; i++ {
+		zeroNodes[i] = NewNode(zeroNodes[i-1], zeroNodes[i-1])
+	}
+	for i, n := range zeroNodes {
+		zeroDepths[n] = uint8(i)
+	}
+}
+
+// Node is an immutable node of a binary Merkle tree. Its root is computed once
+// upon construction, so a node can be shared between any number of trees and read
+// concurrently without locking. Modifying a tree returns a new root node which shares
+// every unchanged subtree with the original.
+type Node struct {
+	left  *Node
+	right *Node
+	root  [32]byte
+}
+
+// Leaf returns a node containing a single 32-byte chunk.
+func Leaf(chunk [32]byte) *Node {
+	return &Node{root: chunk}
+}
+
+// NewNode returns the parent node of the given left and right subtrees.
+func NewNode(left *Node, right *Node) *Node {
+	return &Node{
+		left:  left,
+		right: right,
+		root:  hashing.HashPair(left.root, right.root),
+	}
+}
+
+// ZeroNode returns the root node of a tree of the given depth whose leaves are all zero chunks.
+func ZeroNode(depth uint8) *Node {
+	return zeroNodes[depth]
+}
+
+// FromChunks builds a tree of the given depth with the chunks as its leftmost leaves,
+// right-padding the remaining leaves with zero chunks.
+func FromChunks(chunks [][32]byte, depth uint8) (*Node, error) {
+	leaves := make([]*Node, len(chunks))
+	for i := range chunks {
+		leaves[i] = Leaf(chunks[i])
+	}
+	return FromNodes(leaves, depth)
+}
+
+// FromNodes builds a tree of the given depth with the nodes as its leftmost subtrees
+// at that depth, right-padding the remaining positions with zero chunks.
+func FromNodes(nodes []*Node, depth uint8) (*Node, error) {
+	if depth > maxDepth {
+		return nil, fmt.Errorf("depth %d exceeds the maximum of %d", depth, maxDepth)
+	}
+	if uint64(len(nodes)) > uint64(1)<<depth && depth < maxDepth {
+		return nil, fmt.Errorf("%d nodes do not fit in a tree of depth %d", len(nodes), depth)
+	}
+	if len(nodes) == 0 {
+		return ZeroNode(depth), nil
+	}
+	layer := nodes
+	for d := uint8(0); d < depth; d++ {
+		next := make([]*Node, (len(layer)+1)/2)
+		for i := range next {
+			right := ZeroNode(d)
+			if 2*i+1 < len(layer) {
+				right = layer[2*i+1]
+			}
+			next[i] = NewNode(layer[2*i], right)
+		}
+		layer = next
+	}
+	return layer[0], nil
+}
+
+// Root returns the Merkle root of the subtree starting at this node.
+func (n *Node) Root() [32]byte {
+	return n.root
+}
+
+// IsLeaf returns true if the node has no children.
+func (n *Node) IsLeaf() bool {
+	return n.left == nil && n.right == nil
+}
+
+// Left returns the left child of the node, or nil for a leaf.
+func (n *Node) Left() *Node {
+	return n.left
+}
+
+// Right returns the right child of the node, or nil for a leaf.
+func (n *Node) Right() *Node {
+	return n.right
+}
+
+// Get returns the node at the given generalized index, relative to this node.
+func (n *Node) Get(gindex uint64) (*Node, error) {
+	if gindex == 0 {
+		return nil, errors.New("generalized index 0 is invalid")
+	}
+	node := n
+	for depth := GindexDepth(gindex); depth > 0; depth-- {
+		if node.IsLeaf() {
+			return nil, fmt.Errorf("generalized index %d navigates past a leaf", gindex)
+		}
+		if gindex&(uint64(1)<<(depth-1)) == 0 {
+			node = node.left
+		} else {
+			node = node.right
+		}
+	}
+	return node, nil
+}
+
+// Set returns a new tree with the node at the given generalized index replaced by v.
+// The receiver is left untouched, and every subtree off the modified path is shared
+// between the two trees.
+func (n *Node) Set(gindex uint64, v *Node) (*Node, error) {
+	if gindex == 0 {
+		return nil, errors.New("generalized index 0 is invalid")
+	}
+	return n.set(gindex, GindexDepth(gindex), v)
+}
+
+func (n *Node) set(gindex uint64, depth uint8, v *Node) (*Node, error) {
+	if depth == 0 {
+		return v, nil
+	}
+	if n.IsLeaf() {
+		return nil, fmt.Errorf("generalized index %d navigates past a leaf", gindex)
+	}
+	if gindex&(uint64(1)<<(depth-1)) == 0 {
+		left, err := n.left.set(gindex, depth-1, v)
+		if err != nil {
+			return nil, err
+		}
+		return NewNode(left, n.right), nil
+	}
+	right, err := n.right.set(gindex, depth-1, v)
+	if err != nil {
+		return nil, err
+	}
+	return NewNode(n.left, right), nil
+}
+
+// Branch returns the sibling roots along the path from the node at the given
+// generalized index up to this node, ordered from the bottom of the tree upwards.
+func (n *Node) Branch(gindex uint64) ([][32]byte, error) {
+	if gindex == 0 {
+		return nil, errors.New("generalized index 0 is invalid")
+	}
+	depth := GindexDepth(gindex)
+	branch := make([][32]byte, depth)
+	node := n
+	for d := depth; d > 0; d-- {
+		if node.IsLeaf() {
+			return nil, fmt.Errorf("generalized index %d navigates past a leaf", gindex)
+		}
+		if gindex&(uint64(1)<<(d-1)) == 0 {
+			branch[d-1] = node.right.root
+			node = node.left
+		} else {
+			branch[d-1] = node.left.root
+			node = node.right
+		}
+	}
+	return branch, nil
+}
@@ -0,0 +1,70 @@
+package tree
+
+import (
+	"errors"
+	"math/bits"
+)
+
+// FromNodesProgressive builds the progressive Merkle tree of EIP-7916 with the nodes as
+// its leaves. The tree is a chain of subtrees, each one holding four times as many
+// leaves as the previous one, such that its shape does not depend on a limit:
+//
+//          root
+//         /    \
+//       ...    [0]
+//      /   \
+//    ...   [1..4]
+//
+// Every node of the chain has the rest of the chain on its left, and the next subtree on
+// its right. An empty tree is a zero chunk.
+func FromNodesProgressive(nodes []*Node) (*Node, error) {
+	return fromNodesProgressive(nodes, 1)
+}
+
+func fromNodesProgressive(nodes []*Node, numLeaves uint64) (*Node, error) {
+	if len(nodes) == 0 {
+		return ZeroNode(0), nil
+	}
+	n := numLeaves
+	if uint64(len(nodes)) < n {
+		n = uint64(len(nodes))
+	}
+	// Subtrees hold 4^k leaves, so their depth is 2k.
+	subtree, err := FromNodes(nodes[:n], uint8(bits.Len64(numLeaves)-1))
+	if err != nil {
+		return nil, err
+	}
+	rest, err := fromNodesProgressive(nodes[n:], numLeaves*4)
+	if err != nil {
+		return nil, err
+	}
+	return NewNode(rest, subtree), nil
+}
+
+// FromChunksProgressive builds the progressive Merkle tree with the chunks as its leaves.
+func FromChunksProgressive(chunks [][32]byte) (*Node, error) {
+	leaves := make([]*Node, len(chunks))
+	for i := range chunks {
+		leaves[i] = Leaf(chunks[i])
+	}
+	return FromNodesProgressive(leaves)
+}
+
+// ProgressiveGindex returns the generalized index of the leaf at the given index of a
+// progressive Merkle tree, relative to the root of the tree.
+func ProgressiveGindex(index uint64) (uint64, error) {
+	start, numLeaves := uint64(0), uint64(1)
+	depth := 0
+	// The subtree is reached by going left once per previous subtree, then right, and
+	// the leaf lies 2*depth levels below, so the generalized index has 3*depth+2 bits.
+	for index-start >= numLeaves {
+		start += numLeaves
+		numLeaves *= 4
+		depth++
+		if 3*depth+2 > 64 {
+			return 0, errors.New("generalized index overflows uint64")
+		}
+	}
+	subtree := uint64(1)<<uint(depth+1) | 1
+	return subtree<<uint(2*depth) | (index - start), nil
+}
@@ -0,0 +1,110 @@
+package tree
+
+import (
+	"container/list"
+	"sync"
+	"time"
+)
+
+type proofKey struct {
+	root   [32]byte
+	gindex uint64
+}
+
+type proofEntry struct {
+	key     proofKey
+	branch  [][32]byte
+	expires time.Time
+}
+
+// ProofCache is a size and TTL bounded cache of Merkle branches keyed by the root of
+// the tree they were generated from and the generalized index they prove. Since a root
+// commits to the entire contents of a tree, cached branches never go stale, which makes
+// the cache well suited to servers repeatedly proving against the same finalized states.
+type ProofCache struct {
+	lock       sync.Mutex
+	maxEntries int
+	ttl        time.Duration
+	entries    map[proofKey]*list.Element
+	order      *list.List
+	now        func() time.Time
+}
+
+// NewProofCache returns a cache holding at most maxEntries branches, each evicted
+// after ttl has elapsed since it was stored. A zero ttl disables expiry.
+func NewProofCache(maxEntries int, ttl time.Duration) *ProofCache {
+	return &ProofCache{
+		maxEntries: maxEntries,
+		ttl:        ttl,
+		entries:    make(map[proofKey]*list.Element),
+		order:      list.New(),
+		now:        time.Now,
+	}
+}
+
+// Get returns the cached branch proving gindex against root, if any.
+func (c *ProofCache) Get(root [32]byte, gindex uint64) ([][32]byte, bool) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	elem, ok := c.entries[proofKey{root: root, gindex: gindex}]
+	if !ok {
+		return nil, false
+	}
+	entry := elem.Value.(*proofEntry)
+	if c.ttl > 0 && c.now().After(entry.expires) {
+		c.removeElement(elem)
+		return nil, false
+	}
+	c.order.MoveToFront(elem)
+	return entry.branch, true
+}
+
+// Put stores the branch proving gindex against root, evicting the least recently
+// used branch if the cache is full.
+func (c *ProofCache) Put(root [32]byte, gindex uint64, branch [][32]byte) {
+	if c.maxEntries <= 0 {
+		return
+	}
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	key := proofKey{root: root, gindex: gindex}
+	expires := c.now().Add(c.ttl)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*proofEntry)
+		entry.branch = branch
+		entry.expires = expires
+		c.order.MoveToFront(elem)
+		return
+	}
+	c.entries[key] = c.order.PushFront(&proofEntry{key: key, branch: branch, expires: expires})
+	for c.order.Len() > c.maxEntries {
+		c.removeElement(c.order.Back())
+	}
+}
+
+// Branch returns the branch proving gindex against the given tree, computing
+// and caching it on a miss.
+func (c *ProofCache) Branch(node *Node, gindex uint64) ([][32]byte, error) {
+	root := node.Root()
+	if branch, ok := c.Get(root, gindex); ok {
+		return branch, nil
+	}
+	branch, err := node.Branch(gindex)
+	if err != nil {
+		return nil, err
+	}
+	c.Put(root, gindex, branch)
+	return branch, nil
+}
+
+// Len returns the number of cached branches, including expired ones not yet evicted.
+func (c *ProofCache) Len() int {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	return c.order.Len()
+}
+
+func (c *ProofCache) removeElement(elem *list.Element) {
+	c.order.Remove(elem)
+	delete(c.entries, elem.Value.(*proofEntry).key)
+}
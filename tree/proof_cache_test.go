@@ -0,0 +1,67 @@
+package tree
+
+import (
+	"testing"
+	"time"
+)
+
+func TestProofCache_EvictsLeastRecentlyUsed(t *testing.T) {
+	c := NewProofCache(2, 0)
+	c.Put([32]byte{1}, 2, [][32]byte{{1}})
+	c.Put([32]byte{2}, 2, [][32]byte{{2}})
+	if _, ok := c.Get([32]byte{1}, 2); !ok {
+		t.Fatal("Expected cached branch")
+	}
+	c.Put([32]byte{3}, 2, [][32]byte{{3}})
+	if _, ok := c.Get([32]byte{2}, 2); ok {
+		t.Error("Expected least recently used branch to be evicted")
+	}
+	if _, ok := c.Get([32]byte{1}, 2); !ok {
+		t.Error("Expected recently used branch to be kept")
+	}
+	if c.Len() != 2 {
+		t.Errorf("Wanted 2 entries, received %d", c.Len())
+	}
+}
+
+func TestProofCache_Expiry(t *testing.T) {
+	now := time.Now()
+	c := NewProofCache(10, time.Minute)
+	c.now = func() time.Time { return now }
+	c.Put([32]byte{1}, 3, [][32]byte{{1}})
+	now = now.Add(30 * time.Second)
+	if _, ok := c.Get([32]byte{1}, 3); !ok {
+		t.Fatal("Expected branch to still be cached")
+	}
+	now = now.Add(time.Minute)
+	if _, ok := c.Get([32]byte{1}, 3); ok {
+		t.Error("Expected branch to have expired")
+	}
+	if c.Len() != 0 {
+		t.Errorf("Wanted expired entry to be removed, received %d entries", c.Len())
+	}
+}
+
+func TestProofCache_Branch(t *testing.T) {
+	node, err := FromChunks([][32]byte{{1}, {2}, {3}}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	c := NewProofCache(10, 0)
+	want, err := node.Branch(6)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 2; i++ {
+		branch, err := c.Branch(node, 6)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(branch) != len(want) || branch[0] != want[0] || branch[1] != want[1] {
+			t.Errorf("Wanted branch %v, received %v", want, branch)
+		}
+	}
+	if c.Len() != 1 {
+		t.Errorf("Wanted 1 entry, received %d", c.Len())
+	}
+}
@@ -0,0 +1,70 @@
+package tree
+
+import (
+	"sync"
+	"sync/atomic"
+)
+
+// Tree is a mutable handle to a persistent Merkle tree. Writers are serialized
+// against each other, while readers take O(1) snapshots of the current version
+// without blocking writers or each other.
+//
+//  t := tree.New(root)
+//  snapshot := t.Snapshot()
+//  go func() {
+//      // Roots and proofs computed here are consistent with one another,
+//      // regardless of concurrent calls to t.Set.
+//      r := snapshot.Root()
+//      branch, err := snapshot.Branch(gindex)
+//  }()
+//  if err := t.Set(gindex, tree.Leaf(chunk)); err != nil {
+//      return err
+//  }
+type Tree struct {
+	writeLock sync.Mutex
+	root      atomic.Value
+}
+
+// New returns a tree handle whose current version is root.
+func New(root *Node) *Tree {
+	t := &Tree{}
+	t.root.Store(root)
+	return t
+}
+
+// Snapshot returns the current version of the tree. Since nodes are immutable,
+// the returned node remains valid and unchanged while the tree is being modified.
+func (t *Tree) Snapshot() *Node {
+	return t.root.Load().(*Node)
+}
+
+// Root returns the Merkle root of the current version of the tree.
+func (t *Tree) Root() [32]byte {
+	return t.Snapshot().Root()
+}
+
+// Set replaces the node at the given generalized index, publishing a new version
+// of the tree. Previously taken snapshots are not affected.
+func (t *Tree) Set(gindex uint64, v *Node) error {
+	t.writeLock.Lock()
+	defer t.writeLock.Unlock()
+	newRoot, err := t.Snapshot().Set(gindex, v)
+	if err != nil {
+		return err
+	}
+	t.root.Store(newRoot)
+	return nil
+}
+
+// Update applies fn to the current version of the tree and publishes the result,
+// allowing several modifications to become visible to readers at once.
+func (t *Tree) Update(fn func(root *Node) (*Node, error)) error {
+	t.writeLock.Lock()
+	defer t.writeLock.Unlock()
+	newRoot, err := fn(t.Snapshot())
+	if err != nil {
+		return err
+	}
+	t.root.Store(newRoot)
+	return nil
+}
@@ -0,0 +1,121 @@
+package tree_test
+
+import (
+	"sync"
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+func TestFromChunks_MatchesHashTreeRoot(t *testing.T) {
+	var roots [8][32]byte
+	chunks := make([][32]byte, 5)
+	for i := range chunks {
+		chunks[i][0] = byte(i + 1)
+		roots[i] = chunks[i]
+	}
+	node, err := tree.FromChunks(chunks, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := ssz.HashTreeRoot(roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if node.Root() != want {
+		t.Errorf("Wanted root %#x, received %#x", want, node.Root())
+	}
+}
+
+func TestFromChunksProgressive_Gindices(t *testing.T) {
+	chunks := make([][32]byte, 22)
+	for i := range chunks {
+		chunks[i][0] = byte(i + 1)
+	}
+	node, err := tree.FromChunksProgressive(chunks)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := range chunks {
+		gindex, err := tree.ProgressiveGindex(uint64(i))
+		if err != nil {
+			t.Fatal(err)
+		}
+		leaf, err := node.Get(gindex)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if leaf.Root() != chunks[i] {
+			t.Errorf("Chunk %d is not at generalized index %d", i, gindex)
+		}
+	}
+	if _, err := tree.ProgressiveGindex(1 << 62); err == nil {
+		t.Error("Expected overflowing generalized index to fail")
+	}
+}
+
+func TestTree_SnapshotIsolation(t *testing.T) {
+	chunks := make([][32]byte, 4)
+	node, err := tree.FromChunks(chunks, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tree.New(node)
+	snapshot := tr.Snapshot()
+	before := snapshot.Root()
+
+	if err := tr.Set(5, tree.Leaf([32]byte{1})); err != nil {
+		t.Fatal(err)
+	}
+	if snapshot.Root() != before {
+		t.Error("Snapshot root changed after a write")
+	}
+	if tr.Root() == before {
+		t.Error("Expected tree root to change after a write")
+	}
+	leaf, err := snapshot.Get(5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if leaf.Root() != ([32]byte{}) {
+		t.Error("Snapshot observed a write made after it was taken")
+	}
+	// The untouched right subtree is shared between both versions.
+	if tr.Snapshot().Right() != snapshot.Right() {
+		t.Error("Expected unchanged subtree to be shared")
+	}
+}
+
+func TestTree_ConcurrentReadsAndWrites(t *testing.T) {
+	node, err := tree.FromChunks(make([][32]byte, 16), 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tr := tree.New(node)
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			if err := tr.Set(uint64(16+i%16), tree.Leaf([32]byte{byte(i)})); err != nil {
+				t.Error(err)
+			}
+		}
+	}()
+	go func() {
+		defer wg.Done()
+		for i := 0; i < 100; i++ {
+			snapshot := tr.Snapshot()
+			branch, err := snapshot.Branch(16)
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if len(branch) != 4 {
+				t.Errorf("Wanted branch of length 4, received %d", len(branch))
+			}
+		}
+	}()
+	wg.Wait()
+}
@@ -0,0 +1,512 @@
+package tree
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math/bits"
+)
+
+// Views read and modify SSZ values held in a Merkle tree, without converting them to and
+// from Go values. A view is a mutable handle to an immutable tree: modifying it replaces
+// its node by a new one sharing every unchanged subtree, so that re-rooting a value after
+// a change only hashes the path to the modified node.
+//
+//  state, err := tree.NewContainerView(node, 21)
+//  if err != nil {
+//      return err
+//  }
+//  balances, err := state.FieldList(12, 8, 1<<40)
+//  if err != nil {
+//      return err
+//  }
+//  if err := balances.SetUint64(5, 32000000000); err != nil {
+//      return err
+//  }
+//  root := state.Root()
+//
+// Views of fields and elements, such as balances above, write their changes through to
+// the view they were obtained from. Elements of basic types are described by their size
+// in bytes, 1, 2, 4 or 8, and composite elements, each held in its own subtree, by a
+// size of 0.
+type View interface {
+	// Node returns the current root node of the view.
+	Node() *Node
+	// Root returns the hash tree root of the value held by the view.
+	Root() [32]byte
+}
+
+// backing is the node of a view, along with the hook propagating its changes to the
+// view it was obtained from, if any.
+type backing struct {
+	node *Node
+	hook func(node *Node) error
+}
+
+// Node returns the current root node of the view.
+func (b *backing) Node() *Node {
+	return b.node
+}
+
+// Root returns the hash tree root of the value held by the view.
+func (b *backing) Root() [32]byte {
+	return b.node.Root()
+}
+
+func (b *backing) get(gindex uint64) (*Node, error) {
+	return b.node.Get(gindex)
+}
+
+func (b *backing) set(gindex uint64, v *Node) error {
+	node, err := b.node.Set(gindex, v)
+	if err != nil {
+		return err
+	}
+	if b.hook != nil {
+		if err := b.hook(node); err != nil {
+			return err
+		}
+	}
+	b.node = node
+	return nil
+}
+
+// subtreeHook returns a hook replacing the node at gindex when a child view changes.
+func (b *backing) subtreeHook(gindex uint64) func(*Node) error {
+	return func(node *Node) error {
+		return b.set(gindex, node)
+	}
+}
+
+// ContainerView is a view of a container.
+type ContainerView struct {
+	backing
+	numFields int
+	depth     uint8
+}
+
+// NewContainerView returns a view of the container with numFields fields held by node.
+func NewContainerView(node *Node, numFields int) (*ContainerView, error) {
+	if node == nil {
+		return nil, fmt.Errorf("nil node")
+	}
+	if numFields < 0 {
+		return nil, fmt.Errorf("negative number of fields %d", numFields)
+	}
+	return &ContainerView{
+		backing:   backing{node: node},
+		numFields: numFields,
+		depth:     DepthForLimit(uint64(numFields)),
+	}, nil
+}
+
+// NumFields returns the number of fields of the container.
+func (c *ContainerView) NumFields() int {
+	return c.numFields
+}
+
+// Copy returns an independent view of the container, in constant time. The copy and
+// the original share every subtree until either is modified, after which they only
+// share the subtrees off the modified paths, so that many variants of a beacon state
+// take up little more memory than one. The copy is detached from the view the
+// original was obtained from, if any.
+func (c *ContainerView) Copy() *ContainerView {
+	cp := *c
+	cp.hook = nil
+	return &cp
+}
+
+func (c *ContainerView) fieldGindex(i int) (uint64, error) {
+	if i < 0 || i >= c.numFields {
+		return 0, fmt.Errorf("field %d out of range for container of %d fields", i, c.numFields)
+	}
+	return uint64(1)<<c.depth | uint64(i), nil
+}
+
+// Field returns the root node of the i-th field.
+func (c *ContainerView) Field(i int) (*Node, error) {
+	gindex, err := c.fieldGindex(i)
+	if err != nil {
+		return nil, err
+	}
+	return c.get(gindex)
+}
+
+// SetField replaces the root node of the i-th field.
+func (c *ContainerView) SetField(i int, v *Node) error {
+	gindex, err := c.fieldGindex(i)
+	if err != nil {
+		return err
+	}
+	return c.set(gindex, v)
+}
+
+// FieldUint64 returns the i-th field, of type uint64 or of a smaller basic type.
+func (c *ContainerView) FieldUint64(i int) (uint64, error) {
+	node, err := c.Field(i)
+	if err != nil {
+		return 0, err
+	}
+	root := node.Root()
+	return binary.LittleEndian.Uint64(root[:8]), nil
+}
+
+// SetFieldUint64 replaces the i-th field, of type uint64 or of a smaller basic type.
+func (c *ContainerView) SetFieldUint64(i int, v uint64) error {
+	var chunk [32]byte
+	binary.LittleEndian.PutUint64(chunk[:], v)
+	return c.SetField(i, Leaf(chunk))
+}
+
+// FieldContainer returns a view of the i-th field, a container of numFields fields.
+func (c *ContainerView) FieldContainer(i int, numFields int) (*ContainerView, error) {
+	gindex, node, err := c.subtree(i)
+	if err != nil {
+		return nil, err
+	}
+	v, err := NewContainerView(node, numFields)
+	if err != nil {
+		return nil, err
+	}
+	v.hook = c.subtreeHook(gindex)
+	return v, nil
+}
+
+// FieldVector returns a view of the i-th field, a vector of length elements.
+func (c *ContainerView) FieldVector(i int, elemSize uint64, length uint64) (*VectorView, error) {
+	gindex, node, err := c.subtree(i)
+	if err != nil {
+		return nil, err
+	}
+	v, err := NewVectorView(node, elemSize, length)
+	if err != nil {
+		return nil, err
+	}
+	v.hook = c.subtreeHook(gindex)
+	return v, nil
+}
+
+// FieldList returns a view of the i-th field, a list of at most limit elements.
+func (c *ContainerView) FieldList(i int, elemSize uint64, limit uint64) (*ListView, error) {
+	gindex, node, err := c.subtree(i)
+	if err != nil {
+		return nil, err
+	}
+	v, err := NewListView(node, elemSize, limit)
+	if err != nil {
+		return nil, err
+	}
+	v.hook = c.subtreeHook(gindex)
+	return v, nil
+}
+
+func (c *ContainerView) subtree(i int) (uint64, *Node, error) {
+	gindex, err := c.fieldGindex(i)
+	if err != nil {
+		return 0, nil, err
+	}
+	node, err := c.get(gindex)
+	return gindex, node, err
+}
+
+// sequence holds the elements of a vector or of a list, from the node at gindex base.
+type sequence struct {
+	backing
+	base     uint64
+	elemSize uint64
+	depth    uint8
+}
+
+func newSequence(node *Node, base uint64, elemSize uint64, limit uint64) (sequence, error) {
+	if node == nil {
+		return sequence{}, fmt.Errorf("nil node")
+	}
+	switch elemSize {
+	case 0, 1, 2, 4, 8:
+	default:
+		return sequence{}, fmt.Errorf("unsupported element size %d", elemSize)
+	}
+	chunks := limit
+	if elemSize > 0 {
+		chunks = (limit*elemSize + 31) / 32
+	}
+	s := sequence{
+		backing:  backing{node: node},
+		base:     base,
+		elemSize: elemSize,
+		depth:    DepthForLimit(chunks),
+	}
+	if bits.Len64(base)+int(s.depth) > 64 {
+		return sequence{}, fmt.Errorf("limit %d overflows generalized indices", limit)
+	}
+	return s, nil
+}
+
+// chunkGindex returns the generalized index of the chunk holding the i-th element,
+// along with the offset of the element in the chunk.
+func (s *sequence) chunkGindex(i uint64) (uint64, uint64) {
+	if s.elemSize == 0 {
+		return s.base<<s.depth | i, 0
+	}
+	return s.base<<s.depth | i*s.elemSize/32, i * s.elemSize % 32
+}
+
+func (s *sequence) element(i uint64) (*Node, error) {
+	if s.elemSize != 0 {
+		return nil, fmt.Errorf("elements of %d bytes are packed into chunks", s.elemSize)
+	}
+	gindex, _ := s.chunkGindex(i)
+	return s.get(gindex)
+}
+
+func (s *sequence) setElement(i uint64, v *Node) error {
+	if s.elemSize != 0 {
+		return fmt.Errorf("elements of %d bytes are packed into chunks", s.elemSize)
+	}
+	gindex, _ := s.chunkGindex(i)
+	return s.set(gindex, v)
+}
+
+func (s *sequence) uint64At(i uint64) (uint64, error) {
+	if s.elemSize == 0 {
+		return 0, fmt.Errorf("elements are not of a basic type")
+	}
+	gindex, offset := s.chunkGindex(i)
+	node, err := s.get(gindex)
+	if err != nil {
+		return 0, err
+	}
+	chunk := node.Root()
+	var buf [8]byte
+	copy(buf[:], chunk[offset:offset+s.elemSize])
+	return binary.LittleEndian.Uint64(buf[:]), nil
+}
+
+func (s *sequence) setUint64At(i uint64, v uint64) error {
+	if s.elemSize == 0 {
+		return fmt.Errorf("elements are not of a basic type")
+	}
+	if s.elemSize < 8 && v>>(8*s.elemSize) != 0 {
+		return fmt.Errorf("value %d overflows elements of %d bytes", v, s.elemSize)
+	}
+	gindex, offset := s.chunkGindex(i)
+	node, err := s.get(gindex)
+	if err != nil {
+		return err
+	}
+	chunk := node.Root()
+	var buf [8]byte
+	binary.LittleEndian.PutUint64(buf[:], v)
+	copy(chunk[offset:offset+s.elemSize], buf[:s.elemSize])
+	return s.set(gindex, Leaf(chunk))
+}
+
+// elementHook returns a hook replacing the i-th composite element when a view of it
+// changes.
+func (s *sequence) elementHook(i uint64) func(*Node) error {
+	gindex, _ := s.chunkGindex(i)
+	return s.subtreeHook(gindex)
+}
+
+// VectorView is a view of a vector.
+type VectorView struct {
+	sequence
+	length uint64
+}
+
+// NewVectorView returns a view of the vector of length elements held by node.
+func NewVectorView(node *Node, elemSize uint64, length uint64) (*VectorView, error) {
+	s, err := newSequence(node, 1, elemSize, length)
+	if err != nil {
+		return nil, err
+	}
+	return &VectorView{sequence: s, length: length}, nil
+}
+
+// Copy returns an independent view of the vector, sharing subtrees with the original
+// as ContainerView.Copy does.
+func (v *VectorView) Copy() *VectorView {
+	cp := *v
+	cp.hook = nil
+	return &cp
+}
+
+// Len returns the length of the vector.
+func (v *VectorView) Len() uint64 {
+	return v.length
+}
+
+func (v *VectorView) check(i uint64) error {
+	if i >= v.length {
+		return fmt.Errorf("index %d out of range for vector of length %d", i, v.length)
+	}
+	return nil
+}
+
+// Get returns the root node of the i-th composite element.
+func (v *VectorView) Get(i uint64) (*Node, error) {
+	if err := v.check(i); err != nil {
+		return nil, err
+	}
+	return v.element(i)
+}
+
+// Set replaces the root node of the i-th composite element.
+func (v *VectorView) Set(i uint64, node *Node) error {
+	if err := v.check(i); err != nil {
+		return err
+	}
+	return v.setElement(i, node)
+}
+
+// Uint64 returns the i-th basic element.
+func (v *VectorView) Uint64(i uint64) (uint64, error) {
+	if err := v.check(i); err != nil {
+		return 0, err
+	}
+	return v.uint64At(i)
+}
+
+// SetUint64 replaces the i-th basic element.
+func (v *VectorView) SetUint64(i uint64, x uint64) error {
+	if err := v.check(i); err != nil {
+		return err
+	}
+	return v.setUint64At(i, x)
+}
+
+// Container returns a view of the i-th element, a container of numFields fields.
+func (v *VectorView) Container(i uint64, numFields int) (*ContainerView, error) {
+	node, err := v.Get(i)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewContainerView(node, numFields)
+	if err != nil {
+		return nil, err
+	}
+	c.hook = v.elementHook(i)
+	return c, nil
+}
+
+// ListView is a view of a list.
+type ListView struct {
+	sequence
+	limit uint64
+}
+
+// NewListView returns a view of the list of at most limit elements held by node.
+func NewListView(node *Node, elemSize uint64, limit uint64) (*ListView, error) {
+	if node.IsLeaf() {
+		return nil, fmt.Errorf("list node has no length mixed in")
+	}
+	s, err := newSequence(node, 2, elemSize, limit)
+	if err != nil {
+		return nil, err
+	}
+	return &ListView{sequence: s, limit: limit}, nil
+}
+
+// Copy returns an independent view of the list, sharing subtrees with the original
+// as ContainerView.Copy does.
+func (l *ListView) Copy() *ListView {
+	cp := *l
+	cp.hook = nil
+	return &cp
+}
+
+// Len returns the length of the list.
+func (l *ListView) Len() uint64 {
+	length := l.node.Right().Root()
+	return binary.LittleEndian.Uint64(length[:8])
+}
+
+func (l *ListView) check(i uint64) error {
+	if length := l.Len(); i >= length {
+		return fmt.Errorf("index %d out of range for list of length %d", i, length)
+	}
+	return nil
+}
+
+// Get returns the root node of the i-th composite element.
+func (l *ListView) Get(i uint64) (*Node, error) {
+	if err := l.check(i); err != nil {
+		return nil, err
+	}
+	return l.element(i)
+}
+
+// Set replaces the root node of the i-th composite element.
+func (l *ListView) Set(i uint64, node *Node) error {
+	if err := l.check(i); err != nil {
+		return err
+	}
+	return l.setElement(i, node)
+}
+
+// Uint64 returns the i-th basic element.
+func (l *ListView) Uint64(i uint64) (uint64, error) {
+	if err := l.check(i); err != nil {
+		return 0, err
+	}
+	return l.uint64At(i)
+}
+
+// SetUint64 replaces the i-th basic element.
+func (l *ListView) SetUint64(i uint64, x uint64) error {
+	if err := l.check(i); err != nil {
+		return err
+	}
+	return l.setUint64At(i, x)
+}
+
+// Container returns a view of the i-th element, a container of numFields fields.
+func (l *ListView) Container(i uint64, numFields int) (*ContainerView, error) {
+	node, err := l.Get(i)
+	if err != nil {
+		return nil, err
+	}
+	c, err := NewContainerView(node, numFields)
+	if err != nil {
+		return nil, err
+	}
+	c.hook = l.elementHook(i)
+	return c, nil
+}
+
+// Append adds a composite element at the end of the list.
+func (l *ListView) Append(node *Node) error {
+	length, err := l.grow()
+	if err != nil {
+		return err
+	}
+	if err := l.setElement(length, node); err != nil {
+		return err
+	}
+	return l.setLength(length + 1)
+}
+
+// AppendUint64 adds a basic element at the end of the list.
+func (l *ListView) AppendUint64(x uint64) error {
+	length, err := l.grow()
+	if err != nil {
+		return err
+	}
+	if err := l.setUint64At(length, x); err != nil {
+		return err
+	}
+	return l.setLength(length + 1)
+}
+
+func (l *ListView) grow() (uint64, error) {
+	length := l.Len()
+	if length >= l.limit {
+		return 0, fmt.Errorf("list reached its limit %d", l.limit)
+	}
+	return length, nil
+}
+
+func (l *ListView) setLength(length uint64) error {
+	var chunk [32]byte
+	binary.LittleEndian.PutUint64(chunk[:], length)
+	return l.set(3, Leaf(chunk))
+}
@@ -0,0 +1,194 @@
+package tree_test
+
+import (
+	"testing"
+
+	ssz "github.com/prysmaticlabs/go-ssz"
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+type viewValidator struct {
+	Pubkey           []byte `ssz-size:"48"`
+	EffectiveBalance uint64
+	Slashed          bool
+}
+
+type viewState struct {
+	Slot       uint64
+	BlockRoots [][]byte         `ssz-size:"4,32"`
+	Validators []*viewValidator `ssz-max:"1024"`
+	Balances   []uint64         `ssz-max:"1024"`
+	Flags      []byte           `ssz-max:"1024"`
+}
+
+func TestViews_MatchHashTreeRoot(t *testing.T) {
+	state := &viewState{
+		Slot:       3,
+		BlockRoots: make([][]byte, 4),
+		Validators: []*viewValidator{{Pubkey: make([]byte, 48), EffectiveBalance: 32}},
+		Balances:   []uint64{1, 2, 3, 4, 5},
+		Flags:      []byte{1, 2},
+	}
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = make([]byte, 32)
+	}
+	node, err := ssz.BackingTree(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	view, err := tree.NewContainerView(node, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	check := func(step string) {
+		want, err := ssz.HashTreeRoot(state)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if view.Root() != want {
+			t.Errorf("%s: wanted root %#x, received %#x", step, want, view.Root())
+		}
+	}
+	check("initial")
+
+	if slot, err := view.FieldUint64(0); err != nil || slot != 3 {
+		t.Errorf("Wanted slot 3, received %d: %v", slot, err)
+	}
+	state.Slot = 4
+	if err := view.SetFieldUint64(0, 4); err != nil {
+		t.Fatal(err)
+	}
+	check("slot")
+
+	roots, err := view.FieldVector(1, 0, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.BlockRoots[2][0] = 9
+	if err := roots.Set(2, tree.Leaf([32]byte{9})); err != nil {
+		t.Fatal(err)
+	}
+	check("block root")
+
+	validators, err := view.FieldList(2, 0, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	validator, err := validators.Container(0, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Validators[0].Slashed = true
+	if err := validator.SetFieldUint64(2, 1); err != nil {
+		t.Fatal(err)
+	}
+	check("validator")
+	appended := &viewValidator{Pubkey: make([]byte, 48), EffectiveBalance: 31}
+	appendedNode, err := ssz.BackingTree(appended)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Validators = append(state.Validators, appended)
+	if err := validators.Append(appendedNode); err != nil {
+		t.Fatal(err)
+	}
+	check("append validator")
+
+	balances, err := view.FieldList(3, 8, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if b, err := balances.Uint64(4); err != nil || b != 5 {
+		t.Errorf("Wanted balance 5, received %d: %v", b, err)
+	}
+	state.Balances[4] = 50
+	if err := balances.SetUint64(4, 50); err != nil {
+		t.Fatal(err)
+	}
+	for i := uint64(6); i < 10; i++ {
+		state.Balances = append(state.Balances, i)
+		if err := balances.AppendUint64(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	check("balances")
+	if balances.Len() != uint64(len(state.Balances)) {
+		t.Errorf("Wanted %d balances, received %d", len(state.Balances), balances.Len())
+	}
+
+	flags, err := view.FieldList(4, 1, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	state.Flags[1] = 7
+	if err := flags.SetUint64(1, 7); err != nil {
+		t.Fatal(err)
+	}
+	check("flags")
+
+	if err := flags.SetUint64(0, 256); err == nil {
+		t.Error("Expected error for value overflowing a byte")
+	}
+	if _, err := balances.Uint64(9); err == nil {
+		t.Error("Expected error for index out of range")
+	}
+	if _, err := balances.Get(0); err == nil {
+		t.Error("Expected error getting a node of packed elements")
+	}
+	if _, err := view.Field(5); err == nil {
+		t.Error("Expected error for field out of range")
+	}
+}
+
+func TestViews_CopySharesSubtrees(t *testing.T) {
+	state := &viewState{
+		BlockRoots: make([][]byte, 4),
+		Balances:   []uint64{1, 2, 3},
+	}
+	node, err := ssz.BackingTree(state)
+	if err != nil {
+		t.Fatal(err)
+	}
+	original, err := tree.NewContainerView(node, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := original.Root()
+	cp := original.Copy()
+	balances, err := cp.FieldList(3, 8, 1024)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := balances.SetUint64(0, 10); err != nil {
+		t.Fatal(err)
+	}
+	if original.Root() != root {
+		t.Error("Modifying the copy modified the original")
+	}
+	state.Balances[0] = 10
+	if want, err := ssz.HashTreeRoot(state); err != nil || cp.Root() != want {
+		t.Errorf("Wanted copy root %#x, received %#x: %v", want, cp.Root(), err)
+	}
+	for _, i := range []int{0, 1, 2, 4} {
+		a, err := original.Field(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		b, err := cp.Field(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if a != b {
+			t.Errorf("Field %d is not shared between the copies", i)
+		}
+	}
+
+	// A copy of a field view does not write through to the container.
+	detached := balances.Copy()
+	if err := detached.SetUint64(1, 20); err != nil {
+		t.Fatal(err)
+	}
+	if want, _ := ssz.HashTreeRoot(state); cp.Root() != want {
+		t.Error("Modifying a detached copy modified its container")
+	}
+}
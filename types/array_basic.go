@@ -6,6 +6,7 @@ import (
 
 	"github.com/dgraph-io/ristretto"
 	"github.com/minio/highwayhash"
+	"github.com/protolambda/zssz/merkle"
 )
 
 // BasicArraySizeCache for HashTreeRoot.
@@ -13,15 +14,19 @@ const BasicArraySizeCache = 100000
 
 var fastSumHashKey = toBytes32([]byte("hash_fast_sum64_key"))
 
+// basicArrayCacheMaxCost is the maximum cost of the cache of roots of arrays.
+const basicArrayCacheMaxCost = 1 << 22
+
 type basicArraySSZ struct {
 	hashCache *ristretto.Cache
+	counters  rootCacheCounters
 	lock      sync.Mutex
 }
 
 func newBasicArraySSZ() *basicArraySSZ {
 	cache, _ := ristretto.NewCache(&ristretto.Config{
-		NumCounters: BasicArraySizeCache, // number of keys to track frequency of (1M).
-		MaxCost:     1 << 22,             // maximum cost of cache (3MB).
+		NumCounters: BasicArraySizeCache,    // number of keys to track frequency of (1M).
+		MaxCost:     basicArrayCacheMaxCost, // maximum cost of cache (3MB).
 		// 100,000 roots will take up approximately 3 MB in memory.
 		BufferItems: 64, // number of keys per Get buffer.
 	})
@@ -31,10 +36,13 @@ func newBasicArraySSZ() *basicArraySSZ {
 }
 
 func (b *basicArraySSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
+	cache := cacheEnabled()
 	numItems := val.Len()
+	if useElementCache(fieldName, typ.Elem()) {
+		return containerElementCache.merkleize(fieldName, val, typ.Elem(), merkle.GetDepth(uint64(numItems)))
+	}
 	hashKeyElements := make([]byte, BytesPerChunk*numItems)
 	emptyKey := highwayhash.Sum(hashKeyElements, fastSumHashKey[:])
-	leaves := make([][]byte, numItems)
 	offset := 0
 	var factory SSZAble
 	var err error
@@ -49,27 +57,27 @@ func (b *basicArraySSZ) Root(val reflect.Value, typ reflect.Type, fieldName stri
 		if err != nil {
 			return [32]byte{}, err
 		}
-		leaves[i] = r[:]
 		copy(hashKeyElements[offset:offset+32], r[:])
 		offset += 32
 	}
 	hashKey := highwayhash.Sum(hashKeyElements, fastSumHashKey[:])
-	if enableCache && hashKey != emptyKey {
+	if cache && hashKey != emptyKey {
 		res, ok := b.hashCache.Get(string(hashKey[:]))
 		if res != nil && ok {
+			b.counters.hit()
 			return res.([32]byte), nil
 		}
+		b.counters.miss()
 	}
-	chunks, err := pack(leaves)
-	if err != nil {
-		return [32]byte{}, err
-	}
-	root, err := bitwiseMerkleize(chunks, uint64(len(chunks)), uint64(len(chunks)))
+	// The roots of the elements are laid out next to each other, so the hash key
+	// doubles as the leaves of the tree.
+	root, err := merkleizeBytes(hashKeyElements, uint64(numItems))
 	if err != nil {
 		return [32]byte{}, err
 	}
-	if enableCache && hashKey != emptyKey {
-		b.hashCache.Set(string(hashKey[:]), root, 32)
+	if cache && hashKey != emptyKey {
+		b.hashCache.Set(string(hashKey[:]), root, rootCacheEntryCost)
+		b.counters.set(string(hashKey[:]))
 	}
 	return root, nil
 }
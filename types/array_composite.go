@@ -2,7 +2,11 @@ package types
 
 import (
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"reflect"
+
+	"github.com/protolambda/zssz/merkle"
 )
 
 type compositeArraySSZ struct{}
@@ -21,33 +25,26 @@ func (b *compositeArraySSZ) Root(val reflect.Value, typ reflect.Type, fieldName
 			return [32]byte{}, err
 		}
 	}
-	roots := make([][]byte, numItems)
 	elemSize := uint64(0)
 	if isBasicType(typ.Elem().Kind()) {
 		elemSize = determineFixedSize(val, typ.Elem())
 	} else {
 		elemSize = 32
 	}
-	limit := (uint64(val.Len())*elemSize + 31) / 32
-	for i := 0; i < val.Len(); i++ {
+	limit := (uint64(numItems)*elemSize + 31) / 32
+	if uint64(numItems) > limit {
+		return [32]byte{}, errors.New("merkleizing list that is too large, over limit")
+	}
+	layer := getChunks(numItems)
+	defer putChunks(layer)
+	for i := 0; i < numItems; i++ {
 		r, err := factory.Root(val.Index(i), typ.Elem(), "", 0)
 		if err != nil {
 			return [32]byte{}, err
 		}
-		roots[i] = r[:]
-	}
-	chunks, err := pack(roots)
-	if err != nil {
-		return [32]byte{}, err
-	}
-	if val.Len() == 0 {
-		chunks = [][]byte{}
-	}
-	root, err := bitwiseMerkleize(chunks, uint64(len(chunks)), limit)
-	if err != nil {
-		return [32]byte{}, err
+		(*layer)[i] = r
 	}
-	return root, nil
+	return merkleizeLayer(*layer, merkle.GetDepth(limit), hashWorkers(numItems)), nil
 }
 
 func (b *compositeArraySSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
@@ -96,11 +93,19 @@ func (b *compositeArraySSZ) Marshal(val reflect.Value, typ reflect.Type, buf []b
 func (b *compositeArraySSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
 	currentIndex := startOffset
 	nextIndex := currentIndex
+	endOffset := uint64(len(input))
+	if startOffset+BytesPerLengthOffset > endOffset {
+		return 0, fmt.Errorf("input of %d bytes is too short for an offset at %d", endOffset, startOffset)
+	}
 	offsetVal := input[startOffset : startOffset+BytesPerLengthOffset]
-	firstOffset := startOffset + uint64(binary.LittleEndian.Uint32(offsetVal))
+	first := uint64(binary.LittleEndian.Uint32(offsetVal))
+	fixedSize := uint64(typ.Len()) * BytesPerLengthOffset
+	if err := checkOffset(0, first, 0, fixedSize, endOffset-startOffset); err != nil {
+		return 0, err
+	}
+	firstOffset := startOffset + first
 	currentOffset := firstOffset
 	nextOffset := currentOffset
-	endOffset := uint64(len(input))
 	i := 0
 	if val.Kind() == reflect.Slice {
 		instantiatedArray := reflect.MakeSlice(val.Type(), typ.Len(), typ.Len())
@@ -118,6 +123,9 @@ func (b *compositeArraySSZ) Unmarshal(val reflect.Value, typ reflect.Type, input
 			nextOffsetVal := input[nextIndex : nextIndex+BytesPerLengthOffset]
 			nextOffset = startOffset + uint64(binary.LittleEndian.Uint32(nextOffsetVal))
 		}
+		if err := checkOffset(i+1, nextOffset-startOffset, currentOffset-startOffset, fixedSize, endOffset-startOffset); err != nil {
+			return 0, err
+		}
 		if val.Index(i).Kind() == reflect.Ptr {
 			instantiateConcreteTypeForElement(val.Index(i), typ.Elem().Elem())
 		}
@@ -9,13 +9,19 @@ import (
 	"github.com/dgraph-io/ristretto"
 	"github.com/minio/highwayhash"
 	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
 )
 
 // RootsArraySizeCache for hash tree root.
 const RootsArraySizeCache = 100000
 
+// rootsArrayCacheMaxCost is the maximum cost of the cache of roots of root vectors.
+const rootsArrayCacheMaxCost = 1 << 23
+
 type rootsArraySSZ struct {
-	hashCache    *ristretto.Cache
+	hashCache *ristretto.Cache
+	counters  rootCacheCounters
+	// lock guards the cached leaves and layers.
 	lock         sync.Mutex
 	cachedLeaves map[string][][]byte
 	layers       map[string][][][]byte
@@ -23,8 +29,8 @@ type rootsArraySSZ struct {
 
 func newRootsArraySSZ() *rootsArraySSZ {
 	cache, _ := ristretto.NewCache(&ristretto.Config{
-		NumCounters: RootsArraySizeCache, // number of keys to track frequency of (100000).
-		MaxCost:     1 << 23,             // maximum cost of cache (3MB).
+		NumCounters: RootsArraySizeCache,    // number of keys to track frequency of (100000).
+		MaxCost:     rootsArrayCacheMaxCost, // maximum cost of cache (3MB).
 		// 100,000 roots will take up approximately 3 MB in memory.
 		BufferItems: 64, // number of keys per Get buffer.
 	})
@@ -36,6 +42,7 @@ func newRootsArraySSZ() *rootsArraySSZ {
 }
 
 func (a *rootsArraySSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
+	cache := cacheEnabled()
 	numItems := val.Len()
 	// We make sure to look into the cache only if a field name is provided, that is,
 	// if this function is called when calling HashTreeRoot on a struct type that has
@@ -46,7 +53,9 @@ func (a *rootsArraySSZ) Root(val reflect.Value, typ reflect.Type, fieldName stri
 	// }
 	//
 	// which would allow us to look into the cache by the field "BlockRoots".
-	if enableCache && fieldName != "" {
+	if cache && fieldName != "" {
+		a.lock.Lock()
+		defer a.lock.Unlock()
 		if _, ok := a.layers[fieldName]; !ok {
 			depth := merkle.GetDepth(uint64(numItems))
 			a.layers[fieldName] = make([][][]byte, depth+1)
@@ -69,7 +78,7 @@ func (a *rootsArraySSZ) Root(val reflect.Value, typ reflect.Type, fieldName stri
 		leaves[i] = item[:]
 		copy(hashKeyElements[offset:offset+32], leaves[i])
 		offset += 32
-		if enableCache && fieldName != "" {
+		if cache && fieldName != "" {
 			if _, ok := a.cachedLeaves[fieldName]; ok {
 				if !bytes.Equal(leaves[i], a.cachedLeaves[fieldName][i]) {
 					changedIndices = append(changedIndices, i)
@@ -88,18 +97,21 @@ func (a *rootsArraySSZ) Root(val reflect.Value, typ reflect.Type, fieldName stri
 		return rt, nil
 	}
 	hashKey := highwayhash.Sum(hashKeyElements, fastSumHashKey[:])
-	if enableCache && hashKey != emptyKey {
+	if cache && hashKey != emptyKey {
 		res, ok := a.hashCache.Get(string(hashKey[:]))
 		if res != nil && ok {
+			a.counters.hit()
 			return res.([32]byte), nil
 		}
+		a.counters.miss()
 	}
 	root := a.merkleize(chunks, fieldName)
-	if enableCache && fieldName != "" {
+	if cache && fieldName != "" {
 		a.cachedLeaves[fieldName] = leaves
 	}
-	if enableCache && hashKey != emptyKey {
-		a.hashCache.Set(string(hashKey[:]), root, 32)
+	if cache && hashKey != emptyKey {
+		a.hashCache.Set(string(hashKey[:]), root, rootCacheEntryCost)
+		a.counters.set(string(hashKey[:]))
 	}
 	return root, nil
 }
@@ -128,7 +140,7 @@ func (a *rootsArraySSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []b
 	i := 0
 	index := startOffset
 	for i < val.Len() {
-		val.Index(i).SetBytes(input[index : index+uint64(32)])
+		val.Index(i).SetBytes(input[index : index+32 : index+32])
 		index += uint64(32)
 		i++
 	}
@@ -136,61 +148,62 @@ func (a *rootsArraySSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []b
 }
 
 func (a *rootsArraySSZ) recomputeRoot(idx int, chunks [][]byte, fieldName string) [32]byte {
-	root := chunks[idx]
+	root := toBytes32(chunks[idx])
 	for i := 0; i < len(a.layers[fieldName])-1; i++ {
 		subIndex := (uint64(idx) / (1 << uint64(i))) ^ 1
 		isLeft := uint64(idx) / (1 << uint64(i))
 		parentIdx := uint64(idx) / (1 << uint64(i+1))
-		item := a.layers[fieldName][i][subIndex]
+		// Layers are not padded, the sibling of the last node of an odd layer being
+		// the root of a zero subtree.
+		item := hashing.ZeroHash(uint8(i))
+		if subIndex < uint64(len(a.layers[fieldName][i])) {
+			item = toBytes32(a.layers[fieldName][i][subIndex])
+		}
 		if isLeft%2 != 0 {
-			parentHash := hash(append(item, root...))
-			root = parentHash[:]
+			root = hashing.HashPair(item, root)
 		} else {
-			parentHash := hash(append(root, item...))
-			root = parentHash[:]
+			root = hashing.HashPair(root, item)
 		}
 		// Update the cached layers at the parent index.
-		a.layers[fieldName][i+1][parentIdx] = root
+		copy(a.layers[fieldName][i+1][parentIdx], root[:])
 	}
-	return toBytes32(root)
+	return root
 }
 
 func (a *rootsArraySSZ) merkleize(chunks [][]byte, fieldName string) [32]byte {
+	cache := cacheEnabled() && fieldName != ""
 	if len(chunks) == 1 {
 		var root [32]byte
 		copy(root[:], chunks[0])
 		return root
 	}
-	for !isPowerOf2(len(chunks)) {
-		chunks = append(chunks, make([]byte, BytesPerChunk))
+	// Layers are hashed in place into a single buffer. The last node of a layer of odd
+	// length is paired with the root of a zero subtree rather than padding the bottom
+	// layer with zero chunks.
+	layer := make([][32]byte, len(chunks))
+	for i, c := range chunks {
+		copy(layer[i][:], c)
 	}
-	hashLayer := chunks
-	if enableCache && fieldName != "" {
-		a.layers[fieldName][0] = hashLayer
+	if !cache {
+		return merkleizeLayer(layer, merkle.GetDepth(uint64(len(chunks))), hashWorkers(len(layer)))
 	}
 	// We keep track of the hash layers of a Merkle trie until we reach
 	// the top layer of length 1, which contains the single root element.
 	//        [Root]      -> Top layer has length 1.
 	//    [E]       [F]   -> This layer has length 2.
-	// [A]  [B]  [C]  [D] -> The bottom layer has length 4 (needs to be a power of two).
-	i := 1
-	for len(hashLayer) > 1 {
-		layer := [][]byte{}
-		for i := 0; i < len(hashLayer); i += 2 {
-			hashedChunk := hash(append(hashLayer[i], hashLayer[i+1]...))
-			layer = append(layer, hashedChunk[:])
-		}
-		hashLayer = layer
-		if enableCache && fieldName != "" {
-			a.layers[fieldName][i] = hashLayer
+	// [A]  [B]  [C]  [D] -> The bottom layer has length 4.
+	a.layers[fieldName][0] = chunks
+	for i := 1; len(layer) > 1; i++ {
+		layer = hashLayers(layer, uint8(i-1), uint8(i))
+		// Cached layers are copied out of the buffer, which is overwritten by the next
+		// layer, into a single allocation each.
+		flat := make([]byte, BytesPerChunk*len(layer))
+		cached := make([][]byte, len(layer))
+		for j := range layer {
+			cached[j] = flat[j*BytesPerChunk : (j+1)*BytesPerChunk : (j+1)*BytesPerChunk]
+			copy(cached[j], layer[j][:])
 		}
-		i++
+		a.layers[fieldName][i] = cached
 	}
-	var root [32]byte
-	copy(root[:], hashLayer[0])
-	return root
-}
-
-func isPowerOf2(n int) bool {
-	return n != 0 && (n&(n-1)) == 0
+	return layer[0]
 }
@@ -47,3 +47,56 @@ func BenchmarkRootsArray_Root_MinimalChanges(b *testing.B) {
 		}
 	}
 }
+
+// paddedRoot merkleizes chunks padded with zero chunks to a power of two.
+func paddedRoot(chunks [][32]byte) [32]byte {
+	layer := append([][32]byte{}, chunks...)
+	for len(layer)&(len(layer)-1) != 0 {
+		layer = append(layer, [32]byte{})
+	}
+	for len(layer) > 1 {
+		next := make([][32]byte, len(layer)/2)
+		for i := range next {
+			next[i] = hash(append(layer[2*i][:], layer[2*i+1][:]...))
+		}
+		layer = next
+	}
+	return layer[0]
+}
+
+func TestRootsArray_OddLength(t *testing.T) {
+	defer config.Store(Config{})
+	if err := ToggleCache(true); err != nil {
+		t.Fatal(err)
+	}
+	roots := [5][32]byte{{1}, {2}, {3}, {4}, {5}}
+	ss := newRootsArraySSZ()
+	for _, i := range []int{-1, 4, 0} {
+		if i >= 0 {
+			roots[i] = [32]byte{byte(i), 9}
+		}
+		v := reflect.ValueOf(roots)
+		root, err := ss.Root(v, v.Type(), "Roots", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if want := paddedRoot(roots[:]); root != want {
+			t.Errorf("Wanted root %#x after changing element %d, received %#x", want, i, root)
+		}
+	}
+}
+
+func TestRootsArray_MerkleizeAllocations(t *testing.T) {
+	chunks := make([][]byte, 1024)
+	for i := range chunks {
+		chunks[i] = make([]byte, 32)
+		chunks[i][0] = byte(i)
+	}
+	ss := newRootsArraySSZ()
+	allocs := testing.AllocsPerRun(10, func() {
+		ss.merkleize(chunks, "")
+	})
+	if allocs > 1 {
+		t.Errorf("Wanted a single allocation for the layers, received %v", allocs)
+	}
+}
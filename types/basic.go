@@ -7,20 +7,25 @@ import (
 	"sync"
 
 	"github.com/dgraph-io/ristretto"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
 )
 
 // BasicTypeCacheSize for HashTreeRoot.
 const BasicTypeCacheSize = 100000
 
+// basicCacheMaxCost is the maximum cost of the cache of roots of basic values.
+const basicCacheMaxCost = 1 << 23
+
 type basicSSZ struct {
 	hashCache *ristretto.Cache
+	counters  rootCacheCounters
 	lock      sync.Mutex
 }
 
 func newBasicSSZ() *basicSSZ {
 	cache, _ := ristretto.NewCache(&ristretto.Config{
 		NumCounters: BasicTypeCacheSize, // number of keys to track frequency of (100K).
-		MaxCost:     1 << 23,            // maximum cost of cache (3MB).
+		MaxCost:     basicCacheMaxCost,  // maximum cost of cache (3MB).
 		// 100,000 roots will take up approximately 3 MB in memory.
 		BufferItems: 64, // number of keys per Get buffer.
 	})
@@ -59,6 +64,9 @@ func (b *basicSSZ) Unmarshal(val reflect.Value, typ reflect.Type, buf []byte, st
 	}
 
 	kind := typ.Kind()
+	if isBasicType(kind) && startOffset+determineFixedSize(val, typ) > uint64(len(buf)) {
+		return 0, fmt.Errorf("input of %d bytes is too short for %v at offset %d", len(buf), typ, startOffset)
+	}
 	switch {
 	case kind == reflect.Bool:
 		return unmarshalBool(val, typ, buf, startOffset)
@@ -72,6 +80,11 @@ func (b *basicSSZ) Unmarshal(val reflect.Value, typ reflect.Type, buf []byte, st
 		return unmarshalUint32(val, typ, buf, startOffset)
 	case kind == reflect.Uint64:
 		return unmarshalUint64(val, typ, buf, startOffset)
+	case typ == bitlistType:
+		if err := checkBitlist(buf[startOffset:], 0); err != nil {
+			return 0, err
+		}
+		return unmarshalByteArray(val, typ, buf, startOffset)
 	case kind == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
 		return unmarshalByteArray(val, typ, buf, startOffset)
 	case kind == reflect.Array && isBasicType(typ.Elem().Kind()):
@@ -82,36 +95,40 @@ func (b *basicSSZ) Unmarshal(val reflect.Value, typ reflect.Type, buf []byte, st
 }
 
 func (b *basicSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
-	var chunks [][]byte
-	var err error
-	var hashKey string
 	newVal := reflect.New(val.Type()).Elem()
 	newVal.Set(val)
 	if val.Type().Kind() == reflect.Slice && val.IsNil() {
 		newVal.Set(reflect.MakeSlice(val.Type(), typ.Len(), typ.Len()))
 	}
-	buf := make([]byte, DetermineSize(newVal))
-	if _, err := b.Marshal(newVal, typ, buf, 0); err != nil {
+	buf := getBytes(int(DetermineSize(newVal)))
+	defer putBytes(buf)
+	if _, err := b.Marshal(newVal, typ, *buf, 0); err != nil {
 		return [32]byte{}, err
 	}
-	hashKey = string(buf)
-	res, ok := b.hashCache.Get(string(hashKey))
-	if res != nil && ok {
-		return res.([32]byte), nil
+	// Roots of other tree hashes than sha256 are never cached.
+	cache := !hashing.CustomTreeHash()
+	var hashKey string
+	if cache {
+		hashKey = string(*buf)
+		res, ok := b.hashCache.Get(hashKey)
+		if res != nil && ok {
+			b.counters.hit()
+			return res.([32]byte), nil
+		}
+		b.counters.miss()
 	}
 
 	// In order to find the root of a basic type, we simply marshal it,
 	// split the marshaling into chunks, and compute the most simple
 	// Merkleization over the chunks.
-	chunks, err = pack([][]byte{buf})
+	root, err := merkleizeBytes(*buf, uint64((len(*buf)+31)/32))
 	if err != nil {
 		return [32]byte{}, err
 	}
-	root, err := bitwiseMerkleize(chunks, uint64(len(chunks)), uint64(len(chunks)))
-	if err != nil {
-		return [32]byte{}, err
+	if cache {
+		b.hashCache.Set(hashKey, root, rootCacheEntryCost)
+		b.counters.set(hashKey)
 	}
-	b.hashCache.Set(string(hashKey), root, 32)
 	return root, nil
 }
 
@@ -145,12 +162,14 @@ func marshalByteArray(val reflect.Value, typ reflect.Type, buf []byte, startOffs
 
 func unmarshalByteArray(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
 	offset := startOffset + uint64(len(input))
-	val.SetBytes(input[startOffset:offset])
+	// The capacity is capped so that appending to the slice cannot overwrite the bytes
+	// decoded into the next field.
+	val.SetBytes(input[startOffset:offset:offset])
 	return offset, nil
 }
 
 func marshalBool(val reflect.Value, buf []byte, startOffset uint64) (uint64, error) {
-	if val.Interface().(bool) {
+	if val.Bool() {
 		buf[startOffset] = uint8(1)
 	} else {
 		buf[startOffset] = uint8(0)
@@ -171,7 +190,7 @@ func unmarshalBool(val reflect.Value, typ reflect.Type, input []byte, startOffse
 }
 
 func marshalUint8(val reflect.Value, buf []byte, startOffset uint64) (uint64, error) {
-	buf[startOffset] = val.Interface().(uint8)
+	buf[startOffset] = uint8(val.Uint())
 	return startOffset + 1, nil
 }
 
@@ -181,53 +200,53 @@ func unmarshalUint8(val reflect.Value, typ reflect.Type, input []byte, startOffs
 }
 
 func marshalUint16(val reflect.Value, buf []byte, startOffset uint64) (uint64, error) {
-	binary.LittleEndian.PutUint16(buf[startOffset:], val.Interface().(uint16))
+	binary.LittleEndian.PutUint16(buf[startOffset:], uint16(val.Uint()))
 	return startOffset + 2, nil
 }
 
 func unmarshalUint16(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
 	offset := startOffset + 2
-	buf := make([]byte, 2)
-	copy(buf, input[startOffset:offset])
-	val.SetUint(uint64(binary.LittleEndian.Uint16(buf)))
+	var buf [2]byte
+	copy(buf[:], input[startOffset:offset])
+	val.SetUint(uint64(binary.LittleEndian.Uint16(buf[:])))
 	return offset, nil
 }
 
 func marshalInt32(val reflect.Value, buf []byte, startOffset uint64) (uint64, error) {
-	binary.LittleEndian.PutUint32(buf[startOffset:], uint32(val.Interface().(int32)))
+	binary.LittleEndian.PutUint32(buf[startOffset:], uint32(val.Int()))
 	return startOffset + 4, nil
 }
 
 func unmarshalInt32(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
 	offset := startOffset + 4
-	buf := make([]byte, 4)
-	copy(buf, input[startOffset:offset])
-	val.SetInt(int64(binary.LittleEndian.Uint32(buf)))
+	var buf [4]byte
+	copy(buf[:], input[startOffset:offset])
+	val.SetInt(int64(binary.LittleEndian.Uint32(buf[:])))
 	return offset, nil
 }
 
 func marshalUint32(val reflect.Value, buf []byte, startOffset uint64) (uint64, error) {
-	binary.LittleEndian.PutUint32(buf[startOffset:], val.Interface().(uint32))
+	binary.LittleEndian.PutUint32(buf[startOffset:], uint32(val.Uint()))
 	return startOffset + 4, nil
 }
 
 func unmarshalUint32(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
 	offset := startOffset + 4
-	buf := make([]byte, 4)
-	copy(buf, input[startOffset:offset])
-	val.SetUint(uint64(binary.LittleEndian.Uint32(buf)))
+	var buf [4]byte
+	copy(buf[:], input[startOffset:offset])
+	val.SetUint(uint64(binary.LittleEndian.Uint32(buf[:])))
 	return offset, nil
 }
 
 func marshalUint64(val reflect.Value, buf []byte, startOffset uint64) (uint64, error) {
-	binary.LittleEndian.PutUint64(buf[startOffset:], val.Interface().(uint64))
+	binary.LittleEndian.PutUint64(buf[startOffset:], val.Uint())
 	return startOffset + 8, nil
 }
 
 func unmarshalUint64(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
 	offset := startOffset + 8
-	buf := make([]byte, 8)
-	copy(buf, input[startOffset:offset])
-	val.SetUint(binary.LittleEndian.Uint64(buf))
+	var buf [8]byte
+	copy(buf[:], input[startOffset:offset])
+	val.SetUint(binary.LittleEndian.Uint64(buf[:]))
 	return offset, nil
 }
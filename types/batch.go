@@ -0,0 +1,191 @@
+package types
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// batchTree is a container whose field roots are on the stack of a Hasher, waiting to
+// be merkleized along with the ones of the other containers of a batch.
+type batchTree struct {
+	// index is the position of the container in the batch.
+	index int
+	// base is the position of its field roots on the stack, and size the length of the
+	// layer of its tree being hashed.
+	base int
+	size int
+	// depth is the depth of its tree.
+	depth uint8
+}
+
+// Roots returns the hash tree roots of vals, which may be of different types. The roots
+// of the fields of containers are computed one container at a time, while the layers of
+// their Merkle trees are hashed together, level by level, so that a backend which is a
+// hashing.PairHasher is fed with pairs of chunks from many containers in a single call.
+// Other values are hashed as by Root.
+func (h *Hasher) Roots(vals []reflect.Value) ([][32]byte, error) {
+	h.chunks = h.chunks[:0]
+	defer func() {
+		h.chunks = h.chunks[:0]
+	}()
+	roots := make([][32]byte, len(vals))
+	var trees []batchTree
+	for i, val := range vals {
+		if i%contextCheckInterval == 0 {
+			if err := h.checkContext(); err != nil {
+				return nil, err
+			}
+		}
+		val, ok := batchContainer(val)
+		if !ok {
+			r, err := h.root(val, val.Type(), 0)
+			if err != nil {
+				return nil, errors.Wrapf(err, "could not compute root for type: %v", vals[i].Type())
+			}
+			roots[i] = r
+			continue
+		}
+		base := len(h.chunks)
+		if err := h.pushFields(val, val.Type()); err != nil {
+			return nil, errors.Wrapf(err, "could not compute root for type: %v", vals[i].Type())
+		}
+		size := len(h.chunks) - base
+		trees = append(trees, batchTree{index: i, base: base, size: size, depth: merkle.GetDepth(uint64(size))})
+	}
+	h.merkleizeBatch(trees)
+	for _, t := range trees {
+		if t.size == 0 {
+			roots[t.index] = hashing.ZeroHash(t.depth)
+			continue
+		}
+		roots[t.index] = h.chunks[t.base]
+	}
+	return roots, nil
+}
+
+// merkleizeBatch hashes the trees of a batch up to their roots, in place, one level at a
+// time. Each level gathers the pairs of chunks of every tree which is not done yet, and
+// hashes them in a single call.
+func (h *Hasher) merkleizeBatch(trees []batchTree) {
+	for height := uint8(0); ; height++ {
+		h.pairs = h.pairs[:0]
+		for _, t := range trees {
+			if height >= t.depth || t.size == 0 {
+				continue
+			}
+			layer := h.chunks[t.base : t.base+t.size]
+			h.pairs = append(h.pairs, layer...)
+			if t.size%2 == 1 {
+				h.pairs = append(h.pairs, hashing.ZeroHash(height))
+			}
+		}
+		if len(h.pairs) == 0 {
+			return
+		}
+		n := len(h.pairs)
+		for i := 0; i < n/2; i++ {
+			h.pairs = append(h.pairs, [32]byte{})
+		}
+		hashing.HashPairs(h.pairs[n:], h.pairs[:n])
+		hashed := h.pairs[n:]
+		for i := range trees {
+			t := &trees[i]
+			if height >= t.depth || t.size == 0 {
+				continue
+			}
+			t.size = (t.size + 1) / 2
+			copy(h.chunks[t.base:t.base+t.size], hashed)
+			hashed = hashed[t.size:]
+		}
+	}
+}
+
+// batchContainer dereferences val down to a container whose fields can be hashed by
+// pushFields. It returns false, along with a value to hash with root, for any other
+// value, such as values of registered codecs and pinned roots.
+func batchContainer(val reflect.Value) (reflect.Value, bool) {
+	typ := val.Type()
+	for typ.Kind() == reflect.Ptr {
+		if _, ok := registeredCodec(typ); ok {
+			return val, false
+		}
+		if val.IsNil() {
+			val = reflect.New(typ.Elem()).Elem()
+		} else {
+			if _, ok := PinnedRoot(val); ok {
+				return val, false
+			}
+			val = val.Elem()
+		}
+		typ = typ.Elem()
+	}
+	if _, ok := registeredCodec(typ); ok {
+		return val, false
+	}
+	return val, typ.Kind() == reflect.Struct && !isUnionType(typ) && !isOptionalType(typ) && !isStableType(typ)
+}
+
+// containers merkleizes the roots of the first n elements of a sequence of containers,
+// such as withdrawals or historical summaries. As in Roots, the roots of the fields of
+// the elements are pushed first, and the trees of the elements are then hashed together,
+// level by level.
+func (h *Hasher) containers(val reflect.Value, n int, limit uint64) ([32]byte, error) {
+	base := len(h.chunks)
+	// The roots of the elements are stored at the bottom of their part of the stack,
+	// below the roots of their fields.
+	h.chunks = append(h.chunks, make([][32]byte, n)...)
+	trees := make([]batchTree, 0, n)
+	for i := 0; i < n; i++ {
+		if i%contextCheckInterval == 0 {
+			if err := h.checkContext(); err != nil {
+				h.chunks = h.chunks[:base]
+				return [32]byte{}, err
+			}
+		}
+		elem, ok := batchContainer(val.Index(i))
+		if !ok {
+			r, err := h.root(elem, elem.Type(), 0)
+			if err != nil {
+				h.chunks = h.chunks[:base]
+				return [32]byte{}, err
+			}
+			h.chunks[base+i] = r
+			continue
+		}
+		start := len(h.chunks)
+		if err := h.pushFields(elem, elem.Type()); err != nil {
+			h.chunks = h.chunks[:base]
+			return [32]byte{}, err
+		}
+		size := len(h.chunks) - start
+		trees = append(trees, batchTree{index: i, base: start, size: size, depth: merkle.GetDepth(uint64(size))})
+	}
+	h.merkleizeBatch(trees)
+	for _, t := range trees {
+		if t.size == 0 {
+			h.chunks[base+t.index] = hashing.ZeroHash(t.depth)
+			continue
+		}
+		h.chunks[base+t.index] = h.chunks[t.base]
+	}
+	h.chunks = h.chunks[:base+n]
+	return h.merkleize(base, limit)
+}
+
+// isContainerType returns true for containers hashed from the roots of their fields,
+// and pointers to them, unless a codec is registered for them.
+func isContainerType(typ reflect.Type) bool {
+	for typ.Kind() == reflect.Ptr {
+		if _, ok := registeredCodec(typ); ok {
+			return false
+		}
+		typ = typ.Elem()
+	}
+	if _, ok := registeredCodec(typ); ok {
+		return false
+	}
+	return typ.Kind() == reflect.Struct && !isUnionType(typ) && !isOptionalType(typ) && !isStableType(typ)
+}
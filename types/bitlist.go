@@ -1,10 +1,10 @@
 package types
 
 import (
-	"bytes"
-	"encoding/binary"
+	"fmt"
 
 	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
 )
 
 // BitlistRoot computes the hash tree root of a bitlist type as outlined in the
@@ -12,28 +12,17 @@ import (
 func BitlistRoot(bfield bitfield.Bitfield, maxCapacity uint64) ([32]byte, error) {
 	limit := (maxCapacity + 255) / 256
 	if bfield == nil || bfield.Len() == 0 {
-		length := make([]byte, 32)
 		root, err := bitwiseMerkleize([][]byte{}, 0, limit)
 		if err != nil {
 			return [32]byte{}, err
 		}
-		return mixInLength(root, length), nil
+		return hashing.MixInLength(root, 0), nil
 	}
-	chunks, err := pack([][]byte{bfield.Bytes()})
+	root, err := merkleizeBytes(bfield.Bytes(), limit)
 	if err != nil {
 		return [32]byte{}, err
 	}
-	buf := new(bytes.Buffer)
-	if err := binary.Write(buf, binary.LittleEndian, bfield.Len()); err != nil {
-		return [32]byte{}, err
-	}
-	output := make([]byte, 32)
-	copy(output, buf.Bytes())
-	root, err := bitwiseMerkleize(chunks, uint64(len(chunks)), limit)
-	if err != nil {
-		return [32]byte{}, err
-	}
-	return mixInLength(root, output), nil
+	return hashing.MixInLength(root, bfield.Len()), nil
 }
 
 // Bitvector4Root computes the hash tree root of a bitvector4 type as outlined in the
@@ -43,9 +32,34 @@ func Bitvector4Root(bfield bitfield.Bitfield, maxCapacity uint64) ([32]byte, err
 	if bfield == nil {
 		return bitwiseMerkleize([][]byte{}, 0, limit)
 	}
-	chunks, err := pack([][]byte{bfield.Bytes()})
-	if err != nil {
-		return [32]byte{}, err
+	return merkleizeBytes(bfield.Bytes(), limit)
+}
+
+// BitlistDelimiterError is returned when the encoding of a bitlist does not end with a
+// byte holding the delimiter bit which marks its length, such as an empty encoding or
+// one ending with a zero byte. Callers can retrieve it with errors.Cause.
+type BitlistDelimiterError struct {
+	// Path is the chain of struct fields leading to the bitlist, such as
+	// "Body.Attestations".
+	Path string
+	// Size is the number of bytes of the encoding.
+	Size int
+}
+
+// Error describes the invalid bitlist.
+func (e *BitlistDelimiterError) Error() string {
+	return fmt.Sprintf("bitlist of %d bytes is missing its delimiter bit", e.Size)
+}
+
+// checkBitlist returns an error if b is not the encoding of a bitlist of at most limit
+// bits, 0 standing for no limit. As the delimiter is the highest bit set, a delimiter in
+// the last byte also rules out bits set past the length of the bitlist.
+func checkBitlist(b []byte, limit uint64) error {
+	if len(b) == 0 || b[len(b)-1] == 0 {
+		return &BitlistDelimiterError{Size: len(b)}
+	}
+	if n := bitfield.Bitlist(b).Len(); limit > 0 && n > limit {
+		return &LimitExceededError{Limit: limit, Length: n}
 	}
-	return bitwiseMerkleize(chunks, uint64(len(chunks)), limit)
+	return nil
 }
@@ -0,0 +1,95 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// Bitvector types of go-bitfield, such as bitfield.Bitvector4, are recognized by name,
+// as their number varies across versions of the package. They are fixed-size byte
+// vectors of (N+7)/8 bytes whose padding bits, past the N-th bit, must be zero. Struct
+// fields of these types need no ssz-size tag.
+
+type bitvectorSSZ struct{}
+
+func newBitvectorSSZ() *bitvectorSSZ {
+	return &bitvectorSSZ{}
+}
+
+// BitvectorLength returns the number of bits of typ if it is a bitvector type of
+// go-bitfield.
+func BitvectorLength(typ reflect.Type) (uint64, bool) {
+	if typ.Kind() != reflect.Slice || typ.Elem().Kind() != reflect.Uint8 || typ.PkgPath() != bitlistType.PkgPath() {
+		return 0, false
+	}
+	if !strings.HasPrefix(typ.Name(), "Bitvector") {
+		return 0, false
+	}
+	n, err := strconv.ParseUint(strings.TrimPrefix(typ.Name(), "Bitvector"), 10, 32)
+	return n, err == nil && n > 0
+}
+
+func isBitvectorType(typ reflect.Type) bool {
+	_, ok := BitvectorLength(typ)
+	return ok
+}
+
+// BitvectorRoot computes the hash tree root of a bitvector of n bits.
+func BitvectorRoot(b []byte, n uint64) ([32]byte, error) {
+	if err := checkBitvector(b, n); err != nil {
+		return [32]byte{}, err
+	}
+	return merkleizeBytes(b, (n+255)/256)
+}
+
+// checkBitvector returns an error if b is not the serialization of a bitvector of n
+// bits, that is, if its length is wrong or any of its padding bits is set.
+func checkBitvector(b []byte, n uint64) error {
+	if uint64(len(b)) != (n+7)/8 {
+		return fmt.Errorf("expected %d bytes for bitvector of %d bits, received %d", (n+7)/8, n, len(b))
+	}
+	if n%8 != 0 && b[len(b)-1]>>(n%8) != 0 {
+		return fmt.Errorf("bitvector of %d bits has padding bits set: %#x", n, b[len(b)-1])
+	}
+	return nil
+}
+
+// checkBitvectorField checks the padding bits of a struct field declared with a
+// bitvector type. Values of the wrong length are padded or rejected as for any byte
+// vector.
+func checkBitvectorField(val reflect.Value, field reflect.StructField) error {
+	n, ok := BitvectorLength(field.Type)
+	if !ok || uint64(val.Len()) != (n+7)/8 {
+		return nil
+	}
+	return checkBitvector(val.Bytes(), n)
+}
+
+func (b *bitvectorSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
+	n, _ := BitvectorLength(typ)
+	return BitvectorRoot(val.Bytes(), n)
+}
+
+func (b *bitvectorSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
+	n, _ := BitvectorLength(typ)
+	if err := checkBitvector(val.Bytes(), n); err != nil {
+		return 0, err
+	}
+	return startOffset + uint64(copy(buf[startOffset:], val.Bytes())), nil
+}
+
+func (b *bitvectorSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
+	n, _ := BitvectorLength(typ)
+	size := (n + 7) / 8
+	if startOffset+size > uint64(len(input)) {
+		return 0, fmt.Errorf("input of %d bytes is too short for bitvector of %d bits", uint64(len(input))-startOffset, n)
+	}
+	data := input[startOffset : startOffset+size]
+	if err := checkBitvector(data, n); err != nil {
+		return 0, err
+	}
+	val.SetBytes(append([]byte{}, data...))
+	return startOffset + size, nil
+}
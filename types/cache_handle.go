@@ -0,0 +1,47 @@
+package types
+
+import (
+	"reflect"
+	"sync"
+)
+
+// uncachedTypes holds the struct types whose fields are hashed without the caches.
+var uncachedTypes sync.Map
+
+// CacheHandle controls caching of the roots of the fields of a single struct type, such
+// that a library can opt its own types out of caching without toggling the package-level
+// option, which other users of the package in the same program may rely on:
+//
+//  types.CacheFor(reflect.TypeOf(LightClientUpdate{})).SetEnabled(false)
+//
+// It applies to the caches keyed by field name, which hold the layers of root vectors
+// and the element roots of lists of containers.
+type CacheHandle struct {
+	typ reflect.Type
+}
+
+// CacheFor returns the cache handle of a struct type, or of the struct a pointer type
+// points to.
+func CacheFor(typ reflect.Type) CacheHandle {
+	if typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	return CacheHandle{typ: typ}
+}
+
+// SetEnabled enables or disables caching for the fields of the type. Fields of a type
+// are only cached while caching is enabled at the package level as well, see ToggleCache,
+// and caching is enabled for every type by default.
+func (h CacheHandle) SetEnabled(enabled bool) {
+	if enabled {
+		uncachedTypes.Delete(h.typ)
+		return
+	}
+	uncachedTypes.Store(h.typ, true)
+}
+
+// Enabled returns true if the roots of the fields of the type are cached.
+func (h CacheHandle) Enabled() bool {
+	_, uncached := uncachedTypes.Load(h.typ)
+	return !uncached && cacheEnabled()
+}
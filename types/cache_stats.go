@@ -0,0 +1,145 @@
+package types
+
+import (
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+// rootCacheEntryCost is the cost of every root set in the ristretto caches.
+const rootCacheEntryCost = 32
+
+// CacheStats describes the contents and effectiveness of the hash tree root caches, so
+// that operators can tell whether they are worth their memory.
+type CacheStats struct {
+	// Roots describes the caches of roots keyed by the content of values, which serve
+	// values hashed before, whatever field they are held in.
+	Roots []RootCacheStats
+	// Types describes the caches keyed by field name, which hold the roots of the
+	// elements of root vectors and lists of containers, grouped by struct type.
+	Types []TypeCacheStats
+	// TypeInfo is the number of types whose layout, such as their nesting depth, is
+	// cached.
+	TypeInfo int
+}
+
+// RootCacheStats describes a cache of roots keyed by the content of values. Counters
+// start when the program does, and entries are estimated as the cache evicts them
+// silently once full.
+type RootCacheStats struct {
+	// Name is the kind of values cached: "basic", "basic_array" or "roots_array".
+	Name     string
+	Hits     uint64
+	Misses   uint64
+	Entries  uint64
+	Capacity uint64
+	// Bytes estimates the memory held by the entries, keys and roots.
+	Bytes uint64
+}
+
+// TypeCacheStats describes the cached roots of the fields of a struct type.
+type TypeCacheStats struct {
+	Type   string
+	Fields int
+	// Bytes estimates the memory held by the cached encodings, roots and trees.
+	Bytes uint64
+}
+
+// rootCacheCounters counts the lookups and insertions of a ristretto cache of roots.
+type rootCacheCounters struct {
+	hits     uint64
+	misses   uint64
+	sets     uint64
+	keyBytes uint64
+}
+
+func (c *rootCacheCounters) hit() {
+	atomic.AddUint64(&c.hits, 1)
+	countCacheHit()
+}
+
+func (c *rootCacheCounters) miss() {
+	atomic.AddUint64(&c.misses, 1)
+}
+
+func (c *rootCacheCounters) set(key string) {
+	atomic.AddUint64(&c.sets, 1)
+	atomic.AddUint64(&c.keyBytes, uint64(len(key)))
+}
+
+// stats describes a cache of maxCost whose lookups were counted by c.
+func (c *rootCacheCounters) stats(name string, maxCost int64) RootCacheStats {
+	sets := atomic.LoadUint64(&c.sets)
+	s := RootCacheStats{
+		Name:     name,
+		Hits:     atomic.LoadUint64(&c.hits),
+		Misses:   atomic.LoadUint64(&c.misses),
+		Entries:  sets,
+		Capacity: uint64(maxCost / rootCacheEntryCost),
+	}
+	if s.Entries > s.Capacity {
+		s.Entries = s.Capacity
+	}
+	if sets > 0 {
+		s.Bytes = s.Entries * (atomic.LoadUint64(&c.keyBytes)/sets + 32)
+	}
+	return s
+}
+
+// ReadCacheStats returns a snapshot of the statistics of the hash tree root caches.
+func ReadCacheStats() CacheStats {
+	s := CacheStats{
+		Roots: []RootCacheStats{
+			basicFactory.counters.stats("basic", basicCacheMaxCost),
+			basicArrayFactory.counters.stats("basic_array", basicArrayCacheMaxCost),
+			rootsArrayFactory.counters.stats("roots_array", rootsArrayCacheMaxCost),
+		},
+	}
+	byType := make(map[string]*TypeCacheStats)
+	add := func(fieldName string, bytes uint64) {
+		typeName := fieldName
+		if i := strings.LastIndex(fieldName, "."); i >= 0 {
+			typeName = fieldName[:i]
+		}
+		t, ok := byType[typeName]
+		if !ok {
+			t = &TypeCacheStats{Type: typeName}
+			byType[typeName] = t
+		}
+		t.Fields++
+		t.Bytes += bytes
+	}
+	containerElementCache.entries.Range(func(key, value interface{}) bool {
+		entry := value.(*elementCacheEntry)
+		entry.lock.Lock()
+		defer entry.lock.Unlock()
+		var bytes uint64
+		for _, enc := range entry.encodings {
+			bytes += uint64(len(enc))
+		}
+		// Every root is a leaf of the tree, which has about as many branch nodes.
+		bytes += uint64(len(entry.roots)) * 32 * 3
+		add(key.(string), bytes)
+		return true
+	})
+	rootsArrayFactory.lock.Lock()
+	for fieldName, layers := range rootsArrayFactory.layers {
+		bytes := uint64(len(rootsArrayFactory.cachedLeaves[fieldName])) * 32
+		for _, layer := range layers {
+			bytes += uint64(len(layer)) * 32
+		}
+		add(fieldName, bytes)
+	}
+	rootsArrayFactory.lock.Unlock()
+	for _, t := range byType {
+		s.Types = append(s.Types, *t)
+	}
+	sort.Slice(s.Types, func(i, j int) bool {
+		return s.Types[i].Type < s.Types[j].Type
+	})
+	typeDepths.Range(func(key, value interface{}) bool {
+		s.TypeInfo++
+		return true
+	})
+	return s
+}
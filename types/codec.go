@@ -0,0 +1,152 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sync/atomic"
+
+	"github.com/pkg/errors"
+)
+
+// Codec holds hand-written functions encoding, decoding and hashing the values of a
+// type, which are used in place of reflection once registered with RegisterCodec. Every
+// function receives a pointer to the value. Sizes are still derived from the type, so
+// the functions must implement the SSZ encoding of the type exactly.
+type Codec struct {
+	// MarshalTo appends the serialization of the value to dst.
+	MarshalTo func(val interface{}, dst []byte) ([]byte, error)
+	// Unmarshal decodes the serialization of the value from buf.
+	Unmarshal func(buf []byte, val interface{}) error
+	// HashTreeRoot returns the hash tree root of the value.
+	HashTreeRoot func(val interface{}) ([32]byte, error)
+}
+
+// codecs maps types to their registered codecs. It is replaced rather than mutated on
+// registration, so that lookups on the hot path do not take a lock.
+var codecs atomic.Value
+
+func init() {
+	codecs.Store(map[reflect.Type]*codecSSZ{})
+}
+
+// RegisterCodec registers a codec for the values of typ, wherever they are found, be it
+// at the top level, in a struct field or as list elements:
+//
+//  err := types.RegisterCodec(reflect.TypeOf(Validator{}), &types.Codec{
+//      MarshalTo:    marshalValidator,
+//      Unmarshal:    unmarshalValidator,
+//      HashTreeRoot: validatorRoot,
+//  })
+//
+// Values of other types keep going through reflection. A nil codec removes the codec
+// of typ. It returns ErrConfigLocked after LockConfig.
+func RegisterCodec(typ reflect.Type, codec *Codec) error {
+	if typ == nil {
+		return errors.New("untyped nil is not supported")
+	}
+	if typ.Kind() == reflect.Ptr {
+		return fmt.Errorf("codecs are registered for element types, received %v", typ)
+	}
+	if codec != nil && (codec.MarshalTo == nil || codec.Unmarshal == nil || codec.HashTreeRoot == nil) {
+		return fmt.Errorf("incomplete codec for type %v", typ)
+	}
+	configLock.Lock()
+	defer configLock.Unlock()
+	if locked {
+		return ErrConfigLocked
+	}
+	old := codecs.Load().(map[reflect.Type]*codecSSZ)
+	registered := make(map[reflect.Type]*codecSSZ, len(old)+1)
+	for t, c := range old {
+		registered[t] = c
+	}
+	if codec == nil {
+		delete(registered, typ)
+	} else {
+		registered[typ] = &codecSSZ{codec: *codec}
+	}
+	codecs.Store(registered)
+	resetRootPlans()
+	return nil
+}
+
+// hasCodec returns true if a codec is registered for typ.
+func hasCodec(typ reflect.Type) bool {
+	_, ok := registeredCodec(typ)
+	return ok
+}
+
+func registeredCodec(typ reflect.Type) (*codecSSZ, bool) {
+	registered := codecs.Load().(map[reflect.Type]*codecSSZ)
+	if len(registered) == 0 {
+		return nil, false
+	}
+	c, ok := registered[typ]
+	return c, ok
+}
+
+// codecSSZ implements SSZAble with the functions of a registered codec.
+type codecSSZ struct {
+	codec Codec
+}
+
+func (c *codecSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
+	root, err := c.codec.HashTreeRoot(pointerTo(val, typ).Interface())
+	if err != nil {
+		return [32]byte{}, errors.Wrapf(err, "codec for type %v", typ)
+	}
+	return root, nil
+}
+
+func (c *codecSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
+	out, err := c.codec.MarshalTo(pointerTo(val, typ).Interface(), buf[startOffset:startOffset])
+	if err != nil {
+		return 0, errors.Wrapf(err, "codec for type %v", typ)
+	}
+	// The buffer is sized from the type, so a codec writing past it disagrees with the
+	// SSZ encoding of the type.
+	if uint64(len(out)) > uint64(len(buf))-startOffset {
+		return 0, fmt.Errorf("codec for type %v wrote %d bytes, expected at most %d", typ, len(out), uint64(len(buf))-startOffset)
+	}
+	return startOffset + uint64(len(out)), nil
+}
+
+func (c *codecSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
+	if typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return startOffset, nil
+		}
+		return c.Unmarshal(val.Elem(), typ.Elem(), input, startOffset)
+	}
+	if !val.CanAddr() {
+		return 0, fmt.Errorf("cannot decode into unaddressable value of type %v", typ)
+	}
+	endOffset := uint64(len(input))
+	if !isVariableSizeType(typ) {
+		endOffset = startOffset + determineFixedSize(val, typ)
+	}
+	if startOffset > endOffset || endOffset > uint64(len(input)) {
+		return 0, fmt.Errorf("input of %d bytes is too short for type %v", len(input), typ)
+	}
+	if err := c.codec.Unmarshal(input[startOffset:endOffset], val.Addr().Interface()); err != nil {
+		return 0, errors.Wrapf(err, "codec for type %v", typ)
+	}
+	return endOffset, nil
+}
+
+// pointerTo returns a pointer to val, copying it if it is not addressable. Nil pointers
+// stand for zero values, as in the other factories.
+func pointerTo(val reflect.Value, typ reflect.Type) reflect.Value {
+	if typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return reflect.New(typ.Elem())
+		}
+		return val
+	}
+	if val.CanAddr() {
+		return val.Addr()
+	}
+	ptr := reflect.New(typ)
+	ptr.Elem().Set(val)
+	return ptr
+}
@@ -0,0 +1,119 @@
+package types
+
+import (
+	"errors"
+	"sync"
+	"sync/atomic"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// ErrConfigLocked is returned when package-level options are changed after LockConfig.
+var ErrConfigLocked = errors.New("ssz configuration is locked")
+
+// Config holds the package-level options of the codecs.
+type Config struct {
+	// Cache enables caching of hash tree roots, see ToggleCache.
+	Cache bool
+	// MapCodec enables the codec for map[uint64]T values, see ToggleMapCodec.
+	MapCodec bool
+	// HashWorkers is the number of goroutines hashing large lists, see SetHashWorkers.
+	HashWorkers int
+	// MaxDepth is the maximum nesting depth of decoded and hashed types, see
+	// SetMaxDepth.
+	MaxDepth int
+}
+
+var (
+	config     atomic.Value
+	configLock sync.Mutex
+	locked     bool
+)
+
+func init() {
+	config.Store(Config{})
+}
+
+// CurrentConfig returns a snapshot of the package-level options.
+func CurrentConfig() Config {
+	return config.Load().(Config)
+}
+
+// SetConfig replaces all package-level options at once, so that concurrent calls never
+// observe a mix of old and new options. It returns ErrConfigLocked after LockConfig.
+func SetConfig(c Config) error {
+	return updateConfig(func(old *Config) {
+		*old = c
+	})
+}
+
+// LockConfig makes the package-level options immutable. Large programs should configure
+// the codecs during initialization and then lock the configuration, such that a late
+// mutation from another component fails loudly instead of changing roots computed
+// concurrently:
+//
+//  func main() {
+//      if err := types.SetConfig(types.Config{Cache: true}); err != nil {
+//          log.Fatal(err)
+//      }
+//      types.LockConfig()
+//      ...
+//  }
+//
+// Locking cannot be undone.
+func LockConfig() {
+	configLock.Lock()
+	defer configLock.Unlock()
+	locked = true
+}
+
+// ConfigLocked returns true if LockConfig was called.
+func ConfigLocked() bool {
+	configLock.Lock()
+	defer configLock.Unlock()
+	return locked
+}
+
+func updateConfig(update func(*Config)) error {
+	configLock.Lock()
+	defer configLock.Unlock()
+	if locked {
+		return ErrConfigLocked
+	}
+	c := CurrentConfig()
+	update(&c)
+	config.Store(c)
+	return nil
+}
+
+// cacheEnabled returns true if roots can be cached. Caches hold sha256 roots only, so
+// they are bypassed while a custom tree hash is in use.
+func cacheEnabled() bool {
+	return CurrentConfig().Cache && !hashing.CustomTreeHash()
+}
+
+func mapCodecEnabled() bool {
+	return CurrentConfig().MapCodec
+}
+
+// SetHashBackend replaces the sha256 implementation used for hashing, once it is checked
+// against the standard library. It returns ErrConfigLocked after LockConfig.
+func SetHashBackend(b hashing.Backend) error {
+	configLock.Lock()
+	defer configLock.Unlock()
+	if locked {
+		return ErrConfigLocked
+	}
+	return hashing.SetBackend(b)
+}
+
+// SetTreeHash replaces the hash function of Merkleization by one other than sha256, see
+// ssz.SetTreeHash. It returns ErrConfigLocked after LockConfig.
+func SetTreeHash(b hashing.Backend) error {
+	configLock.Lock()
+	defer configLock.Unlock()
+	if locked {
+		return ErrConfigLocked
+	}
+	return hashing.SetTreeHash(b)
+}
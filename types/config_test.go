@@ -0,0 +1,45 @@
+package types
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestLockConfig(t *testing.T) {
+	defer func() {
+		configLock.Lock()
+		locked = false
+		configLock.Unlock()
+		config.Store(Config{})
+	}()
+	if err := SetConfig(Config{Cache: true, MapCodec: true}); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if c := CurrentConfig(); !c.Cache || !c.MapCodec {
+				t.Errorf("Unexpected config snapshot %+v", c)
+			}
+		}()
+	}
+	wg.Wait()
+	LockConfig()
+	if !ConfigLocked() {
+		t.Error("Expected config to be locked")
+	}
+	if err := ToggleCache(false); err != ErrConfigLocked {
+		t.Errorf("Wanted %v, received %v", ErrConfigLocked, err)
+	}
+	if err := ToggleMapCodec(false); err != ErrConfigLocked {
+		t.Errorf("Wanted %v, received %v", ErrConfigLocked, err)
+	}
+	if err := SetConfig(Config{}); err != ErrConfigLocked {
+		t.Errorf("Wanted %v, received %v", ErrConfigLocked, err)
+	}
+	if c := CurrentConfig(); !c.Cache || !c.MapCodec {
+		t.Errorf("Locked config was mutated: %+v", c)
+	}
+}
@@ -0,0 +1,23 @@
+package types
+
+import (
+	"sync/atomic"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+var cacheHits uint64
+
+// CacheHits returns the number of roots served by the hash tree root caches while
+// statistics were being collected, including element roots reused by the element
+// roots cache.
+func CacheHits() uint64 {
+	return atomic.LoadUint64(&cacheHits)
+}
+
+// countCacheHit records a cache hit if statistics are being collected.
+func countCacheHit() {
+	if hashing.Counting() {
+		atomic.AddUint64(&cacheHits, 1)
+	}
+}
@@ -0,0 +1,94 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// DefaultMaxDepth is the maximum nesting depth of decoded and hashed types unless
+// configured otherwise with SetMaxDepth. Beacon chain types nest less than a dozen
+// levels deep.
+const DefaultMaxDepth = 64
+
+// ErrMaxDepthExceeded is returned when a type nests deeper than the maximum depth, or
+// refers to itself, such that walking its values could exhaust the stack.
+var ErrMaxDepthExceeded = errors.New("maximum nesting depth exceeded")
+
+// unboundedDepth is the depth of self-referential types.
+const unboundedDepth = int(^uint(0) >> 1)
+
+// typeDepths caches the nesting depth of types, which is only ever computed once.
+var typeDepths sync.Map
+
+// SetMaxDepth sets the maximum number of nested pointers, structs, lists, vectors and
+// maps of the types decoded and hashed, 0 restoring DefaultMaxDepth. It returns
+// ErrConfigLocked after LockConfig.
+func SetMaxDepth(n int) error {
+	return updateConfig(func(c *Config) {
+		c.MaxDepth = n
+	})
+}
+
+// CheckDepth returns ErrMaxDepthExceeded if typ nests deeper than the maximum depth.
+// SSZ has no recursive types, so types which refer to themselves, such as a struct with
+// a pointer to its own type, are rejected whatever the depth of their values.
+func CheckDepth(typ reflect.Type) error {
+	max := CurrentConfig().MaxDepth
+	if max <= 0 {
+		max = DefaultMaxDepth
+	}
+	depth, ok := typeDepths.Load(typ)
+	if !ok {
+		depth = typeDepth(typ, make(map[reflect.Type]bool))
+		typeDepths.Store(typ, depth)
+	}
+	if depth.(int) > max {
+		return ErrMaxDepthExceeded
+	}
+	return nil
+}
+
+// typeDepth returns the number of nested composite types of typ, or unboundedDepth if
+// typ is reachable from itself. Types with a registered codec are decoded and hashed by
+// the codec, and count as a single level.
+func typeDepth(typ reflect.Type, onStack map[reflect.Type]bool) int {
+	if _, ok := registeredCodec(typ); ok {
+		return 1
+	}
+	var elems []reflect.Type
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array, reflect.Map:
+		elems = append(elems, typ.Elem())
+	case reflect.Struct:
+		for i := 0; i < typ.NumField(); i++ {
+			field := typ.Field(i)
+			if SkipField(field) {
+				continue
+			}
+			fType, err := determineFieldType(field)
+			if err != nil {
+				fType = field.Type
+			}
+			elems = append(elems, fType)
+		}
+	default:
+		return 0
+	}
+	if onStack[typ] {
+		return unboundedDepth
+	}
+	onStack[typ] = true
+	defer delete(onStack, typ)
+	depth := 0
+	for _, elem := range elems {
+		d := typeDepth(elem, onStack)
+		if d == unboundedDepth {
+			return unboundedDepth
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth + 1
+}
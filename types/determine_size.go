@@ -2,7 +2,6 @@ package types
 
 import (
 	"reflect"
-	"strings"
 )
 
 // DetermineSize returns the required byte size of a buffer for
@@ -20,6 +19,21 @@ func DetermineSize(val reflect.Value) uint64 {
 	return determineFixedSize(val, val.Type())
 }
 
+// SizeOf returns the serialized byte size of a value when encoded as the given type,
+// which differs from the value's own type for struct fields with ssz-size tags.
+func SizeOf(val reflect.Value, typ reflect.Type) uint64 {
+	if isVariableSizeType(typ) {
+		return determineVariableSize(val, typ)
+	}
+	return determineFixedSize(val, typ)
+}
+
+// IsVariableSize returns true if the serialized size of a type depends on its value,
+// in which case it is referenced through an offset when nested in a container or list.
+func IsVariableSize(typ reflect.Type) bool {
+	return isVariableSizeType(typ)
+}
+
 func isBasicType(kind reflect.Kind) bool {
 	return kind == reflect.Bool ||
 		kind == reflect.Int32 ||
@@ -51,11 +65,17 @@ func isVariableSizeType(typ reflect.Type) bool {
 		return true
 	case kind == reflect.String:
 		return true
+	case kind == reflect.Map:
+		return true
+	case isUnionType(typ) || isOptionalType(typ) || isStableContainerType(typ):
+		return true
+	case isProfileType(typ):
+		return isVariableProfile(typ)
 	case kind == reflect.Array:
 		return isVariableSizeType(typ.Elem())
 	case kind == reflect.Struct:
 		for i := 0; i < typ.NumField(); i++ {
-			if strings.Contains(typ.Field(i).Name, "XXX_") {
+			if SkipField(typ.Field(i)) {
 				continue
 			}
 			f := typ.Field(i)
@@ -97,10 +117,12 @@ func determineFixedSize(val reflect.Value, typ reflect.Type) uint64 {
 			num += determineFixedSize(val.Index(i), typ.Elem())
 		}
 		return num
+	case isProfileType(typ):
+		return stableSize(val, typ)
 	case kind == reflect.Struct:
 		totalSize := uint64(0)
 		for i := 0; i < typ.NumField(); i++ {
-			if strings.Contains(typ.Field(i).Name, "XXX_") {
+			if SkipField(typ.Field(i)) {
 				continue
 			}
 			f := typ.Field(i)
@@ -108,7 +130,8 @@ func determineFixedSize(val reflect.Value, typ reflect.Type) uint64 {
 			if err != nil {
 				return 0
 			}
-			totalSize += determineFixedSize(val.Field(i), fType)
+			fieldVal, _ := vectorValue(val.Field(i), fType)
+			totalSize += determineFixedSize(fieldVal, fType)
 		}
 		return totalSize
 	case kind == reflect.Ptr:
@@ -125,10 +148,22 @@ func determineFixedSize(val reflect.Value, typ reflect.Type) uint64 {
 func determineVariableSize(val reflect.Value, typ reflect.Type) uint64 {
 	kind := typ.Kind()
 	switch {
+	case typ == bitlistType && val.Len() == 0:
+		// An empty bitlist is encoded as its delimiter bit alone.
+		return 1
 	case kind == reflect.Slice && typ.Elem().Kind() == reflect.Uint8:
 		return uint64(val.Len())
 	case kind == reflect.String:
 		return uint64(val.Len())
+	case kind == reflect.Map:
+		entries := mapEntries(val, typ)
+		return determineVariableSize(entries, entries.Type())
+	case isUnionType(typ):
+		return unionSize(val, typ)
+	case isOptionalType(typ):
+		return optionalSize(val, typ)
+	case isStableType(typ):
+		return stableSize(val, typ)
 	case kind == reflect.Slice || kind == reflect.Array:
 		totalSize := uint64(0)
 		for i := 0; i < val.Len(); i++ {
@@ -143,7 +178,7 @@ func determineVariableSize(val reflect.Value, typ reflect.Type) uint64 {
 	case kind == reflect.Struct:
 		totalSize := uint64(0)
 		for i := 0; i < typ.NumField(); i++ {
-			if strings.Contains(typ.Field(i).Name, "XXX_") {
+			if SkipField(typ.Field(i)) {
 				continue
 			}
 			f := typ.Field(i)
@@ -151,11 +186,12 @@ func determineVariableSize(val reflect.Value, typ reflect.Type) uint64 {
 			if err != nil {
 				return 0
 			}
+			fieldVal, _ := vectorValue(val.Field(i), fType)
 			if isVariableSizeType(fType) {
-				varSize := determineVariableSize(val.Field(i), fType)
+				varSize := determineVariableSize(fieldVal, fType)
 				totalSize += varSize + BytesPerLengthOffset
 			} else {
-				varSize := determineFixedSize(val.Field(i), fType)
+				varSize := determineFixedSize(fieldVal, fType)
 				totalSize += varSize
 			}
 		}
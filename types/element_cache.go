@@ -0,0 +1,124 @@
+package types
+
+import (
+	"bytes"
+	"reflect"
+	"sync"
+
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+// elementCacheEntry holds the encodings and roots of the elements of a single field
+// from the previous hash tree root computation, along with the Merkle tree built
+// over those roots.
+type elementCacheEntry struct {
+	lock      sync.Mutex
+	encodings [][]byte
+	roots     [][32]byte
+	depth     uint8
+	node      *tree.Node
+	// generation counts the updates of the entry, so that an update computed from an
+	// outdated copy of the entry rebuilds its tree rather than patching it.
+	generation uint64
+}
+
+// elementRootsCache caches the element roots of vectors and lists of fixed-size
+// containers by field name. Elements are compared against their cached encoding, which
+// is far cheaper than hashing them, so only elements which changed or were appended
+// since the previous call are re-hashed, along with their branches of the Merkle tree.
+// Every field has a lock of its own, so that different fields, such as those of the
+// states of several goroutines, are hashed concurrently.
+type elementRootsCache struct {
+	entries sync.Map
+}
+
+var containerElementCache = &elementRootsCache{}
+
+// useElementCache returns true if the elements of a sequence of type elemTyp held by the
+// given field can have their roots cached.
+func useElementCache(fieldName string, elemTyp reflect.Type) bool {
+	if !cacheEnabled() || fieldName == "" {
+		return false
+	}
+	if elemTyp.Kind() == reflect.Ptr {
+		elemTyp = elemTyp.Elem()
+	}
+	return elemTyp.Kind() == reflect.Struct && !isVariableSizeType(elemTyp)
+}
+
+// merkleize returns the root of a Merkle tree of the given depth whose leaves are the
+// roots of the elements of val. The lock of the entry is not held while the roots of the
+// elements are computed, as elements may hold fields sharing the same name, whose entry
+// would then be locked twice.
+func (c *elementRootsCache) merkleize(fieldName string, val reflect.Value, elemTyp reflect.Type, depth uint8) ([32]byte, error) {
+	e, ok := c.entries.Load(fieldName)
+	if !ok {
+		e, _ = c.entries.LoadOrStore(fieldName, &elementCacheEntry{depth: depth})
+	}
+	entry := e.(*elementCacheEntry)
+	entry.lock.Lock()
+	prevEncodings, prevRoots, generation := entry.encodings, entry.roots, entry.generation
+	if entry.depth != depth {
+		prevEncodings, prevRoots = nil, nil
+	}
+	entry.lock.Unlock()
+
+	numItems := val.Len()
+	encodings := make([][]byte, numItems)
+	roots := make([][32]byte, numItems)
+	changed := make([]int, 0)
+	for i := 0; i < numItems; i++ {
+		elem := val.Index(i)
+		factory, err := SSZFactory(elem, elemTyp)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		enc := make([]byte, determineFixedSize(elem, elemTyp))
+		if _, err := factory.Marshal(elem, elemTyp, enc, 0); err != nil {
+			return [32]byte{}, err
+		}
+		encodings[i] = enc
+		if i < len(prevEncodings) && bytes.Equal(prevEncodings[i], enc) {
+			roots[i] = prevRoots[i]
+			countCacheHit()
+			continue
+		}
+		roots[i], err = factory.Root(elem, elemTyp, "", 0)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		changed = append(changed, i)
+	}
+
+	entry.lock.Lock()
+	defer entry.lock.Unlock()
+	if entry.node == nil || entry.depth != depth || entry.generation != generation {
+		// The entry is empty, or was updated by another call since it was read.
+		node, err := tree.FromChunks(roots, depth)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		entry.node = node
+	} else {
+		node := entry.node
+		var err error
+		firstLeaf := uint64(1) << depth
+		for _, i := range changed {
+			if node, err = node.Set(firstLeaf+uint64(i), tree.Leaf(roots[i])); err != nil {
+				return [32]byte{}, err
+			}
+		}
+		// Elements removed since the previous call are replaced by zero leaves.
+		for i := numItems; i < len(entry.roots); i++ {
+			if node, err = node.Set(firstLeaf+uint64(i), tree.Leaf([32]byte{})); err != nil {
+				return [32]byte{}, err
+			}
+		}
+		entry.node = node
+	}
+	entry.encodings = encodings
+	entry.roots = roots
+	entry.depth = depth
+	entry.generation++
+	return entry.node.Root(), nil
+}
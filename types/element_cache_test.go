@@ -0,0 +1,144 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/protolambda/zssz/merkle"
+)
+
+type cachedCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+func checkpoints(n int, seed byte) []cachedCheckpoint {
+	items := make([]cachedCheckpoint, n)
+	for i := range items {
+		items[i] = cachedCheckpoint{Epoch: uint64(i), Root: [32]byte{seed, byte(i)}}
+	}
+	return items
+}
+
+func TestElementRootsCache_ConcurrentFields(t *testing.T) {
+	c := &elementRootsCache{}
+	elemTyp := reflect.TypeOf(cachedCheckpoint{})
+	depth := merkle.GetDepth(64)
+	var wg sync.WaitGroup
+	for f := 0; f < 8; f++ {
+		wg.Add(1)
+		go func(f int) {
+			defer wg.Done()
+			items := checkpoints(64, byte(f))
+			val := reflect.ValueOf(items)
+			for i := 0; i < 4; i++ {
+				items[i].Epoch++
+				want, err := merkleizeElements(val, elemTyp, depth)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				got, err := c.merkleize(fmt.Sprintf("State.Field%d", f), val, elemTyp, depth)
+				if err != nil {
+					t.Error(err)
+					return
+				}
+				if got != want {
+					t.Errorf("Field %d: wanted root %#x, received %#x", f, want, got)
+				}
+			}
+		}(f)
+	}
+	wg.Wait()
+}
+
+// TestElementRootsCache_NestedSameKey hashes a vector of containers whose elements hold
+// a field of the same name, and so share its cache entry, from several goroutines.
+func TestElementRootsCache_NestedSameKey(t *testing.T) {
+	defer config.Store(Config{})
+	// Fields of unnamed structs are all keyed ".Items".
+	type inner = struct {
+		Items [2]cachedCheckpoint
+	}
+	type outer = struct {
+		Items [3]inner
+	}
+	item := outer{}
+	for i := range item.Items {
+		for j := range item.Items[i].Items {
+			item.Items[i].Items[j] = cachedCheckpoint{Epoch: uint64(3*i + j), Root: [32]byte{byte(i), byte(j)}}
+		}
+	}
+	val := reflect.ValueOf(item)
+	want, err := StructFactory.Root(val, val.Type(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ToggleCache(true); err != nil {
+		t.Fatal(err)
+	}
+	done := make(chan error)
+	for g := 0; g < 4; g++ {
+		go func() {
+			for i := 0; i < 8; i++ {
+				got, err := StructFactory.Root(val, val.Type(), "", 0)
+				if err != nil {
+					done <- err
+					return
+				}
+				if got != want {
+					done <- fmt.Errorf("wanted root %#x, received %#x", want, got)
+					return
+				}
+			}
+			done <- nil
+		}()
+	}
+	for g := 0; g < 4; g++ {
+		select {
+		case err := <-done:
+			if err != nil {
+				t.Error(err)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("Hashing nested fields sharing a cache entry did not complete")
+		}
+	}
+}
+
+// merkleizeElements computes the root of the elements of val without a cache.
+func merkleizeElements(val reflect.Value, elemTyp reflect.Type, depth uint8) ([32]byte, error) {
+	roots := make([][32]byte, val.Len())
+	for i := range roots {
+		r, err := StructFactory.Root(val.Index(i), elemTyp, "", 0)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		roots[i] = r
+	}
+	return merkleizeLayer(roots, depth, 1), nil
+}
+
+// BenchmarkElementRootsCache_Parallel hashes a different field from every goroutine, as
+// when several states are hashed at once, with a single element changed between calls.
+func BenchmarkElementRootsCache_Parallel(b *testing.B) {
+	c := &elementRootsCache{}
+	elemTyp := reflect.TypeOf(cachedCheckpoint{})
+	depth := merkle.GetDepth(1024)
+	var fields int32
+	b.RunParallel(func(pb *testing.PB) {
+		field := fmt.Sprintf("State.Field%d", atomic.AddInt32(&fields, 1))
+		items := checkpoints(1024, 1)
+		val := reflect.ValueOf(items)
+		for i := 0; pb.Next(); i++ {
+			items[i%len(items)].Epoch++
+			if _, err := c.merkleize(field, val, elemTyp, depth); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
@@ -1,15 +1,16 @@
 package types
 
 import (
-	"fmt"
 	"reflect"
 )
 
-var enableCache = false
-
 // ToggleCache enables caching of ssz hash tree root. It is disabled by default.
-func ToggleCache(val bool) {
-	enableCache = val
+// It returns ErrConfigLocked after LockConfig. As it applies to the whole program,
+// libraries should rather control the caching of their own types with CacheFor.
+func ToggleCache(val bool) error {
+	return updateConfig(func(c *Config) {
+		c.Cache = val
+	})
 }
 
 // StructFactory exports an implementation of a interface
@@ -23,6 +24,11 @@ var compositeArrayFactory = newCompositeArraySSZ()
 var basicSliceFactory = newBasicSliceSSZ()
 var stringFactory = newStringSSZ()
 var compositeSliceFactory = newCompositeSliceSSZ()
+var mapFactory = newMapSSZ()
+var unionFactory = newUnionSSZ()
+var optionalFactory = newOptionalSSZ()
+var stableContainerFactory = newStableContainerSSZ()
+var bitvectorFactory = newBitvectorSSZ()
 
 // SSZAble defines a type which can marshal/unmarshal and compute its
 // hash tree root according to the Simple Serialize specification.
@@ -38,12 +44,17 @@ type SSZAble interface {
 // SSZ-able that contains marshal, unmarshal, and hash tree root related
 // functions for use.
 func SSZFactory(val reflect.Value, typ reflect.Type) (SSZAble, error) {
+	if c, ok := registeredCodec(typ); ok {
+		return c, nil
+	}
 	kind := typ.Kind()
 	switch {
 	case isBasicType(kind) || isBasicTypeArray(typ, typ.Kind()):
 		return basicFactory, nil
 	case kind == reflect.String:
 		return stringFactory, nil
+	case isBitvectorType(typ):
+		return bitvectorFactory, nil
 	case kind == reflect.Slice:
 		switch {
 		case isBasicType(typ.Elem().Kind()):
@@ -64,11 +75,22 @@ func SSZFactory(val reflect.Value, typ reflect.Type) (SSZAble, error) {
 		default:
 			return compositeArrayFactory, nil
 		}
+	case kind == reflect.Struct && isUnionType(typ):
+		return unionFactory, nil
+	case kind == reflect.Struct && isOptionalType(typ):
+		return optionalFactory, nil
+	case kind == reflect.Struct && isStableType(typ):
+		return stableContainerFactory, nil
 	case kind == reflect.Struct:
 		return StructFactory, nil
 	case kind == reflect.Ptr:
 		return SSZFactory(val.Elem(), typ.Elem())
+	case kind == reflect.Map:
+		if !mapCodecEnabled() || typ.Key().Kind() != reflect.Uint64 {
+			return nil, newUnsupportedTypeError(typ)
+		}
+		return mapFactory, nil
 	default:
-		return nil, fmt.Errorf("unsupported kind: %v", kind)
+		return nil, newUnsupportedTypeError(typ)
 	}
 }
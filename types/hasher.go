@@ -0,0 +1,312 @@
+package types
+
+import (
+	"context"
+	"encoding/binary"
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// Hasher computes hash tree roots using scratch buffers which are kept across calls,
+// so that hashing the same kind of object repeatedly does not reallocate the chunks
+// of every list and container it holds. The zero value is ready to use. A Hasher is
+// not safe for concurrent use.
+//
+// Roots are identical to the ones computed by the SSZ factories, but the hash tree root
+// caches are bypassed, which makes a Hasher best suited to hashing many distinct objects.
+// The fields of containers are hashed by following a plan compiled once per type, which
+// serializes basic values and short byte vectors straight into their chunks.
+type Hasher struct {
+	// chunks is a stack of the chunks pending merkleization, each nested value pushing
+	// its chunks on top of the ones of its parent and merkleizing them in place.
+	chunks [][32]byte
+	// buf holds the serialization of basic values before they are packed into chunks.
+	buf []byte
+	// pairs holds pairs of chunks which are hashed together, in a single call.
+	pairs [][32]byte
+	// ctx, if set, aborts the computation of roots once done.
+	ctx context.Context
+}
+
+// contextCheckInterval is the number of elements hashed between checks of the context.
+const contextCheckInterval = 256
+
+// SetContext makes the computation of roots check ctx periodically, such as every few
+// hundred elements of a list, and return its error once it is cancelled or its deadline
+// passes. A nil context is never checked.
+func (h *Hasher) SetContext(ctx context.Context) {
+	h.ctx = ctx
+}
+
+// checkContext returns the error of the context of the hasher, if any.
+func (h *Hasher) checkContext() error {
+	if h.ctx == nil {
+		return nil
+	}
+	return h.ctx.Err()
+}
+
+// Root returns the hash tree root of val encoded as typ. The max capacity is the
+// ssz-max limit of val if it is a list, or 0 otherwise.
+func (h *Hasher) Root(val reflect.Value, typ reflect.Type, maxCapacity uint64) ([32]byte, error) {
+	h.chunks = h.chunks[:0]
+	return h.root(val, typ, maxCapacity)
+}
+
+func (h *Hasher) root(val reflect.Value, typ reflect.Type, maxCapacity uint64) ([32]byte, error) {
+	kind := typ.Kind()
+	if c, ok := registeredCodec(typ); ok {
+		return c.Root(val, typ, "", maxCapacity)
+	}
+	switch {
+	case kind == reflect.Ptr:
+		if val.IsNil() {
+			return h.root(reflect.New(typ.Elem()).Elem(), typ.Elem(), maxCapacity)
+		}
+		if root, ok := PinnedRoot(val); ok {
+			return root, nil
+		}
+		return h.root(val.Elem(), typ.Elem(), maxCapacity)
+	case isBasicType(kind):
+		h.buf = appendBasic(h.buf[:0], val, kind)
+		return h.packed(h.buf, 0)
+	case isByteVector(typ):
+		h.buf = appendByteVector(h.buf[:0], val, typ.Len())
+		return h.packed(h.buf, 0)
+	case isBasicTypeArray(typ, kind):
+		h.buf = h.buf[:0]
+		for i := 0; i < typ.Len(); i++ {
+			if i < val.Len() {
+				h.buf = appendBasic(h.buf, val.Index(i), typ.Elem().Kind())
+			} else {
+				h.buf = appendBasic(h.buf, reflect.Zero(typ.Elem()), typ.Elem().Kind())
+			}
+		}
+		return h.packed(h.buf, 0)
+	case kind == reflect.String:
+		h.buf = append(h.buf[:0], val.String()...)
+		limit := (maxCapacity + 31) / 32
+		if limit == 0 {
+			limit = 1
+		}
+		root, err := h.packed(h.buf, limit)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return hashing.MixInLength(root, uint64(val.Len())), nil
+	case isBitvectorType(typ):
+		return bitvectorFactory.Root(val, typ, "", maxCapacity)
+	case kind == reflect.Slice && isBasicType(typ.Elem().Kind()):
+		elemKind := typ.Elem().Kind()
+		h.buf = h.buf[:0]
+		for i := 0; i < val.Len(); i++ {
+			h.buf = appendBasic(h.buf, val.Index(i), elemKind)
+		}
+		return h.packedList(val.Len(), basicSize(elemKind), maxCapacity)
+	case kind == reflect.Slice:
+		limit := maxCapacity
+		if limit == 0 {
+			limit = uint64(val.Len())
+		}
+		root, err := h.elements(val, typ.Elem(), val.Len(), limit)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return hashing.MixInLength(root, uint64(val.Len())), nil
+	case kind == reflect.Array:
+		if val.Kind() == reflect.Slice && val.Len() != typ.Len() {
+			padded := reflect.MakeSlice(val.Type(), typ.Len(), typ.Len())
+			reflect.Copy(padded, val)
+			val = padded
+		}
+		return h.elements(val, typ.Elem(), typ.Len(), uint64(typ.Len()))
+	case kind == reflect.Struct && !isUnionType(typ) && !isOptionalType(typ) && !isStableType(typ):
+		return h.fields(val, typ)
+	default:
+		// Values which are not part of the hot path, such as maps, unions, optionals,
+		// stable containers and profiles, are hashed by their factory.
+		factory, err := SSZFactory(val, typ)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return factory.Root(val, typ, "", maxCapacity)
+	}
+}
+
+// elements merkleizes the roots of the first n elements of a sequence of composite
+// elements. Unless they are hashed across workers, containers are hashed as a batch.
+func (h *Hasher) elements(val reflect.Value, elemTyp reflect.Type, n int, limit uint64) ([32]byte, error) {
+	base := len(h.chunks)
+	if workers := hashWorkers(n); workers > 1 {
+		// Every worker hashes its elements with a Hasher of its own.
+		h.chunks = append(h.chunks, make([][32]byte, n)...)
+		roots := h.chunks[base:]
+		err := parallelFor(n, workers, func(start, end int) error {
+			wh := &Hasher{ctx: h.ctx}
+			for i := start; i < end; i++ {
+				if (i-start)%contextCheckInterval == 0 {
+					if err := wh.checkContext(); err != nil {
+						return err
+					}
+				}
+				r, err := wh.Root(val.Index(i), elemTyp, 0)
+				if err != nil {
+					return err
+				}
+				roots[i] = r
+			}
+			return nil
+		})
+		if err != nil {
+			h.chunks = h.chunks[:base]
+			return [32]byte{}, err
+		}
+		return h.merkleize(base, limit)
+	}
+	if isContainerType(elemTyp) {
+		return h.containers(val, n, limit)
+	}
+	for i := 0; i < n; i++ {
+		if i%contextCheckInterval == 0 {
+			if err := h.checkContext(); err != nil {
+				h.chunks = h.chunks[:base]
+				return [32]byte{}, err
+			}
+		}
+		r, err := h.root(val.Index(i), elemTyp, 0)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		h.chunks = append(h.chunks, r)
+	}
+	return h.merkleize(base, limit)
+}
+
+// fields merkleizes the roots of the fields of a container.
+func (h *Hasher) fields(val reflect.Value, typ reflect.Type) ([32]byte, error) {
+	base := len(h.chunks)
+	if err := h.pushFields(val, typ); err != nil {
+		h.chunks = h.chunks[:base]
+		return [32]byte{}, err
+	}
+	count := uint64(len(h.chunks) - base)
+	return h.merkleize(base, count)
+}
+
+// pushFields pushes the roots of the fields of a container on top of the stack, as
+// given by the plan compiled for its type.
+func (h *Hasher) pushFields(val reflect.Value, typ reflect.Type) error {
+	return planFor(typ).run(h, val)
+}
+
+// packedList merkleizes a list of n basic values of the given size, serialized in h.buf,
+// and mixes in its length.
+func (h *Hasher) packedList(n int, elemSize uint64, maxCapacity uint64) ([32]byte, error) {
+	limit := (maxCapacity*elemSize + 31) / 32
+	if limit == 0 {
+		limit = uint64(n)
+	}
+	if limit == 0 {
+		limit = 1
+	}
+	root, err := h.packed(h.buf, limit)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.MixInLength(root, uint64(n)), nil
+}
+
+// packed packs serialized basic values into chunks and merkleizes them. A limit of 0
+// stands for the number of chunks.
+func (h *Hasher) packed(buf []byte, limit uint64) ([32]byte, error) {
+	base := len(h.chunks)
+	for i := 0; i < len(buf); i += 32 {
+		var chunk [32]byte
+		copy(chunk[:], buf[i:])
+		h.chunks = append(h.chunks, chunk)
+	}
+	if limit == 0 {
+		limit = uint64(len(h.chunks) - base)
+	}
+	return h.merkleize(base, limit)
+}
+
+// merkleize computes the root of the chunks on top of the stack, starting at base, in
+// a tree whose depth is given by limit. The chunks are hashed in place and popped off
+// the stack.
+func (h *Hasher) merkleize(base int, limit uint64) ([32]byte, error) {
+	layer := h.chunks[base:]
+	defer func() {
+		h.chunks = h.chunks[:base]
+	}()
+	if uint64(len(layer)) > limit {
+		return [32]byte{}, errors.New("merkleizing list that is too large, over limit")
+	}
+	if len(layer) >= contextCheckInterval {
+		if err := h.checkContext(); err != nil {
+			return [32]byte{}, err
+		}
+	}
+	return merkleizeLayer(layer, merkle.GetDepth(limit), hashWorkers(len(layer))), nil
+}
+
+// appendBasic appends the little-endian serialization of a basic value to buf.
+func appendBasic(buf []byte, val reflect.Value, kind reflect.Kind) []byte {
+	var scratch [8]byte
+	switch kind {
+	case reflect.Bool:
+		if val.Bool() {
+			return append(buf, 1)
+		}
+		return append(buf, 0)
+	case reflect.Uint8:
+		return append(buf, uint8(val.Uint()))
+	case reflect.Uint16:
+		binary.LittleEndian.PutUint16(scratch[:], uint16(val.Uint()))
+		return append(buf, scratch[:2]...)
+	case reflect.Int32:
+		binary.LittleEndian.PutUint32(scratch[:], uint32(val.Int()))
+		return append(buf, scratch[:4]...)
+	case reflect.Uint32:
+		binary.LittleEndian.PutUint32(scratch[:], uint32(val.Uint()))
+		return append(buf, scratch[:4]...)
+	default:
+		binary.LittleEndian.PutUint64(scratch[:], val.Uint())
+		return append(buf, scratch[:8]...)
+	}
+}
+
+// appendByteVector appends a byte vector of length n, held in a byte slice or array, to
+// buf, copying its bytes at once rather than one element at a time. Vectors held in
+// shorter slices are padded with zero bytes.
+func appendByteVector(buf []byte, val reflect.Value, n int) []byte {
+	start := len(buf)
+	buf = append(buf, make([]byte, n)...)
+	switch {
+	case val.Kind() == reflect.Slice:
+		copy(buf[start:], val.Bytes())
+	case val.CanAddr():
+		// Slicing the array, such as a commitment in a list, does not allocate.
+		copy(buf[start:], val.Slice(0, val.Len()).Bytes())
+	default:
+		reflect.Copy(reflect.ValueOf(buf[start:]), val)
+	}
+	return buf
+}
+
+// basicSize returns the serialized size of a basic type.
+func basicSize(kind reflect.Kind) uint64 {
+	switch kind {
+	case reflect.Bool, reflect.Uint8:
+		return 1
+	case reflect.Uint16:
+		return 2
+	case reflect.Int32, reflect.Uint32:
+		return 4
+	default:
+		return 8
+	}
+}
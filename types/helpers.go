@@ -5,9 +5,8 @@ import (
 	"errors"
 	"reflect"
 
-	"github.com/minio/sha256-simd"
-	"github.com/protolambda/zssz/htr"
 	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
 )
 
 var (
@@ -15,30 +14,37 @@ var (
 	BytesPerChunk = 32
 	// BytesPerLengthOffset defines a constant for off-setting serialized chunks.
 	BytesPerLengthOffset = uint64(4)
-	zeroHashes           = make([][32]byte, 100)
 )
 
-func init() {
-	for i := 1; i < 100; i++ {
-		leaf := append(zeroHashes[i-1][:], zeroHashes[i-1][:]...)
-		result := hash(leaf)
-		copy(zeroHashes[i][:], result[:])
-	}
-}
+// maxInt is the largest value of the int type, which is 32 bits wide on some platforms.
+const maxInt = uint64(^uint(0) >> 1)
 
-// Given ordered BYTES_PER_CHUNK-byte chunks, if necessary utilize zero chunks so that the
-// number of chunks is a power of two, Merkleize the chunks, and return the root.
+// Given ordered BYTES_PER_CHUNK-byte chunks, Merkleize the chunks in a tree with room
+// for limit chunks, and return the root. The tree is padded with the precomputed roots
+// of zero subtrees, so that no zero chunk is allocated or hashed.
 // Note that merkleize on a single chunk is simply that chunk, i.e. the identity
 // when the number of chunks is one.
 func bitwiseMerkleize(chunks [][]byte, count uint64, limit uint64) ([32]byte, error) {
 	if count > limit {
 		return [32]byte{}, errors.New("merkleizing list that is too large, over limit")
 	}
-	hasher := htr.HashFn(hash)
-	leafIndexer := func(i uint64) []byte {
-		return chunks[i]
+	layer := make([][32]byte, count)
+	for i := range layer {
+		copy(layer[i][:], chunks[i])
+	}
+	return merkleizeLayer(layer, merkle.GetDepth(limit), hashWorkers(int(count))), nil
+}
+
+// MerkleizeChunks returns the root of a Merkle tree with room for limit chunks whose
+// first leaves are chunks, the others being zero chunks. It returns an error if there
+// are more chunks than the limit.
+func MerkleizeChunks(chunks [][32]byte, limit uint64) ([32]byte, error) {
+	if uint64(len(chunks)) > limit {
+		return [32]byte{}, errors.New("merkleizing list that is too large, over limit")
 	}
-	return merkle.Merkleize(hasher, count, limit, leafIndexer), nil
+	// Layers are hashed in place, which must not overwrite the chunks of the caller.
+	layer := append(make([][32]byte, 0, len(chunks)), chunks...)
+	return merkleizeLayer(layer, merkle.GetDepth(limit), hashWorkers(len(layer))), nil
 }
 
 // Given ordered objects of the same basic type, serialize them, pack them into BYTES_PER_CHUNK-byte
@@ -91,21 +97,6 @@ func pack(serializedItems [][]byte) ([][]byte, error) {
 	return chunks, nil
 }
 
-// Given a Merkle root root and a length length ("uint256" little-endian serialization)
-// return hash(root + length).
-func mixInLength(root [32]byte, length []byte) [32]byte {
-	var hash [32]byte
-	h := sha256.New()
-	h.Write(root[:])
-	h.Write(length)
-	// The hash interface never returns an error, for that reason
-	// we are not handling the error below. For reference, it is
-	// stated here https://golang.org/pkg/hash/#Hash
-	// #nosec G104
-	h.Sum(hash[:0])
-	return hash
-}
-
 // Instantiates a reflect value which may not have a concrete type to have a concrete type
 // for unmarshaling. For example, we cannot unmarshal into a nil value - instead, it must have
 // a concrete type even if all of its values are zero values.
@@ -126,7 +117,7 @@ func growConcreteSliceType(val reflect.Value, typ reflect.Type, length int) {
 
 // hash defines a function that returns the sha256 hash of the data passed in.
 func hash(data []byte) [32]byte {
-	return sha256.Sum256(data)
+	return hashing.Hash(data)
 }
 
 func growSliceFromSizeTags(val reflect.Value, sizes []uint64) reflect.Value {
@@ -3,6 +3,8 @@ package types
 import (
 	"reflect"
 	"testing"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
 )
 
 func TestPack_NoItems(t *testing.T) {
@@ -141,3 +143,84 @@ func BenchmarkMerkleize(b *testing.B) {
 		}
 	}
 }
+
+func TestBitwiseMerkleize_ZeroSubtrees(t *testing.T) {
+	chunks := [][]byte{{1}, {2}, {3}}
+	var leaves [][32]byte
+	for _, c := range chunks {
+		leaves = append(leaves, toBytes32(c))
+	}
+	if root, err := bitwiseMerkleize(chunks, 3, 16); err != nil || root != paddedRoot(append(leaves, make([][32]byte, 13)...)) {
+		t.Errorf("Unexpected root %#x: %v", root, err)
+	}
+
+	// Padding a single chunk to a tree of 2^40 leaves hashes one node per layer.
+	hashing.StartCounting()
+	defer hashing.StopCounting()
+	_, before := hashing.Counters()
+	if _, err := bitwiseMerkleize(chunks[:1], 1, 1<<40); err != nil {
+		t.Fatal(err)
+	}
+	if _, after := hashing.Counters(); after-before != 2*40 {
+		t.Errorf("Wanted %d chunks hashed, received %d", 2*40, after-before)
+	}
+}
+
+func TestMerkleizeBytes(t *testing.T) {
+	for _, size := range []int{0, 1, 31, 32, 33, 64, 100, 1000} {
+		data := make([]byte, size)
+		for i := range data {
+			data[i] = byte(i + 1)
+		}
+		chunks, err := pack([][]byte{data})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if size == 0 {
+			chunks = nil
+		}
+		for _, limit := range []uint64{uint64(len(chunks)), 64} {
+			if limit == 0 {
+				continue
+			}
+			want, err := bitwiseMerkleize(chunks, uint64(len(chunks)), limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			// A dirty pooled buffer must not leak into the padding of the last chunk.
+			putChunks(&[][32]byte{{0xff}, {0xff}, {0xff}, {0xff}})
+			got, err := merkleizeBytes(data, limit)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != want {
+				t.Errorf("merkleizeBytes(%d bytes, %d) = %#x, want %#x", size, limit, got, want)
+			}
+		}
+	}
+	if _, err := merkleizeBytes(make([]byte, 65), 2); err == nil {
+		t.Error("Expected error merkleizing over limit")
+	}
+}
+
+func TestBasicSliceRoot_Allocations(t *testing.T) {
+	val := make([]uint64, 4096)
+	for i := range val {
+		val[i] = uint64(i)
+	}
+	v := reflect.ValueOf(val)
+	factory, err := SSZFactory(v, v.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	root := func() {
+		if _, err := factory.Root(v, v.Type(), "", 4096); err != nil {
+			t.Fatal(err)
+		}
+	}
+	root()
+	// The serialized list and its chunks come from the pools.
+	if allocs := testing.AllocsPerRun(10, root); allocs > 1 {
+		t.Errorf("Hashing %d uint64s allocated %v objects", len(val), allocs)
+	}
+}
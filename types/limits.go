@@ -0,0 +1,180 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"reflect"
+
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+var bitlistType = reflect.TypeOf(bitfield.Bitlist{})
+
+// LimitExceededError is returned when a list holds more elements than the limit given
+// by its ssz-max tag, such as when decoding an encoding supplied by an attacker. Callers
+// can retrieve it with errors.Cause.
+type LimitExceededError struct {
+	// Path is the chain of struct fields leading to the list, such as "Body.Deposits".
+	Path   string
+	Limit  uint64
+	Length uint64
+}
+
+// Error describes the exceeded limit.
+func (e *LimitExceededError) Error() string {
+	return fmt.Sprintf("list of %d elements exceeds its limit of %d", e.Length, e.Limit)
+}
+
+// checkListLimit returns a LimitExceededError if the list value of a field exceeds the
+// limit given by its ssz-max tag.
+func checkListLimit(val reflect.Value, typ reflect.Type, limit uint64) error {
+	if limit == 0 || typ == bitlistType || (typ.Kind() != reflect.Slice && typ.Kind() != reflect.String) {
+		return nil
+	}
+	if n := uint64(val.Len()); n > limit {
+		return &LimitExceededError{Limit: limit, Length: n}
+	}
+	return nil
+}
+
+// checkEncodedListLimit returns a LimitExceededError if the encoding of a list field
+// holds more elements than the limit given by its ssz-max tag, before it is decoded.
+func checkEncodedListLimit(data []byte, typ reflect.Type, limit uint64) error {
+	if limit == 0 || typ == bitlistType || (typ.Kind() != reflect.Slice && typ.Kind() != reflect.String) {
+		return nil
+	}
+	var n uint64
+	switch {
+	case typ.Kind() == reflect.String:
+		n = uint64(len(data))
+	case isVariableSizeType(typ.Elem()):
+		// The first offset gives the size of the offsets, one per element.
+		if uint64(len(data)) >= BytesPerLengthOffset {
+			n = uint64(binary.LittleEndian.Uint32(data)) / BytesPerLengthOffset
+		}
+	default:
+		size := determineFixedSize(reflect.New(typ.Elem()).Elem(), typ.Elem())
+		if size == 0 {
+			return nil
+		}
+		n = uint64(len(data)) / size
+	}
+	if n > limit {
+		return &LimitExceededError{Limit: limit, Length: n}
+	}
+	return nil
+}
+
+// LimitKind distinguishes between bounded lists and fixed-size vectors.
+type LimitKind string
+
+const (
+	// ListLimit is a variable-length list bounded by an ssz-max tag.
+	ListLimit LimitKind = "list"
+	// VectorSize is a fixed-length vector, either a Go array or a slice with an ssz-size tag.
+	VectorSize LimitKind = "vector"
+	// BitlistLimit is a bitlist bounded by an ssz-max tag.
+	BitlistLimit LimitKind = "bitlist"
+)
+
+// LimitEntry describes a single list limit or vector size used by a type, along with
+// the depth of the Merkle tree it produces when computing a hash tree root.
+type LimitEntry struct {
+	Path       string
+	Kind       LimitKind
+	Limit      uint64
+	ChunkCount uint64
+	Depth      uint8
+	Warnings   []string
+}
+
+// CollectLimits walks a type and returns every list limit, vector size and tree depth in use.
+// Entries carry warnings for suspicious values, such as limits which are not powers of two,
+// lists without an ssz-max tag, or chunk counts which exceed what fits in a uint32.
+func CollectLimits(typ reflect.Type) ([]LimitEntry, error) {
+	entries := make([]LimitEntry, 0)
+	if err := collectLimits(typ, typ.String(), 0, &entries, make(map[reflect.Type]bool)); err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+func collectLimits(typ reflect.Type, path string, maxCapacity uint64, entries *[]LimitEntry, visited map[reflect.Type]bool) error {
+	switch typ.Kind() {
+	case reflect.Ptr:
+		return collectLimits(typ.Elem(), path, maxCapacity, entries, visited)
+	case reflect.Struct:
+		if visited[typ] {
+			return nil
+		}
+		visited[typ] = true
+		defer delete(visited, typ)
+		for i := 0; i < typ.NumField(); i++ {
+			// We skip protobuf related metadata fields and fields tagged ssz:"-".
+			if SkipField(typ.Field(i)) {
+				continue
+			}
+			fType, err := determineFieldType(typ.Field(i))
+			if err != nil {
+				return err
+			}
+			fieldPath := path + "." + typ.Field(i).Name
+			if typ.Field(i).Type == bitlistType {
+				*entries = append(*entries, newLimitEntry(fieldPath, BitlistLimit, determineFieldCapacity(typ.Field(i)), 256))
+				continue
+			}
+			if err := collectLimits(fType, fieldPath, determineFieldCapacity(typ.Field(i)), entries, visited); err != nil {
+				return err
+			}
+		}
+	case reflect.Array:
+		*entries = append(*entries, newLimitEntry(path, VectorSize, uint64(typ.Len()), elementsPerChunk(typ.Elem())))
+		return collectLimits(typ.Elem(), path+"[]", 0, entries, visited)
+	case reflect.Slice:
+		*entries = append(*entries, newLimitEntry(path, ListLimit, maxCapacity, elementsPerChunk(typ.Elem())))
+		return collectLimits(typ.Elem(), path+"[]", 0, entries, visited)
+	case reflect.Map:
+		*entries = append(*entries, newLimitEntry(path, ListLimit, maxCapacity, 1))
+		return collectLimits(typ.Elem(), path+"[]", 0, entries, visited)
+	case reflect.String:
+		*entries = append(*entries, newLimitEntry(path, ListLimit, maxCapacity, 32))
+	default:
+		if !isBasicType(typ.Kind()) {
+			return fmt.Errorf("unsupported kind: %v", typ.Kind())
+		}
+	}
+	return nil
+}
+
+// elementsPerChunk returns how many elements of a type are packed into a single chunk.
+func elementsPerChunk(typ reflect.Type) uint64 {
+	if !isBasicType(typ.Kind()) {
+		return 1
+	}
+	return uint64(BytesPerChunk) / determineFixedSize(reflect.Value{}, typ)
+}
+
+func newLimitEntry(path string, kind LimitKind, limit uint64, perChunk uint64) LimitEntry {
+	entry := LimitEntry{
+		Path:       path,
+		Kind:       kind,
+		Limit:      limit,
+		ChunkCount: (limit + perChunk - 1) / perChunk,
+	}
+	if limit > math.MaxUint64-perChunk {
+		entry.ChunkCount = limit/perChunk + 1
+	}
+	entry.Depth = merkle.GetDepth(entry.ChunkCount)
+	if kind != VectorSize && limit == 0 {
+		entry.Warnings = append(entry.Warnings, "no ssz-max limit set, the root will depend on the list length")
+	}
+	if kind != VectorSize && limit != 0 && limit&(limit-1) != 0 {
+		entry.Warnings = append(entry.Warnings, fmt.Sprintf("limit %d is not a power of two", limit))
+	}
+	if entry.ChunkCount > math.MaxUint32 {
+		entry.Warnings = append(entry.Warnings, fmt.Sprintf("chunk count %d exceeds uint32", entry.ChunkCount))
+	}
+	return entry
+}
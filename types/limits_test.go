@@ -0,0 +1,95 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+)
+
+func TestCollectLimits(t *testing.T) {
+	type checkpoint struct {
+		Epoch uint64
+		Root  []byte `ssz-size:"32"`
+	}
+	type state struct {
+		Balances    []uint64         `ssz-max:"1099511627776"`
+		BlockRoots  [][]byte         `ssz-size:"8192,32"`
+		Checkpoints []checkpoint     `ssz-max:"1000"`
+		Bits        bitfield.Bitlist `ssz-max:"2048"`
+		Unbounded   []uint64
+	}
+	entries, err := CollectLimits(reflect.TypeOf(&state{}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	byPath := make(map[string]LimitEntry)
+	for _, e := range entries {
+		byPath[e.Path] = e
+	}
+	tests := []struct {
+		path     string
+		kind     LimitKind
+		chunks   uint64
+		depth    uint8
+		warnings int
+	}{
+		{path: "*types.state.Balances", kind: ListLimit, chunks: 1 << 38, depth: 38, warnings: 1},
+		{path: "*types.state.BlockRoots", kind: VectorSize, chunks: 8192, depth: 13},
+		{path: "*types.state.BlockRoots[]", kind: VectorSize, chunks: 1, depth: 0},
+		{path: "*types.state.Checkpoints", kind: ListLimit, chunks: 1000, depth: 10, warnings: 1},
+		{path: "*types.state.Checkpoints[].Root", kind: VectorSize, chunks: 1, depth: 0},
+		{path: "*types.state.Bits", kind: BitlistLimit, chunks: 8, depth: 3},
+		{path: "*types.state.Unbounded", kind: ListLimit, chunks: 0, depth: 0, warnings: 1},
+	}
+	for _, tt := range tests {
+		e, ok := byPath[tt.path]
+		if !ok {
+			t.Errorf("Missing entry for %s", tt.path)
+			continue
+		}
+		if e.Kind != tt.kind || e.ChunkCount != tt.chunks || e.Depth != tt.depth || len(e.Warnings) != tt.warnings {
+			t.Errorf("Unexpected entry for %s: %+v", tt.path, e)
+		}
+	}
+}
+
+func TestLint(t *testing.T) {
+	type inner struct {
+		Data    []byte
+		Balance int64
+	}
+	type container struct {
+		Root      []byte   `ssz-size:"32"`
+		Roots     [][]byte `ssz-size:"?,32"`
+		Limited   []uint64 `ssz-max:"16"`
+		Any       interface{}
+		Inner     inner
+		Slot      uint64
+		Bits      bitfield.Bitlist
+		Offsets   []int32 `ssz-max:"4"`
+		Unlimited string
+	}
+	issues := Lint(reflect.TypeOf(container{}))
+	wanted := []struct {
+		path string
+		rule LintRule
+	}{
+		{path: "types.container.Roots", rule: MissingLimit},
+		{path: "types.container.Any", rule: InterfaceField},
+		{path: "types.container.Inner.Data", rule: UntaggedSlice},
+		{path: "types.container.Inner.Balance", rule: SignedInteger},
+		{path: "types.container.Inner.Balance", rule: FixedAfterVariable},
+		{path: "types.container.Slot", rule: FixedAfterVariable},
+		{path: "types.container.Bits", rule: MissingLimit},
+		{path: "types.container.Offsets", rule: SignedInteger},
+	}
+	if len(issues) != len(wanted) {
+		t.Fatalf("Wanted %d issues, received %v", len(wanted), issues)
+	}
+	for i, w := range wanted {
+		if issues[i].Path != w.path || issues[i].Rule != w.rule {
+			t.Errorf("Wanted %s at %s, received %v", w.rule, w.path, issues[i])
+		}
+	}
+}
@@ -0,0 +1,124 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// LintRule identifies the category of a LintIssue.
+type LintRule string
+
+const (
+	// UntaggedSlice flags slices with neither an ssz-size nor an ssz-max tag, which are
+	// hashed as lists whose limit is their own length even where a vector is expected.
+	UntaggedSlice LintRule = "untagged-slice"
+	// MissingLimit flags lists declared through ssz-size tags without an ssz-max limit.
+	MissingLimit LintRule = "missing-limit"
+	// SignedInteger flags signed integer fields, which have no SSZ equivalent.
+	SignedInteger LintRule = "signed-integer"
+	// InterfaceField flags interface fields, whose concrete type cannot be known when decoding.
+	InterfaceField LintRule = "interface-field"
+	// UnsupportedKind flags fields of a kind which cannot be serialized at all.
+	UnsupportedKind LintRule = "unsupported-kind"
+	// FixedAfterVariable flags fixed-size fields declared after a variable-size field, as their
+	// position in the fixed part no longer matches the declaration order of the data.
+	FixedAfterVariable LintRule = "fixed-after-variable"
+)
+
+// LintIssue describes a struct field definition likely to diverge from the specification.
+type LintIssue struct {
+	Path    string
+	Rule    LintRule
+	Message string
+}
+
+// String returns a human readable description of the issue.
+func (l LintIssue) String() string {
+	return fmt.Sprintf("%s: %s (%s)", l.Path, l.Message, l.Rule)
+}
+
+// Lint walks the fields of a struct type, and of every struct type nested within it,
+// and returns the definitions likely to diverge from the SSZ specification.
+func Lint(typ reflect.Type) []LintIssue {
+	issues := make([]LintIssue, 0)
+	lintType(typ, typ.String(), &issues, make(map[reflect.Type]bool))
+	return issues
+}
+
+func lintType(typ reflect.Type, path string, issues *[]LintIssue, visited map[reflect.Type]bool) {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		lintType(typ.Elem(), path, issues, visited)
+		return
+	case reflect.Struct:
+	default:
+		return
+	}
+	if visited[typ] {
+		return
+	}
+	visited[typ] = true
+	seenVariable := ""
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(field) {
+			continue
+		}
+		fieldPath := path + "." + field.Name
+		report := func(rule LintRule, format string, args ...interface{}) {
+			*issues = append(*issues, LintIssue{Path: fieldPath, Rule: rule, Message: fmt.Sprintf(format, args...)})
+		}
+		if !lintFieldKind(field.Type, report) {
+			continue
+		}
+		fType, err := determineFieldType(field)
+		if err != nil {
+			report(UnsupportedKind, "could not parse ssz-size tag: %v", err)
+			continue
+		}
+		_, hasSize := field.Tag.Lookup("ssz-size")
+		_, hasMax := field.Tag.Lookup("ssz-max")
+		switch {
+		case IsProgressive(field):
+			if fType.Kind() != reflect.Slice || field.Type == bitlistType {
+				report(UnsupportedKind, "only lists can be progressive, not %v", fType)
+			}
+		case field.Type == bitlistType:
+			if !hasMax {
+				report(MissingLimit, "bitlist has no ssz-max limit")
+			}
+		case field.Type.Kind() == reflect.Slice && !hasSize && !hasMax && !isBitvectorType(field.Type):
+			report(UntaggedSlice, "slice has neither ssz-size nor ssz-max, add ssz-size if a vector is expected or ssz-max if it is a list")
+		case fType.Kind() == reflect.Slice && !hasMax:
+			report(MissingLimit, "list has no ssz-max limit")
+		}
+		if isVariableSizeType(fType) {
+			if seenVariable == "" {
+				seenVariable = field.Name
+			}
+		} else if seenVariable != "" {
+			report(FixedAfterVariable, "fixed-size field is declared after variable-size field %s", seenVariable)
+		}
+		lintType(fType, fieldPath, issues, visited)
+	}
+}
+
+// lintFieldKind reports kinds which have no SSZ equivalent anywhere within a field's type,
+// and returns false if the field cannot be laid out at all.
+func lintFieldKind(typ reflect.Type, report func(rule LintRule, format string, args ...interface{})) bool {
+	switch typ.Kind() {
+	case reflect.Ptr, reflect.Slice, reflect.Array:
+		return lintFieldKind(typ.Elem(), report)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		report(SignedInteger, "signed integer %v has no SSZ equivalent, use an unsigned type", typ)
+	case reflect.Interface:
+		report(InterfaceField, "interface %v cannot be decoded into a concrete type", typ)
+		return false
+	case reflect.Uint, reflect.Uintptr, reflect.Float32, reflect.Float64, reflect.Complex64,
+		reflect.Complex128, reflect.Map, reflect.Chan, reflect.Func, reflect.UnsafePointer:
+		report(UnsupportedKind, "kind %v is not supported by SSZ", typ.Kind())
+		return false
+	}
+	return true
+}
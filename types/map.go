@@ -0,0 +1,122 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// ToggleMapCodec enables the codec for map[uint64]T values, which are otherwise
+// rejected as unsupported. It is disabled by default, as maps have no SSZ type in
+// the specification and are only meant for off-chain tooling.
+//
+// A map is encoded and hashed as the list of its entries sorted by key, that is,
+// as List[Container{Key: uint64, Value: T}, N] where N is the limit given by the
+// ssz-max tag of the field. Decoding rejects entries which are not in strictly
+// increasing key order, so that every map has a single canonical encoding.
+//
+// It returns ErrConfigLocked after LockConfig.
+func ToggleMapCodec(val bool) error {
+	return updateConfig(func(c *Config) {
+		c.MapCodec = val
+	})
+}
+
+type mapSSZ struct{}
+
+func newMapSSZ() *mapSSZ {
+	return &mapSSZ{}
+}
+
+func (m *mapSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
+	keys := sortedMapKeys(val)
+	roots := make([][]byte, len(keys))
+	for i, key := range keys {
+		value := val.MapIndex(key)
+		factory, err := SSZFactory(value, typ.Elem())
+		if err != nil {
+			return [32]byte{}, err
+		}
+		// Entries are hashed without a field name, as the caches keyed by field
+		// names expect a single value per name.
+		valueRoot, err := factory.Root(value, typ.Elem(), "", 0)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		var keyRoot [32]byte
+		binary.LittleEndian.PutUint64(keyRoot[:8], key.Uint())
+		entryRoot := hashing.HashPair(keyRoot, valueRoot)
+		roots[i] = entryRoot[:]
+	}
+	limit := maxCapacity
+	if limit == 0 {
+		limit = uint64(len(keys))
+	}
+	root, err := bitwiseMerkleize(roots, uint64(len(roots)), limit)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.MixInLength(root, uint64(len(keys))), nil
+}
+
+func (m *mapSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
+	entries := mapEntries(val, typ)
+	factory, err := SSZFactory(entries, entries.Type())
+	if err != nil {
+		return 0, err
+	}
+	return factory.Marshal(entries, entries.Type(), buf, startOffset)
+}
+
+func (m *mapSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
+	entries := reflect.New(reflect.SliceOf(mapEntryType(typ))).Elem()
+	factory, err := SSZFactory(entries, entries.Type())
+	if err != nil {
+		return 0, err
+	}
+	index, err := factory.Unmarshal(entries, entries.Type(), input, startOffset)
+	if err != nil {
+		return 0, err
+	}
+	result := reflect.MakeMapWithSize(typ, entries.Len())
+	for i := 0; i < entries.Len(); i++ {
+		key := entries.Index(i).Field(0)
+		if i > 0 && key.Uint() <= entries.Index(i-1).Field(0).Uint() {
+			return 0, fmt.Errorf("map keys must be strictly increasing, key %d follows %d", key.Uint(), entries.Index(i-1).Field(0).Uint())
+		}
+		result.SetMapIndex(key.Convert(typ.Key()), entries.Index(i).Field(1))
+	}
+	val.Set(result)
+	return index, nil
+}
+
+// mapEntryType returns the container type each entry of a map is serialized as.
+func mapEntryType(typ reflect.Type) reflect.Type {
+	return reflect.StructOf([]reflect.StructField{
+		{Name: "Key", Type: reflect.TypeOf(uint64(0))},
+		{Name: "Value", Type: typ.Elem()},
+	})
+}
+
+// mapEntries returns the entries of a map as a slice of containers sorted by key.
+func mapEntries(val reflect.Value, typ reflect.Type) reflect.Value {
+	keys := sortedMapKeys(val)
+	entryTyp := mapEntryType(typ)
+	entries := reflect.MakeSlice(reflect.SliceOf(entryTyp), len(keys), len(keys))
+	for i, key := range keys {
+		entries.Index(i).Field(0).SetUint(key.Uint())
+		entries.Index(i).Field(1).Set(val.MapIndex(key))
+	}
+	return entries
+}
+
+func sortedMapKeys(val reflect.Value) []reflect.Value {
+	keys := val.MapKeys()
+	sort.Slice(keys, func(i, j int) bool {
+		return keys[i].Uint() < keys[j].Uint()
+	})
+	return keys
+}
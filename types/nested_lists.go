@@ -0,0 +1,84 @@
+package types
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// nestedRoot computes the root of a list or vector whose elements hold lists with limits
+// of their own, such as the transactions of an execution payload, which are byte lists
+// of at most MAX_BYTES_PER_TRANSACTION bytes:
+//
+//  type ExecutionPayload struct {
+//      ...
+//      Transactions [][]byte `ssz-max:"1048576,1073741824"`
+//  }
+//
+// The first element limit bounds the elements, the following ones the lists nested in
+// them. A limit of 0 stands for a level which is not bounded, such as a vector.
+func (h *Hasher) nestedRoot(val reflect.Value, typ reflect.Type, maxCapacity uint64, elemCapacities []uint64) ([32]byte, error) {
+	kind := typ.Kind()
+	if len(elemCapacities) == 0 || (kind != reflect.Slice && kind != reflect.Array) {
+		return h.root(val, typ, maxCapacity)
+	}
+	n := val.Len()
+	if kind == reflect.Array {
+		n = typ.Len()
+	}
+	base := len(h.chunks)
+	for i := 0; i < n; i++ {
+		if i%contextCheckInterval == 0 {
+			if err := h.checkContext(); err != nil {
+				h.chunks = h.chunks[:base]
+				return [32]byte{}, err
+			}
+		}
+		elem := reflect.Zero(typ.Elem())
+		if i < val.Len() {
+			elem = val.Index(i)
+		}
+		if err := checkListLimit(elem, typ.Elem(), elemCapacities[0]); err != nil {
+			h.chunks = h.chunks[:base]
+			return [32]byte{}, err
+		}
+		r, err := h.nestedRoot(elem, typ.Elem(), elemCapacities[0], elemCapacities[1:])
+		if err != nil {
+			h.chunks = h.chunks[:base]
+			return [32]byte{}, err
+		}
+		h.chunks = append(h.chunks, r)
+	}
+	if kind == reflect.Array {
+		return h.merkleize(base, uint64(n))
+	}
+	limit := maxCapacity
+	if limit == 0 {
+		limit = uint64(n)
+	}
+	root, err := h.merkleize(base, limit)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.MixInLength(root, uint64(n)), nil
+}
+
+// checkNestedLimits checks the lists nested in the elements of a decoded list or vector
+// against their limits, which the factories decoding them do not know about.
+func checkNestedLimits(val reflect.Value, typ reflect.Type, elemCapacities []uint64) error {
+	kind := typ.Kind()
+	if len(elemCapacities) == 0 || (kind != reflect.Slice && kind != reflect.Array) {
+		return nil
+	}
+	for i := 0; i < val.Len(); i++ {
+		elem := val.Index(i)
+		if err := checkListLimit(elem, typ.Elem(), elemCapacities[0]); err != nil {
+			return errors.Wrapf(err, "element %d", i)
+		}
+		if err := checkNestedLimits(elem, typ.Elem(), elemCapacities[1:]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
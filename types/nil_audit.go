@@ -0,0 +1,81 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// NilSubstitution describes a nil pointer or a nil fixed-size field which was
+// silently replaced by its zero value while marshaling or computing a hash tree root.
+type NilSubstitution struct {
+	// Operation is either "marshal" or "root".
+	Operation string
+	// Path is the location of the nil value, such as "BeaconBlock.Body" or
+	// "BeaconState.Validators[3]".
+	Path string
+	// Type is the declared type of the nil value.
+	Type reflect.Type
+}
+
+// String returns a human readable description of the substitution.
+func (n NilSubstitution) String() string {
+	return fmt.Sprintf("%s: nil %v at %s replaced by zero value", n.Operation, n.Type, n.Path)
+}
+
+var (
+	nilAuditHook func(NilSubstitution)
+	nilAuditLock sync.RWMutex
+)
+
+// SetNilAuditHook enables the nil-safety audit mode, in which every zero-value
+// substitution of a nil pointer or nil fixed-size field is reported to hook.
+// It is meant to be enabled in tests so that intentional defaults can be told apart
+// from bugs when roots unexpectedly match the zero object. Passing a nil hook disables
+// the audit mode, which is the default.
+func SetNilAuditHook(hook func(NilSubstitution)) {
+	nilAuditLock.Lock()
+	defer nilAuditLock.Unlock()
+	nilAuditHook = hook
+}
+
+// ReportNilSubstitution notifies the nil audit hook, if any, that the value at path
+// was replaced by its zero value.
+func ReportNilSubstitution(operation string, path string, typ reflect.Type) {
+	nilAuditLock.RLock()
+	hook := nilAuditHook
+	nilAuditLock.RUnlock()
+	if hook == nil {
+		return
+	}
+	hook(NilSubstitution{
+		Operation: operation,
+		Path:      path,
+		Type:      typ,
+	})
+}
+
+// auditNilValue reports val if it is a nil pointer, or a nil slice which is
+// treated as a fixed-size vector through its inferred type.
+func auditNilValue(operation string, path string, val reflect.Value, typ reflect.Type) {
+	if !val.IsValid() {
+		return
+	}
+	switch val.Kind() {
+	case reflect.Ptr:
+		if val.IsNil() {
+			ReportNilSubstitution(operation, path, val.Type())
+		}
+	case reflect.Slice:
+		if val.IsNil() && typ.Kind() == reflect.Array && typ.Len() > 0 {
+			ReportNilSubstitution(operation, path, val.Type())
+		}
+	}
+}
+
+// nilAuditEnabled is used to skip building field paths when no hook is set.
+func nilAuditEnabled() bool {
+	nilAuditLock.RLock()
+	defer nilAuditLock.RUnlock()
+	return nilAuditHook != nil
+}
@@ -0,0 +1,67 @@
+package types
+
+import (
+	"fmt"
+)
+
+// FirstOffsetError is returned when the first offset of a composite value does not
+// point right past its fixed part, leaving bytes of the encoding unaccounted for or
+// overlapping the offsets. Callers can retrieve it with errors.Cause.
+type FirstOffsetError struct {
+	// Path is the chain of struct fields leading to the value, such as "Body.Deposits".
+	Path   string
+	Offset uint64
+	// FixedSize is the size of the fixed part, or 0 for lists, whose number of elements
+	// is given by the first offset, which must then be a non-zero multiple of the size
+	// of an offset.
+	FixedSize uint64
+}
+
+// Error describes the invalid offset.
+func (e *FirstOffsetError) Error() string {
+	if e.FixedSize == 0 {
+		return fmt.Sprintf("first offset %d of list is not a non-zero multiple of %d", e.Offset, BytesPerLengthOffset)
+	}
+	return fmt.Sprintf("first offset %d does not match the fixed size %d", e.Offset, e.FixedSize)
+}
+
+// OffsetOrderError is returned when an offset is lower than the one before it, such
+// that the variable size parts of a composite value would overlap.
+type OffsetOrderError struct {
+	Path     string
+	Offset   uint64
+	Previous uint64
+}
+
+// Error describes the invalid offset.
+func (e *OffsetOrderError) Error() string {
+	return fmt.Sprintf("offset %d is lower than the previous offset %d", e.Offset, e.Previous)
+}
+
+// OffsetOutOfBoundsError is returned when an offset points past the end of the encoding
+// of a composite value.
+type OffsetOutOfBoundsError struct {
+	Path   string
+	Offset uint64
+	Size   uint64
+}
+
+// Error describes the invalid offset.
+func (e *OffsetOutOfBoundsError) Error() string {
+	return fmt.Sprintf("offset %d is out of bounds of the %d bytes of the encoding", e.Offset, e.Size)
+}
+
+// checkOffset validates the i-th offset of a composite value of size bytes whose fixed
+// part is fixedSize bytes, given the offset before it. Offsets are relative to the start
+// of the value.
+func checkOffset(i int, offset uint64, previous uint64, fixedSize uint64, size uint64) error {
+	switch {
+	case i == 0 && offset != fixedSize:
+		return &FirstOffsetError{Offset: offset, FixedSize: fixedSize}
+	case offset > size:
+		return &OffsetOutOfBoundsError{Offset: offset, Size: size}
+	case i > 0 && offset < previous:
+		return &OffsetOrderError{Offset: offset, Previous: previous}
+	}
+	return nil
+}
@@ -0,0 +1,131 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// Optional is implemented by struct types encoding an EIP-6475 Optional[T]. Such a
+// struct has a single field, a pointer to the value, which is None when nil:
+//
+//  type OptionalCheckpoint struct {
+//      Value *Checkpoint
+//  }
+//
+//  func (OptionalCheckpoint) SSZOptional() {}
+//
+// None is serialized as no bytes at all, while a value is serialized as a 0x01 byte
+// followed by its serialization. The root is the root of the value, or a zero chunk for
+// None, with the presence selector mixed in.
+type Optional interface {
+	SSZOptional()
+}
+
+var optionalType = reflect.TypeOf((*Optional)(nil)).Elem()
+
+type optionalSSZ struct{}
+
+func newOptionalSSZ() *optionalSSZ {
+	return &optionalSSZ{}
+}
+
+// IsOptional returns true if typ is a struct type encoded as an EIP-6475 optional.
+func IsOptional(typ reflect.Type) bool {
+	return isOptionalType(typ)
+}
+
+func isOptionalType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && (typ.Implements(optionalType) || reflect.PtrTo(typ).Implements(optionalType))
+}
+
+// optionalValue returns the value held by an optional, which is invalid for None.
+func optionalValue(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if typ.NumField() != 1 || typ.Field(0).Type.Kind() != reflect.Ptr {
+		return reflect.Value{}, fmt.Errorf("optional %v must have a single pointer field", typ)
+	}
+	if val.Field(0).IsNil() {
+		return reflect.Value{}, nil
+	}
+	return val.Field(0).Elem(), nil
+}
+
+func (o *optionalSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
+	if typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			instance := reflect.New(typ.Elem()).Elem()
+			return o.Root(instance, instance.Type(), fieldName, maxCapacity)
+		}
+		return o.Root(val.Elem(), typ.Elem(), fieldName, maxCapacity)
+	}
+	value, err := optionalValue(val, typ)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if !value.IsValid() {
+		return hashing.MixInLength([32]byte{}, 0), nil
+	}
+	factory, err := SSZFactory(value, value.Type())
+	if err != nil {
+		return [32]byte{}, err
+	}
+	root, err := factory.Root(value, value.Type(), "", 0)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.MixInLength(root, 1), nil
+}
+
+func (o *optionalSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
+	value, err := optionalValue(val, typ)
+	if err != nil {
+		return 0, err
+	}
+	if !value.IsValid() {
+		return startOffset, nil
+	}
+	buf[startOffset] = 1
+	factory, err := SSZFactory(value, value.Type())
+	if err != nil {
+		return 0, err
+	}
+	return factory.Marshal(value, value.Type(), buf, startOffset+1)
+}
+
+func (o *optionalSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
+	if _, err := optionalValue(val, typ); err != nil {
+		return 0, err
+	}
+	if startOffset >= uint64(len(input)) {
+		val.Field(0).Set(reflect.Zero(typ.Field(0).Type))
+		return startOffset, nil
+	}
+	if input[startOffset] != 1 {
+		return 0, fmt.Errorf("expected 0x01 to prefix the value of optional %v, received %#x", typ, input[startOffset])
+	}
+	valueTyp := typ.Field(0).Type.Elem()
+	value := reflect.New(valueTyp)
+	factory, err := SSZFactory(value.Elem(), valueTyp)
+	if err != nil {
+		return 0, err
+	}
+	remaining := uint64(len(input)) - startOffset - 1
+	if !isVariableSizeType(valueTyp) && determineFixedSize(value.Elem(), valueTyp) != remaining {
+		return 0, fmt.Errorf("value of optional %v has %d bytes, wanted %d", typ, remaining, determineFixedSize(value.Elem(), valueTyp))
+	}
+	if _, err := factory.Unmarshal(value.Elem(), valueTyp, input[startOffset+1:], 0); err != nil {
+		return 0, err
+	}
+	val.Field(0).Set(value)
+	return uint64(len(input)), nil
+}
+
+// optionalSize returns the serialized size of an optional value.
+func optionalSize(val reflect.Value, typ reflect.Type) uint64 {
+	value, err := optionalValue(val, typ)
+	if err != nil || !value.IsValid() {
+		return 0
+	}
+	return 1 + DetermineSize(value)
+}
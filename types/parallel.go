@@ -0,0 +1,119 @@
+package types
+
+import (
+	"sync"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// parallelThreshold is the number of leaves or elements under which hashing stays on
+// the calling goroutine, as handing work over to other goroutines would cost more than
+// it saves.
+const parallelThreshold = 1024
+
+// SetHashWorkers sets the number of goroutines hashing large lists, such as the
+// validator registry or the balances of a beacon state. The roots of list elements and
+// the subtrees of the Merkle tree above them are then computed across cores:
+//
+//  if err := types.SetHashWorkers(runtime.NumCPU()); err != nil {
+//      return err
+//  }
+//
+// A value of 1 or less, the default, hashes everything on the calling goroutine. It
+// returns ErrConfigLocked after LockConfig.
+func SetHashWorkers(n int) error {
+	return updateConfig(func(c *Config) {
+		c.HashWorkers = n
+	})
+}
+
+// hashWorkers returns the number of workers hashing n leaves or elements.
+func hashWorkers(n int) int {
+	workers := CurrentConfig().HashWorkers
+	if workers <= 1 || n < parallelThreshold {
+		return 1
+	}
+	if workers > n {
+		return n
+	}
+	return workers
+}
+
+// parallelFor calls fn over contiguous batches of [0, n), one per worker, and returns
+// the error of the first failed batch.
+func parallelFor(n int, workers int, fn func(start, end int) error) error {
+	if workers <= 1 {
+		return fn(0, n)
+	}
+	batch := (n + workers - 1) / workers
+	errs := make([]error, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		start, end := w*batch, (w+1)*batch
+		if end > n {
+			end = n
+		}
+		if start >= end {
+			break
+		}
+		wg.Add(1)
+		go func(w, start, end int) {
+			defer wg.Done()
+			errs[w] = fn(start, end)
+		}(w, start, end)
+	}
+	wg.Wait()
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// merkleizeLayer computes the root of a tree of the given depth whose first leaves are
+// layer, padded with zero chunks. The leaves are hashed in place. With several workers,
+// equally sized subtrees are merkleized concurrently before hashing the layers above.
+func merkleizeLayer(layer [][32]byte, depth uint8, workers int) [32]byte {
+	if len(layer) == 0 {
+		return hashing.ZeroHash(depth)
+	}
+	from := uint8(0)
+	if workers > 1 {
+		// Subtrees of depth from hold the leaves of a single worker each.
+		for from < depth && (len(layer)+(1<<from)-1)>>from > workers {
+			from++
+		}
+		roots := make([][32]byte, (len(layer)+(1<<from)-1)>>from)
+		leaves := layer
+		// Subtrees are hashed in place, within their own part of the layer.
+		_ = parallelFor(len(roots), len(roots), func(start, end int) error {
+			for k := start; k < end; k++ {
+				sub := leaves[k<<from:]
+				if len(sub) > 1<<from {
+					sub = sub[:1<<from]
+				}
+				roots[k] = hashLayers(sub, 0, from)[0]
+			}
+			return nil
+		})
+		layer = roots
+	}
+	return hashLayers(layer, from, depth)[0]
+}
+
+// hashLayers hashes the layer holding the nodes at height from of a tree up to the
+// layer at height to, in place, padding odd layers with zero subtrees.
+func hashLayers(layer [][32]byte, from uint8, to uint8) [][32]byte {
+	for d := from; d < to; d++ {
+		n := len(layer)
+		for i := 0; i < n/2; i++ {
+			layer[i] = hashing.HashPair(layer[2*i], layer[2*i+1])
+		}
+		if n%2 == 1 {
+			layer[n/2] = hashing.HashPair(layer[n-1], hashing.ZeroHash(d))
+		}
+		layer = layer[:(n+1)/2]
+	}
+	return layer
+}
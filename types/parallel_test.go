@@ -0,0 +1,61 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+type parallelValidator struct {
+	Pubkey           [48]byte
+	EffectiveBalance uint64
+	Slashed          bool
+}
+
+type parallelState struct {
+	Validators []*parallelValidator `ssz-max:"1099511627776"`
+	Balances   []uint64             `ssz-max:"1099511627776"`
+	Roots      [][]byte             `ssz-max:"8192"`
+}
+
+func TestSetHashWorkers(t *testing.T) {
+	defer config.Store(Config{})
+	state := &parallelState{}
+	for i := 0; i < 3000; i++ {
+		state.Validators = append(state.Validators, &parallelValidator{Pubkey: [48]byte{byte(i), byte(i >> 8)}, EffectiveBalance: uint64(i)})
+		state.Balances = append(state.Balances, uint64(i)*3)
+		state.Roots = append(state.Roots, []byte{byte(i)})
+	}
+	val := reflect.ValueOf(state)
+	want, err := StructFactory.Root(val, val.Type(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, workers := range []int{2, 3, 8, 5000} {
+		if err := SetHashWorkers(workers); err != nil {
+			t.Fatal(err)
+		}
+		root, err := StructFactory.Root(val, val.Type(), "", 0)
+		if err != nil || root != want {
+			t.Errorf("Wanted root %#x with %d workers, received %#x: %v", want, workers, root, err)
+		}
+		root, err = (&Hasher{}).Root(val, val.Type(), 0)
+		if err != nil || root != want {
+			t.Errorf("Wanted hasher root %#x with %d workers, received %#x: %v", want, workers, root, err)
+		}
+	}
+}
+
+func TestMerkleizeLayer(t *testing.T) {
+	for _, n := range []int{1, 2, 5, 1000, 1025} {
+		leaves := make([][32]byte, n)
+		for i := range leaves {
+			leaves[i][0] = byte(i)
+		}
+		want := merkleizeLayer(append([][32]byte{}, leaves...), 12, 1)
+		for _, workers := range []int{2, 3, 16} {
+			if root := merkleizeLayer(append([][32]byte{}, leaves...), 12, workers); root != want {
+				t.Errorf("Wanted root %#x of %d leaves with %d workers, received %#x", want, n, workers, root)
+			}
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package types
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+	"github.com/prysmaticlabs/go-ssz/tree"
+)
+
+// ValidatorRegistryLimit is the maximum number of validators in the registry, which
+// bounds the per-validator lists of a beacon state such as participation flags.
+const ValidatorRegistryLimit = uint64(1) << 40
+
+// ParticipationHasher computes the hash tree root of a participation flag list, a
+// []byte holding one flags byte per validator. The Merkle tree of the previous call
+// is retained, so only the branches of the 32-byte chunks which changed since then
+// are re-hashed. A hasher is meant to track a single list across many calls, such as
+// the current epoch participation of a beacon state.
+type ParticipationHasher struct {
+	lock   sync.Mutex
+	limit  uint64
+	depth  uint8
+	chunks [][32]byte
+	node   *tree.Node
+}
+
+// NewParticipationHasher returns a hasher for participation lists with the given
+// maximum length, typically ValidatorRegistryLimit.
+func NewParticipationHasher(limit uint64) *ParticipationHasher {
+	chunkLimit := (limit + 31) / 32
+	return &ParticipationHasher{
+		limit: limit,
+		depth: merkle.GetDepth(chunkLimit),
+	}
+}
+
+// Root returns the hash tree root of the participation flags as an SSZ List[uint8, limit].
+func (p *ParticipationHasher) Root(flags []byte) ([32]byte, error) {
+	if uint64(len(flags)) > p.limit {
+		return [32]byte{}, fmt.Errorf("participation list of length %d exceeds limit %d", len(flags), p.limit)
+	}
+	p.lock.Lock()
+	defer p.lock.Unlock()
+	numChunks := (len(flags) + 31) / 32
+	chunks := make([][32]byte, numChunks)
+	for i := 0; i < numChunks; i++ {
+		copy(chunks[i][:], flags[i*32:])
+	}
+	if p.node == nil {
+		node, err := tree.FromChunks(chunks, p.depth)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		p.node = node
+	} else {
+		node := p.node
+		var err error
+		firstLeaf := uint64(1) << p.depth
+		for i := 0; i < len(chunks) || i < len(p.chunks); i++ {
+			var chunk [32]byte
+			if i < len(chunks) {
+				chunk = chunks[i]
+			}
+			if i < len(p.chunks) && p.chunks[i] == chunk {
+				continue
+			}
+			node, err = node.Set(firstLeaf+uint64(i), tree.Leaf(chunk))
+			if err != nil {
+				return [32]byte{}, err
+			}
+		}
+		p.node = node
+	}
+	p.chunks = chunks
+	return hashing.MixInLength(p.node.Root(), uint64(len(flags))), nil
+}
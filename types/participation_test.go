@@ -0,0 +1,58 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParticipationHasher_MatchesListRoot(t *testing.T) {
+	h := NewParticipationHasher(ValidatorRegistryLimit)
+	flags := make([]byte, 100)
+	for i := range flags {
+		flags[i] = byte(i % 8)
+	}
+	updates := []func(){
+		func() {},
+		func() { flags[65] = 7 },
+		func() { flags = append(flags, make([]byte, 40)...) },
+		func() { flags[0], flags[139] = 1, 3 },
+		func() { flags = flags[:10] },
+		func() { flags = flags[:0] },
+	}
+	for i, update := range updates {
+		update()
+		want, err := basicSliceFactory.Root(reflect.ValueOf(flags), reflect.TypeOf(flags), "", ValidatorRegistryLimit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := h.Root(flags)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("Update %d: wanted root %#x, received %#x", i, want, got)
+		}
+	}
+}
+
+func TestParticipationHasher_ExceedsLimit(t *testing.T) {
+	h := NewParticipationHasher(16)
+	if _, err := h.Root(make([]byte, 17)); err == nil {
+		t.Error("Expected error for list exceeding limit")
+	}
+}
+
+func BenchmarkParticipationHasher_SingleChange(b *testing.B) {
+	h := NewParticipationHasher(ValidatorRegistryLimit)
+	flags := make([]byte, 500000)
+	if _, err := h.Root(flags); err != nil {
+		b.Fatal(err)
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		flags[i%len(flags)]++
+		if _, err := h.Root(flags); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,74 @@
+package types
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+)
+
+var (
+	pinnedRoots = make(map[interface{}][32]byte)
+	pinnedLock  sync.RWMutex
+)
+
+// PinRoot records root as the hash tree root of the object pointed to by val, which is then
+// returned without hashing whenever the object is encountered, either at the top level
+// or as a field of another object. A pin holds on to the object until it is invalidated.
+func PinRoot(val reflect.Value, root [32]byte) error {
+	if val.Kind() != reflect.Ptr || val.IsNil() || val.Elem().Kind() != reflect.Struct {
+		return errors.New("only non-nil pointers to structs can have their root pinned")
+	}
+	pinnedLock.Lock()
+	defer pinnedLock.Unlock()
+	pinnedRoots[val.Interface()] = root
+	return nil
+}
+
+// PinnedRoot returns the root pinned for the object pointed to by val, if any.
+func PinnedRoot(val reflect.Value) ([32]byte, bool) {
+	if val.Kind() != reflect.Ptr || val.IsNil() {
+		return [32]byte{}, false
+	}
+	pinnedLock.RLock()
+	defer pinnedLock.RUnlock()
+	if len(pinnedRoots) == 0 {
+		return [32]byte{}, false
+	}
+	root, ok := pinnedRoots[val.Interface()]
+	return root, ok
+}
+
+// InvalidateRoot drops the root pinned for the object pointed to by val, along with the
+// cached Merkle layers of its root array fields, so that the next hash tree root
+// computation hashes the object from scratch after it was mutated in place.
+func InvalidateRoot(val reflect.Value) {
+	if val.Kind() == reflect.Ptr && !val.IsNil() {
+		pinnedLock.Lock()
+		delete(pinnedRoots, val.Interface())
+		pinnedLock.Unlock()
+	}
+	typ := val.Type()
+	for typ.Kind() == reflect.Ptr {
+		typ = typ.Elem()
+	}
+	if typ.Kind() == reflect.Struct {
+		rootsArrayFactory.invalidate(typ.Name() + ".")
+	}
+}
+
+// invalidate drops the cached leaves and layers of every field whose name starts with prefix.
+func (a *rootsArraySSZ) invalidate(prefix string) {
+	a.lock.Lock()
+	defer a.lock.Unlock()
+	for fieldName := range a.cachedLeaves {
+		if strings.HasPrefix(fieldName, prefix) {
+			delete(a.cachedLeaves, fieldName)
+		}
+	}
+	for fieldName := range a.layers {
+		if strings.HasPrefix(fieldName, prefix) {
+			delete(a.layers, fieldName)
+		}
+	}
+}
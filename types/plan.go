@@ -0,0 +1,296 @@
+package types
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// fieldOp is the operation computing the root of a field of a container.
+type fieldOp uint8
+
+const (
+	// opRoot computes the root of the field like the one of any other value.
+	opRoot fieldOp = iota
+	// opBasic serializes a basic value into its chunk.
+	opBasic
+	// opBytes copies a byte vector of at most 32 bytes into its chunk.
+	opBytes
+	// opBitlist merkleizes a bitlist.
+	opBitlist
+	// opProgressive merkleizes a progressive list.
+	opProgressive
+	// opPackedList merkleizes a list of uint64 or uint8 values, such as the inactivity
+	// scores or the participation flags of a beacon state, serialized without reflecting
+	// over its elements.
+	opPackedList
+	// opByteVectors merkleizes a list or vector of byte vectors of at most 64 bytes, such
+	// as the public keys of a sync committee or the KZG commitments of a block body,
+	// hashing their chunks in a single call.
+	opByteVectors
+	// opNestedLists merkleizes a list or vector of lists which have limits of their own,
+	// such as the transactions of an execution payload.
+	opNestedLists
+)
+
+var uint64SliceType = reflect.TypeOf([]uint64(nil))
+
+// fieldStep computes the root of a single field of a container.
+type fieldStep struct {
+	op    fieldOp
+	index int
+	field reflect.StructField
+	// typ is the SSZ type of the field, as given by its ssz-size tags, and capacity its
+	// ssz-max limit.
+	typ      reflect.Type
+	capacity uint64
+	// elemCapacities are the limits of the lists nested in the elements of the field.
+	elemCapacities []uint64
+	// err is the error of the tags of the field, returned once the field is hashed.
+	err error
+}
+
+// rootPlan is the flat list of steps computing the roots of the fields of a container
+// type, which are compiled once, so that hashing a container does not parse the tags of
+// its fields or look up how to hash them again.
+type rootPlan struct {
+	steps []fieldStep
+}
+
+// rootPlans maps container types to their plans.
+var rootPlans sync.Map
+
+// planFor returns the plan of a container type, compiling it on first use.
+func planFor(typ reflect.Type) *rootPlan {
+	if p, ok := rootPlans.Load(typ); ok {
+		return p.(*rootPlan)
+	}
+	p, _ := rootPlans.LoadOrStore(typ, compileRootPlan(typ))
+	return p.(*rootPlan)
+}
+
+// resetRootPlans drops the compiled plans, whose steps depend on the registered codecs.
+func resetRootPlans() {
+	rootPlans.Range(func(typ, _ interface{}) bool {
+		rootPlans.Delete(typ)
+		return true
+	})
+}
+
+func compileRootPlan(typ reflect.Type) *rootPlan {
+	p := &rootPlan{}
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(field) {
+			continue
+		}
+		s := fieldStep{index: i, field: field, capacity: determineFieldCapacity(field)}
+		if field.Type == bitlistType {
+			s.op = opBitlist
+			p.steps = append(p.steps, s)
+			continue
+		}
+		s.typ, s.err = determineFieldType(field)
+		if s.err == nil {
+			s.elemCapacities = elementCapacities(field)
+			s.op = fieldOpFor(field, s.typ, s.elemCapacities)
+		}
+		p.steps = append(p.steps, s)
+	}
+	return p
+}
+
+// fieldOpFor returns the operation computing the root of a field of the given SSZ type.
+// Values held in a single chunk are serialized in place, unless a codec hashes them.
+func fieldOpFor(field reflect.StructField, typ reflect.Type, elemCapacities []uint64) fieldOp {
+	if IsProgressive(field) {
+		return opProgressive
+	}
+	if _, ok := registeredCodec(typ); ok {
+		return opRoot
+	}
+	kind := typ.Kind()
+	switch {
+	case elemCapacities != nil && (kind == reflect.Slice || kind == reflect.Array):
+		return opNestedLists
+	case isBasicType(kind) && field.Type.Kind() == kind:
+		return opBasic
+	case kind == reflect.Array && typ.Elem().Kind() == reflect.Uint8 && typ.Len() <= 32:
+		if k := field.Type.Kind(); k == reflect.Array || (k == reflect.Slice && field.Type.Elem().Kind() == reflect.Uint8) {
+			return opBytes
+		}
+	case field.Type == typ && (typ == uint64SliceType || (kind == reflect.Slice && typ.Elem().Kind() == reflect.Uint8)):
+		return opPackedList
+	case (kind == reflect.Slice || kind == reflect.Array) && isByteVector(typ.Elem()) && typ.Elem().Len() <= 64:
+		if _, ok := registeredCodec(typ.Elem()); !ok && field.Type.Kind() == reflect.Slice {
+			if k := field.Type.Elem().Kind(); k == reflect.Slice || k == reflect.Array {
+				return opByteVectors
+			}
+		}
+	}
+	return opRoot
+}
+
+// CompileRootPlans compiles the plans computing the roots of the fields of a container
+// type, and of every container type found in its fields and elements, ahead of hashing
+// them with a Hasher. It returns the first error found in the tags of their fields.
+func CompileRootPlans(typ reflect.Type) error {
+	return compileRootPlans(typ, make(map[reflect.Type]bool))
+}
+
+func compileRootPlans(typ reflect.Type, seen map[reflect.Type]bool) error {
+	for typ.Kind() == reflect.Ptr || typ.Kind() == reflect.Slice || typ.Kind() == reflect.Array {
+		typ = typ.Elem()
+	}
+	if typ.Kind() != reflect.Struct || seen[typ] || isUnionType(typ) || isOptionalType(typ) || isStableType(typ) {
+		return nil
+	}
+	seen[typ] = true
+	for _, s := range planFor(typ).steps {
+		if s.err != nil {
+			return withFieldPath(s.err, s.field)
+		}
+		if s.typ == nil {
+			continue
+		}
+		if err := compileRootPlans(s.typ, seen); err != nil {
+			return withFieldPath(err, s.field)
+		}
+	}
+	return nil
+}
+
+// run pushes the roots of the fields of a container on top of the stack of h.
+func (p *rootPlan) run(h *Hasher, val reflect.Value) error {
+	for i := range p.steps {
+		s := &p.steps[i]
+		f := val.Field(s.index)
+		var r [32]byte
+		var err error
+		switch s.op {
+		case opBasic:
+			h.buf = appendBasic(h.buf[:0], f, s.typ.Kind())
+			copy(r[:], h.buf)
+		case opBytes:
+			if f.Kind() == reflect.Slice {
+				copy(r[:s.typ.Len()], f.Bytes())
+			} else {
+				for j := 0; j < s.typ.Len() && j < f.Len(); j++ {
+					r[j] = byte(f.Index(j).Uint())
+				}
+			}
+		case opBitlist:
+			r, err = BitlistRoot(bitfield.Bitlist(f.Bytes()), s.capacity)
+		case opPackedList:
+			r, err = h.packedListStep(s, f)
+		case opByteVectors:
+			r, err = h.byteVectorsStep(s, f)
+		default:
+			r, err = h.stepRoot(s, f)
+		}
+		if err != nil {
+			return withFieldPath(err, s.field)
+		}
+		h.chunks = append(h.chunks, r)
+	}
+	return nil
+}
+
+// stepRoot computes the root of a field which does not fit a single chunk.
+func (h *Hasher) stepRoot(s *fieldStep, f reflect.Value) ([32]byte, error) {
+	if s.err != nil {
+		return [32]byte{}, s.err
+	}
+	if err := checkListLimit(f, s.typ, s.capacity); err != nil {
+		return [32]byte{}, err
+	}
+	if s.op == opProgressive {
+		return ProgressiveListRoot(f, s.typ)
+	}
+	fieldVal, err := vectorValue(f, s.typ)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if s.op == opNestedLists {
+		return h.nestedRoot(fieldVal, s.typ, s.capacity, s.elemCapacities)
+	}
+	return h.root(fieldVal, s.typ, s.capacity)
+}
+
+// isByteVector returns true for arrays of bytes.
+func isByteVector(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Array && typ.Elem().Kind() == reflect.Uint8
+}
+
+// packedListStep computes the root of a list of uint64 or uint8 values.
+func (h *Hasher) packedListStep(s *fieldStep, f reflect.Value) ([32]byte, error) {
+	if err := checkListLimit(f, s.typ, s.capacity); err != nil {
+		return [32]byte{}, err
+	}
+	if s.typ != uint64SliceType {
+		h.buf = append(h.buf[:0], f.Bytes()...)
+		return h.packedList(f.Len(), 1, s.capacity)
+	}
+	values := f.Interface().([]uint64)
+	h.buf = h.buf[:0]
+	for _, v := range values {
+		h.buf = append(h.buf, byte(v), byte(v>>8), byte(v>>16), byte(v>>24), byte(v>>32), byte(v>>40), byte(v>>48), byte(v>>56))
+	}
+	return h.packedList(len(values), 8, s.capacity)
+}
+
+// byteVectorsStep computes the root of a list or vector of byte vectors held in slices
+// or arrays. The roots of vectors over 32 bytes long are hashed together, in a single
+// call to the hash backend.
+func (h *Hasher) byteVectorsStep(s *fieldStep, f reflect.Value) ([32]byte, error) {
+	if err := checkListLimit(f, s.typ, s.capacity); err != nil {
+		return [32]byte{}, err
+	}
+	// Vectors are padded to their lengths, and so are the byte vectors they hold.
+	fieldVal, err := vectorValue(f, s.typ)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	n := fieldVal.Len()
+	size := s.typ.Elem().Len()
+	base := len(h.chunks)
+	if size <= 32 {
+		for i := 0; i < n; i++ {
+			var chunk [32]byte
+			h.buf = appendByteVector(h.buf[:0], fieldVal.Index(i), size)
+			copy(chunk[:], h.buf)
+			h.chunks = append(h.chunks, chunk)
+		}
+	} else {
+		h.pairs = h.pairs[:0]
+		for i := 0; i < n; i++ {
+			if i%contextCheckInterval == 0 {
+				if err := h.checkContext(); err != nil {
+					return [32]byte{}, err
+				}
+			}
+			var lo, hi [32]byte
+			h.buf = appendByteVector(h.buf[:0], fieldVal.Index(i), size)
+			copy(lo[:], h.buf)
+			copy(hi[:], h.buf[32:])
+			h.pairs = append(h.pairs, lo, hi)
+		}
+		h.chunks = append(h.chunks, make([][32]byte, n)...)
+		hashing.HashPairs(h.chunks[base:], h.pairs)
+	}
+	if s.typ.Kind() == reflect.Array {
+		return h.merkleize(base, uint64(s.typ.Len()))
+	}
+	limit := s.capacity
+	if limit == 0 {
+		limit = uint64(n)
+	}
+	root, err := h.merkleize(base, limit)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.MixInLength(root, uint64(n)), nil
+}
@@ -0,0 +1,410 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+type planSlot uint64
+
+type planCheckpoint struct {
+	Epoch uint64
+	Root  [32]byte
+}
+
+type planContainer struct {
+	Slot        planSlot
+	Flag        bool
+	Index       uint32
+	Version     [4]byte
+	Root        []byte           `ssz-size:"32"`
+	Pubkey      []byte           `ssz-size:"48"`
+	Bits        bitfield.Bitlist `ssz-max:"2048"`
+	Balances    []uint64         `ssz-max:"1024"`
+	Checkpoint  *planCheckpoint
+	Checkpoints []*planCheckpoint `ssz-max:"16"`
+	Cache       []byte            `ssz:"-"`
+}
+
+func TestRootPlan_MatchesFactory(t *testing.T) {
+	item := &planContainer{
+		Slot:        9,
+		Flag:        true,
+		Index:       7,
+		Version:     [4]byte{1, 2, 3, 4},
+		Root:        make([]byte, 32),
+		Pubkey:      make([]byte, 48),
+		Bits:        bitfield.NewBitlist(10),
+		Balances:    []uint64{32, 31},
+		Checkpoint:  &planCheckpoint{Epoch: 3, Root: [32]byte{8}},
+		Checkpoints: []*planCheckpoint{{Epoch: 1}, {Epoch: 2}},
+		Cache:       []byte{1},
+	}
+	item.Root[1] = 5
+	item.Pubkey[47] = 0xff
+	item.Bits.SetBitAt(3, true)
+	val := reflect.ValueOf(item)
+	factory, err := SSZFactory(val, val.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := factory.Root(val, val.Type(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plain, err := (&Hasher{}).Root(val, val.Type(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if plain != want {
+		t.Errorf("Wanted root %#x, received %#x", want, plain)
+	}
+	ops := []fieldOp{opBasic, opBasic, opBasic, opBytes, opBytes, opRoot, opBitlist, opPackedList, opRoot, opRoot}
+	steps := planFor(reflect.TypeOf(planContainer{})).steps
+	if len(steps) != len(ops) {
+		t.Fatalf("Wanted %d steps, received %d", len(ops), len(steps))
+	}
+	for i, s := range steps {
+		if s.op != ops[i] {
+			t.Errorf("Field %s: wanted operation %d, received %d", s.field.Name, ops[i], s.op)
+		}
+	}
+
+	// Registering a codec recompiles the plans, which then hash slots with the codec.
+	slotType := reflect.TypeOf(planSlot(0))
+	if err := RegisterCodec(slotType, &Codec{
+		MarshalTo:    func(val interface{}, dst []byte) ([]byte, error) { return dst, nil },
+		Unmarshal:    func(buf []byte, val interface{}) error { return nil },
+		HashTreeRoot: func(val interface{}) ([32]byte, error) { return [32]byte{0xaa}, nil },
+	}); err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := RegisterCodec(slotType, nil); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	withCodec, err := (&Hasher{}).Root(val, val.Type(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if withCodec == plain {
+		t.Error("Expected the codec to hash slots")
+	}
+}
+
+func TestCompileRootPlans(t *testing.T) {
+	type invalidNested struct {
+		Data []byte `ssz-size:"x"`
+	}
+	type invalid struct {
+		Slot   uint64
+		Nested []*invalidNested `ssz-max:"4"`
+	}
+	if err := CompileRootPlans(reflect.TypeOf(&planContainer{})); err != nil {
+		t.Fatal(err)
+	}
+	if err := CompileRootPlans(reflect.TypeOf(&invalid{})); err == nil {
+		t.Error("Expected error for invalid tags of a nested container")
+	}
+}
+
+type planSyncCommittee struct {
+	Pubkeys         [][]byte `ssz-size:"32,48"`
+	AggregatePubkey []byte   `ssz-size:"48"`
+}
+
+// planAltairState holds the fields added to the beacon state by Altair.
+type planAltairState struct {
+	Slot                       uint64
+	BlockRoots                 [][]byte `ssz-size:"64,32"`
+	PreviousEpochParticipation []byte   `ssz-max:"1099511627776"`
+	CurrentEpochParticipation  []byte   `ssz-max:"1099511627776"`
+	InactivityScores           []uint64 `ssz-max:"1099511627776"`
+	CurrentSyncCommittee       *planSyncCommittee
+	NextSyncCommittee          *planSyncCommittee
+	HistoricalRoots            [][]byte `ssz-size:"?,32" ssz-max:"16777216"`
+}
+
+func TestRootPlan_AltairState(t *testing.T) {
+	state := &planAltairState{
+		Slot:                       100,
+		BlockRoots:                 make([][]byte, 64),
+		PreviousEpochParticipation: []byte{7, 3, 0, 1},
+		CurrentEpochParticipation:  make([]byte, 100),
+		InactivityScores:           []uint64{0, 4, 1 << 40},
+		CurrentSyncCommittee:       &planSyncCommittee{AggregatePubkey: make([]byte, 48)},
+		HistoricalRoots:            [][]byte{make([]byte, 32), make([]byte, 32)},
+	}
+	state.HistoricalRoots[1][31] = 9
+	for i := range state.BlockRoots {
+		state.BlockRoots[i] = make([]byte, 32)
+		state.BlockRoots[i][0] = byte(i)
+	}
+	for i := 0; i < 32; i++ {
+		pubkey := make([]byte, 48)
+		pubkey[0], pubkey[47] = byte(i), 0xc0
+		state.CurrentSyncCommittee.Pubkeys = append(state.CurrentSyncCommittee.Pubkeys, pubkey)
+	}
+	state.NextSyncCommittee = state.CurrentSyncCommittee
+	state.CurrentEpochParticipation[99] = 7
+	val := reflect.ValueOf(state)
+	factory, err := SSZFactory(val, val.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := factory.Root(val, val.Type(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := (&Hasher{}).Root(val, val.Type(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+	for _, tt := range []struct {
+		typ   reflect.Type
+		field int
+		op    fieldOp
+	}{
+		{reflect.TypeOf(planAltairState{}), 1, opByteVectors},
+		{reflect.TypeOf(planAltairState{}), 2, opPackedList},
+		{reflect.TypeOf(planAltairState{}), 4, opPackedList},
+		{reflect.TypeOf(planAltairState{}), 7, opByteVectors},
+		{reflect.TypeOf(planSyncCommittee{}), 0, opByteVectors},
+	} {
+		if s := planFor(tt.typ).steps[tt.field]; s.op != tt.op {
+			t.Errorf("Field %s: wanted operation %d, received %d", s.field.Name, tt.op, s.op)
+		}
+	}
+
+	state.CurrentSyncCommittee.Pubkeys[3] = make([]byte, 49)
+	if _, err := (&Hasher{}).Root(val, val.Type(), 0); err == nil {
+		t.Error("Expected error for a public key over 48 bytes")
+	}
+}
+
+type planPayload struct {
+	GasUsed      uint64
+	Transactions [][]byte `ssz-max:"4,3"`
+}
+
+func TestRootPlan_NestedLists(t *testing.T) {
+	payload := &planPayload{GasUsed: 21000, Transactions: [][]byte{{1, 2}, {3}}}
+	var roots [][32]byte
+	for _, tx := range payload.Transactions {
+		var chunk [32]byte
+		copy(chunk[:], tx)
+		r, err := MerkleizeChunks([][32]byte{chunk}, 1)
+		if err != nil {
+			t.Fatal(err)
+		}
+		roots = append(roots, hashing.MixInLength(r, uint64(len(tx))))
+	}
+	r, err := MerkleizeChunks(roots, 4)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gasUsed [32]byte
+	gasUsed[0], gasUsed[1] = 0x08, 0x52
+	want, err := MerkleizeChunks([][32]byte{gasUsed, hashing.MixInLength(r, 2)}, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	val := reflect.ValueOf(payload)
+	factory, err := SSZFactory(val, val.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	fromFactory, err := factory.Root(val, val.Type(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := (&Hasher{}).Root(val, val.Type(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want || fromFactory != want {
+		t.Errorf("Wanted root %#x, received %#x from the hasher and %#x from the factory", want, got, fromFactory)
+	}
+	if s := planFor(reflect.TypeOf(planPayload{})).steps[1]; s.op != opNestedLists {
+		t.Errorf("Wanted operation %d, received %d", opNestedLists, s.op)
+	}
+
+	payload.Transactions[1] = []byte{3, 4, 5, 6}
+	if _, err := (&Hasher{}).Root(val, val.Type(), 0); err == nil {
+		t.Error("Expected error for a transaction over its limit")
+	}
+	if _, err := factory.Root(val, val.Type(), "", 0); err == nil {
+		t.Error("Expected error for a transaction over its limit")
+	}
+}
+
+type planWithdrawal struct {
+	Index          uint64
+	ValidatorIndex uint64
+	Address        []byte `ssz-size:"20"`
+	Amount         uint64
+}
+
+type planBLSToExecutionChange struct {
+	ValidatorIndex     uint64
+	FromBLSPubkey      []byte `ssz-size:"48"`
+	ToExecutionAddress []byte `ssz-size:"20"`
+}
+
+type planSignedBLSToExecutionChange struct {
+	Message   *planBLSToExecutionChange
+	Signature []byte `ssz-size:"96"`
+}
+
+type planHistoricalSummary struct {
+	BlockSummaryRoot [32]byte
+	StateSummaryRoot [32]byte
+}
+
+// planCapella holds the lists of containers added by Capella to the beacon block body,
+// the execution payload and the beacon state.
+type planCapella struct {
+	Withdrawals           []*planWithdrawal                 `ssz-max:"16"`
+	BLSToExecutionChanges []*planSignedBLSToExecutionChange `ssz-max:"16"`
+	NextWithdrawalIndex   uint64
+	HistoricalSummaries   []planHistoricalSummary `ssz-max:"16777216"`
+}
+
+func TestRootPlan_CapellaContainers(t *testing.T) {
+	item := &planCapella{NextWithdrawalIndex: 12}
+	for i := 0; i < 5; i++ {
+		address := make([]byte, 20)
+		address[19] = byte(i)
+		item.Withdrawals = append(item.Withdrawals, &planWithdrawal{
+			Index:          uint64(i),
+			ValidatorIndex: uint64(1000 + i),
+			Address:        address,
+			Amount:         uint64(i) * 1e9,
+		})
+		item.HistoricalSummaries = append(item.HistoricalSummaries, planHistoricalSummary{
+			BlockSummaryRoot: [32]byte{byte(i)},
+			StateSummaryRoot: [32]byte{31: byte(i)},
+		})
+	}
+	change := &planBLSToExecutionChange{ValidatorIndex: 4, FromBLSPubkey: make([]byte, 48), ToExecutionAddress: make([]byte, 20)}
+	change.FromBLSPubkey[47] = 0xa0
+	item.BLSToExecutionChanges = []*planSignedBLSToExecutionChange{
+		{Message: change, Signature: make([]byte, 96)},
+		{Signature: make([]byte, 96)},
+	}
+	val := reflect.ValueOf(item)
+	factory, err := SSZFactory(val, val.Type())
+	if err != nil {
+		t.Fatal(err)
+	}
+	want, err := factory.Root(val, val.Type(), "", 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := (&Hasher{}).Root(val, val.Type(), 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+
+	for len(item.Withdrawals) <= 16 {
+		item.Withdrawals = append(item.Withdrawals, &planWithdrawal{Address: make([]byte, 20)})
+	}
+	if _, err := (&Hasher{}).Root(val, val.Type(), 0); err == nil {
+		t.Error("Expected error for more than 16 withdrawals")
+	}
+}
+
+type planBeaconBlockHeader struct {
+	Slot          uint64
+	ProposerIndex uint64
+	ParentRoot    [32]byte
+	StateRoot     [32]byte
+	BodyRoot      [32]byte
+}
+
+type planSignedBeaconBlockHeader struct {
+	Message   *planBeaconBlockHeader
+	Signature []byte `ssz-size:"96"`
+}
+
+// planBlobSidecar is the Deneb blob sidecar, with a blob of 4096 field elements.
+type planBlobSidecar struct {
+	Index                       uint64
+	Blob                        []byte `ssz-size:"131072"`
+	KZGCommitment               []byte `ssz-size:"48"`
+	KZGProof                    [48]byte
+	SignedBlockHeader           *planSignedBeaconBlockHeader
+	KZGCommitmentInclusionProof [][]byte `ssz-size:"17,32"`
+}
+
+type planBlobKZGCommitments struct {
+	Commitments [][48]byte `ssz-max:"4096"`
+	Proofs      [][]byte   `ssz-size:"?,48" ssz-max:"4096"`
+}
+
+func TestRootPlan_DenebBlobs(t *testing.T) {
+	sidecar := &planBlobSidecar{
+		Index:             3,
+		Blob:              make([]byte, 131072),
+		KZGCommitment:     make([]byte, 48),
+		KZGProof:          [48]byte{0xc0},
+		SignedBlockHeader: &planSignedBeaconBlockHeader{Message: &planBeaconBlockHeader{Slot: 9}, Signature: make([]byte, 96)},
+	}
+	for i := range sidecar.Blob {
+		sidecar.Blob[i] = byte(i * 7)
+	}
+	sidecar.KZGCommitment[47] = 0x0b
+	for i := 0; i < 17; i++ {
+		sidecar.KZGCommitmentInclusionProof = append(sidecar.KZGCommitmentInclusionProof, make([]byte, 32))
+		sidecar.KZGCommitmentInclusionProof[i][0] = byte(i)
+	}
+	commitments := &planBlobKZGCommitments{}
+	for i := 0; i < 6; i++ {
+		proof := make([]byte, 48)
+		proof[47] = byte(i)
+		commitments.Commitments = append(commitments.Commitments, [48]byte{byte(i), 47: 0xc0})
+		commitments.Proofs = append(commitments.Proofs, proof)
+	}
+	for _, item := range []interface{}{sidecar, commitments} {
+		val := reflect.ValueOf(item)
+		factory, err := SSZFactory(val, val.Type())
+		if err != nil {
+			t.Fatal(err)
+		}
+		want, err := factory.Root(val, val.Type(), "", 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := (&Hasher{}).Root(val, val.Type(), 0)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got != want {
+			t.Errorf("%T: wanted root %#x, received %#x", item, want, got)
+		}
+	}
+	for _, tt := range []struct {
+		typ   reflect.Type
+		field int
+		op    fieldOp
+	}{
+		{reflect.TypeOf(planBlobSidecar{}), 1, opRoot},
+		{reflect.TypeOf(planBlobSidecar{}), 5, opByteVectors},
+		{reflect.TypeOf(planBlobKZGCommitments{}), 0, opByteVectors},
+		{reflect.TypeOf(planBlobKZGCommitments{}), 1, opByteVectors},
+	} {
+		if s := planFor(tt.typ).steps[tt.field]; s.op != tt.op {
+			t.Errorf("Field %s: wanted operation %d, received %d", s.field.Name, tt.op, s.op)
+		}
+	}
+}
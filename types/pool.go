@@ -0,0 +1,78 @@
+package types
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/protolambda/zssz/merkle"
+)
+
+// maxPooledChunks bounds the size of the buffers returned to the pools, so that hashing
+// a large state once does not keep its chunks alive for the lifetime of the process.
+const maxPooledChunks = 1 << 15
+
+// Scratch buffers are pooled, so that hashing blocks and attestations in a steady state
+// produces little garbage. Pointers to slices are pooled, as putting a slice in a pool
+// allocates.
+var (
+	bytesPool = sync.Pool{
+		New: func() interface{} {
+			return new([]byte)
+		},
+	}
+	chunksPool = sync.Pool{
+		New: func() interface{} {
+			return new([][32]byte)
+		},
+	}
+)
+
+// getBytes returns a pooled byte buffer of length n, whose content is undefined.
+func getBytes(n int) *[]byte {
+	b := bytesPool.Get().(*[]byte)
+	if cap(*b) < n {
+		*b = make([]byte, n)
+	}
+	*b = (*b)[:n]
+	return b
+}
+
+func putBytes(b *[]byte) {
+	if cap(*b) <= maxPooledChunks*32 {
+		bytesPool.Put(b)
+	}
+}
+
+// getChunks returns a pooled slice of n chunks, whose content is undefined.
+func getChunks(n int) *[][32]byte {
+	c := chunksPool.Get().(*[][32]byte)
+	if cap(*c) < n {
+		*c = make([][32]byte, n)
+	}
+	*c = (*c)[:n]
+	return c
+}
+
+func putChunks(c *[][32]byte) {
+	if cap(*c) <= maxPooledChunks {
+		chunksPool.Put(c)
+	}
+}
+
+// merkleizeBytes packs serialized basic values into chunks, right-padding the last one
+// with zero bytes, and merkleizes them in a tree with room for limit chunks.
+func merkleizeBytes(data []byte, limit uint64) ([32]byte, error) {
+	n := (len(data) + 31) / 32
+	if uint64(n) > limit {
+		return [32]byte{}, errors.New("merkleizing list that is too large, over limit")
+	}
+	layer := getChunks(n)
+	defer putChunks(layer)
+	for i := range *layer {
+		chunk := &(*layer)[i]
+		for j := copy(chunk[:], data[i*32:]); j < 32; j++ {
+			chunk[j] = 0
+		}
+	}
+	return merkleizeLayer(*layer, merkle.GetDepth(limit), hashWorkers(n)), nil
+}
@@ -0,0 +1,95 @@
+package types
+
+import (
+	"fmt"
+	"math/bits"
+	"reflect"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// progressiveTag marks list fields merkleized as EIP-7916 progressive lists:
+//
+//  type Block struct {
+//      Transactions [][]byte `ssz:"progressive"`
+//  }
+//
+// Progressive lists are serialized as regular lists, but have no limit. Their chunks
+// are merkleized into a chain of subtrees of 1, 4, 16... leaves, so that generalized
+// indices of their elements do not depend on a limit which may be raised later.
+const progressiveTag = "progressive"
+
+// IsProgressive returns true if a struct field is tagged as a progressive list.
+func IsProgressive(field reflect.StructField) bool {
+	tag, ok := field.Tag.Lookup("ssz")
+	return ok && tag == progressiveTag
+}
+
+// ProgressiveListRoot returns the hash tree root of a list value of type typ,
+// merkleized as a progressive list.
+func ProgressiveListRoot(val reflect.Value, typ reflect.Type) ([32]byte, error) {
+	if typ == bitlistType {
+		return [32]byte{}, fmt.Errorf("progressive bitlists are not supported")
+	}
+	if typ.Kind() != reflect.Slice && typ.Kind() != reflect.String {
+		return [32]byte{}, fmt.Errorf("only lists can be progressive, not %v", typ)
+	}
+	var chunks [][]byte
+	if typ.Kind() == reflect.String || isBasicType(typ.Elem().Kind()) {
+		buf := make([]byte, determineVariableSize(val, typ))
+		factory, err := SSZFactory(val, typ)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		if _, err := factory.Marshal(val, typ, buf, 0); err != nil {
+			return [32]byte{}, err
+		}
+		for i := 0; i < len(buf); i += 32 {
+			chunk := make([]byte, 32)
+			copy(chunk, buf[i:])
+			chunks = append(chunks, chunk)
+		}
+	} else {
+		chunks = make([][]byte, val.Len())
+		for i := 0; i < val.Len(); i++ {
+			factory, err := SSZFactory(val.Index(i), typ.Elem())
+			if err != nil {
+				return [32]byte{}, err
+			}
+			r, err := factory.Root(val.Index(i), typ.Elem(), "", 0)
+			if err != nil {
+				return [32]byte{}, err
+			}
+			chunks[i] = r[:]
+		}
+	}
+	root, err := merkleizeProgressive(chunks, 1)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.MixInLength(root, uint64(val.Len())), nil
+}
+
+// merkleizeProgressive is merkleize_progressive from EIP-7916, which hashes the chunks
+// past the first numLeaves ones with the subtree of those first chunks.
+func merkleizeProgressive(chunks [][]byte, numLeaves uint64) ([32]byte, error) {
+	if len(chunks) == 0 {
+		return [32]byte{}, nil
+	}
+	n := numLeaves
+	if uint64(len(chunks)) < n {
+		n = uint64(len(chunks))
+	}
+	subtree, err := bitwiseMerkleize(chunks[:n], n, numLeaves)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if bits.Len64(numLeaves) > 62 {
+		return [32]byte{}, fmt.Errorf("progressive list of %d chunks is too large", len(chunks))
+	}
+	rest, err := merkleizeProgressive(chunks[n:], numLeaves*4)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.HashPair(rest, subtree), nil
+}
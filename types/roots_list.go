@@ -0,0 +1,114 @@
+package types
+
+import (
+	"fmt"
+
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// RootsList maintains the Merkle tree of a list of leaf roots, such as the roots of the
+// validators of the registry, so that replacing or appending a leaf re-hashes a single
+// branch rather than the whole list. Every layer of the tree is kept in memory, up to
+// the last node which is not the root of a zero subtree.
+//
+//  list, err := types.NewRootsList(validatorRoots, ValidatorRegistryLimit)
+//  if err != nil {
+//      return err
+//  }
+//  if err := list.Set(i, validatorRoot); err != nil {
+//      return err
+//  }
+//  root := list.Root()
+type RootsList struct {
+	// layers[d] holds the nodes at height d, the leaves being at height 0.
+	layers [][][32]byte
+	depth  uint8
+	limit  uint64
+}
+
+// NewRootsList returns the tree of a list of at most limit leaves, holding the given ones.
+func NewRootsList(leaves [][32]byte, limit uint64) (*RootsList, error) {
+	if uint64(len(leaves)) > limit {
+		return nil, fmt.Errorf("list of length %d exceeds limit %d", len(leaves), limit)
+	}
+	l := &RootsList{
+		depth: merkle.GetDepth(limit),
+		limit: limit,
+	}
+	l.layers = make([][][32]byte, l.depth+1)
+	l.layers[0] = append(make([][32]byte, 0, len(leaves)), leaves...)
+	for d := uint8(0); d < l.depth; d++ {
+		below := l.layers[d]
+		layer := make([][32]byte, (len(below)+1)/2)
+		for i := range layer {
+			layer[i] = l.parent(d, below, 2*i)
+		}
+		l.layers[d+1] = layer
+	}
+	return l, nil
+}
+
+// Len returns the number of leaves.
+func (l *RootsList) Len() int {
+	return len(l.layers[0])
+}
+
+// Leaf returns the i-th leaf.
+func (l *RootsList) Leaf(i int) [32]byte {
+	return l.layers[0][i]
+}
+
+// Set replaces the i-th leaf.
+func (l *RootsList) Set(i int, leaf [32]byte) error {
+	if i < 0 || i >= l.Len() {
+		return fmt.Errorf("index %d out of range for list of length %d", i, l.Len())
+	}
+	l.layers[0][i] = leaf
+	l.update(i)
+	return nil
+}
+
+// Append adds a leaf at the end of the list.
+func (l *RootsList) Append(leaf [32]byte) error {
+	if uint64(l.Len()) >= l.limit {
+		return fmt.Errorf("list reached its limit %d", l.limit)
+	}
+	l.layers[0] = append(l.layers[0], leaf)
+	l.update(l.Len() - 1)
+	return nil
+}
+
+// DataRoot returns the root of the tree of leaves, before mixing in the length.
+func (l *RootsList) DataRoot() [32]byte {
+	if top := l.layers[l.depth]; len(top) > 0 {
+		return top[0]
+	}
+	return hashing.ZeroHash(l.depth)
+}
+
+// Root returns the hash tree root of the list, that is the root of the tree of leaves
+// mixed in with the number of leaves.
+func (l *RootsList) Root() [32]byte {
+	return hashing.MixInLength(l.DataRoot(), uint64(l.Len()))
+}
+
+// update re-hashes the branch above the i-th leaf, growing layers after an append.
+func (l *RootsList) update(i int) {
+	for d := uint8(0); d < l.depth; d++ {
+		i /= 2
+		if i == len(l.layers[d+1]) {
+			l.layers[d+1] = append(l.layers[d+1], [32]byte{})
+		}
+		l.layers[d+1][i] = l.parent(d, l.layers[d], 2*i)
+	}
+}
+
+// parent hashes the node at index i of a layer at height d with its right sibling,
+// which is the root of a zero subtree past the end of the layer.
+func (l *RootsList) parent(d uint8, layer [][32]byte, i int) [32]byte {
+	if i+1 < len(layer) {
+		return hashing.HashPair(layer[i], layer[i+1])
+	}
+	return hashing.HashPair(layer[i], hashing.ZeroHash(d))
+}
@@ -0,0 +1,79 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+func TestRootsList_SetAndAppend(t *testing.T) {
+	const limit = 1 << 10
+	want := func(leaves [][32]byte) [32]byte {
+		layer := append([][32]byte(nil), leaves...)
+		return hashing.MixInLength(merkleizeLayer(layer, merkle.GetDepth(limit), 1), uint64(len(leaves)))
+	}
+	var leaves [][32]byte
+	list, err := NewRootsList(nil, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Root() != want(leaves) {
+		t.Errorf("Wrong root for empty list")
+	}
+	for i := 0; i < 37; i++ {
+		leaf := [32]byte{byte(i + 1)}
+		leaves = append(leaves, leaf)
+		if err := list.Append(leaf); err != nil {
+			t.Fatal(err)
+		}
+		if list.Root() != want(leaves) {
+			t.Fatalf("Wrong root after appending %d leaves", i+1)
+		}
+	}
+	for _, i := range []int{0, 17, 36} {
+		leaves[i] = [32]byte{0xff, byte(i)}
+		if err := list.Set(i, leaves[i]); err != nil {
+			t.Fatal(err)
+		}
+		if list.Root() != want(leaves) {
+			t.Fatalf("Wrong root after setting leaf %d", i)
+		}
+	}
+	rebuilt, err := NewRootsList(leaves, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rebuilt.Root() != list.Root() {
+		t.Error("Tree built from leaves differs from the updated one")
+	}
+	if err := list.Set(37, [32]byte{}); err == nil {
+		t.Error("Expected error setting a leaf out of range")
+	}
+	if _, err := NewRootsList(make([][32]byte, 3), 2); err == nil {
+		t.Error("Expected error for list over limit")
+	}
+	full, err := NewRootsList(make([][32]byte, 2), 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := full.Append([32]byte{}); err == nil {
+		t.Error("Expected error appending past the limit")
+	}
+}
+
+func TestRootsList_UpdateHashesOneBranch(t *testing.T) {
+	list, err := NewRootsList(make([][32]byte, 1000), 1<<40)
+	if err != nil {
+		t.Fatal(err)
+	}
+	hashing.StartCounting()
+	defer hashing.StopCounting()
+	_, before := hashing.Counters()
+	if err := list.Set(500, [32]byte{1}); err != nil {
+		t.Fatal(err)
+	}
+	if _, after := hashing.Counters(); after-before != 2*40 {
+		t.Errorf("Wanted %d chunks hashed, received %d", 2*40, after-before)
+	}
+}
@@ -1,9 +1,12 @@
 package types
 
 import (
-	"bytes"
-	"encoding/binary"
+	"errors"
+	"fmt"
 	"reflect"
+
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
 )
 
 type basicSliceSSZ struct{}
@@ -38,43 +41,64 @@ func (b *basicSliceSSZ) Root(val reflect.Value, typ reflect.Type, fieldName stri
 			limit = uint64(numItems)
 		}
 	}
-	leaves := make([][]byte, numItems)
-	for i := 0; i < numItems; i++ {
-		if isBasicType(val.Index(i).Kind()) {
-			innerBuf := make([]byte, elemSize)
-			if _, err = factory.Marshal(val.Index(i), typ.Elem(), innerBuf, 0); err != nil {
+	if useElementCache(fieldName, typ.Elem()) {
+		merkleRoot, err := containerElementCache.merkleize(fieldName, val, typ.Elem(), merkle.GetDepth(limit))
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return hashing.MixInLength(merkleRoot, uint64(numItems)), nil
+	}
+	if isBasicType(typ.Elem().Kind()) {
+		// Basic values are serialized next to each other in a pooled buffer, which is
+		// then packed into chunks.
+		buf := getBytes(numItems * int(elemSize))
+		defer putBytes(buf)
+		for i := 0; i < numItems; i++ {
+			if _, err := factory.Marshal(val.Index(i), typ.Elem(), *buf, uint64(i)*elemSize); err != nil {
 				return [32]byte{}, err
 			}
-			leaves[i] = innerBuf
-		} else {
+		}
+		merkleRoot, err := merkleizeBytes(*buf, limit)
+		if err != nil {
+			return [32]byte{}, err
+		}
+		return hashing.MixInLength(merkleRoot, uint64(numItems)), nil
+	}
+	if uint64(numItems) > limit {
+		return [32]byte{}, errors.New("merkleizing list that is too large, over limit")
+	}
+	// Roots of composite elements of large lists are computed across workers.
+	layer := getChunks(numItems)
+	defer putChunks(layer)
+	err = parallelFor(numItems, hashWorkers(numItems), func(start, end int) error {
+		for i := start; i < end; i++ {
+			if nilAuditEnabled() {
+				auditNilValue("root", fmt.Sprintf("%s[%d]", fieldName, i), val.Index(i), typ.Elem())
+			}
 			r, err := factory.Root(val.Index(i), typ.Elem(), fieldName, 0)
 			if err != nil {
-				return [32]byte{}, err
+				return err
 			}
-			leaves[i] = r[:]
+			(*layer)[i] = r
 		}
-	}
-	chunks, err := pack(leaves)
-	if err != nil {
-		return [32]byte{}, err
-	}
-	buf := new(bytes.Buffer)
-	if err := binary.Write(buf, binary.LittleEndian, uint64(val.Len())); err != nil {
-		return [32]byte{}, err
-	}
-	output := make([]byte, 32)
-	copy(output, buf.Bytes())
-	merkleRoot, err := bitwiseMerkleize(chunks, uint64(len(chunks)), limit)
+		return nil
+	})
 	if err != nil {
 		return [32]byte{}, err
 	}
-	return mixInLength(merkleRoot, output), nil
+	merkleRoot := merkleizeLayer(*layer, merkle.GetDepth(limit), hashWorkers(numItems))
+	return hashing.MixInLength(merkleRoot, uint64(numItems)), nil
 }
 
 func (b *basicSliceSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
 	index := startOffset
 	var err error
 	if val.Len() == 0 {
+		// An empty bitlist is encoded as its delimiter bit alone.
+		if typ == bitlistType {
+			buf[index] = 1
+			return index + 1, nil
+		}
 		return index, nil
 	}
 	factory, err := SSZFactory(val.Index(0), typ.Elem())
@@ -1,9 +1,13 @@
 package types
 
 import (
-	"bytes"
 	"encoding/binary"
+	"errors"
+	"fmt"
 	"reflect"
+
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
 )
 
 type compositeSliceSSZ struct{}
@@ -13,14 +17,6 @@ func newCompositeSliceSSZ() *compositeSliceSSZ {
 }
 
 func (b *compositeSliceSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
-	output := make([]byte, 32)
-	if val.Len() == 0 && maxCapacity == 0 {
-		root, err := bitwiseMerkleize([][]byte{}, 0, 0)
-		if err != nil {
-			return [32]byte{}, err
-		}
-		return mixInLength(root, output), nil
-	}
 	numItems := val.Len()
 	var factory SSZAble
 	var err error
@@ -30,32 +26,33 @@ func (b *compositeSliceSSZ) Root(val reflect.Value, typ reflect.Type, fieldName
 			return [32]byte{}, err
 		}
 	}
-	roots := make([][]byte, numItems)
-	for i := 0; i < numItems; i++ {
-		r, err := factory.Root(val.Index(i), typ.Elem(), fieldName, 0)
-		if err != nil {
-			return [32]byte{}, err
-		}
-		roots[i] = r[:]
-	}
-	chunks, err := pack(roots)
-	if err != nil {
-		return [32]byte{}, err
-	}
-	buf := new(bytes.Buffer)
-	if err := binary.Write(buf, binary.LittleEndian, uint64(val.Len())); err != nil {
-		return [32]byte{}, err
-	}
-	copy(output, buf.Bytes())
-	objLen := maxCapacity
+	limit := maxCapacity
 	if maxCapacity == 0 {
-		objLen = uint64(val.Len())
+		limit = uint64(numItems)
+	}
+	if uint64(numItems) > limit {
+		return [32]byte{}, errors.New("merkleizing list that is too large, over limit")
 	}
-	root, err := bitwiseMerkleize(chunks, uint64(len(chunks)), objLen)
+	layer := getChunks(numItems)
+	defer putChunks(layer)
+	err = parallelFor(numItems, hashWorkers(numItems), func(start, end int) error {
+		for i := start; i < end; i++ {
+			if nilAuditEnabled() {
+				auditNilValue("root", fmt.Sprintf("%s[%d]", fieldName, i), val.Index(i), typ.Elem())
+			}
+			r, err := factory.Root(val.Index(i), typ.Elem(), fieldName, 0)
+			if err != nil {
+				return err
+			}
+			(*layer)[i] = r
+		}
+		return nil
+	})
 	if err != nil {
 		return [32]byte{}, err
 	}
-	return mixInLength(root, output), nil
+	root := merkleizeLayer(*layer, merkle.GetDepth(limit), hashWorkers(numItems))
+	return hashing.MixInLength(root, uint64(numItems)), nil
 }
 
 func (b *compositeSliceSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
@@ -112,8 +109,19 @@ func (b *compositeSliceSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input
 
 	currentIndex := startOffset
 	nextIndex := currentIndex
+	if startOffset+BytesPerLengthOffset > endOffset {
+		return 0, fmt.Errorf("input of %d bytes is too short for an offset at %d", endOffset, startOffset)
+	}
 	offsetVal := input[startOffset : startOffset+BytesPerLengthOffset]
-	firstOffset := startOffset + uint64(binary.LittleEndian.Uint32(offsetVal))
+	// The first offset gives the size of the offsets, and so the number of elements.
+	first := uint64(binary.LittleEndian.Uint32(offsetVal))
+	if first == 0 || first%BytesPerLengthOffset != 0 {
+		return 0, &FirstOffsetError{Offset: first}
+	}
+	if err := checkOffset(0, first, 0, first, endOffset-startOffset); err != nil {
+		return 0, err
+	}
+	firstOffset := startOffset + first
 	currentOffset := firstOffset
 	nextOffset := currentOffset
 	i := 0
@@ -125,8 +133,8 @@ func (b *compositeSliceSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input
 			nextOffsetVal := input[nextIndex : nextIndex+BytesPerLengthOffset]
 			nextOffset = startOffset + uint64(binary.LittleEndian.Uint32(nextOffsetVal))
 		}
-		if nextOffset < currentOffset {
-			break
+		if err := checkOffset(i+1, nextOffset-startOffset, currentOffset-startOffset, first, endOffset-startOffset); err != nil {
+			return 0, err
 		}
 		// We grow the slice's size to accommodate a new element being unmarshaled.
 		growConcreteSliceType(val, typ, i+1)
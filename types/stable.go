@@ -0,0 +1,498 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/prysmaticlabs/go-bitfield"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// stableTagPrefix starts the tag of the marker field of a stable container.
+const stableTagPrefix = "stable,max="
+
+// profileTag is the tag of the marker field of a profile, and optionalTag the tag of
+// its optional fields.
+const (
+	profileTag  = "profile"
+	optionalTag = "optional"
+)
+
+type stableContainerSSZ struct{}
+
+func newStableContainerSSZ() *stableContainerSSZ {
+	return &stableContainerSSZ{}
+}
+
+// IsStableContainer returns true if typ is a struct type encoded as an EIP-7495
+// StableContainer, which is the case when its first field is a blank marker declaring
+// the maximum number of fields the container may ever have:
+//
+//  type StableBody struct {
+//      _          struct{} `ssz:"stable,max=64"`
+//      Slot       *uint64
+//      Checkpoint *Checkpoint
+//      Deposits   []*Deposit `ssz-max:"16"`
+//  }
+//
+// Every other field is optional, and must be a pointer, slice or map, which is absent
+// when nil. Serialization starts with the bitvector of active fields, followed by the
+// active fields encoded as a container. The root is the root of the field roots, with
+// zero chunks for absent fields and padded to the maximum number of fields, with the
+// root of the active fields bitvector mixed in. Fields can be appended to a stable
+// container without changing the generalized indices of the existing ones.
+func IsStableContainer(typ reflect.Type) bool {
+	return isStableContainerType(typ)
+}
+
+// IsProfile returns true if typ is a struct type encoded as an EIP-7495 Profile of a
+// stable container, which is the case when its first field is a blank pointer to the
+// stable container:
+//
+//  type Phase0Body struct {
+//      _          *StableBody `ssz:"profile"`
+//      Slot       uint64
+//      Checkpoint *Checkpoint `ssz:"optional"`
+//  }
+//
+// The fields of a profile are a subset of the fields of the stable container, with the
+// same names and in the same order. They are required unless tagged as optional, in
+// which case they must be pointers, slices or maps which are absent when nil. Required
+// fields are always serialized, and only optional fields have a bit in the leading
+// active fields bitvector, which is omitted when there are none. A profile has the same
+// root as the stable container holding the same values, so roots and generalized
+// indices are stable across forks which constrain the container differently.
+func IsProfile(typ reflect.Type) bool {
+	return isProfileType(typ)
+}
+
+func isStableContainerType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.NumField() == 0 || typ.Field(0).Name != "_" {
+		return false
+	}
+	tag, ok := typ.Field(0).Tag.Lookup("ssz")
+	return ok && strings.HasPrefix(tag, stableTagPrefix)
+}
+
+func isProfileType(typ reflect.Type) bool {
+	if typ.Kind() != reflect.Struct || typ.NumField() == 0 || typ.Field(0).Name != "_" {
+		return false
+	}
+	tag, ok := typ.Field(0).Tag.Lookup("ssz")
+	return ok && tag == profileTag
+}
+
+// isStableType returns true for the types encoded by the stable container factory.
+func isStableType(typ reflect.Type) bool {
+	return isStableContainerType(typ) || isProfileType(typ)
+}
+
+// stableLayout describes how the fields of a stable container or profile are encoded.
+type stableLayout struct {
+	// capacity is the maximum number of fields of the stable container.
+	capacity uint64
+	// bits is the length of the serialized active fields bitvector.
+	bits   uint64
+	fields []stableField
+}
+
+// stableField is a field of a stable container or profile.
+type stableField struct {
+	index    int
+	name     string
+	typ      reflect.Type
+	capacity uint64
+	// position is the index of the field in the stable container, which is both its
+	// chunk index and its bit in the active fields bitvector that is hashed.
+	position uint64
+	// optional fields have a bit in the serialized active fields bitvector.
+	optional bool
+	bit      uint64
+}
+
+// numOptional returns the number of fields having a bit in the serialized bitvector.
+func (l *stableLayout) numOptional() uint64 {
+	n := uint64(0)
+	for _, f := range l.fields {
+		if f.optional {
+			n++
+		}
+	}
+	return n
+}
+
+// layoutOf returns the layout of a stable container or profile type.
+func layoutOf(typ reflect.Type) (*stableLayout, error) {
+	if isProfileType(typ) {
+		return profileLayout(typ)
+	}
+	tag := typ.Field(0).Tag.Get("ssz")
+	capacity, err := strconv.ParseUint(strings.TrimPrefix(tag, stableTagPrefix), 10, 64)
+	if err != nil || capacity == 0 {
+		return nil, fmt.Errorf("invalid stable container tag %q of %v", tag, typ)
+	}
+	layout := &stableLayout{capacity: capacity, bits: capacity}
+	for i := 1; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(f) {
+			continue
+		}
+		if !isNillable(f.Type) {
+			return nil, fmt.Errorf("field %s of stable container %v must be a pointer, slice or map", f.Name, typ)
+		}
+		field, err := newStableField(f, i)
+		if err != nil {
+			return nil, err
+		}
+		field.position = uint64(len(layout.fields))
+		field.optional, field.bit = true, field.position
+		layout.fields = append(layout.fields, field)
+	}
+	if uint64(len(layout.fields)) > capacity {
+		return nil, fmt.Errorf("stable container %v has %d fields, over its maximum of %d", typ, len(layout.fields), capacity)
+	}
+	return layout, nil
+}
+
+func profileLayout(typ reflect.Type) (*stableLayout, error) {
+	baseTyp := typ.Field(0).Type
+	if baseTyp.Kind() != reflect.Ptr || !isStableContainerType(baseTyp.Elem()) {
+		return nil, fmt.Errorf("profile %v must point to a stable container, not %v", typ, baseTyp)
+	}
+	base, err := layoutOf(baseTyp.Elem())
+	if err != nil {
+		return nil, err
+	}
+	baseFields := make(map[string]stableField, len(base.fields))
+	for _, f := range base.fields {
+		baseFields[f.name] = f
+	}
+	layout := &stableLayout{capacity: base.capacity}
+	for i := 1; i < typ.NumField(); i++ {
+		f := typ.Field(i)
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(f) {
+			continue
+		}
+		baseField, ok := baseFields[f.Name]
+		if !ok {
+			return nil, fmt.Errorf("field %s of profile %v is not a field of %v", f.Name, typ, baseTyp.Elem())
+		}
+		if n := len(layout.fields); n > 0 && layout.fields[n-1].position > baseField.position {
+			return nil, fmt.Errorf("field %s of profile %v is out of the order of %v", f.Name, typ, baseTyp.Elem())
+		}
+		field, err := newStableField(f, i)
+		if err != nil {
+			return nil, err
+		}
+		if field.valueType() != baseField.valueType() {
+			return nil, fmt.Errorf("field %s of profile %v is encoded as %v, wanted %v", f.Name, typ, field.valueType(), baseField.valueType())
+		}
+		field.capacity, field.position = baseField.capacity, baseField.position
+		if tag, ok := f.Tag.Lookup("ssz"); ok && tag == optionalTag {
+			if !isNillable(f.Type) {
+				return nil, fmt.Errorf("optional field %s of profile %v must be a pointer, slice or map", f.Name, typ)
+			}
+			field.optional, field.bit = true, layout.bits
+			layout.bits++
+		}
+		layout.fields = append(layout.fields, field)
+	}
+	return layout, nil
+}
+
+func newStableField(f reflect.StructField, index int) (stableField, error) {
+	fType, err := determineFieldType(f)
+	if err != nil {
+		return stableField{}, err
+	}
+	if f.Type == bitlistType {
+		fType = f.Type
+	}
+	return stableField{index: index, name: f.Name, typ: fType, capacity: determineFieldCapacity(f)}, nil
+}
+
+// valueType returns the type the value of a field is encoded as.
+func (f stableField) valueType() reflect.Type {
+	if f.typ.Kind() == reflect.Ptr {
+		return f.typ.Elem()
+	}
+	return f.typ
+}
+
+func isNillable(typ reflect.Type) bool {
+	kind := typ.Kind()
+	return kind == reflect.Ptr || kind == reflect.Slice || kind == reflect.Map
+}
+
+// stableValue returns the value held by a field along with the type to encode it as,
+// and whether the field is active. A nil required field stands for its zero value.
+func stableValue(val reflect.Value, f stableField) (reflect.Value, reflect.Type, bool) {
+	field := val.Field(f.index)
+	if f.optional && field.IsNil() {
+		return reflect.Value{}, nil, false
+	}
+	if field.Kind() == reflect.Ptr {
+		if field.IsNil() {
+			return reflect.New(f.typ.Elem()).Elem(), f.typ.Elem(), true
+		}
+		return field.Elem(), f.typ.Elem(), true
+	}
+	return field, f.typ, true
+}
+
+// activeBitsLength returns the number of bytes of the serialized active fields
+// bitvector.
+func (l *stableLayout) activeBitsLength() uint64 {
+	return (l.bits + 7) / 8
+}
+
+func (s *stableContainerSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
+	if typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			instance := reflect.New(typ.Elem()).Elem()
+			return s.Root(instance, instance.Type(), fieldName, maxCapacity)
+		}
+		return s.Root(val.Elem(), typ.Elem(), fieldName, maxCapacity)
+	}
+	layout, err := layoutOf(typ)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	// Profiles are hashed as their stable container, so chunks and active bits are
+	// laid out by field position.
+	activeBits := make([]byte, (layout.capacity+7)/8)
+	var roots [][]byte
+	for _, f := range layout.fields {
+		v, t, ok := stableValue(val, f)
+		if !ok {
+			continue
+		}
+		activeBits[f.position/8] |= 1 << (f.position % 8)
+		var r [32]byte
+		if t == bitlistType {
+			r, err = BitlistRoot(v.Interface().(bitfield.Bitlist), f.capacity)
+		} else {
+			var factory SSZAble
+			if factory, err = SSZFactory(v, t); err == nil {
+				r, err = factory.Root(v, t, typ.Name()+"."+f.name, f.capacity)
+			}
+		}
+		if err != nil {
+			return [32]byte{}, withFieldPath(err, typ.Field(f.index))
+		}
+		for uint64(len(roots)) <= f.position {
+			roots = append(roots, make([]byte, 32))
+		}
+		copy(roots[f.position], r[:])
+	}
+	fieldsRoot, err := bitwiseMerkleize(roots, uint64(len(roots)), layout.capacity)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	activeChunks := make([][]byte, 0, (len(activeBits)+31)/32)
+	for i := 0; i < len(activeBits); i += 32 {
+		chunk := make([]byte, 32)
+		copy(chunk, activeBits[i:])
+		activeChunks = append(activeChunks, chunk)
+	}
+	activeRoot, err := bitwiseMerkleize(activeChunks, uint64(len(activeChunks)), (layout.capacity+255)/256)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	return hashing.HashPair(fieldsRoot, activeRoot), nil
+}
+
+func (s *stableContainerSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
+	layout, err := layoutOf(typ)
+	if err != nil {
+		return 0, err
+	}
+	fixedLength := uint64(0)
+	for _, f := range layout.fields {
+		v, t, ok := stableValue(val, f)
+		if !ok {
+			continue
+		}
+		if f.optional {
+			buf[startOffset+f.bit/8] |= 1 << (f.bit % 8)
+		}
+		if isVariableSizeType(t) {
+			fixedLength += BytesPerLengthOffset
+		} else {
+			fixedLength += determineFixedSize(v, t)
+		}
+	}
+	containerOffset := startOffset + layout.activeBitsLength()
+	fixedIndex := containerOffset
+	currentOffsetIndex := containerOffset + fixedLength
+	for _, f := range layout.fields {
+		v, t, ok := stableValue(val, f)
+		if !ok {
+			continue
+		}
+		factory, err := SSZFactory(v, t)
+		if err != nil {
+			return 0, withFieldPath(err, typ.Field(f.index))
+		}
+		if !isVariableSizeType(t) {
+			if fixedIndex, err = factory.Marshal(v, t, buf, fixedIndex); err != nil {
+				return 0, withFieldPath(err, typ.Field(f.index))
+			}
+			continue
+		}
+		binary.LittleEndian.PutUint32(buf[fixedIndex:fixedIndex+BytesPerLengthOffset], uint32(currentOffsetIndex-containerOffset))
+		fixedIndex += BytesPerLengthOffset
+		if currentOffsetIndex, err = factory.Marshal(v, t, buf, currentOffsetIndex); err != nil {
+			return 0, withFieldPath(err, typ.Field(f.index))
+		}
+	}
+	return currentOffsetIndex, nil
+}
+
+func (s *stableContainerSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
+	layout, err := layoutOf(typ)
+	if err != nil {
+		return 0, err
+	}
+	activeLength := layout.activeBitsLength()
+	if uint64(len(input)) < startOffset+activeLength {
+		return 0, fmt.Errorf("%v is missing its active fields bitvector", typ)
+	}
+	activeBits := input[startOffset : startOffset+activeLength]
+	for i := layout.numOptional(); i < 8*activeLength; i++ {
+		if activeBits[i/8]&(1<<(i%8)) != 0 {
+			return 0, fmt.Errorf("%v has no optional field at active index %d", typ, i)
+		}
+	}
+	// Active fields are decoded into fresh values, and absent ones are reset to nil.
+	type activeField struct {
+		stableField
+		value    reflect.Value
+		valueTyp reflect.Type
+		size     uint64
+	}
+	active := make([]activeField, 0, len(layout.fields))
+	fixedLength := uint64(0)
+	for _, f := range layout.fields {
+		field := val.Field(f.index)
+		field.Set(reflect.Zero(field.Type()))
+		if f.optional && activeBits[f.bit/8]&(1<<(f.bit%8)) == 0 {
+			continue
+		}
+		a := activeField{stableField: f, value: field, valueTyp: f.typ}
+		if field.Kind() == reflect.Ptr {
+			ptr := reflect.New(f.typ.Elem())
+			field.Set(ptr)
+			a.value, a.valueTyp = ptr.Elem(), f.typ.Elem()
+		} else if field.Kind() == reflect.Slice && f.typ.Kind() == reflect.Array {
+			sizes, _, err := parseSSZFieldTags(typ.Field(f.index))
+			if err != nil {
+				return 0, err
+			}
+			field.Set(growSliceFromSizeTags(field, sizes))
+		}
+		if isVariableSizeType(a.valueTyp) {
+			fixedLength += BytesPerLengthOffset
+		} else {
+			a.size = determineFixedSize(reflect.New(a.valueTyp).Elem(), a.valueTyp)
+			fixedLength += a.size
+		}
+		active = append(active, a)
+	}
+	container := input[startOffset+activeLength:]
+	if fixedLength > uint64(len(container)) {
+		return 0, fmt.Errorf("%v has %d bytes, wanted at least %d", typ, len(container), fixedLength)
+	}
+	// Offsets are read ahead of decoding, so that each variable-size field is given
+	// exactly the bytes up to the next one.
+	var offsets []uint64
+	index := uint64(0)
+	for _, a := range active {
+		if !isVariableSizeType(a.valueTyp) {
+			index += a.size
+			continue
+		}
+		offset := uint64(binary.LittleEndian.Uint32(container[index : index+BytesPerLengthOffset]))
+		previous := uint64(0)
+		if len(offsets) > 0 {
+			previous = offsets[len(offsets)-1]
+		}
+		if err := checkOffset(len(offsets), offset, previous, fixedLength, uint64(len(container))); err != nil {
+			return 0, withFieldPath(err, typ.Field(a.index))
+		}
+		offsets = append(offsets, offset)
+		index += BytesPerLengthOffset
+	}
+	if len(offsets) == 0 && fixedLength != uint64(len(container)) {
+		return 0, fmt.Errorf("%v has %d bytes, wanted %d", typ, len(container), fixedLength)
+	}
+	offsets = append(offsets, uint64(len(container)))
+	index = 0
+	next := 0
+	for _, a := range active {
+		factory, err := SSZFactory(a.value, a.valueTyp)
+		if err != nil {
+			return 0, withFieldPath(err, typ.Field(a.index))
+		}
+		var data []byte
+		if isVariableSizeType(a.valueTyp) {
+			data = container[offsets[next]:offsets[next+1]]
+			next++
+			index += BytesPerLengthOffset
+		} else {
+			data = container[index : index+a.size]
+			index += a.size
+		}
+		if _, err := factory.Unmarshal(a.value, a.valueTyp, data, 0); err != nil {
+			return 0, withFieldPath(err, typ.Field(a.index))
+		}
+		// An active optional list is kept distinct from an absent one even when empty.
+		if a.optional && a.value.Kind() == reflect.Slice && a.value.IsNil() {
+			a.value.Set(reflect.MakeSlice(a.value.Type(), 0, 0))
+		}
+		if a.optional && a.value.Kind() == reflect.Map && a.value.IsNil() {
+			a.value.Set(reflect.MakeMap(a.value.Type()))
+		}
+	}
+	return uint64(len(input)), nil
+}
+
+// isVariableProfile returns true if a profile has optional fields or variable-size
+// required fields, in which case it is referenced through an offset when nested.
+func isVariableProfile(typ reflect.Type) bool {
+	layout, err := layoutOf(typ)
+	if err != nil {
+		return true
+	}
+	for _, f := range layout.fields {
+		if f.optional || isVariableSizeType(f.valueType()) {
+			return true
+		}
+	}
+	return false
+}
+
+// stableSize returns the serialized size of a stable container or profile value.
+func stableSize(val reflect.Value, typ reflect.Type) uint64 {
+	layout, err := layoutOf(typ)
+	if err != nil {
+		return 0
+	}
+	size := layout.activeBitsLength()
+	for _, f := range layout.fields {
+		v, t, ok := stableValue(val, f)
+		if !ok {
+			continue
+		}
+		if isVariableSizeType(t) {
+			size += BytesPerLengthOffset
+		}
+		size += SizeOf(v, t)
+	}
+	return size
+}
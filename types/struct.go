@@ -16,6 +16,26 @@ import (
 // is chosen as the default value given its simplicity to represent unbounded size.
 var UnboundedSSZFieldSizeMarker = "?"
 
+// skipTag excludes a struct field from serialization and hashing, so that types can
+// carry in-memory-only state, such as caches, without changing their encoding or root:
+//
+//  type Validator struct {
+//      Pubkey  []byte `ssz-size:"48"`
+//      Balance uint64
+//      Cache   *ValidatorCache `ssz:"-"`
+//  }
+const skipTag = "-"
+
+// SkipField returns true if a struct field is not part of the SSZ representation of its
+// struct, that is, a protobuf metadata field or a field tagged ssz:"-".
+func SkipField(field reflect.StructField) bool {
+	if strings.HasPrefix(field.Name, "XXX_") {
+		return true
+	}
+	tag, ok := field.Tag.Lookup("ssz")
+	return ok && tag == skipTag
+}
+
 type structSSZ struct{}
 
 func newStructSSZ() *structSSZ {
@@ -28,45 +48,63 @@ func (b *structSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string,
 			instance := reflect.New(typ.Elem()).Elem()
 			return b.Root(instance, instance.Type(), fieldName, maxCapacity)
 		}
+		if root, ok := PinnedRoot(val); ok {
+			return root, nil
+		}
 		return b.Root(val.Elem(), typ.Elem(), fieldName, maxCapacity)
 	}
 	numFields := typ.NumField()
 	return b.FieldsHasher(val, typ, numFields)
 }
 
+// FieldTrace is called before the root of a field of a container is computed, and
+// returns the function called with the outcome of the computation.
+type FieldTrace func(field reflect.StructField) func(err error)
+
+// TracedRoot computes the root of a container like Root, calling trace around the
+// computation of the root of each of its fields.
+func (b *structSSZ) TracedRoot(val reflect.Value, typ reflect.Type, trace FieldTrace) ([32]byte, error) {
+	if typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			instance := reflect.New(typ.Elem()).Elem()
+			return b.TracedRoot(instance, instance.Type(), trace)
+		}
+		if root, ok := PinnedRoot(val); ok {
+			return root, nil
+		}
+		return b.TracedRoot(val.Elem(), typ.Elem(), trace)
+	}
+	return b.fieldsHasher(val, typ, typ.NumField(), trace)
+}
+
 func (b *structSSZ) FieldsHasher(val reflect.Value, typ reflect.Type, numFields int) ([32]byte, error) {
-	roots := make([][]byte, numFields)
-	var err error
+	return b.fieldsHasher(val, typ, numFields, nil)
+}
+
+func (b *structSSZ) fieldsHasher(val reflect.Value, typ reflect.Type, numFields int, trace FieldTrace) ([32]byte, error) {
+	roots := make([][]byte, 0, numFields)
 	totalCountedFields := uint64(0)
-	structName := typ.Name()
-	for i := 0; i < numFields; i++ {
-		// We skip protobuf related metadata fields.
-		if strings.HasPrefix(typ.Field(i).Name, "XXX_") {
+	// Fields are cached by name, so that leaving their names out bypasses the caches.
+	_, uncached := uncachedTypes.Load(typ)
+	// Only the first numFields serialized fields are hashed, skipped fields aside.
+	for i := 0; i < typ.NumField() && totalCountedFields < uint64(numFields); i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(typ.Field(i)) {
 			continue
 		}
 		totalCountedFields++
-		fCapacity := determineFieldCapacity(typ.Field(i))
-		if b, ok := val.Field(i).Interface().(bitfield.Bitlist); ok {
-			r, err := BitlistRoot(b, fCapacity)
-			if err != nil {
-				return [32]byte{}, nil
-			}
-			roots[i] = r[:]
-			continue
-		}
-		fType, err := determineFieldType(typ.Field(i))
-		if err != nil {
-			return [32]byte{}, err
+		var end func(error)
+		if trace != nil {
+			end = trace(typ.Field(i))
 		}
-		factory, err := SSZFactory(val.Field(i), fType)
-		if err != nil {
-			return [32]byte{}, err
+		r, err := b.fieldRoot(val, typ, i, uncached)
+		if end != nil {
+			end(err)
 		}
-		r, err := factory.Root(val.Field(i), fType, structName+"."+typ.Field(i).Name, fCapacity)
 		if err != nil {
 			return [32]byte{}, err
 		}
-		roots[i] = r[:]
+		roots = append(roots, r[:])
 	}
 	root, err := bitwiseMerkleize(roots, totalCountedFields, totalCountedFields)
 	if err != nil {
@@ -75,6 +113,63 @@ func (b *structSSZ) FieldsHasher(val reflect.Value, typ reflect.Type, numFields
 	return root, nil
 }
 
+// fieldRoot computes the root of the i-th field of a container. Uncached fields are
+// hashed without the caches keyed by field name.
+func (b *structSSZ) fieldRoot(val reflect.Value, typ reflect.Type, i int, uncached bool) ([32]byte, error) {
+	structName := typ.Name()
+	fCapacity := determineFieldCapacity(typ.Field(i))
+	if b, ok := val.Field(i).Interface().(bitfield.Bitlist); ok {
+		r, err := BitlistRoot(b, fCapacity)
+		if err != nil {
+			return [32]byte{}, withFieldPath(err, typ.Field(i))
+		}
+		return r, nil
+	}
+	fType, err := determineFieldType(typ.Field(i))
+	if err != nil {
+		return [32]byte{}, err
+	}
+	if nilAuditEnabled() {
+		auditNilValue("root", structName+"."+typ.Field(i).Name, val.Field(i), fType)
+	}
+	if err := checkListLimit(val.Field(i), fType, fCapacity); err != nil {
+		return [32]byte{}, withFieldPath(err, typ.Field(i))
+	}
+	if IsProgressive(typ.Field(i)) {
+		r, err := ProgressiveListRoot(val.Field(i), fType)
+		if err != nil {
+			return [32]byte{}, withFieldPath(err, typ.Field(i))
+		}
+		return r, nil
+	}
+	fieldVal, err := vectorValue(val.Field(i), fType)
+	if err != nil {
+		return [32]byte{}, withFieldPath(err, typ.Field(i))
+	}
+	if elemCapacities := elementCapacities(typ.Field(i)); elemCapacities != nil && !hasCodec(fType) {
+		// Lists nested in the elements have limits of their own, which the factories
+		// of their types do not know about.
+		r, err := (&Hasher{}).nestedRoot(fieldVal, fType, fCapacity, elemCapacities)
+		if err != nil {
+			return [32]byte{}, withFieldPath(err, typ.Field(i))
+		}
+		return r, nil
+	}
+	factory, err := SSZFactory(fieldVal, fType)
+	if err != nil {
+		return [32]byte{}, withFieldPath(err, typ.Field(i))
+	}
+	name := structName + "." + typ.Field(i).Name
+	if uncached {
+		name = ""
+	}
+	r, err := factory.Root(fieldVal, fType, name, fCapacity)
+	if err != nil {
+		return [32]byte{}, withFieldPath(err, typ.Field(i))
+	}
+	return r, nil
+}
+
 func (b *structSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
 	if typ.Kind() == reflect.Ptr {
 		if val.IsNil() {
@@ -85,52 +180,58 @@ func (b *structSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, sta
 	}
 	fixedIndex := startOffset
 	fixedLength := uint64(0)
+	// Vectors declared through ssz-size tags are padded to their declared length.
+	fields := make([]reflect.Value, typ.NumField())
 	// For every field, we add up the total length of the items depending if they
 	// are variable or fixed-size fields.
 	for i := 0; i < typ.NumField(); i++ {
-		// We skip protobuf related metadata fields.
-		if strings.Contains(typ.Field(i).Name, "XXX_") {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(typ.Field(i)) {
 			continue
 		}
 		fType, err := determineFieldType(typ.Field(i))
 		if err != nil {
 			return 0, err
 		}
+		if fields[i], err = vectorValue(val.Field(i), fType); err != nil {
+			return 0, withFieldPath(err, typ.Field(i))
+		}
 		if isVariableSizeType(fType) {
 			fixedLength += BytesPerLengthOffset
 		} else {
-			if val.Type().Kind() == reflect.Ptr && val.IsNil() {
-				elem := reflect.New(val.Type().Elem()).Elem()
-				fixedLength += determineFixedSize(elem, fType)
-			} else {
-				fixedLength += determineFixedSize(val.Field(i), fType)
-			}
+			fixedLength += determineFixedSize(fields[i], fType)
 		}
 	}
 	currentOffsetIndex := startOffset + fixedLength
 	nextOffsetIndex := currentOffsetIndex
 	for i := 0; i < typ.NumField(); i++ {
-		// We skip protobuf related metadata fields.
-		if strings.Contains(typ.Field(i).Name, "XXX_") {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(typ.Field(i)) {
 			continue
 		}
 		fType, err := determineFieldType(typ.Field(i))
 		if err != nil {
 			return 0, err
 		}
-		factory, err := SSZFactory(val.Field(i), fType)
+		if nilAuditEnabled() {
+			auditNilValue("marshal", typ.Name()+"."+typ.Field(i).Name, val.Field(i), fType)
+		}
+		factory, err := SSZFactory(fields[i], fType)
 		if err != nil {
-			return 0, err
+			return 0, withFieldPath(err, typ.Field(i))
+		}
+		if err := checkBitvectorField(fields[i], typ.Field(i)); err != nil {
+			return 0, withFieldPath(err, typ.Field(i))
 		}
 		if !isVariableSizeType(fType) {
-			fixedIndex, err = factory.Marshal(val.Field(i), fType, buf, fixedIndex)
+			fixedIndex, err = factory.Marshal(fields[i], fType, buf, fixedIndex)
 			if err != nil {
-				return 0, err
+				return 0, withFieldPath(err, typ.Field(i))
 			}
 		} else {
-			nextOffsetIndex, err = factory.Marshal(val.Field(i), fType, buf, currentOffsetIndex)
+			nextOffsetIndex, err = factory.Marshal(fields[i], fType, buf, currentOffsetIndex)
 			if err != nil {
-				return 0, err
+				return 0, withFieldPath(err, typ.Field(i))
 			}
 			// Write the offset.
 			offsetBuf := make([]byte, BytesPerLengthOffset)
@@ -155,23 +256,23 @@ func (b *structSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte,
 	endOffset := uint64(len(input))
 	currentIndex := startOffset
 	nextIndex := currentIndex
-	numFields := 0
-
-	for i := 0; i < typ.NumField(); i++ {
-		// We skip protobuf related metadata fields.
-		if strings.Contains(typ.Field(i).Name, "XXX_") {
-			continue
-		}
-		numFields++
-	}
+	numFields := typ.NumField()
 
 	fixedSizes := make(map[int]uint64)
+	// fixedPartSize is the size of the fixed-size fields and of the offsets of the
+	// variable-size ones.
+	fixedPartSize := uint64(0)
 	for i := 0; i < numFields; i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(typ.Field(i)) {
+			continue
+		}
 		fType, err := determineFieldType(typ.Field(i))
 		if err != nil {
 			return 0, err
 		}
 		if isVariableSizeType(fType) {
+			fixedPartSize += BytesPerLengthOffset
 			continue
 		}
 		if val.Field(i).Kind() == reflect.Ptr {
@@ -193,26 +294,40 @@ func (b *structSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte,
 		}
 		fixedSz := determineFixedSize(concreteVal, fType)
 		fixedSizes[i] = fixedSz
+		fixedPartSize += fixedSz
 	}
 
 	offsets := make([]uint64, 0)
 	offsetIndexCounter := startOffset
 	for i := 0; i < numFields; i++ {
+		if SkipField(typ.Field(i)) {
+			continue
+		}
 		if item, ok := fixedSizes[i]; ok {
 			offsetIndexCounter += item
 		} else {
-			if offsetIndexCounter+BytesPerLengthOffset > uint64(len(input)) {
-				offsetIndexCounter += BytesPerLengthOffset
-				continue
+			if offsetIndexCounter+BytesPerLengthOffset > endOffset {
+				return 0, withFieldPath(errors.Errorf("input of %d bytes ends within the offset at %d", endOffset, offsetIndexCounter), typ.Field(i))
 			}
 			offsetVal := input[offsetIndexCounter : offsetIndexCounter+BytesPerLengthOffset]
-			offsets = append(offsets, startOffset+uint64(binary.LittleEndian.Uint32(offsetVal)))
+			offset := uint64(binary.LittleEndian.Uint32(offsetVal))
+			previous := uint64(0)
+			if len(offsets) > 0 {
+				previous = offsets[len(offsets)-1] - startOffset
+			}
+			if err := checkOffset(len(offsets), offset, previous, fixedPartSize, endOffset-startOffset); err != nil {
+				return 0, withFieldPath(err, typ.Field(i))
+			}
+			offsets = append(offsets, startOffset+offset)
 			offsetIndexCounter += BytesPerLengthOffset
 		}
 	}
 	offsets = append(offsets, endOffset)
 	offsetIndex := uint64(0)
 	for i := 0; i < numFields; i++ {
+		if SkipField(typ.Field(i)) {
+			continue
+		}
 		fType, err := determineFieldType(typ.Field(i))
 		if err != nil {
 			return 0, err
@@ -222,26 +337,43 @@ func (b *structSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte,
 		}
 		factory, err := SSZFactory(val.Field(i), fType)
 		if err != nil {
-			return 0, err
+			return 0, withFieldPath(err, typ.Field(i))
 		}
 		if item, ok := fixedSizes[i]; ok {
 			if item == 0 {
 				continue
 			}
 			nextIndex = currentIndex + item
+			if nextIndex > endOffset {
+				return 0, withFieldPath(errors.Errorf("input of %d bytes ends within field at %d", endOffset, currentIndex), typ.Field(i))
+			}
 			if _, err := factory.Unmarshal(val.Field(i), fType, input[currentIndex:nextIndex], 0); err != nil {
-				return 0, err
+				return 0, withFieldPath(err, typ.Field(i))
+			}
+			if err := checkBitvectorField(val.Field(i), typ.Field(i)); err != nil {
+				return 0, withFieldPath(err, typ.Field(i))
 			}
 			currentIndex = nextIndex
 		} else {
 			firstOff := offsets[offsetIndex]
+			nextOff := offsets[offsetIndex+1]
+			if typ.Field(i).Type == bitlistType {
+				if err := checkBitlist(input[firstOff:nextOff], determineFieldCapacity(typ.Field(i))); err != nil {
+					return 0, withFieldPath(err, typ.Field(i))
+				}
+			}
 			if firstOff == uint64(len(input)) {
 				currentIndex += BytesPerLengthOffset
 				continue
 			}
-			nextOff := offsets[offsetIndex+1]
+			if err := checkEncodedListLimit(input[firstOff:nextOff], fType, determineFieldCapacity(typ.Field(i))); err != nil {
+				return 0, withFieldPath(err, typ.Field(i))
+			}
 			if _, err := factory.Unmarshal(val.Field(i), fType, input[firstOff:nextOff], 0); err != nil {
-				return 0, err
+				return 0, withFieldPath(err, typ.Field(i))
+			}
+			if err := checkNestedLimits(val.Field(i), fType, elementCapacities(typ.Field(i))); err != nil {
+				return 0, withFieldPath(err, typ.Field(i))
 			}
 			offsetIndex++
 			currentIndex += BytesPerLengthOffset
@@ -250,12 +382,126 @@ func (b *structSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte,
 	return currentIndex, nil
 }
 
+// FieldType returns the type a struct field is serialized as, which differs from its
+// declared type when ssz-size tags are used.
+func FieldType(field reflect.StructField) (reflect.Type, error) {
+	return determineFieldType(field)
+}
+
+// FieldCapacity returns the list limit declared by the ssz-max tag of a struct field,
+// or 0 if the field has no such tag.
+func FieldCapacity(field reflect.StructField) uint64 {
+	return determineFieldCapacity(field)
+}
+
+// FieldCapacities returns the list limits declared by the ssz-max tag of a struct field,
+// the first one bounding the field and the following ones the lists nested in it, with
+// 0 for levels which are not bounded. It returns nil if the field has no such tag.
+func FieldCapacities(field reflect.StructField) []uint64 {
+	return determineFieldCapacities(field)
+}
+
+// ContainerField describes the serialization of a field of a container.
+type ContainerField struct {
+	// Index is the index of the field in its Go struct.
+	Index int
+	// Name is the name of the field in its Go struct.
+	Name string
+	// Type is the type the field is serialized as.
+	Type reflect.Type
+	// Variable is set for fields of variable size, which are referenced through an
+	// offset in the fixed-size part of the container.
+	Variable bool
+	// Size is the serialized size of fixed-size fields.
+	Size uint64
+}
+
+// ContainerFields returns the serialized fields of a struct type in order, skipped
+// fields aside, which lets callers locate a field in an encoded container without
+// decoding the others.
+func ContainerFields(typ reflect.Type) ([]ContainerField, error) {
+	fields := make([]ContainerField, 0, typ.NumField())
+	for i := 0; i < typ.NumField(); i++ {
+		// We skip protobuf related metadata fields and fields tagged ssz:"-".
+		if SkipField(typ.Field(i)) {
+			continue
+		}
+		fType, err := determineFieldType(typ.Field(i))
+		if err != nil {
+			return nil, withFieldPath(err, typ.Field(i))
+		}
+		f := ContainerField{Index: i, Name: typ.Field(i).Name, Type: fType}
+		if isVariableSizeType(fType) {
+			f.Variable = true
+		} else {
+			f.Size = determineFixedSize(reflect.New(fType).Elem(), fType)
+		}
+		fields = append(fields, f)
+	}
+	return fields, nil
+}
+
+// UnmarshalField decodes the serialization of the i-th field of the struct val, as
+// located through ContainerFields, into that field.
+func UnmarshalField(val reflect.Value, i int, input []byte) error {
+	field := val.Type().Field(i)
+	fType, err := determineFieldType(field)
+	if err != nil {
+		return withFieldPath(err, field)
+	}
+	variable := isVariableSizeType(fType)
+	if variable && len(input) == 0 {
+		// Empty lists are left to their zero value, as in a whole container.
+		return nil
+	}
+	if val.Field(i).Kind() == reflect.Ptr {
+		instantiateConcreteTypeForElement(val.Field(i), fType.Elem())
+	}
+	if !variable && val.Field(i).Kind() == reflect.Slice {
+		sszSizeTags, hasTags, err := parseSSZFieldTags(field)
+		if err != nil {
+			return err
+		}
+		if hasTags {
+			// If the item is a slice, we grow it accordingly based on the size tags.
+			val.Field(i).Set(growSliceFromSizeTags(val.Field(i), sszSizeTags))
+		}
+	}
+	if variable {
+		if err := checkEncodedListLimit(input, fType, determineFieldCapacity(field)); err != nil {
+			return withFieldPath(err, field)
+		}
+	}
+	factory, err := SSZFactory(val.Field(i), fType)
+	if err != nil {
+		return withFieldPath(err, field)
+	}
+	if _, err := factory.Unmarshal(val.Field(i), fType, input, 0); err != nil {
+		return withFieldPath(err, field)
+	}
+	if !variable {
+		if err := checkBitvectorField(val.Field(i), field); err != nil {
+			return withFieldPath(err, field)
+		}
+	} else if err := checkNestedLimits(val.Field(i), fType, elementCapacities(field)); err != nil {
+		return withFieldPath(err, field)
+	}
+	return nil
+}
+
 func determineFieldType(field reflect.StructField) (reflect.Type, error) {
 	fieldSizeTags, exists, err := parseSSZFieldTags(field)
 	if err != nil {
 		return nil, errors.Wrap(err, "could not parse ssz struct field tags")
 	}
 	if exists {
+		// Sizes become array lengths, which are only 32 bits wide on platforms such
+		// as 32-bit ARM.
+		for _, size := range fieldSizeTags {
+			if size > maxInt {
+				return nil, errors.Errorf("ssz-size %d of field %s exceeds the maximum array length of this platform", size, field.Name)
+			}
+		}
 		// If the field does indeed specify ssz struct tags, we infer the field's type.
 		return inferFieldTypeFromSizeTags(field, fieldSizeTags), nil
 	}
@@ -263,20 +509,59 @@ func determineFieldType(field reflect.StructField) (reflect.Type, error) {
 }
 
 func determineFieldCapacity(field reflect.StructField) uint64 {
+	capacities := determineFieldCapacities(field)
+	if len(capacities) == 0 {
+		return 0
+	}
+	return capacities[0]
+}
+
+// determineFieldCapacities returns the limits of the ssz-max tag of a field, one per
+// level of nesting like the sizes of ssz-size tags, such as `ssz-max:"1048576,1073741824"`
+// for a list of transactions which are byte lists. Levels which are not lists are marked
+// with UnboundedSSZFieldSizeMarker, and have a limit of 0.
+func determineFieldCapacities(field reflect.StructField) []uint64 {
 	tag, exists := field.Tag.Lookup("ssz-max")
 	if !exists {
-		return 0
+		return nil
 	}
-	val, err := strconv.ParseUint(tag, 10, 64)
-	if err != nil {
-		return 0
+	items := strings.Split(tag, ",")
+	capacities := make([]uint64, len(items))
+	for i, item := range items {
+		if item == UnboundedSSZFieldSizeMarker {
+			continue
+		}
+		c, err := strconv.ParseUint(item, 10, 64)
+		if err != nil {
+			return nil
+		}
+		capacities[i] = c
+	}
+	return capacities
+}
+
+// elementCapacities returns the limits of the lists nested in the elements of a field,
+// or nil if its ssz-max tag only bounds the field itself.
+func elementCapacities(field reflect.StructField) []uint64 {
+	capacities := determineFieldCapacities(field)
+	if len(capacities) < 2 {
+		return nil
+	}
+	for _, c := range capacities[1:] {
+		if c != 0 {
+			return capacities[1:]
+		}
 	}
-	return val
+	return nil
 }
 
 func parseSSZFieldTags(field reflect.StructField) ([]uint64, bool, error) {
 	tag, exists := field.Tag.Lookup("ssz-size")
 	if !exists {
+		// Bitvectors are byte vectors whose size is given by their type.
+		if n, ok := BitvectorLength(field.Type); ok {
+			return []uint64{(n + 7) / 8}, true, nil
+		}
 		return nil, false, nil
 	}
 	items := strings.Split(tag, ",")
@@ -311,3 +596,62 @@ func inferFieldTypeFromSizeTags(field reflect.StructField, sizes []uint64) refle
 	}
 	return currentType
 }
+
+// vectorValue returns the value of a slice typed as the vector typ through ssz-size
+// tags, with every vector, nested ones and those held by lists included, of exactly its
+// declared length. Shorter values, such as nil slices, are padded with zero values,
+// while longer ones are rejected. Other values are returned as is.
+func vectorValue(val reflect.Value, typ reflect.Type) (reflect.Value, error) {
+	if !isSequenceOfSlices(val, typ) {
+		return val, nil
+	}
+	exact, err := isExactVector(val, typ)
+	if err != nil || exact {
+		return val, err
+	}
+	n := val.Len()
+	if typ.Kind() == reflect.Array {
+		n = typ.Len()
+	}
+	padded := reflect.MakeSlice(val.Type(), n, n)
+	reflect.Copy(padded, val)
+	for i := 0; i < padded.Len(); i++ {
+		elem, err := vectorValue(padded.Index(i), typ.Elem())
+		if err != nil {
+			return val, err
+		}
+		padded.Index(i).Set(elem)
+	}
+	return padded, nil
+}
+
+// isExactVector returns true if the vectors typed through ssz-size tags within a slice
+// already have their declared lengths.
+func isExactVector(val reflect.Value, typ reflect.Type) (bool, error) {
+	if !isSequenceOfSlices(val, typ) {
+		return true, nil
+	}
+	exact := true
+	if typ.Kind() == reflect.Array {
+		if val.Len() > typ.Len() {
+			return false, errors.Errorf("expected vector of length %d, received %d", typ.Len(), val.Len())
+		}
+		exact = val.Len() == typ.Len()
+	}
+	elemKind := typ.Elem().Kind()
+	if (elemKind != reflect.Array && elemKind != reflect.Slice) || val.Type().Elem().Kind() != reflect.Slice {
+		return exact, nil
+	}
+	for i := 0; i < val.Len(); i++ {
+		ok, err := isExactVector(val.Index(i), typ.Elem())
+		if err != nil {
+			return false, errors.Wrapf(err, "[%d]", i)
+		}
+		exact = exact && ok
+	}
+	return exact, nil
+}
+
+func isSequenceOfSlices(val reflect.Value, typ reflect.Type) bool {
+	return val.Kind() == reflect.Slice && (typ.Kind() == reflect.Array || typ.Kind() == reflect.Slice)
+}
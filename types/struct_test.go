@@ -79,3 +79,12 @@ func TestParseSSZFieldTags_HandlesOverflow(t *testing.T) {
 		t.Errorf("got: %d, wanted %d", result, want)
 	}
 }
+
+func TestDetermineFieldType_SizeExceedsPlatformLimit(t *testing.T) {
+	input := struct {
+		Data []byte `ssz-size:"18446744073709551615"`
+	}{}
+	if _, err := determineFieldType(reflect.TypeOf(input).Field(0)); err == nil {
+		t.Error("Expected error for a size which does not fit in an int")
+	}
+}
@@ -0,0 +1,102 @@
+package types
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+)
+
+// Uint128 is a 128-bit unsigned integer, held in its little-endian SSZ serialization.
+// As a byte vector of 16 bytes, it is serialized and hashed as the uint128 basic type:
+// its root is its serialization right-padded to a single chunk.
+type Uint128 [16]byte
+
+// Uint256 is a 256-bit unsigned integer, held in its little-endian SSZ serialization,
+// such as the base fee of execution payloads:
+//
+//  type ExecutionPayload struct {
+//      ...
+//      BaseFeePerGas types.Uint256
+//  }
+//
+// Its root is its serialization. Values of the [4]uint64 form used by
+// github.com/holiman/uint256, least significant word first, have the same
+// serialization and root, and need no conversion.
+type Uint256 [32]byte
+
+var (
+	uint128Type = reflect.TypeOf(Uint128{})
+	uint256Type = reflect.TypeOf(Uint256{})
+)
+
+// IsWideUint returns true if typ is Uint128 or Uint256.
+func IsWideUint(typ reflect.Type) bool {
+	return typ == uint128Type || typ == uint256Type
+}
+
+// NewUint128 returns the Uint128 holding n, or an error if n does not fit in 128 bits.
+func NewUint128(n *big.Int) (Uint128, error) {
+	var u Uint128
+	err := putLittleEndian(u[:], n)
+	return u, err
+}
+
+// NewUint256 returns the Uint256 holding n, or an error if n does not fit in 256 bits.
+func NewUint256(n *big.Int) (Uint256, error) {
+	var u Uint256
+	err := putLittleEndian(u[:], n)
+	return u, err
+}
+
+// Big returns the value of u.
+func (u Uint128) Big() *big.Int {
+	return fromLittleEndian(u[:])
+}
+
+// String returns the decimal representation of u.
+func (u Uint128) String() string {
+	return u.Big().String()
+}
+
+// Big returns the value of u.
+func (u Uint256) Big() *big.Int {
+	return fromLittleEndian(u[:])
+}
+
+// String returns the decimal representation of u.
+func (u Uint256) String() string {
+	return u.Big().String()
+}
+
+// SetWideUint parses the decimal representation of a Uint128 or Uint256 into val.
+func SetWideUint(val reflect.Value, s string) error {
+	n, ok := new(big.Int).SetString(s, 10)
+	if !ok {
+		return fmt.Errorf("invalid decimal integer %q", s)
+	}
+	buf := make([]byte, val.Len())
+	if err := putLittleEndian(buf, n); err != nil {
+		return err
+	}
+	reflect.Copy(val, reflect.ValueOf(buf))
+	return nil
+}
+
+func putLittleEndian(buf []byte, n *big.Int) error {
+	if n.Sign() < 0 || n.BitLen() > len(buf)*8 {
+		return fmt.Errorf("%v does not fit in uint%d", n, len(buf)*8)
+	}
+	be := n.Bytes()
+	for i := range be {
+		buf[i] = be[len(be)-1-i]
+	}
+	return nil
+}
+
+func fromLittleEndian(buf []byte) *big.Int {
+	be := make([]byte, len(buf))
+	for i := range buf {
+		be[len(buf)-1-i] = buf[i]
+	}
+	return new(big.Int).SetBytes(be)
+}
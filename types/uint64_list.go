@@ -0,0 +1,118 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// Uint64List holds an SSZ List[uint64, limit], such as the balances of a beacon state,
+// along with the Merkle tree of its packed 32-byte chunks. Changing a value re-packs the
+// chunk of four values holding it and re-hashes its branch, rather than re-packing and
+// re-merkleizing the whole list:
+//
+//  balances, err := types.NewUint64List(state.Balances, ValidatorRegistryLimit)
+//  if err != nil {
+//      return err
+//  }
+//  if err := balances.Set(i, balances.Get(i)+reward); err != nil {
+//      return err
+//  }
+//  root := balances.Root()
+type Uint64List struct {
+	values []uint64
+	limit  uint64
+	chunks *RootsList
+}
+
+// uint64sPerChunk is the number of uint64 values packed into a chunk.
+const uint64sPerChunk = 32 / 8
+
+// NewUint64List returns a list of at most limit values, holding a copy of values.
+func NewUint64List(values []uint64, limit uint64) (*Uint64List, error) {
+	if uint64(len(values)) > limit {
+		return nil, fmt.Errorf("list of length %d exceeds limit %d", len(values), limit)
+	}
+	l := &Uint64List{
+		values: append(make([]uint64, 0, len(values)), values...),
+		limit:  limit,
+	}
+	leaves := make([][32]byte, (len(values)+uint64sPerChunk-1)/uint64sPerChunk)
+	for i := range leaves {
+		leaves[i] = l.chunk(i)
+	}
+	chunks, err := NewRootsList(leaves, (limit+uint64sPerChunk-1)/uint64sPerChunk)
+	if err != nil {
+		return nil, err
+	}
+	l.chunks = chunks
+	return l, nil
+}
+
+// Len returns the number of values.
+func (l *Uint64List) Len() int {
+	return len(l.values)
+}
+
+// Get returns the i-th value.
+func (l *Uint64List) Get(i int) uint64 {
+	return l.values[i]
+}
+
+// Values returns the values of the list, which must not be modified.
+func (l *Uint64List) Values() []uint64 {
+	return l.values
+}
+
+// Set replaces the i-th value.
+func (l *Uint64List) Set(i int, v uint64) error {
+	if i < 0 || i >= len(l.values) {
+		return fmt.Errorf("index %d out of range for list of length %d", i, len(l.values))
+	}
+	if l.values[i] == v {
+		return nil
+	}
+	l.values[i] = v
+	return l.chunks.Set(i/uint64sPerChunk, l.chunk(i/uint64sPerChunk))
+}
+
+// Append adds a value at the end of the list, starting a new chunk every four values.
+func (l *Uint64List) Append(v uint64) error {
+	i := len(l.values)
+	if uint64(i) >= l.limit {
+		return fmt.Errorf("list reached its limit %d", l.limit)
+	}
+	if i%uint64sPerChunk != 0 {
+		l.values = append(l.values, v)
+		return l.chunks.Set(i/uint64sPerChunk, l.chunk(i/uint64sPerChunk))
+	}
+	if err := l.chunks.Append(uint64Chunk(v)); err != nil {
+		return err
+	}
+	l.values = append(l.values, v)
+	return nil
+}
+
+// Root returns the hash tree root of the list, including its length.
+func (l *Uint64List) Root() [32]byte {
+	return hashing.MixInLength(l.chunks.DataRoot(), uint64(len(l.values)))
+}
+
+// chunk packs the values of the i-th chunk.
+func (l *Uint64List) chunk(i int) [32]byte {
+	var chunk [32]byte
+	for j, v := range l.values[i*uint64sPerChunk:] {
+		if j == uint64sPerChunk {
+			break
+		}
+		binary.LittleEndian.PutUint64(chunk[j*8:], v)
+	}
+	return chunk
+}
+
+func uint64Chunk(v uint64) [32]byte {
+	var chunk [32]byte
+	binary.LittleEndian.PutUint64(chunk[:], v)
+	return chunk
+}
@@ -0,0 +1,54 @@
+package types
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestUint64List_MatchesHashTreeRoot(t *testing.T) {
+	const limit = 1 << 20
+	want := func(values []uint64) [32]byte {
+		v := reflect.ValueOf(values)
+		root, err := newBasicSliceSSZ().Root(v, v.Type(), "", limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return root
+	}
+	values := []uint64{1, 2, 3, 4, 5}
+	list, err := NewUint64List(values, limit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if list.Root() != want(values) {
+		t.Fatal("Wrong root for new list")
+	}
+	for i := uint64(6); i < 15; i++ {
+		values = append(values, i)
+		if err := list.Append(i); err != nil {
+			t.Fatal(err)
+		}
+		if list.Root() != want(values) {
+			t.Fatalf("Wrong root after appending %d values", len(values))
+		}
+	}
+	for _, i := range []int{0, 3, 4, 13} {
+		values[i] = 32000000000 + uint64(i)
+		if err := list.Set(i, values[i]); err != nil {
+			t.Fatal(err)
+		}
+		if list.Root() != want(values) || list.Get(i) != values[i] {
+			t.Fatalf("Wrong root after setting value %d", i)
+		}
+	}
+	if err := list.Set(len(values), 0); err == nil {
+		t.Error("Expected error setting a value out of range")
+	}
+	full, err := NewUint64List([]uint64{1, 2, 3, 4, 5}, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := full.Append(6); err == nil {
+		t.Error("Expected error appending past the limit")
+	}
+}
@@ -0,0 +1,173 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// Union is implemented by struct types encoding an SSZ union. Every field of such a
+// struct is a pointer to one of the variants of the union, in selector order, and at
+// most one of them is set at a time:
+//
+//  type Transaction struct {
+//      Legacy *LegacyTransaction
+//      Blob   *BlobTransaction
+//  }
+//
+//  func (Transaction) SSZUnion() {}
+//
+// The first field may be of type *UnionNone, standing for the None variant, which is
+// selected when no field is set. A union is serialized as its selector byte followed by
+// the serialization of the selected variant, and its root is the root of the variant
+// with the selector mixed in.
+type Union interface {
+	SSZUnion()
+}
+
+// UnionNone is the type of the None variant of a union.
+type UnionNone struct{}
+
+var unionType = reflect.TypeOf((*Union)(nil)).Elem()
+var unionNoneType = reflect.TypeOf(&UnionNone{})
+
+type unionSSZ struct{}
+
+func newUnionSSZ() *unionSSZ {
+	return &unionSSZ{}
+}
+
+// IsUnion returns true if typ is a struct type encoded as an SSZ union.
+func IsUnion(typ reflect.Type) bool {
+	return isUnionType(typ)
+}
+
+// isUnionType returns true if typ is a struct type encoded as an SSZ union.
+func isUnionType(typ reflect.Type) bool {
+	return typ.Kind() == reflect.Struct && (typ.Implements(unionType) || reflect.PtrTo(typ).Implements(unionType))
+}
+
+// unionVariant returns the selector of the variant set in a union value, along with the
+// variant value, which is invalid for the None variant.
+func unionVariant(val reflect.Value, typ reflect.Type) (uint8, reflect.Value, error) {
+	if typ.NumField() == 0 || typ.NumField() > 128 {
+		return 0, reflect.Value{}, fmt.Errorf("union %v must have between 1 and 128 variants", typ)
+	}
+	selector := -1
+	for i := 0; i < typ.NumField(); i++ {
+		if typ.Field(i).Type.Kind() != reflect.Ptr {
+			return 0, reflect.Value{}, fmt.Errorf("variant %s of union %v is not a pointer", typ.Field(i).Name, typ)
+		}
+		if i > 0 && typ.Field(i).Type == unionNoneType {
+			return 0, reflect.Value{}, fmt.Errorf("only the first variant of union %v can be None", typ)
+		}
+		if val.Field(i).IsNil() {
+			continue
+		}
+		if selector >= 0 {
+			return 0, reflect.Value{}, fmt.Errorf("union %v has both variants %s and %s set", typ, typ.Field(selector).Name, typ.Field(i).Name)
+		}
+		selector = i
+	}
+	if selector < 0 {
+		if typ.Field(0).Type != unionNoneType {
+			return 0, reflect.Value{}, fmt.Errorf("union %v has no variant set", typ)
+		}
+		return 0, reflect.Value{}, nil
+	}
+	if typ.Field(selector).Type == unionNoneType {
+		return 0, reflect.Value{}, nil
+	}
+	return uint8(selector), val.Field(selector).Elem(), nil
+}
+
+func (u *unionSSZ) Root(val reflect.Value, typ reflect.Type, fieldName string, maxCapacity uint64) ([32]byte, error) {
+	if typ.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			instance := reflect.New(typ.Elem()).Elem()
+			return u.Root(instance, instance.Type(), fieldName, maxCapacity)
+		}
+		return u.Root(val.Elem(), typ.Elem(), fieldName, maxCapacity)
+	}
+	selector, variant, err := unionVariant(val, typ)
+	if err != nil {
+		return [32]byte{}, err
+	}
+	var root [32]byte
+	if variant.IsValid() {
+		factory, err := SSZFactory(variant, variant.Type())
+		if err != nil {
+			return [32]byte{}, err
+		}
+		if root, err = factory.Root(variant, variant.Type(), "", 0); err != nil {
+			return [32]byte{}, err
+		}
+	}
+	var selectorChunk [32]byte
+	selectorChunk[0] = selector
+	return hashing.HashPair(root, selectorChunk), nil
+}
+
+func (u *unionSSZ) Marshal(val reflect.Value, typ reflect.Type, buf []byte, startOffset uint64) (uint64, error) {
+	selector, variant, err := unionVariant(val, typ)
+	if err != nil {
+		return 0, err
+	}
+	buf[startOffset] = selector
+	if !variant.IsValid() {
+		return startOffset + 1, nil
+	}
+	factory, err := SSZFactory(variant, variant.Type())
+	if err != nil {
+		return 0, err
+	}
+	return factory.Marshal(variant, variant.Type(), buf, startOffset+1)
+}
+
+func (u *unionSSZ) Unmarshal(val reflect.Value, typ reflect.Type, input []byte, startOffset uint64) (uint64, error) {
+	if startOffset >= uint64(len(input)) {
+		return 0, errors.New("union is missing its selector")
+	}
+	selector := int(input[startOffset])
+	if selector >= typ.NumField() {
+		return 0, fmt.Errorf("selector %d out of range for union %v of %d variants", selector, typ, typ.NumField())
+	}
+	for i := 0; i < typ.NumField(); i++ {
+		val.Field(i).Set(reflect.Zero(typ.Field(i).Type))
+	}
+	variantTyp := typ.Field(selector).Type
+	if variantTyp == unionNoneType {
+		if uint64(len(input)) != startOffset+1 {
+			return 0, fmt.Errorf("none variant of union %v followed by %d bytes", typ, uint64(len(input))-startOffset-1)
+		}
+		return startOffset + 1, nil
+	}
+	if variantTyp.Kind() != reflect.Ptr {
+		return 0, fmt.Errorf("variant %s of union %v is not a pointer", typ.Field(selector).Name, typ)
+	}
+	variant := reflect.New(variantTyp.Elem())
+	factory, err := SSZFactory(variant.Elem(), variantTyp.Elem())
+	if err != nil {
+		return 0, err
+	}
+	remaining := uint64(len(input)) - startOffset - 1
+	if !isVariableSizeType(variantTyp.Elem()) && determineFixedSize(variant.Elem(), variantTyp.Elem()) != remaining {
+		return 0, fmt.Errorf("variant %s of union %v has %d bytes, wanted %d", typ.Field(selector).Name, typ, remaining, determineFixedSize(variant.Elem(), variantTyp.Elem()))
+	}
+	if _, err := factory.Unmarshal(variant.Elem(), variantTyp.Elem(), input[startOffset+1:], 0); err != nil {
+		return 0, err
+	}
+	val.Field(selector).Set(variant)
+	return uint64(len(input)), nil
+}
+
+// unionSize returns the serialized size of a union value.
+func unionSize(val reflect.Value, typ reflect.Type) uint64 {
+	_, variant, err := unionVariant(val, typ)
+	if err != nil || !variant.IsValid() {
+		return 1
+	}
+	return 1 + DetermineSize(variant)
+}
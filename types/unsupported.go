@@ -0,0 +1,118 @@
+package types
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// UnsupportedTypeError is returned when a value contains a type which has no SSZ
+// representation. Libraries embedding this codec can retrieve it with errors.Cause
+// to show their own users which field to change, and how.
+type UnsupportedTypeError struct {
+	// Type is the offending type, nested as deep as it was found.
+	Type reflect.Type
+	// Path is the chain of struct fields leading to the offending type, relative to
+	// the outermost value, such as "Body.Deposits". It is empty for top-level values.
+	Path string
+	// Suggestion describes how to make the type serializable.
+	Suggestion string
+}
+
+// Error keeps the message format of the codec, while the path and suggestion are
+// available as fields.
+func (e *UnsupportedTypeError) Error() string {
+	return fmt.Sprintf("unsupported kind: %v", e.Type.Kind())
+}
+
+func newUnsupportedTypeError(typ reflect.Type) *UnsupportedTypeError {
+	var suggestion string
+	switch typ.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int64:
+		suggestion = "SSZ has no signed integers, use an unsigned integer type of the same width"
+	case reflect.Uint, reflect.Uintptr:
+		suggestion = "use a fixed-width unsigned integer type such as uint64"
+	case reflect.Float32, reflect.Float64, reflect.Complex64, reflect.Complex128:
+		suggestion = "SSZ has no floating point types, store the value as a fixed-point uint64"
+	case reflect.Interface:
+		suggestion = "use a concrete struct type, or a pointer to one"
+	case reflect.Map:
+		if mapCodecEnabled() {
+			suggestion = "only maps with uint64 keys are supported"
+		} else {
+			suggestion = "enable the map codec with ToggleMapCodec, or use a sorted list of key-value containers"
+		}
+	default:
+		suggestion = "wrap the value in a struct made of supported types, or tag the field with ssz-size"
+	}
+	return &UnsupportedTypeError{Type: typ, Suggestion: suggestion}
+}
+
+// withFieldPath prepends the name of a struct field to the path of an unsupported
+// type, exceeded limit, invalid offset or invalid bitlist error found within that
+// field.
+func withFieldPath(err error, field reflect.StructField) error {
+	switch e := err.(type) {
+	case *UnsupportedTypeError:
+		e.Path = joinFieldPath(field.Name, e.Path)
+	case *LimitExceededError:
+		e.Path = joinFieldPath(field.Name, e.Path)
+	case *FirstOffsetError:
+		e.Path = joinFieldPath(field.Name, e.Path)
+	case *OffsetOrderError:
+		e.Path = joinFieldPath(field.Name, e.Path)
+	case *OffsetOutOfBoundsError:
+		e.Path = joinFieldPath(field.Name, e.Path)
+	case *BitlistDelimiterError:
+		e.Path = joinFieldPath(field.Name, e.Path)
+	}
+	return err
+}
+
+func joinFieldPath(name string, path string) string {
+	if path == "" {
+		return name
+	}
+	return name + "." + path
+}
+
+// CheckType walks a type, along with the types of its fields and elements, and returns
+// an UnsupportedTypeError for the first one which has no SSZ representation.
+func CheckType(typ reflect.Type) error {
+	return checkType(typ, make(map[reflect.Type]bool))
+}
+
+func checkType(typ reflect.Type, visited map[reflect.Type]bool) error {
+	kind := typ.Kind()
+	switch {
+	case isBasicType(kind) || kind == reflect.String:
+		return nil
+	case kind == reflect.Ptr || kind == reflect.Slice || kind == reflect.Array:
+		return checkType(typ.Elem(), visited)
+	case kind == reflect.Map:
+		if !mapCodecEnabled() || typ.Key().Kind() != reflect.Uint64 {
+			return newUnsupportedTypeError(typ)
+		}
+		return checkType(typ.Elem(), visited)
+	case kind == reflect.Struct:
+		if visited[typ] {
+			return nil
+		}
+		visited[typ] = true
+		for i := 0; i < typ.NumField(); i++ {
+			// We skip protobuf related metadata fields and fields tagged ssz:"-".
+			if SkipField(typ.Field(i)) {
+				continue
+			}
+			fType, err := determineFieldType(typ.Field(i))
+			if err != nil {
+				return err
+			}
+			if err := checkType(fType, visited); err != nil {
+				return withFieldPath(err, typ.Field(i))
+			}
+		}
+		return nil
+	default:
+		return newUnsupportedTypeError(typ)
+	}
+}
@@ -0,0 +1,164 @@
+package types
+
+import (
+	"encoding/binary"
+	"fmt"
+
+	"github.com/protolambda/zssz/merkle"
+	"github.com/prysmaticlabs/go-ssz/internal/hashing"
+)
+
+// ValidatorSize is the serialized size of a beacon chain Validator container.
+const ValidatorSize = 121
+
+// ValidatorColumns holds a validator registry column by column, such that the i-th
+// validator is made of the i-th element of every column. Epoch processing and analytics
+// typically scan a couple of fields across the whole registry, which is far more cache
+// friendly with columns than with a slice of structs, and avoids allocating a struct and
+// its padding per validator.
+type ValidatorColumns struct {
+	Pubkeys                     [][48]byte
+	WithdrawalCredentials       [][32]byte
+	EffectiveBalances           []uint64
+	Slashed                     []bool
+	ActivationEligibilityEpochs []uint64
+	ActivationEpochs            []uint64
+	ExitEpochs                  []uint64
+	WithdrawableEpochs          []uint64
+}
+
+// UnmarshalValidatorColumns decodes a serialized List[Validator] into columns.
+//
+//  cols, err := types.UnmarshalValidatorColumns(registry)
+//  if err != nil {
+//      return errors.Wrap(err, "could not decode validators")
+//  }
+//  for i, balance := range cols.EffectiveBalances {
+//      ...
+//  }
+func UnmarshalValidatorColumns(buf []byte) (*ValidatorColumns, error) {
+	if len(buf)%ValidatorSize != 0 {
+		return nil, fmt.Errorf("validator list of %d bytes is not a multiple of the validator size %d", len(buf), ValidatorSize)
+	}
+	n := len(buf) / ValidatorSize
+	c := &ValidatorColumns{
+		Pubkeys:                     make([][48]byte, n),
+		WithdrawalCredentials:       make([][32]byte, n),
+		EffectiveBalances:           make([]uint64, n),
+		Slashed:                     make([]bool, n),
+		ActivationEligibilityEpochs: make([]uint64, n),
+		ActivationEpochs:            make([]uint64, n),
+		ExitEpochs:                  make([]uint64, n),
+		WithdrawableEpochs:          make([]uint64, n),
+	}
+	for i := 0; i < n; i++ {
+		v := buf[i*ValidatorSize : (i+1)*ValidatorSize]
+		copy(c.Pubkeys[i][:], v[0:48])
+		copy(c.WithdrawalCredentials[i][:], v[48:80])
+		c.EffectiveBalances[i] = binary.LittleEndian.Uint64(v[80:88])
+		switch v[88] {
+		case 0:
+		case 1:
+			c.Slashed[i] = true
+		default:
+			return nil, fmt.Errorf("validator %d: expected 0 or 1 but received %d", i, v[88])
+		}
+		c.ActivationEligibilityEpochs[i] = binary.LittleEndian.Uint64(v[89:97])
+		c.ActivationEpochs[i] = binary.LittleEndian.Uint64(v[97:105])
+		c.ExitEpochs[i] = binary.LittleEndian.Uint64(v[105:113])
+		c.WithdrawableEpochs[i] = binary.LittleEndian.Uint64(v[113:121])
+	}
+	return c, nil
+}
+
+// Len returns the number of validators.
+func (c *ValidatorColumns) Len() int {
+	return len(c.Pubkeys)
+}
+
+func (c *ValidatorColumns) validate() error {
+	n := len(c.Pubkeys)
+	if len(c.WithdrawalCredentials) != n || len(c.EffectiveBalances) != n || len(c.Slashed) != n ||
+		len(c.ActivationEligibilityEpochs) != n || len(c.ActivationEpochs) != n ||
+		len(c.ExitEpochs) != n || len(c.WithdrawableEpochs) != n {
+		return fmt.Errorf("validator columns have mismatched lengths")
+	}
+	return nil
+}
+
+// Marshal serializes the columns as a List[Validator].
+func (c *ValidatorColumns) Marshal() ([]byte, error) {
+	if err := c.validate(); err != nil {
+		return nil, err
+	}
+	buf := make([]byte, c.Len()*ValidatorSize)
+	for i := 0; i < c.Len(); i++ {
+		v := buf[i*ValidatorSize : (i+1)*ValidatorSize]
+		copy(v[0:48], c.Pubkeys[i][:])
+		copy(v[48:80], c.WithdrawalCredentials[i][:])
+		binary.LittleEndian.PutUint64(v[80:88], c.EffectiveBalances[i])
+		if c.Slashed[i] {
+			v[88] = 1
+		}
+		binary.LittleEndian.PutUint64(v[89:97], c.ActivationEligibilityEpochs[i])
+		binary.LittleEndian.PutUint64(v[97:105], c.ActivationEpochs[i])
+		binary.LittleEndian.PutUint64(v[105:113], c.ExitEpochs[i])
+		binary.LittleEndian.PutUint64(v[113:121], c.WithdrawableEpochs[i])
+	}
+	return buf, nil
+}
+
+// ValidatorRoot returns the hash tree root of the i-th validator.
+func (c *ValidatorColumns) ValidatorRoot(i int) [32]byte {
+	var fields [8][32]byte
+	var pubkeyChunks [2][32]byte
+	copy(pubkeyChunks[0][:], c.Pubkeys[i][:32])
+	copy(pubkeyChunks[1][:], c.Pubkeys[i][32:])
+	fields[0] = hashing.HashPair(pubkeyChunks[0], pubkeyChunks[1])
+	fields[1] = c.WithdrawalCredentials[i]
+	binary.LittleEndian.PutUint64(fields[2][:], c.EffectiveBalances[i])
+	if c.Slashed[i] {
+		fields[3][0] = 1
+	}
+	binary.LittleEndian.PutUint64(fields[4][:], c.ActivationEligibilityEpochs[i])
+	binary.LittleEndian.PutUint64(fields[5][:], c.ActivationEpochs[i])
+	binary.LittleEndian.PutUint64(fields[6][:], c.ExitEpochs[i])
+	binary.LittleEndian.PutUint64(fields[7][:], c.WithdrawableEpochs[i])
+	for n := len(fields); n > 1; n /= 2 {
+		for j := 0; j < n/2; j++ {
+			fields[j] = hashing.HashPair(fields[2*j], fields[2*j+1])
+		}
+	}
+	return fields[0]
+}
+
+// Root returns the hash tree root of the columns as a List[Validator, limit], where the
+// limit is typically ValidatorRegistryLimit.
+func (c *ValidatorColumns) Root(limit uint64) ([32]byte, error) {
+	if err := c.validate(); err != nil {
+		return [32]byte{}, err
+	}
+	if uint64(c.Len()) > limit {
+		return [32]byte{}, fmt.Errorf("validator list of length %d exceeds limit %d", c.Len(), limit)
+	}
+	depth := merkle.GetDepth(limit)
+	layer := make([][32]byte, c.Len())
+	for i := range layer {
+		layer[i] = c.ValidatorRoot(i)
+	}
+	root := hashing.ZeroHash(depth)
+	for d := uint8(0); d < depth && len(layer) > 0; d++ {
+		n := len(layer)
+		for i := 0; i < n/2; i++ {
+			layer[i] = hashing.HashPair(layer[2*i], layer[2*i+1])
+		}
+		if n%2 == 1 {
+			layer[n/2] = hashing.HashPair(layer[n-1], hashing.ZeroHash(d))
+		}
+		layer = layer[:(n+1)/2]
+	}
+	if len(layer) > 0 {
+		root = layer[0]
+	}
+	return hashing.MixInLength(root, uint64(c.Len())), nil
+}
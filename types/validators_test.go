@@ -0,0 +1,75 @@
+package types
+
+import (
+	"bytes"
+	"reflect"
+	"testing"
+)
+
+type testValidator struct {
+	Pubkey                     [48]byte
+	WithdrawalCredentials      [32]byte
+	EffectiveBalance           uint64
+	Slashed                    bool
+	ActivationEligibilityEpoch uint64
+	ActivationEpoch            uint64
+	ExitEpoch                  uint64
+	WithdrawableEpoch          uint64
+}
+
+func TestValidatorColumns_MatchesStructCodec(t *testing.T) {
+	validators := make([]testValidator, 5)
+	for i := range validators {
+		validators[i] = testValidator{
+			EffectiveBalance:           32e9 + uint64(i),
+			Slashed:                    i%2 == 1,
+			ActivationEligibilityEpoch: uint64(i),
+			ActivationEpoch:            uint64(i) + 1,
+			ExitEpoch:                  ^uint64(0),
+			WithdrawableEpoch:          ^uint64(0) - uint64(i),
+		}
+		validators[i].Pubkey[0], validators[i].Pubkey[47] = byte(i), 0xaa
+		validators[i].WithdrawalCredentials[31] = byte(i)
+	}
+	val, typ := reflect.ValueOf(validators), reflect.TypeOf(validators)
+	enc := make([]byte, DetermineSize(val))
+	if _, err := basicSliceFactory.Marshal(val, typ, enc, 0); err != nil {
+		t.Fatal(err)
+	}
+	cols, err := UnmarshalValidatorColumns(enc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cols.Len() != len(validators) || cols.EffectiveBalances[3] != 32e9+3 || !cols.Slashed[3] || cols.Pubkeys[4][0] != 4 {
+		t.Errorf("Unexpected columns %+v", cols)
+	}
+	output, err := cols.Marshal()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(output, enc) {
+		t.Errorf("Wanted serialization %#x, received %#x", enc, output)
+	}
+	want, err := basicSliceFactory.Root(val, typ, "", ValidatorRegistryLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := cols.Root(ValidatorRegistryLimit)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != want {
+		t.Errorf("Wanted root %#x, received %#x", want, got)
+	}
+}
+
+func TestUnmarshalValidatorColumns_InvalidInput(t *testing.T) {
+	if _, err := UnmarshalValidatorColumns(make([]byte, ValidatorSize+1)); err == nil {
+		t.Error("Expected error for a truncated validator")
+	}
+	enc := make([]byte, ValidatorSize)
+	enc[88] = 2
+	if _, err := UnmarshalValidatorColumns(enc); err == nil {
+		t.Error("Expected error for an invalid slashed flag")
+	}
+}
@@ -0,0 +1,68 @@
+package ssz
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+)
+
+// RootMismatchError is returned by UnmarshalVerified when decoded data does not have
+// the expected hash tree root.
+type RootMismatchError struct {
+	Expected [32]byte
+	Actual   [32]byte
+}
+
+func (e *RootMismatchError) Error() string {
+	return fmt.Sprintf("decoded data has root %#x, expected %#x", e.Actual, e.Expected)
+}
+
+// verifyHashers holds the hashers of UnmarshalVerified, whose scratch buffers are kept
+// across calls.
+var verifyHashers = sync.Pool{
+	New: func() interface{} {
+		return &Hasher{}
+	},
+}
+
+// UnmarshalVerified decodes input into the object pointed to by val, like Unmarshal,
+// and checks that the decoded value has the expected hash tree root, such as a block
+// received by root from a peer:
+//
+//  block := &SignedBeaconBlock{}
+//  if err := ssz.UnmarshalVerified(data, block, requestedRoot); err != nil {
+//      return errors.Wrap(err, "invalid block")
+//  }
+//
+// The object pointed to by val is only written once the root is verified, so that
+// unverified data is never observed by the caller. A mismatch is reported as a
+// *RootMismatchError.
+func UnmarshalVerified(input []byte, val interface{}, expectedRoot [32]byte) error {
+	if val == nil {
+		return errors.New("cannot unmarshal into untyped, nil value")
+	}
+	rval := reflect.ValueOf(val)
+	if rval.Kind() != reflect.Ptr {
+		return errors.New("can only unmarshal into a pointer target")
+	}
+	if rval.IsNil() {
+		return errors.New("cannot output to pointer of nil value")
+	}
+	decoded := reflect.New(rval.Type().Elem())
+	if err := Unmarshal(input, decoded.Interface()); err != nil {
+		return err
+	}
+	h := verifyHashers.Get().(*Hasher)
+	defer verifyHashers.Put(h)
+	root, err := HashTreeRootWith(decoded.Interface(), h)
+	if err != nil {
+		return err
+	}
+	if root != expectedRoot {
+		return &RootMismatchError{Expected: expectedRoot, Actual: root}
+	}
+	rval.Elem().Set(decoded.Elem())
+	return nil
+}